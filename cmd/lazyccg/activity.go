@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// A session whose screen changed in the last few seconds is RUNNING,
+// whatever its text says, unless the text shows it waiting on the user or
+// rate limited. Text is otherwise only trusted for the other statuses: the
+// words that mark RUNNING stay in the scrollback long after the agent
+// stopped.
+
+// defaultActivityWindow is how long after its screen last changed a session
+// counts as active.
+const defaultActivityWindow = 5 * time.Second
+
+func activityWindow() time.Duration {
+	if cfg.ActivityWindow > 0 {
+		return cfg.ActivityWindow
+	}
+	return defaultActivityWindow
+}
+
+// activeAt reports whether a screen that last changed between captures at
+// moved is still active at now.
+func activeAt(moved, now time.Time, window time.Duration) bool {
+	return !moved.IsZero() && now.Sub(moved) < window
+}
+
+// reportedStatus checks a status an agent reported at at, by hook or log,
+// against its screen, which last changed at moved. A RUNNING the screen has
+// been still for longer than the activity window since is dropped, as the
+// agent may have been stopped without saying so.
+func reportedStatus(status agentstatus.Status, at, moved, now time.Time) (agentstatus.Status, bool) {
+	if status == "" {
+		return "", false
+	}
+	if status == agentstatus.Running && now.Sub(at) >= activityWindow() && !activeAt(moved, now, activityWindow()) {
+		return "", false
+	}
+	return status, true
+}
+
+// activityWhy explains a status withActivity changed; textWhy is what the
+// text alone gave.
+func activityWhy(status agentstatus.Status, textWhy string) string {
+	if status == agentstatus.Running {
+		return "the screen changed in the last " + activityWindow().String()
+	}
+	return "the screen stopped changing, so this is old output: " + textWhy
+}
+
+// interruptHint reports whether recent, the last lines of the screen, show
+// "ctrl+c to interrupt", which is only up while the agent works. A rate
+// limit in lines beats it: agents keep the hint up while they retry.
+func interruptHint(recent, lines []string) bool {
+	text := strings.ToLower(strings.Join(recent, " "))
+	return strings.Contains(text, "ctrl+c to interrupt") && !agentstatus.RateLimitedOn(lines)
+}
+
+// withActivity combines what the text says with whether the screen is
+// changing. known is false for a session's first capture, when whether it
+// changes isn't known yet.
+func withActivity(known, active bool, status agentstatus.Status, source agentstatus.Source, confidence float64) (agentstatus.Status, agentstatus.Source, float64) {
+	switch {
+	case status.IsWaiting() || status == agentstatus.RateLimited:
+		// A prompt can show while a spinner still moves
+		return status, source, confidence
+	case active:
+		return agentstatus.Running, agentstatus.SourceActivity, agentstatus.Likely
+	case known && status == agentstatus.Running && source == agentstatus.SourceOutput:
+		// RUNNING words on a screen that stopped changing are old output
+		return agentstatus.Idle, agentstatus.SourceActivity, agentstatus.Likely
+	}
+	return status, source, confidence
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestWithActivity(t *testing.T) {
+	tests := []struct {
+		name          string
+		known, active bool
+		status        agentstatus.Status
+		source        agentstatus.Source
+		want          agentstatus.Status
+		wantSource    agentstatus.Source
+	}{
+		{"changing screen", true, true, agentstatus.Idle, agentstatus.SourceOutput, agentstatus.Running, agentstatus.SourceActivity},
+		{"prompt while a spinner moves", true, true, agentstatus.NeedsApproval, agentstatus.SourceOutput, agentstatus.NeedsApproval, agentstatus.SourceOutput},
+		{"rate limited while retrying", true, true, agentstatus.RateLimited, agentstatus.SourceOutput, agentstatus.RateLimited, agentstatus.SourceOutput},
+		{"old RUNNING words", true, false, agentstatus.Running, agentstatus.SourceOutput, agentstatus.Idle, agentstatus.SourceActivity},
+		{"RUNNING from a rule", true, false, agentstatus.Running, agentstatus.SourceRule, agentstatus.Running, agentstatus.SourceRule},
+		{"first capture", false, false, agentstatus.Running, agentstatus.SourceOutput, agentstatus.Running, agentstatus.SourceOutput},
+		{"quiet and done", true, false, agentstatus.Done, agentstatus.SourceOutput, agentstatus.Done, agentstatus.SourceOutput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, source, _ := withActivity(tt.known, tt.active, tt.status, tt.source, agentstatus.Likely)
+			if got != tt.want || source != tt.wantSource {
+				t.Errorf("withActivity() = %s (%s), want %s (%s)", got, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestActiveAt(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if activeAt(time.Time{}, now, 5*time.Second) {
+		t.Error("a screen that never changed is not active")
+	}
+	if !activeAt(now.Add(-4*time.Second), now, 5*time.Second) {
+		t.Error("a screen that changed 4s ago is active")
+	}
+	if activeAt(now.Add(-5*time.Second), now, 5*time.Second) {
+		t.Error("a screen that changed 5s ago is not active")
+	}
+}
+
+func TestInterruptHint(t *testing.T) {
+	working := []string{"⠙ Working on it (ctrl+c to interrupt)"}
+	if !interruptHint(working, working) {
+		t.Error("interruptHint() = false with the hint up")
+	}
+	retrying := []string{"API error: rate limit exceeded, retrying in 20s", "⠙ Working on it (ctrl+c to interrupt)"}
+	if interruptHint(retrying, retrying) {
+		t.Error("interruptHint() = true while retrying after a rate limit")
+	}
+	if interruptHint([]string{"> "}, []string{"> "}) {
+		t.Error("interruptHint() = true at a prompt")
+	}
+}
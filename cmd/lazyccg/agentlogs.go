@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// Codex and Gemini CLI write their sessions to disk as they go: Codex a
+// JSONL rollout per session, Gemini CLI a JSON chat per session. lazyccg
+// reads them, matched to windows by the file an agent process has open or
+// else by directory, for token usage, the last tool call, and status
+// changes the screen only hints at.
+
+// defaultAgentLogs are where the agents write their session logs, by AI.
+var defaultAgentLogs = map[string][]string{
+	"codex":  {"~/.codex/sessions/*/*/*/rollout-*.jsonl"},
+	"gemini": {"~/.gemini/tmp/*/chats/session-*.json"},
+}
+
+const (
+	logGlobEvery = 10 * time.Second // how often to look for new log files
+	logMaxAge    = 24 * time.Hour   // logs untouched for longer are left alone
+)
+
+// agentLogGlobs returns the globs ai's logs are found by; agent_logs in the
+// config overrides the defaults, and an empty list turns them off.
+func agentLogGlobs(ai string) []string {
+	if globs, ok := cfg.AgentLogs[ai]; ok {
+		return globs
+	}
+	return defaultAgentLogs[ai]
+}
+
+// agentLog is what is known from one session log.
+type agentLog struct {
+	path    string
+	cwd     string             // the session's directory, when the log says
+	project string             // Gemini CLI's hash of the directory
+	status  agentstatus.Status // "" when the log doesn't tell
+	tokens  int                // tokens used so far
+	action  string             // last tool call, e.g. "shell: go test ./..."
+	prompt  string             // the first prompt the agent was given
+	limit   usageLimit         // when a usage limit it hit resets
+	at      time.Time          // when the log was last written
+
+	// Reading position
+	offset  int64
+	size    int64
+	modTime time.Time
+}
+
+// matches reports whether the log is of a session in dir.
+func (l *agentLog) matches(dir string) bool {
+	return dir != "" && (l.cwd == dir || l.project == geminiProjectHash(dir))
+}
+
+// why describes what the log says, e.g. "rollout-….jsonl says RUNNING at
+// 14:03:05".
+func (l *agentLog) why() string {
+	return fmt.Sprintf("%s says %s at %s", filepath.Base(l.path), l.status, l.at.Format("15:04:05"))
+}
+
+// geminiProjectHash is the name Gemini CLI gives dir's directory under
+// ~/.gemini/tmp.
+func geminiProjectHash(dir string) string {
+	sum := sha256.Sum256([]byte(dir))
+	return hex.EncodeToString(sum[:])
+}
+
+// logTailer keeps reading the logs it has found from where it left off.
+// Between expanding its globs, a window still in the same directory is
+// only given the log it was found to have, or none. Polls run in tea.Cmds,
+// hence the lock.
+type logTailer struct {
+	mu      sync.Mutex
+	logs    map[string]*agentLog // by path
+	paths   map[string][]string  // AI -> recent log files
+	globbed map[string]time.Time // AI -> when its globs were last expanded
+	windows map[int]windowLog    // by window ID
+}
+
+// windowLog is the log found for a window in dir; path is "" for none.
+type windowLog struct {
+	dir, path string
+}
+
+var agentLogs = newLogTailer()
+
+func newLogTailer() *logTailer {
+	return &logTailer{
+		logs:    make(map[string]*agentLog),
+		paths:   make(map[string][]string),
+		globbed: make(map[string]time.Time),
+		windows: make(map[int]windowLog),
+	}
+}
+
+// lookup returns the log of ai's session in window, in dir and run by one
+// of pids: the log one of them has open, or else the latest log from dir.
+func (t *logTailer) lookup(ai string, window int, dir string, pids []int, now time.Time) (agentLog, bool) {
+	globs := agentLogGlobs(ai)
+	if len(globs) == 0 {
+		return agentLog{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.Sub(t.globbed[ai]) >= logGlobEvery {
+		t.paths[ai] = recentLogs(globs, now)
+		t.globbed[ai] = now
+	} else if w, ok := t.windows[window]; ok && w.dir == dir {
+		if l := t.logs[w.path]; l != nil && l.update() == nil {
+			return *l, true
+		}
+		return agentLog{}, false
+	}
+	l, ok := t.find(ai, dir, pids)
+	t.windows[window] = windowLog{dir: dir, path: l.path}
+	return l, ok
+}
+
+// find looks through ai's recent logs for the one in dir run by pids.
+func (t *logTailer) find(ai, dir string, pids []int) (agentLog, bool) {
+	open := openFiles(pids)
+	var best *agentLog
+	for _, path := range t.paths[ai] {
+		l := t.logs[path]
+		if l == nil {
+			l = &agentLog{path: path}
+			t.logs[path] = l
+		}
+		if err := l.update(); err != nil {
+			continue
+		}
+		if open[path] {
+			return *l, true
+		}
+		if l.matches(dir) && (best == nil || l.at.After(best.at)) {
+			best = l
+		}
+	}
+	if best == nil {
+		return agentLog{}, false
+	}
+	return *best, true
+}
+
+// screenOverLog reports whether status, read off the screen, stands over
+// what a log says: a prompt, since not every agent logs its approvals, or a
+// rate limit, since a turn retrying after a 429 is still running in the log.
+func screenOverLog(status agentstatus.Status) bool {
+	return status.IsWaiting() || status == agentstatus.RateLimited
+}
+
+// forget drops what is kept for the windows not in alive, and the logs that
+// no window still listed has or could be given.
+func (t *logTailer) forget(alive map[int]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	used, gone := make(map[string]bool), make(map[string]bool)
+	for id, w := range t.windows {
+		if _, ok := alive[id]; !ok {
+			gone[w.path] = true
+			delete(t.windows, id)
+			continue
+		}
+		used[w.path] = true
+	}
+	recent := make(map[string]bool)
+	for _, paths := range t.paths {
+		for _, path := range paths {
+			recent[path] = true
+		}
+	}
+	for path := range t.logs {
+		if used[path] {
+			continue
+		}
+		// A gone window's log, or one too old to look at
+		if !recent[path] || gone[path] {
+			delete(t.logs, path)
+		}
+	}
+}
+
+// recentLogs expands globs to the files written to in the last logMaxAge.
+func recentLogs(globs []string, now time.Time) []string {
+	var paths []string
+	for _, glob := range globs {
+		matches, _ := filepath.Glob(expandHome(glob))
+		for _, path := range matches {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() && now.Sub(info.ModTime()) < logMaxAge {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// openFiles lists the files pids have open, where /proc tells.
+func openFiles(pids []int) map[string]bool {
+	open := make(map[string]bool)
+	for _, pid := range pids {
+		dir := filepath.Join("/proc", strconv.Itoa(pid), "fd")
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if target, err := os.Readlink(filepath.Join(dir, e.Name())); err == nil {
+				open[target] = true
+			}
+		}
+	}
+	return open
+}
+
+// update reads what was written to the log since the last look. JSONL logs
+// are read on from where the last read stopped; JSON logs, rewritten whole,
+// are read again whenever they change.
+func (l *agentLog) update() error {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return err
+	}
+	if info.Size() == l.size && info.ModTime().Equal(l.modTime) {
+		return nil
+	}
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if !strings.HasSuffix(l.path, ".jsonl") {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		*l = agentLog{path: l.path}
+		l.readGeminiChat(data)
+	} else {
+		if info.Size() < l.offset {
+			// Rewritten
+			*l = agentLog{path: l.path}
+		}
+		if _, err := f.Seek(l.offset, io.SeekStart); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		// Leave a line still being written for the next read
+		end := bytes.LastIndexByte(data, '\n') + 1
+		for _, line := range bytes.Split(data[:end], []byte("\n")) {
+			l.readCodexRecord(line)
+		}
+		l.offset += int64(end)
+	}
+	l.size, l.modTime = info.Size(), info.ModTime()
+	if l.at.IsZero() || l.modTime.After(l.at) {
+		l.at = l.modTime
+	}
+	return nil
+}
+
+// codexRecord is a line of a Codex rollout.
+type codexRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"` // "session_meta", "event_msg", "response_item", ...
+	Payload   struct {
+		Type      string `json:"type"`
+		Cwd       string `json:"cwd"`
+		Name      string `json:"name"`
+		Message   string `json:"message"` // a user_message's prompt
+		Arguments string `json:"arguments"`
+		Action    struct {
+			Command []string `json:"command"`
+		} `json:"action"`
+		Info *struct {
+			Total struct {
+				Tokens int `json:"total_tokens"`
+			} `json:"total_token_usage"`
+		} `json:"info"`
+		RateLimits *struct {
+			Primary   *codexRateLimit `json:"primary"`
+			Secondary *codexRateLimit `json:"secondary"`
+		} `json:"rate_limits"`
+	} `json:"payload"`
+}
+
+// codexRateLimit is how much of one of the plan's usage windows is used.
+type codexRateLimit struct {
+	UsedPercent     float64 `json:"used_percent"`
+	WindowMinutes   int     `json:"window_minutes"`
+	ResetsInSeconds int     `json:"resets_in_seconds"`
+	ResetsAt        int64   `json:"resets_at"` // Unix seconds, in newer versions
+}
+
+// usageLimit is when the window resets if it is used up, counted from at.
+func (r *codexRateLimit) usageLimit(at time.Time) (usageLimit, bool) {
+	if r == nil || r.UsedPercent < 100 {
+		return usageLimit{}, false
+	}
+	limit := usageLimit{Weekly: r.WindowMinutes >= 7*24*60}
+	if r.ResetsAt > 0 {
+		limit.Resets = time.Unix(r.ResetsAt, 0)
+	} else {
+		limit.Resets = at.Add(time.Duration(r.ResetsInSeconds) * time.Second)
+	}
+	return limit, true
+}
+
+// readCodexRecord takes in one line of a Codex rollout.
+func (l *agentLog) readCodexRecord(line []byte) {
+	var r codexRecord
+	if json.Unmarshal(line, &r) != nil {
+		return
+	}
+	p := r.Payload
+	switch r.Type {
+	case "session_meta", "turn_context":
+		if p.Cwd != "" {
+			l.cwd = p.Cwd
+		}
+	case "event_msg":
+		switch p.Type {
+		case "task_started", "user_message":
+			l.status = agentstatus.Running
+			if l.prompt == "" {
+				l.prompt = strings.TrimSpace(p.Message)
+			}
+		case "task_complete", "turn_aborted":
+			l.status = agentstatus.Idle
+		case "exec_approval_request", "apply_patch_approval_request":
+			l.status = agentstatus.NeedsApproval
+		case "token_count":
+			if p.Info != nil {
+				l.tokens = p.Info.Total.Tokens
+			}
+			if p.RateLimits != nil {
+				l.limit = usageLimit{}
+				// The later reset of the windows used up
+				for _, w := range []*codexRateLimit{p.RateLimits.Primary, p.RateLimits.Secondary} {
+					if limit, ok := w.usageLimit(r.Timestamp); ok && limit.Resets.After(l.limit.Resets) {
+						l.limit = limit
+					}
+				}
+			}
+		}
+	case "response_item":
+		switch p.Type {
+		case "function_call", "custom_tool_call":
+			var args struct {
+				Command []string `json:"command"`
+			}
+			json.Unmarshal([]byte(p.Arguments), &args)
+			l.action = toolAction(p.Name, args.Command)
+			l.status = agentstatus.Running
+		case "local_shell_call":
+			l.action = toolAction("shell", p.Action.Command)
+			l.status = agentstatus.Running
+		}
+	}
+}
+
+// toolAction describes a tool call by name and, for a shell, the command
+// it ran.
+func toolAction(name string, command []string) string {
+	if len(command) == 0 {
+		return name
+	}
+	// ["bash", "-lc", "go test ./..."] ran the last argument
+	return name + ": " + command[len(command)-1]
+}
+
+// geminiChat is a Gemini CLI chat file.
+type geminiChat struct {
+	ProjectHash string `json:"projectHash"`
+	Messages    []struct {
+		Type      string          `json:"type"` // "user", "gemini", ...
+		Content   json.RawMessage `json:"content"`
+		ToolCalls []struct {
+			Name string `json:"name"`
+		} `json:"toolCalls"`
+		Tokens *struct {
+			Total int `json:"total"`
+		} `json:"tokens"`
+	} `json:"messages"`
+}
+
+// readGeminiChat takes in a whole Gemini CLI chat. A chat ending on the
+// user's message is being answered; otherwise it doesn't tell.
+func (l *agentLog) readGeminiChat(data []byte) {
+	var chat geminiChat
+	if json.Unmarshal(data, &chat) != nil {
+		return
+	}
+	l.project = chat.ProjectHash
+	if l.project == "" {
+		// ~/.gemini/tmp/<hash>/chats/session-….json
+		l.project = filepath.Base(filepath.Dir(filepath.Dir(l.path)))
+	}
+	for _, msg := range chat.Messages {
+		if msg.Type == "user" && l.prompt == "" {
+			l.prompt = geminiText(msg.Content)
+		}
+		if msg.Tokens != nil {
+			l.tokens += msg.Tokens.Total
+		}
+		for _, call := range msg.ToolCalls {
+			l.action = call.Name
+		}
+	}
+	if n := len(chat.Messages); n > 0 && chat.Messages[n-1].Type == "user" {
+		l.status = agentstatus.Running
+	}
+}
+
+// geminiText is the text of a chat message's content: a string, or a list
+// of parts.
+func geminiText(content json.RawMessage) string {
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return strings.TrimSpace(text)
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	json.Unmarshal(content, &parts)
+	var texts []string
+	for _, part := range parts {
+		texts = append(texts, part.Text)
+	}
+	return strings.TrimSpace(strings.Join(texts, ""))
+}
+
+// compactCount shortens n for display: 950, 12.3k, 1.2M.
+func compactCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	}
+	return strconv.Itoa(n)
+}
+
+// foregroundPids lists the processes in win's foreground.
+func foregroundPids(win kittyWindow) []int {
+	pids := make([]int, 0, len(win.ForegroundProcesses))
+	for _, proc := range win.ForegroundProcesses {
+		pids = append(pids, proc.Pid)
+	}
+	return pids
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestCodexLog(t *testing.T) {
+	dir := t.TempDir()
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg.AgentLogs = map[string][]string{"codex": {filepath.Join(dir, "*", "rollout-*.jsonl")}}
+
+	path := filepath.Join(dir, "10", "rollout-2025-01-01T12-00-00-abc.jsonl")
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	os.WriteFile(path, []byte(`{"timestamp":"2025-01-01T12:00:00.000Z","type":"session_meta","payload":{"id":"abc","cwd":"/src/api"}}
+{"type":"event_msg","payload":{"type":"task_started"}}
+{"type":"event_msg","payload":{"type":"user_message","message":"Fix the flaky login test\n"}}
+{"type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"go test ./...\"]}"}}
+{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"total_tokens":12345}}}}
+{"type":"event_msg","payload":{"type":"task_comp`), 0o644)
+
+	tailer := newLogTailer()
+	now := time.Now()
+	l, ok := tailer.lookup("codex", 1, "/src/api", nil, now)
+	if !ok {
+		t.Fatal("lookup() found no log for /src/api")
+	}
+	// The last line is still being written
+	if l.status != agentstatus.Running || l.tokens != 12345 || l.action != "shell: go test ./..." {
+		t.Errorf("log = %s, %d tokens, %q; want RUNNING, 12345, the shell call", l.status, l.tokens, l.action)
+	}
+	if l.prompt != "Fix the flaky login test" {
+		t.Errorf("prompt = %q, want the user message", l.prompt)
+	}
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`lete"}}` + "\n")
+	f.Close()
+	if l, _ = tailer.lookup("codex", 1, "/src/api", nil, now); l.status != agentstatus.Idle || l.tokens != 12345 {
+		t.Errorf("after task_complete: %s, %d tokens; want IDLE, 12345", l.status, l.tokens)
+	}
+
+	if _, ok := tailer.lookup("codex", 1, "/src/web", nil, now); ok {
+		t.Error("lookup() matched a log from another directory")
+	}
+	if _, ok := tailer.lookup("aider", 1, "/src/api", nil, now); ok {
+		t.Error("lookup() found a log for an agent without any")
+	}
+}
+
+func TestLogTailerWindows(t *testing.T) {
+	dir := t.TempDir()
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg.AgentLogs = map[string][]string{"codex": {filepath.Join(dir, "rollout-*.jsonl")}}
+	path := filepath.Join(dir, "rollout-1.jsonl")
+	os.WriteFile(path, []byte(`{"type":"session_meta","payload":{"cwd":"/src/api"}}`+"\n"), 0o644)
+
+	tailer := newLogTailer()
+	now := time.Now()
+	if _, ok := tailer.lookup("codex", 1, "/src/api", nil, now); !ok {
+		t.Fatal("lookup() found no log for /src/api")
+	}
+	// Until the globs are expanded again, window 2 keeps having none
+	if _, ok := tailer.lookup("codex", 2, "/src/web", nil, now); ok {
+		t.Fatal("lookup() matched a log from another directory")
+	}
+	os.WriteFile(filepath.Join(dir, "rollout-2.jsonl"), []byte(`{"type":"session_meta","payload":{"cwd":"/src/web"}}`+"\n"), 0o644)
+	if _, ok := tailer.lookup("codex", 2, "/src/web", nil, now.Add(time.Second)); ok {
+		t.Error("lookup() looked for new logs before logGlobEvery")
+	}
+	if _, ok := tailer.lookup("codex", 2, "/src/web", nil, now.Add(logGlobEvery)); !ok {
+		t.Error("lookup() didn't find the new log after logGlobEvery")
+	}
+
+	// Window 1 closed: its log goes, window 2's stays
+	tailer.forget(map[int]string{2: "codex"})
+	if _, ok := tailer.logs[path]; ok || len(tailer.windows) != 1 {
+		t.Errorf("after forget: %d logs, windows %v; want window 1 and its log gone", len(tailer.logs), tailer.windows)
+	}
+}
+
+func TestScreenOverLog(t *testing.T) {
+	for status, want := range map[agentstatus.Status]bool{
+		agentstatus.NeedsApproval: true,
+		agentstatus.RateLimited:   true,
+		agentstatus.Idle:          false,
+		agentstatus.Running:       false,
+	} {
+		if got := screenOverLog(status); got != want {
+			t.Errorf("screenOverLog(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestCodexRateLimits(t *testing.T) {
+	var l agentLog
+	l.readCodexRecord([]byte(`{"timestamp":"2025-01-01T12:00:00Z","type":"event_msg","payload":{"type":"token_count","rate_limits":{"primary":{"used_percent":40,"window_minutes":300,"resets_in_seconds":600},"secondary":{"used_percent":100,"window_minutes":10080,"resets_in_seconds":7200}}}}`))
+	want := time.Date(2025, 1, 1, 14, 0, 0, 0, time.UTC)
+	if !l.limit.Resets.Equal(want) || !l.limit.Weekly {
+		t.Errorf("limit = %v, weekly %v; want %v, weekly", l.limit.Resets, l.limit.Weekly, want)
+	}
+	l.readCodexRecord([]byte(`{"timestamp":"2025-01-01T15:00:00Z","type":"event_msg","payload":{"type":"token_count","rate_limits":{"primary":{"used_percent":10,"window_minutes":300,"resets_in_seconds":600}}}}`))
+	if !l.limit.Resets.IsZero() {
+		t.Errorf("limit = %v after usage was back under it", l.limit.Resets)
+	}
+}
+
+func TestGeminiLog(t *testing.T) {
+	dir := t.TempDir()
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg.AgentLogs = map[string][]string{"gemini": {filepath.Join(dir, "*", "chats", "session-*.json")}}
+
+	path := filepath.Join(dir, geminiProjectHash("/src/web"), "chats", "session-1.json")
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	os.WriteFile(path, []byte(`{"sessionId":"s1","messages":[
+		{"type":"user","content":"fix the build"},
+		{"type":"gemini","content":"Running it.","toolCalls":[{"name":"run_shell_command"}],"tokens":{"input":900,"output":100,"total":1000}},
+		{"type":"user","content":[{"text":"and the lint"}]}
+	]}`), 0o644)
+
+	l, ok := newLogTailer().lookup("gemini", 1, "/src/web", nil, time.Now())
+	if !ok {
+		t.Fatal("lookup() found no chat for /src/web")
+	}
+	if l.status != agentstatus.Running || l.tokens != 1000 || l.action != "run_shell_command" {
+		t.Errorf("chat = %s, %d tokens, %q; want RUNNING, 1000, run_shell_command", l.status, l.tokens, l.action)
+	}
+	if l.prompt != "fix the build" {
+		t.Errorf("prompt = %q, want the first user message", l.prompt)
+	}
+	if got := geminiText([]byte(`[{"text":"and "},{"text":"the lint"}]`)); got != "and the lint" {
+		t.Errorf("geminiText() of parts = %q", got)
+	}
+}
+
+func TestOpenFiles(t *testing.T) {
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		t.Skip("no /proc")
+	}
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !openFiles([]int{os.Getpid()})[path] {
+		t.Errorf("openFiles() doesn't list %s", path)
+	}
+}
+
+func TestCompactCount(t *testing.T) {
+	for n, want := range map[int]string{950: "950", 12345: "12.3k", 1_250_000: "1.2M"} {
+		if got := compactCount(n); got != want {
+			t.Errorf("compactCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// agentInfo describes an agent CLI: the processes that run it, and how the
+// Sessions panel tags it. How its screen reads is up to its parser in the
+// status package, keyed by Name, and to its rules. In the config:
+//
+//	agents:
+//	  - name: acme
+//	    match: ['python3? -m acme_agent\b']
+//	    label: AC
+//	    color: "#00afff"
+//	    parser: claude
+//	    lookback: 30
+//	    rules:
+//	      - match: 'Approve\? \[y/N\]'
+//	        status: NEEDS_APPROVAL
+type agentInfo struct {
+	Name      string        `yaml:"name"`
+	Processes []string      `yaml:"processes"` // executable names; defaults to [name]
+	Match     []string      `yaml:"match"`     // regexps for the command line, for wrappers
+	Label     string        `yaml:"label"`     // tag in the Sessions panel, e.g. "CL"
+	Color     string        `yaml:"color"`     // the tag's color: an ANSI number or #rrggbb
+	Parser    string        `yaml:"parser"`    // reads its screen with another agent's parser
+	Rules     []profileRule `yaml:"rules"`     // status rules for this agent only
+	Hints     []string      `yaml:"hints"`     // keys and commands shown under its output
+	Lookback  int           `yaml:"lookback"`  // lines up the screen its parser reads
+	matchRes  []*regexp.Regexp
+
+	// generic marks a name that other commands use too, e.g. goose: it is
+	// only detected as the command itself, and, when subcommands are set,
+	// only run bare or with one of them.
+	generic     bool
+	subcommands []string
+}
+
+// builtinAgents are the agents detected out of the box, in the order they
+// are looked for.
+var builtinAgents = []agentInfo{
+	{Name: "codex", Label: "CO", Color: "252", Hints: []string{"? shortcuts", "/model", "/approvals", "/diff", "esc interrupt"}},
+	{Name: "claude", Label: "CL", Color: "209", Hints: []string{"shift+tab modes", "/compact", "/clear", "# memory", "esc interrupt"}},
+	{Name: "gemini", Label: "GE", Color: "75", Hints: []string{"/help", "/chat save", "@ files", "ctrl+y yolo", "esc cancel"}},
+	{Name: "aider", Label: "AI", Color: "42", Hints: []string{"/add", "/drop", "/undo", "/ask", "/architect"}, generic: true},
+	// Not pressly/goose, the database migration tool
+	{Name: "goose", Label: "GO", Color: "221", generic: true, subcommands: []string{"session", "s", "run", "web"}},
+	{Name: "amp", Label: "AM", Color: "171", generic: true},
+	{Name: "opencode", Label: "OC", Color: "250", generic: true},
+	{Name: "cursor-agent", Label: "CU", Color: "39"},
+	{Name: "openhands", Label: "OH", Color: "179"},
+	{Name: "qwen", Processes: []string{"qwen", "qwen-code"}, Label: "QW", Color: "99", Hints: []string{"/help", "@ files", "ctrl+y yolo", "esc cancel"}, generic: true},
+}
+
+// agentRegistry is the built-in agents with the config's agents added, or
+// changing the fields they set of a built-in one.
+type agentRegistry struct {
+	order       []string             // agent names
+	byName      map[string]agentInfo // by agent name
+	byProcess   map[string]string    // process name -> agent name
+	commandOnly map[string][]string  // generic process name -> its subcommands
+}
+
+var agents = newAgentRegistry(nil)
+
+func newAgentRegistry(extra []agentInfo) agentRegistry {
+	r := agentRegistry{byName: make(map[string]agentInfo), byProcess: make(map[string]string), commandOnly: make(map[string][]string)}
+	for _, a := range append(append([]agentInfo{}, builtinAgents...), extra...) {
+		old, ok := r.byName[a.Name]
+		if !ok {
+			r.order = append(r.order, a.Name)
+		}
+		if len(a.Processes) == 0 {
+			a.Processes = old.Processes
+		}
+		if a.Label == "" {
+			a.Label = old.Label
+		}
+		if a.Color == "" {
+			a.Color = old.Color
+		}
+		if a.Parser == "" {
+			a.Parser = old.Parser
+		}
+		if a.Lookback == 0 {
+			a.Lookback = old.Lookback
+		}
+		if a.Hints == nil {
+			a.Hints = old.Hints
+		}
+		if len(a.matchRes) == 0 {
+			a.matchRes = old.matchRes
+		}
+		if !a.generic {
+			a.generic, a.subcommands = old.generic, old.subcommands
+		}
+		r.byName[a.Name] = a
+	}
+	for _, name := range r.order {
+		a := r.byName[name]
+		for _, proc := range a.processes() {
+			r.byProcess[proc] = name
+			if a.generic {
+				r.commandOnly[proc] = a.subcommands
+			}
+		}
+	}
+	return r
+}
+
+func (a agentInfo) processes() []string {
+	if len(a.Processes) == 0 {
+		return []string{a.Name}
+	}
+	return a.Processes
+}
+
+// compileAgents validates the config's agents and compiles their patterns.
+// Their rules become status rules for the agent, tried after the config's
+// own status rules of the same priority.
+func compileAgents(c *config) error {
+	for i := range c.Agents {
+		a := &c.Agents[i]
+		a.Name = strings.ToLower(strings.TrimSpace(a.Name))
+		if a.Name == "" {
+			return fmt.Errorf("agents[%d]: no name", i)
+		}
+		a.Parser = strings.ToLower(strings.TrimSpace(a.Parser))
+		if a.Lookback < 0 {
+			return fmt.Errorf("agents[%d] (%s): lookback: %d is negative", i, a.Name, a.Lookback)
+		}
+		for j := range a.Processes {
+			a.Processes[j] = strings.ToLower(strings.TrimSpace(a.Processes[j]))
+		}
+		for _, pattern := range a.Match {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return fmt.Errorf("agents[%d] (%s): %w", i, a.Name, err)
+			}
+			a.matchRes = append(a.matchRes, re)
+		}
+		for _, r := range a.Rules {
+			c.StatusRules = append(c.StatusRules, statusRule{profileRule: r, AI: a.Name})
+		}
+	}
+	return nil
+}
+
+// processes lists the process names of every agent, to detect by default.
+func (r agentRegistry) processes() []string {
+	var procs []string
+	for _, name := range r.order {
+		for _, proc := range r.byName[name].processes() {
+			if r.byProcess[proc] == name {
+				procs = appendUnique(procs, proc)
+			}
+		}
+	}
+	return procs
+}
+
+// match returns the agent whose match patterns one of win's foreground
+// command lines matches.
+func (r agentRegistry) match(win kittyWindow) (string, bool) {
+	for _, proc := range win.ForegroundProcesses {
+		cmdline := strings.Join(proc.Cmdline, " ")
+		for _, name := range r.order {
+			for _, re := range r.byName[name].matchRes {
+				if cmdline != "" && re.MatchString(cmdline) {
+					return name, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// detectAgent reports which agent runs in win: one of prefixes by process
+// name, or else an agent whose match patterns fit its command line.
+func detectAgent(win kittyWindow, prefixes []string) (string, bool) {
+	if ai, ok := agentsession.DetectAICommand(win, prefixes, agents.commandOnly); ok {
+		return ai, true
+	}
+	return agents.match(win)
+}
+
+// name maps a detected process name to its agent, e.g. qwen-code to qwen.
+func (r agentRegistry) name(process string) string {
+	if name, ok := r.byProcess[process]; ok {
+		return name
+	}
+	return process
+}
+
+// parser is the agent whose parser reads ai's screen: its own unless the
+// config says otherwise.
+func (r agentRegistry) parser(ai string) string {
+	if a, ok := r.byName[ai]; ok && a.Parser != "" {
+		return a.Parser
+	}
+	return ai
+}
+
+// lookback is how many lines up ai's screen its parser reads: its own
+// lookback, else the config's, else 0 for the parser's default.
+func (r agentRegistry) lookback(ai string) int {
+	if a, ok := r.byName[ai]; ok && a.Lookback > 0 {
+		return a.Lookback
+	}
+	return cfg.Lookback
+}
+
+// label is ai's tag in the Sessions panel: its label, or the first two
+// letters of its name.
+func (r agentRegistry) label(ai string) string {
+	ai = strings.ToLower(ai)
+	if a, ok := r.byName[ai]; ok && a.Label != "" {
+		return a.Label
+	}
+	if len(ai) >= 2 {
+		return strings.ToUpper(ai[:2])
+	}
+	return strings.ToUpper(ai)
+}
+
+// tag renders ai's label in its color.
+func (r agentRegistry) tag(ai string) string {
+	label := r.label(ai)
+	if a, ok := r.byName[strings.ToLower(ai)]; ok && a.Color != "" {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(a.Color)).Render(label)
+	}
+	return label
+}
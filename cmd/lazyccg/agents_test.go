@@ -0,0 +1,187 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAgentRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte(`agents:
+  - name: Claude
+    color: "#ff8800"
+  - name: crush
+    processes: [crush, crush-cli]
+    label: CR
+    parser: opencode
+`), 0o644)
+	c, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newAgentRegistry(c.Agents)
+
+	procs := r.processes()
+	for _, want := range []string{"claude", "aider", "goose", "amp", "opencode", "cursor-agent", "openhands", "qwen-code", "crush-cli"} {
+		if !slices.Contains(procs, want) {
+			t.Errorf("processes() = %q, missing %s", procs, want)
+		}
+	}
+	if procs[0] != "codex" {
+		// Built-ins first, in their order
+		t.Errorf("processes()[0] = %q, want codex", procs[0])
+	}
+
+	tests := []struct {
+		process, name, label, parser string
+	}{
+		{"claude", "claude", "CL", "claude"}, // label kept when only the color changes
+		{"qwen-code", "qwen", "QW", "qwen"},
+		{"crush-cli", "crush", "CR", "opencode"},
+		{"mystery", "mystery", "MY", "mystery"},
+	}
+	for _, tt := range tests {
+		name := r.name(tt.process)
+		if name != tt.name || r.label(name) != tt.label || r.parser(name) != tt.parser {
+			t.Errorf("%s: name %q, label %q, parser %q; want %q, %q, %q",
+				tt.process, name, r.label(name), r.parser(name), tt.name, tt.label, tt.parser)
+		}
+	}
+	if got := r.byName["claude"].Color; got != "#ff8800" {
+		t.Errorf("claude color = %q, want the config's", got)
+	}
+
+	os.WriteFile(path, []byte("agents:\n  - label: XX\n"), 0o644)
+	if _, err := loadConfig(path); err == nil {
+		t.Error("loadConfig() took an agent without a name")
+	}
+}
+
+func TestAgentMatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte(`agents:
+  - name: acme
+    match: ['python3? -m acme_agent\b']
+    label: AC
+    rules:
+      - match: 'Approve\? \[y/N\]'
+        status: NEEDS_APPROVAL
+`), 0o644)
+	c, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := agents
+	defer func() { agents = saved }()
+	agents = newAgentRegistry(c.Agents)
+
+	wrapper := kittyWindow{ForegroundProcesses: []foregroundProcess{{Cmdline: []string{"python3", "-m", "acme_agent", "--fast"}}}}
+	if ai, ok := detectAgent(wrapper, []string{"claude"}); !ok || ai != "acme" {
+		t.Errorf("detectAgent(wrapper) = %q, %v; want acme", ai, ok)
+	}
+	other := kittyWindow{ForegroundProcesses: []foregroundProcess{{Cmdline: []string{"python3", "-m", "acme_agent_tests"}}}}
+	if ai, ok := detectAgent(other, []string{"claude"}); ok {
+		t.Errorf("detectAgent(other) = %q, want no agent", ai)
+	}
+	if got := agentCommand(wrapper, "acme", []string{"claude"}); !slices.Equal(got, wrapper.ForegroundProcesses[0].Cmdline) {
+		t.Errorf("agentCommand(wrapper) = %q, want it started as it was", got)
+	}
+
+	lines := []string{"Run migrations?", "Approve? [y/N]"}
+	if got, _ := matchStatusRules(c.StatusRules, "acme", lines); got != "NEEDS_APPROVAL" {
+		t.Errorf("acme's rule gave %q, want NEEDS_APPROVAL", got)
+	}
+	if got, _ := matchStatusRules(c.StatusRules, "claude", lines); got != "" {
+		t.Errorf("acme's rule applied to claude: %q", got)
+	}
+
+	os.WriteFile(path, []byte("agents:\n  - name: bad\n    match: ['(']\n"), 0o644)
+	if _, err := loadConfig(path); err == nil {
+		t.Error("loadConfig() took a bad match pattern")
+	}
+}
+
+func TestDetectGenericAgents(t *testing.T) {
+	saved := agents
+	defer func() { agents = saved }()
+	agents = newAgentRegistry([]agentInfo{{Name: "goose", Color: "1"}}) // still generic
+	prefixes := agents.processes()
+
+	tests := []struct {
+		cmdline []string
+		want    string
+	}{
+		{[]string{"goose"}, "goose"},
+		{[]string{"/usr/local/bin/goose", "session", "--resume"}, "goose"},
+		{[]string{"node", "--no-warnings", "/usr/lib/node_modules/@sourcegraph/amp/dist/main.js"}, "amp"},
+		{[]string{"/home/me/.local/bin/aider", "--model", "sonnet"}, "aider"},
+		{[]string{"python3", "-m", "aider"}, "aider"},
+		// Not agents
+		{[]string{"goose", "up"}, ""}, // pressly/goose
+		{[]string{"goose", "postgres", "user=me dbname=app", "status"}, ""},
+		{[]string{"git", "checkout", "amp"}, ""},
+		{[]string{"brew", "install", "aider"}, ""},
+		{[]string{"vim", "src/opencode/main.go"}, ""},
+		// Other agents still match any argument
+		{[]string{"node", "/opt/lib/node_modules/@openai/codex/bin/codex"}, "codex"},
+		{[]string{"caffeinate", "-i", "claude"}, "claude"},
+	}
+	for _, tt := range tests {
+		win := kittyWindow{ForegroundProcesses: []foregroundProcess{{Cmdline: tt.cmdline}}}
+		if ai, _ := detectAgent(win, prefixes); ai != tt.want {
+			t.Errorf("detectAgent(%q) = %q, want %q", tt.cmdline, ai, tt.want)
+		}
+	}
+}
+
+func TestAgentHints(t *testing.T) {
+	savedAgents, savedProfiles := agents, profiles
+	defer func() { agents, profiles = savedAgents, savedProfiles }()
+	agents = newAgentRegistry([]agentInfo{{Name: "codex", Color: "1"}, {Name: "gemini", Hints: []string{}}})
+	profiles = profileSet{byName: map[string]*profile{"aider": {Name: "aider", Hints: []string{"/run tests"}}}}
+
+	tests := []struct {
+		ai   string
+		want []string
+	}{
+		{"codex", []string{"? shortcuts", "/model", "/approvals", "/diff", "esc interrupt"}}, // kept from the built-in
+		{"gemini", []string{}},            // turned off
+		{"aider", []string{"/run tests"}}, // the profile's
+		{"mystery", nil},
+	}
+	for _, tt := range tests {
+		if got := profiles.hints(tt.ai); !slices.Equal(got, tt.want) {
+			t.Errorf("hints(%s) = %q, want %q", tt.ai, got, tt.want)
+		}
+	}
+
+	if got := renderHints([]string{"shift+tab modes", "/compact", "/clear"}, 28); got != " shift+tab modes · /compact" {
+		t.Errorf("renderHints() = %q, want the hints that fit", got)
+	}
+}
+
+func TestAgentLookback(t *testing.T) {
+	savedAgents, savedCfg := agents, cfg
+	defer func() { agents, cfg = savedAgents, savedCfg }()
+	agents = newAgentRegistry([]agentInfo{{Name: "claude", Lookback: 30}, {Name: "claude", Color: "1"}})
+	cfg = config{Lookback: 20}
+
+	if got := agents.lookback("claude"); got != 30 {
+		t.Errorf("lookback(claude) = %d, want its own 30 kept when later changed", got)
+	}
+	if got := agents.lookback("codex"); got != 20 {
+		t.Errorf("lookback(codex) = %d, want the config's 20", got)
+	}
+	cfg = config{}
+	if got := agents.lookback("codex"); got != 0 {
+		t.Errorf("lookback(codex) = %d, want 0 for the parser's own", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	os.WriteFile(path, []byte("agents:\n  - name: acme\n    lookback: -1\n"), 0o644)
+	if _, err := loadConfig(path); err == nil {
+		t.Error("loadConfig() took a negative lookback")
+	}
+}
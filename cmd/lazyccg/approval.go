@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/atani/lazyccg/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// approvalOption is one answer to an agent's approval prompt.
+type approvalOption struct {
+	Label string `yaml:"label"`
+	Send  string `yaml:"send"` // keys sent to the window; "\r" is enter
+}
+
+var yesNoPattern = regexp.MustCompile(`(?i)[\[(]y/n[\])]`)
+
+// parseApprovalOptions finds the options of an approval prompt near the end
+// of lines: a numbered menu, or yes and no for a "[y/n]" prompt.
+func parseApprovalOptions(lines []string) []approvalOption {
+	if menu, ok := parseMenu(lines); ok {
+		return menu.Options
+	}
+	start := len(lines) - menuLookback
+	if start < 0 {
+		start = 0
+	}
+	for i := len(lines) - 1; i >= start; i-- {
+		if yesNoPattern.MatchString(lines[i]) {
+			return []approvalOption{{Label: "Yes", Send: "y\r"}, {Label: "No", Send: "n\r"}}
+		}
+	}
+	return nil
+}
+
+// approvalOptions returns the detected options for s, falling back to the
+// configured mapping for its AI.
+func approvalOptions(s session) []approvalOption {
+	if options := parseApprovalOptions(s.Lines); len(options) > 0 {
+		return options
+	}
+	return cfg.Approvals[s.AI]
+}
+
+// approvalPrompt is the open popup for answering a session's menu or
+// approval prompt.
+type approvalPrompt struct {
+	windowID int
+	title    string
+	question string
+	options  []approvalOption
+	selected int
+}
+
+// newApprovalPrompt offers the menu s is showing, or else its approval
+// options. It returns nil when there is nothing to answer.
+func newApprovalPrompt(s session) *approvalPrompt {
+	a := &approvalPrompt{windowID: s.WindowID, title: s.Title}
+	if s.Menu != nil {
+		a.question, a.options, a.selected = s.Menu.Question, s.Menu.Options, s.Menu.Selected
+	} else {
+		a.options = approvalOptions(s)
+	}
+	if len(a.options) == 0 {
+		return nil
+	}
+	return a
+}
+
+type approvalSentMsg struct {
+	label string
+	err   error
+}
+
+func sendApprovalCmd(windowID int, option approvalOption) tea.Cmd {
+	return func() tea.Msg {
+		return approvalSentMsg{label: option.Label, err: backend.SendText(windowID, option.Send)}
+	}
+}
+
+func (m model) updateApproval(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	a := *m.approval
+	switch key := msg.String(); key {
+	case "esc", "q", "y":
+		m.approval = nil
+		return m, nil
+	case "up", "k":
+		if a.selected > 0 {
+			a.selected--
+		}
+	case "down", "j":
+		if a.selected < len(a.options)-1 {
+			a.selected++
+		}
+	case "enter":
+		m.approval = nil
+		return m, sendApprovalCmd(a.windowID, a.options[a.selected])
+	case "ctrl+c":
+		return m, tea.Quit
+	default:
+		if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= len(a.options) {
+			m.approval = nil
+			return m, sendApprovalCmd(a.windowID, a.options[n-1])
+		}
+	}
+	m.approval = &a
+	return m, nil
+}
+
+func (m model) renderApprovalPopup(width, height int) string {
+	a := m.approval
+	boxWidth := min(width, 60)
+	var content []string
+	if a.question != "" {
+		content = append(content, helpDescStyle.Render(" "+ui.Truncate(a.question, boxWidth-3)), "")
+	}
+	for i, option := range a.options {
+		line := ui.Truncate(fmt.Sprintf(" %d. %s", i+1, option.Label), boxWidth-2)
+		if i == a.selected {
+			line = selectedStyle.Render(line + strings.Repeat(" ", max(0, boxWidth-2-lipgloss.Width(line))))
+		}
+		content = append(content, line)
+	}
+	box := drawBox("Answer: "+ui.Truncate(a.title, boxWidth-14), content, boxWidth, len(content)+2, yellow)
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseApprovalOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []approvalOption
+	}{
+		{
+			name: "claude numbered menu",
+			lines: []string{
+				"│ Do you want to make this edit to main.go?",
+				"│ ❯ 1. Yes",
+				"│   2. Yes, allow all edits during this session (shift+tab)",
+				"│   3. No, and tell Claude what to do differently (esc)",
+			},
+			want: []approvalOption{
+				{Label: "Yes", Send: "1"},
+				{Label: "Yes, allow all edits during this session (shift+tab)", Send: "2"},
+				{Label: "No, and tell Claude what to do differently (esc)", Send: "3"},
+			},
+		},
+		{
+			name:  "options on one line",
+			lines: []string{"Allow command?", "1. Yes  2. Yes, don't ask again  3. No"},
+			want: []approvalOption{
+				{Label: "Yes", Send: "1"},
+				{Label: "Yes, don't ask again", Send: "2"},
+				{Label: "No", Send: "3"},
+			},
+		},
+		{
+			name:  "latest list wins",
+			lines: []string{"1. Old", "2. Older", "Next question", "1. Allow", "2. Deny"},
+			want:  []approvalOption{{Label: "Allow", Send: "1"}, {Label: "Deny", Send: "2"}},
+		},
+		{
+			name:  "y/n prompt",
+			lines: []string{"Run `rm -rf build`? [y/N]"},
+			want:  []approvalOption{{Label: "Yes", Send: "y\r"}, {Label: "No", Send: "n\r"}},
+		},
+		{
+			name:  "numbered output is not a prompt",
+			lines: []string{"Steps:", "2. build", "3. test"},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseApprovalOptions(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseApprovalOptions() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApprovalOptionsFallsBackToConfig(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg.Approvals = map[string][]approvalOption{"codex": {{Label: "Approve", Send: "a"}}}
+
+	got := approvalOptions(session{AI: "codex", Lines: []string{"working..."}})
+	if len(got) != 1 || got[0].Send != "a" {
+		t.Errorf("approvalOptions() = %v, want configured mapping", got)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/atani/lazyccg/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// archiveMatch is one matching line of a transcript with surrounding lines.
+type archiveMatch struct {
+	Lines []string
+	Index int // position of the matching line within Lines
+}
+
+// archiveResult is a transcript that matched an archive search.
+type archiveResult struct {
+	Transcript transcript
+	MetaMatch  bool // query matched the title, cwd, AI, or prompt
+	Matches    []archiveMatch
+	Added      []historyRecord // recorded during the span, for a diff
+}
+
+// searchArchive full-text searches transcripts in dir, newest first. Each
+// matching line is returned with context lines on either side.
+func searchArchive(dir, query string, context, limit int) ([]archiveResult, error) {
+	paths, err := listTranscripts(dir)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(query)
+	var results []archiveResult
+	for _, path := range paths {
+		t, err := readTranscript(path)
+		if err != nil {
+			continue
+		}
+		r := archiveResult{Transcript: t}
+		meta := strings.ToLower(strings.Join([]string{t.AI, t.Title, t.Cwd, t.Prompt}, "\n"))
+		r.MetaMatch = strings.Contains(meta, needle)
+
+		lines := t.Lines()
+		for _, idx := range findMatches(lines, query) {
+			start, end := idx-context, idx+context+1
+			if start < 0 {
+				start = 0
+			}
+			if end > len(lines) {
+				end = len(lines)
+			}
+			r.Matches = append(r.Matches, archiveMatch{Lines: lines[start:end], Index: idx - start})
+		}
+		if r.MetaMatch || len(r.Matches) > 0 {
+			results = append(results, r)
+			if limit > 0 && len(results) >= limit {
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+// archiveHeader describes a transcript on one line.
+func archiveHeader(t transcript) string {
+	title := t.Title
+	if title == "" {
+		title = filepath.Base(t.Path)
+	}
+	header := fmt.Sprintf("%s  %s  %s", t.Started.Local().Format("2006-01-02 15:04"), strings.ToUpper(t.AI), title)
+	if t.Cwd != "" {
+		header += "  (" + shortenHome(t.Cwd) + ")"
+	}
+	return header
+}
+
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	context := fs.Int("context", 2, "lines of context around each match")
+	limit := fs.Int("limit", 20, "maximum number of sessions to show (0 = no limit)")
+	dir := fs.String("dir", historyDir(), "transcript directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: lazyccg search [-context N] [-limit N] <query>")
+	}
+	query := strings.Join(fs.Args(), " ")
+
+	results, err := searchArchive(*dir, query, *context, *limit)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Printf("no sessions match %q\n", query)
+		return nil
+	}
+	for _, r := range results {
+		fmt.Println(archiveHeader(r.Transcript))
+		if r.Transcript.Prompt != "" {
+			fmt.Println("  prompt:", r.Transcript.Prompt)
+		}
+		for _, match := range r.Matches {
+			for i, line := range match.Lines {
+				marker := "  "
+				if i == match.Index {
+					marker = "> "
+				}
+				fmt.Println("  " + marker + line)
+			}
+			fmt.Println("  --")
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// Archive search screen in the TUI.
+
+type archiveResultMsg struct {
+	query   string
+	span    *timeSpan // for a diff
+	results []archiveResult
+	err     error
+}
+
+func archiveSearchCmd(query string) tea.Cmd {
+	return func() tea.Msg {
+		results, err := searchArchive(historyDir(), query, 1, 50)
+		return archiveResultMsg{query: query, results: results, err: err}
+	}
+}
+
+// archiveLines flattens results into display lines for the search screen,
+// truncated to width.
+func archiveLines(results []archiveResult, width int) []string {
+	var lines []string
+	for _, r := range results {
+		lines = append(lines, titleStyle.Render(ui.Truncate(archiveHeader(r.Transcript), width)))
+		lines = append(lines, archiveDiffLines(r, width)...)
+		for _, match := range r.Matches {
+			for i, line := range match.Lines {
+				if i == match.Index {
+					lines = append(lines, statusWaiting.Render(ui.Truncate("  > "+line, width)))
+				} else {
+					lines = append(lines, helpDescStyle.Render(ui.Truncate("    "+line, width)))
+				}
+			}
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func (m model) renderArchiveScreen(width, height int) string {
+	title := "Archive search"
+	switch {
+	case m.archiveSpan != nil && m.archiveQuery != "":
+		title = fmt.Sprintf("Archive [%s] %s: %d session(s)", m.archiveQuery, m.archiveSpan, len(m.archiveResults))
+	case m.archiveSpan != nil:
+		title = fmt.Sprintf("Archive %s: %d session(s)", m.archiveSpan, len(m.archiveResults))
+	case m.archiveQuery != "":
+		title = fmt.Sprintf("Archive search [%s] %d session(s)", m.archiveQuery, len(m.archiveResults))
+	}
+	var content []string
+	innerWidth := width - 2
+	lines := archiveLines(m.archiveResults, innerWidth-1)
+	if len(lines) == 0 {
+		switch {
+		case m.archiveSpan != nil:
+			content = append(content, helpDescStyle.Render(" (nothing recorded then)"))
+		case m.archiveQuery == "":
+			content = append(content, helpDescStyle.Render(" type a query and press enter"))
+		default:
+			content = append(content, helpDescStyle.Render(" (no matches)"))
+		}
+	}
+	start := m.archiveScroll
+	if start > len(lines) {
+		start = len(lines)
+	}
+	for _, line := range lines[start:] {
+		content = append(content, " "+line)
+	}
+	return drawBox(title, content, width, height, cyan)
+}
+
+func recordHistory(events []sessionEvent) {
+	if err := history.Record(events); err != nil && debugLog != nil {
+		fmt.Fprintf(debugLog, "[%s] history: %v\n", time.Now().Format("15:04:05"), err)
+	}
+}
+
+func (m model) updateArchive(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.archiveTyping {
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.archiveTyping = false
+			input := strings.TrimSpace(string(m.archiveInput))
+			if m.archiveSpanTyping {
+				m.archiveSpanTyping = false
+				span, err := parseTimeSpan(input, time.Now())
+				if err != nil {
+					m.notice = err.Error()
+					return m, nil
+				}
+				return m, archiveDiffCmd(m.archiveQuery, span)
+			}
+			if input != "" {
+				return m, archiveSearchCmd(input)
+			}
+		case tea.KeyEsc:
+			if m.archiveSpanTyping {
+				// Back to the results
+				m.archiveSpanTyping, m.archiveTyping = false, false
+				return m, nil
+			}
+			m.archiveOpen = false
+			m.archiveTyping = false
+		case tea.KeyBackspace:
+			if len(m.archiveInput) > 0 {
+				m.archiveInput = m.archiveInput[:len(m.archiveInput)-1]
+			}
+		case tea.KeySpace:
+			m.archiveInput = append(m.archiveInput, ' ')
+		case tea.KeyRunes:
+			m.archiveInput = append(m.archiveInput, msg.Runes...)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q", "F":
+		m.archiveOpen = false
+	case "/":
+		m.archiveTyping = true
+		m.archiveInput = nil
+	case "t":
+		// What was recorded between two times, for the sessions the query
+		// matches
+		m.archiveTyping, m.archiveSpanTyping = true, true
+		m.archiveInput = nil
+	case "up", "k":
+		if m.archiveScroll > 0 {
+			m.archiveScroll--
+		}
+	case "down", "j":
+		m.archiveScroll++
+	case "pgup":
+		m.archiveScroll -= m.height / 2
+		if m.archiveScroll < 0 {
+			m.archiveScroll = 0
+		}
+	case "pgdown":
+		m.archiveScroll += m.height / 2
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
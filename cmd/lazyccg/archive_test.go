@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestSearchArchive(t *testing.T) {
+	dir := t.TempDir()
+	h := newHistoryRecorder(dir)
+	sessions := []session{
+		{WindowID: 1, AI: "claude", Title: "api", Cwd: "/src/billing", Lines: []string{"edit invoice.go", "tests pass"}},
+		{WindowID: 2, AI: "codex", Title: "web", Cwd: "/src/web", Lines: []string{"update Billing page", "done"}},
+	}
+	if err := h.Record(diffSessions(nil, sessions)); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := searchArchive(dir, "billing", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("searchArchive() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		switch r.Transcript.AI {
+		case "claude":
+			// Matches only the cwd
+			if !r.MetaMatch || len(r.Matches) != 0 {
+				t.Errorf("claude result = %+v", r)
+			}
+		case "codex":
+			if len(r.Matches) != 1 || r.Matches[0].Lines[r.Matches[0].Index] != "update Billing page" {
+				t.Errorf("codex matches = %+v", r.Matches)
+			}
+		}
+	}
+
+	if results, _ := searchArchive(dir, "billing", 1, 1); len(results) != 1 {
+		t.Errorf("limit 1 returned %d results", len(results))
+	}
+}
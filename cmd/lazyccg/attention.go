@@ -0,0 +1,42 @@
+package main
+
+import agentstatus "github.com/atani/lazyccg/pkg/status"
+
+// attentionStatuses are the statuses that mean a session wants the user.
+var attentionStatuses = map[agentstatus.Status]bool{
+	agentstatus.Waiting:       true,
+	agentstatus.NeedsApproval: true,
+	agentstatus.NeedsInput:    true,
+	agentstatus.Error:         true,
+	agentstatus.RateLimited:   true,
+	agentstatus.Done:          true,
+	agentstatus.Idle:          true,
+	agentstatus.Exited:        true,
+}
+
+// updateUnread counts, per window, status changes that need attention since
+// the session was last acknowledged. Snoozed sessions don't count, and
+// windows that disappeared are dropped.
+func updateUnread(unread map[int]int, events []sessionEvent, next []session) map[int]int {
+	changed := make(map[int]int)
+	for _, e := range events {
+		if e.Kind == statusChanged && !e.Snoozed && attentionStatuses[e.Session.Status] {
+			changed[e.Session.WindowID]++
+		}
+	}
+	result := make(map[int]int)
+	for _, s := range next {
+		if count := unread[s.WindowID] + changed[s.WindowID]; count > 0 {
+			result[s.WindowID] = count
+		}
+	}
+	return result
+}
+
+func totalUnread(unread map[int]int) int {
+	total := 0
+	for _, n := range unread {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpdateUnread(t *testing.T) {
+	prev := []session{
+		{WindowID: 1, Status: "RUNNING"},
+		{WindowID: 2, Status: "RUNNING"},
+		{WindowID: 3, Status: "IDLE"},
+		{WindowID: 4, Status: "WAITING"},
+	}
+	next := []session{
+		{WindowID: 1, Status: "WAITING"}, // needs attention
+		{WindowID: 2, Status: "ERROR"},   // crashed
+		{WindowID: 3, Status: "RUNNING"}, // started working, no attention
+		{WindowID: 5, Status: "IDLE"},    // new session
+	}
+	unread := map[int]int{1: 1, 4: 2}
+
+	got := updateUnread(unread, diffSessions(prev, next), next)
+	want := map[int]int{1: 2, 2: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("updateUnread() = %v, want %v", got, want)
+	}
+	if total := totalUnread(map[int]int{1: 2, 3: 1}); total != 3 {
+		t.Errorf("totalUnread() = %d, want 3", total)
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	m := model{unread: map[int]int{1: 2, 2: 1}}
+	m.acknowledge(1)
+	if !reflect.DeepEqual(m.unread, map[int]int{2: 1}) {
+		t.Errorf("unread after acknowledge = %v", m.unread)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Backend is a terminal lazyccg can read sessions from and drive. Window IDs
+// are whatever the terminal uses to address a window or pane.
+type Backend interface {
+	Name() string
+	// List returns every window, grouped the way kitty reports them.
+	List() ([]kittyOSWindow, error)
+	// CaptureText returns a window's text; extent "all" includes the
+	// scrollback.
+	CaptureText(windowID int, extent string) (string, error)
+	Focus(windowID int) error
+	Rename(windowID int, title string) error
+	// SendText types text into a window as if it were entered at the
+	// keyboard.
+	SendText(windowID int, text string) error
+	// Scroll scrolls a window's scrollback to lines above the bottom.
+	Scroll(windowID, lines int) error
+}
+
+var backend Backend = kittyBackend{}
+
+// remoteBackend is implemented by backends that can reach a terminal on
+// another machine.
+type remoteBackend interface {
+	// Remote returns the address of the remote terminal, or "" if local.
+	Remote() string
+}
+
+// backendRemote returns b's remote address, or "" when it is local.
+func backendRemote(b Backend) string {
+	if r, ok := b.(remoteBackend); ok {
+		return r.Remote()
+	}
+	return ""
+}
+
+// commandRunner runs a backend's CLI locally, or over SSH when host is set.
+type commandRunner struct {
+	host string
+}
+
+func (r commandRunner) command(name string, args ...string) *exec.Cmd {
+	if r.host == "" {
+		return exec.Command(name, args...)
+	}
+	return exec.Command("ssh", sshArgs(r.host, append([]string{name}, args...))...)
+}
+
+// mark flags windows listed over SSH, whose pids aren't ours.
+func (r commandRunner) mark(osWindows []kittyOSWindow) []kittyOSWindow {
+	if r.host != "" {
+		for i := range osWindows {
+			osWindows[i].Remote = true
+		}
+	}
+	return osWindows
+}
+
+// windowIDs gives small integer IDs to terminals that address windows by
+// name or UUID. An ID is assigned the first time a window is listed and kept
+// for the life of the process.
+type windowIDs struct {
+	mu    sync.Mutex
+	byKey map[string]int
+	keys  map[int]string
+}
+
+func newWindowIDs() *windowIDs {
+	return &windowIDs{byKey: make(map[string]int), keys: make(map[int]string)}
+}
+
+// id returns the integer ID for key, assigning the next one if needed.
+func (ids *windowIDs) id(key string) int {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	if id, ok := ids.byKey[key]; ok {
+		return id
+	}
+	id := len(ids.byKey) + 1
+	ids.byKey[key] = id
+	ids.keys[id] = key
+	return id
+}
+
+// key returns the key an ID was assigned to.
+func (ids *windowIDs) key(id int) (string, error) {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	if key, ok := ids.keys[id]; ok {
+		return key, nil
+	}
+	return "", fmt.Errorf("no window %d", id)
+}
+
+// backendChoice is how lazyccg was told to read sessions: -backend,
+// -kitty-socket and -ssh.
+type backendChoice struct {
+	Name        string
+	KittySocket string
+	SSH         string
+}
+
+// localBackend returns the backend called name on this machine, looking
+// inside its windows for tmux.
+func localBackend(name, kittySocket string) (Backend, error) {
+	b, err := newBackend(name, resolveKittySocket(kittySocket))
+	if err != nil {
+		return nil, err
+	}
+	return withLocalTmux(b), nil
+}
+
+// withLocalTmux looks for tmux clients inside b's windows, for the
+// backends that can have them.
+func withLocalTmux(b Backend) Backend {
+	switch b.Name() {
+	case "tmux", "mock", "docker":
+		// No local tmux clients to look inside
+		return b
+	}
+	return withNestedTmux(b)
+}
+
+// newBackend returns the backend called name. kittySocket is only used by
+// kitty; it may list several sockets separated by commas, or be "auto" for
+// every kitty socket in /tmp.
+func newBackend(name, kittySocket string) (Backend, error) {
+	switch name {
+	case "auto":
+		return newAutoBackend(kittySocket)
+	case "kitty":
+		var sockets []string
+		if kittySocket == "auto" {
+			if sockets = discoverKittySockets(); len(sockets) == 0 {
+				return nil, fmt.Errorf("no kitty sockets found in %s", os.TempDir())
+			}
+		} else if strings.Contains(kittySocket, ",") {
+			for _, socket := range strings.Split(kittySocket, ",") {
+				if socket = strings.TrimSpace(socket); socket != "" {
+					sockets = append(sockets, socket)
+				}
+			}
+		}
+		if len(sockets) > 1 {
+			return newMultiKittyBackend(sockets), nil
+		}
+		if len(sockets) == 1 {
+			kittySocket = sockets[0]
+		}
+		return newKittyBackend(kittySocket), nil
+	case "tmux":
+		return tmuxBackend{}, nil
+	case "wezterm":
+		return weztermBackend{}, nil
+	case "iterm2":
+		return newItermBackend(), nil
+	case "screen":
+		return newScreenBackend(), nil
+	case "ps":
+		return newPsBackend(), nil
+	case "docker":
+		return newDockerBackend("")
+	case "vscode":
+		return newVSCodeBackend(), nil
+	case "mock":
+		return loadMockBackend(mockFile)
+	}
+	return nil, fmt.Errorf("unknown backend %q (want auto, kitty, tmux, wezterm, iterm2, screen, docker, vscode, ps or mock)", name)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewBackend(t *testing.T) {
+	for _, name := range []string{"kitty", "tmux", "wezterm", "iterm2", "screen", "ps"} {
+		b, err := newBackend(name, "")
+		if err != nil {
+			t.Fatalf("newBackend(%q) error = %v", name, err)
+		}
+		if b.Name() != name {
+			t.Errorf("newBackend(%q).Name() = %q", name, b.Name())
+		}
+	}
+	if _, err := newBackend("alacritty", ""); err == nil {
+		t.Error("unknown backend should fail")
+	}
+}
+
+func TestKittyArgs(t *testing.T) {
+	if got, want := (kittyBackend{}).args("ls"), []string{"@", "ls"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args() = %v, want %v", got, want)
+	}
+	k := kittyBackend{socket: "unix:/tmp/kitty"}
+	if got, want := k.args("ls"), []string{"@", "--to", "unix:/tmp/kitty", "ls"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import "time"
+
+const maxRetryBackoff = 30 * time.Second
+
+// refreshErrorMsg reports a failed poll; the last good sessions are kept.
+type refreshErrorMsg struct {
+	err error
+}
+
+// retryBackoff doubles the poll interval for each consecutive failure, up to
+// maxRetryBackoff.
+func retryBackoff(base time.Duration, failures int) time.Duration {
+	if failures <= 0 {
+		return base
+	}
+	d := base
+	for i := 0; i < failures && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	return d
+}
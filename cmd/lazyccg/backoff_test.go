@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{10, maxRetryBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := retryBackoff(time.Second, tt.failures); got != tt.want {
+			t.Errorf("retryBackoff(1s, %d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
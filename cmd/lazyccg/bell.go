@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// Claude Code and Codex ring the terminal bell when they want the user.
+// Terminals don't report bells over their remote control, but they can run
+// a command on one, which can post it to the hook listener:
+//
+//	# kitty.conf
+//	command_on_bell sh -c 'curl -s -m 1 -d "" "http://127.0.0.1:7787/bell?window=$KITTY_WINDOW_ID"'
+//	# tmux.conf
+//	set-hook -g alert-bell 'run-shell -b "curl -s -m 1 -d \"\" http://127.0.0.1:7787/bell?window=#{pane_id}"'
+//
+// A bell makes the session WAITING until its screen changes again.
+//
+// OSC 99 desktop notifications would say the same, but kitty handles them
+// itself and passes them to nothing lazyccg can read: not remote control,
+// not watchers, not get-text. They aren't used.
+
+// bellSettle is how long after a bell the screen may still change, as the
+// agent finishes drawing, and the bell still count.
+const bellSettle = 2 * time.Second
+
+// bellTracker keeps the time of each window's last bell.
+type bellTracker struct {
+	mu       sync.Mutex
+	byWindow map[int]time.Time
+}
+
+var bells = newBellTracker()
+
+func newBellTracker() *bellTracker {
+	return &bellTracker{byWindow: make(map[int]time.Time)}
+}
+
+func (t *bellTracker) ring(window int, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.byWindow[window] = at
+}
+
+// last returns when the window last rang its bell.
+func (t *bellTracker) last(window int) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	at, ok := t.byWindow[window]
+	return at, ok
+}
+
+// bellHandler records the bells posted to it.
+func bellHandler(t *bellTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST a bell", http.StatusMethodNotAllowed)
+			return
+		}
+		window, ok := parseHookWindow(r.URL.Query().Get("window"))
+		if !ok {
+			http.Error(w, "no window", http.StatusBadRequest)
+			return
+		}
+		t.ring(window, time.Now())
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// bellStatus is the status a bell at rang gives a session whose screen
+// last changed at moved: WAITING, or status when it already says what is
+// waited for.
+func bellStatus(status agentstatus.Status, rang, moved time.Time) (agentstatus.Status, bool) {
+	if rang.IsZero() || moved.After(rang.Add(bellSettle)) {
+		return "", false
+	}
+	if status.IsWaiting() {
+		return status, true
+	}
+	return agentstatus.Waiting, true
+}
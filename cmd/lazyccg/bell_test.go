@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestBellHandler(t *testing.T) {
+	tracker := newBellTracker()
+	srv := httptest.NewServer(bellHandler(tracker))
+	defer srv.Close()
+
+	post := func(query string) int {
+		resp, err := http.Post(srv.URL+"/bell"+query, "text/plain", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("?window=%257"); code != http.StatusNoContent {
+		t.Fatalf("POST = %d", code)
+	}
+	if code := post(""); code != http.StatusBadRequest {
+		t.Errorf("POST without a window = %d, want 400", code)
+	}
+	if _, ok := tracker.last(7); !ok {
+		t.Error("the tmux pane's bell wasn't kept")
+	}
+	if _, ok := tracker.last(8); ok {
+		t.Error("a window that didn't ring has a bell")
+	}
+	if code := post("?window=%250"); code != http.StatusNoContent {
+		t.Errorf("POST for tmux's first pane = %d, want 204", code)
+	}
+	if _, ok := tracker.last(0); !ok {
+		t.Error("the first tmux pane's bell wasn't kept")
+	}
+}
+
+func TestBellStatus(t *testing.T) {
+	rang := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status agentstatus.Status
+		rang   time.Time
+		moved  time.Time
+		want   agentstatus.Status
+		ok     bool
+	}{
+		{"no bell", agentstatus.Idle, time.Time{}, rang, "", false},
+		{"still since", agentstatus.Idle, rang, rang.Add(-time.Minute), agentstatus.Waiting, true},
+		{"last frame after it", agentstatus.Running, rang, rang.Add(time.Second), agentstatus.Waiting, true},
+		{"more specific status kept", agentstatus.NeedsApproval, rang, rang, agentstatus.NeedsApproval, true},
+		{"working again", agentstatus.Running, rang, rang.Add(time.Minute), "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := bellStatus(tt.status, tt.rang, tt.moved); got != tt.want || ok != tt.ok {
+			t.Errorf("%s: bellStatus() = %s, %v; want %s, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// ttlCache is the shared cache for expensive queries: backend RPCs, ps, and
+// git. Every lookup says how old a value it can use, so features asking for
+// the same data within that window share one query instead of each running
+// their own. Errors are not cached.
+type ttlCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	entries map[K]cacheEntry[V]
+}
+
+type cacheEntry[V any] struct {
+	value   V
+	fetched time.Time
+}
+
+func newTTLCache[K comparable, V any]() *ttlCache[K, V] {
+	return &ttlCache[K, V]{entries: make(map[K]cacheEntry[V])}
+}
+
+// get returns the value for key if it was fetched less than maxAge ago,
+// otherwise it calls fetch and caches the result. Concurrent misses may
+// both fetch; the later result wins.
+func (c *ttlCache[K, V]) get(key K, maxAge time.Duration, fetch func() (V, error)) (V, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Since(e.fetched) < maxAge {
+		return e.value, nil
+	}
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+	c.mu.Lock()
+	c.entries[key] = cacheEntry[V]{value: v, fetched: time.Now()}
+	c.mu.Unlock()
+	return v, nil
+}
+
+// TTLs of the shared queries.
+const (
+	// listTTL lets everything that runs in one poll share a window list,
+	// while each poll still sees a fresh one.
+	listTTL = minCaptureInterval
+	// psTTL shares one process table between backends and nested tmux.
+	psTTL         = time.Second
+	gitInfoTTL    = 10 * time.Second
+	gitStatusTTL  = 2 * time.Second
+	gitStatusNone = "\x00" // cached "not a repository"
+)
+
+var (
+	windowCache    = newTTLCache[string, []kittyOSWindow]()
+	psCache        = newTTLCache[string, []byte]()
+	gitInfoCache   = newTTLCache[string, gitInfo]()
+	gitStatusCache = newTTLCache[string, string]()
+)
+
+// listWindows returns the backend's windows, at most listTTL old.
+func listWindows() ([]kittyOSWindow, error) {
+	return windowCache.get(backend.Name(), listTTL, backend.List)
+}
+
+// runPs returns the output of ps with args on r's host, at most psTTL old.
+func runPs(r commandRunner, args ...string) ([]byte, error) {
+	key := r.host + "\x00" + strings.Join(args, " ")
+	return psCache.get(key, psTTL, func() ([]byte, error) {
+		return r.command("ps", args...).Output()
+	})
+}
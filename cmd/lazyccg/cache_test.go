@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTTLCache(t *testing.T) {
+	c := newTTLCache[string, int]()
+	fetches := 0
+	fetch := func() (int, error) {
+		fetches++
+		return fetches, nil
+	}
+
+	if v, _ := c.get("a", time.Hour, fetch); v != 1 {
+		t.Errorf("first get = %d, want 1", v)
+	}
+	if v, _ := c.get("a", time.Hour, fetch); v != 1 || fetches != 1 {
+		t.Errorf("fresh get = %d after %d fetches, want the cached 1", v, fetches)
+	}
+	// A caller that needs fresher data than what's cached refetches
+	if v, _ := c.get("a", 0, fetch); v != 2 {
+		t.Errorf("get with maxAge 0 = %d, want 2", v)
+	}
+	if v, _ := c.get("b", time.Hour, fetch); v != 3 {
+		t.Errorf("other key = %d, want 3", v)
+	}
+
+	boom := errors.New("boom")
+	if _, err := c.get("c", time.Hour, func() (int, error) { return 0, boom }); err != boom {
+		t.Errorf("error = %v, want boom", err)
+	}
+	if v, _ := c.get("c", time.Hour, fetch); v != 4 {
+		t.Errorf("get after an error = %d, want a new fetch", v)
+	}
+}
+
+func TestRunPsShared(t *testing.T) {
+	out, err := runPs(commandRunner{}, "-o", "pid=", "-p", "1")
+	if err != nil {
+		t.Skip("ps not available:", err)
+	}
+	again, _ := runPs(commandRunner{}, "-o", "pid=", "-p", "1")
+	if &out[0] != &again[0] {
+		t.Error("second call within psTTL ran ps again")
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// An agent running a tool command, a test suite or a build, waits on it
+// without thinking. Where /proc shows the agent's process tree, a RUNNING
+// session blocked on a command for a while says so (RUNNING 12m — pytest
+// 12m), and the Detail panel splits its busy time between the model
+// thinking and the tools it ran.
+//
+// Agents run their commands through a shell of their own, e.g. Claude
+// Code's `bash -c` and Codex's `bash -lc`; what that shell runs is the
+// command. Other children, such as MCP servers, run for the whole session
+// and don't count, nor do the shells they run under: npx runs its package
+// through `sh -c`.
+
+// longChildAfter is how long a command must have run to be shown.
+const longChildAfter = 30 * time.Second
+
+// clockTicks is the unit of process start times in /proc, USER_HZ, which is
+// 100 on every Linux architecture lazyccg runs on.
+const clockTicks = 100
+
+// procEntry is a process as /proc shows it.
+type procEntry struct {
+	Pid, PPid int
+	Cmdline   []string
+	Start     time.Time
+}
+
+// readProcs lists the processes /proc shows; none where there is no /proc.
+func readProcs() []procEntry {
+	boot, ok := bootTime()
+	if !ok {
+		return nil
+	}
+	dirs, _ := os.ReadDir("/proc")
+	var procs []procEntry
+	for _, d := range dirs {
+		pid, err := strconv.Atoi(d.Name())
+		if err != nil {
+			continue
+		}
+		stat, err := os.ReadFile(filepath.Join("/proc", d.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		ppid, ticks, ok := parseProcStat(string(stat))
+		if !ok {
+			continue
+		}
+		cmdline, _ := os.ReadFile(filepath.Join("/proc", d.Name(), "cmdline"))
+		procs = append(procs, procEntry{
+			Pid:     pid,
+			PPid:    ppid,
+			Cmdline: strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00"),
+			Start:   boot.Add(time.Duration(ticks) * time.Second / clockTicks),
+		})
+	}
+	return procs
+}
+
+// bootTime reads when the machine booted from /proc/stat.
+func bootTime() (time.Time, bool) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, false
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if rest, ok := strings.CutPrefix(sc.Text(), "btime "); ok {
+			secs, err := strconv.ParseInt(strings.TrimSpace(rest), 10, 64)
+			return time.Unix(secs, 0), err == nil
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseProcStat reads the parent pid and start time, in clock ticks since
+// boot, from a /proc/<pid>/stat line. The command name before them is in
+// parentheses and may hold spaces and parentheses of its own.
+func parseProcStat(stat string) (ppid int, start int64, ok bool) {
+	i := strings.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, 0, false
+	}
+	// Fields from the state on: state is field 3, ppid 4, starttime 22
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) < 20 {
+		return 0, 0, false
+	}
+	ppid, err1 := strconv.Atoi(fields[1])
+	start, err2 := strconv.ParseInt(fields[19], 10, 64)
+	return ppid, start, err1 == nil && err2 == nil
+}
+
+// childProcess is the command an agent is waiting on.
+type childProcess struct {
+	Name  string // e.g. "pytest" or "go test"
+	Since time.Time
+}
+
+// toolChild returns the longest-running command that the agents in pids
+// run through a shell started by the agent itself.
+func toolChild(procs []procEntry, pids []int) (childProcess, bool) {
+	byPid := make(map[int]procEntry, len(procs))
+	children := make(map[int][]procEntry)
+	for _, p := range procs {
+		byPid[p.Pid] = p
+		children[p.PPid] = append(children[p.PPid], p)
+	}
+	var found procEntry
+	seen := make(map[int]bool)
+	var queue []int
+	for _, pid := range pids {
+		for _, c := range children[pid] {
+			if isShellCommand(c.Cmdline) {
+				queue = append(queue, c.Pid)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		for _, c := range children[pid] {
+			queue = append(queue, c.Pid)
+			if isShellCommand(byPid[pid].Cmdline) && !isShellCommand(c.Cmdline) && (found.Pid == 0 || c.Start.Before(found.Start)) {
+				found = c
+			}
+		}
+	}
+	if found.Pid == 0 {
+		return childProcess{}, false
+	}
+	return childProcess{Name: commandName(found.Cmdline), Since: found.Start}, true
+}
+
+var shells = []string{"sh", "bash", "zsh", "dash", "fish"}
+
+// isShellCommand reports whether cmdline is a shell running a command
+// string, e.g. `bash -lc 'pytest -x'`.
+func isShellCommand(cmdline []string) bool {
+	if len(cmdline) < 2 || !slices.Contains(shells, filepath.Base(cmdline[0])) {
+		return false
+	}
+	for _, arg := range cmdline[1:] {
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, "c") {
+			return true
+		}
+	}
+	return false
+}
+
+// interpreters run the program named after them, e.g. `python3 -m pytest`.
+var interpreters = []string{"python", "python3", "node", "ruby", "perl", "bun", "deno"}
+
+// subcommanded are tools named with their subcommand, e.g. `go test`.
+var subcommanded = []string{"go", "npm", "pnpm", "yarn", "cargo", "make", "mvn", "gradle", "dotnet", "docker", "uv"}
+
+// commandName names the command cmdline runs, e.g. "pytest" for
+// `/usr/bin/python3 -m pytest -x` or "go test" for `go test ./...`.
+func commandName(cmdline []string) string {
+	if len(cmdline) == 0 || cmdline[0] == "" {
+		return ""
+	}
+	name := filepath.Base(cmdline[0])
+	args := cmdline[1:]
+	if slices.Contains(interpreters, name) {
+		for i := 0; i < len(args); i++ {
+			switch {
+			case args[i] == "-m" && i+1 < len(args):
+				return args[i+1]
+			case !strings.HasPrefix(args[i], "-"):
+				return filepath.Base(args[i])
+			}
+		}
+		return name
+	}
+	if slices.Contains(subcommanded, name) && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return name + " " + args[0]
+	}
+	return name
+}
+
+// busyTime splits the time a session spent RUNNING between the model and
+// the commands it ran.
+type busyTime struct {
+	Thinking time.Duration
+	Tools    time.Duration
+}
+
+// add counts the span since the last capture toward what prev, as it was
+// then, was doing.
+func (b busyTime) add(prev session, span time.Duration) busyTime {
+	if prev.Status != agentstatus.Running || span <= 0 {
+		return b
+	}
+	if prev.Child != "" {
+		b.Tools += span
+	} else {
+		b.Thinking += span
+	}
+	return b
+}
+
+// String renders e.g. "thinking 12m · tools 30m".
+func (b busyTime) String() string {
+	if b.Thinking+b.Tools == 0 {
+		return ""
+	}
+	round := func(d time.Duration) string { return timeFmt.Duration(d.Truncate(time.Second)) }
+	return "thinking " + round(b.Thinking) + " · tools " + round(b.Tools)
+}
+
+// childNote renders the command a RUNNING session has waited on for long,
+// e.g. " — pytest 12m", or "".
+func childNote(s session, now time.Time) string {
+	if s.Status != agentstatus.Running || s.Child == "" || now.Sub(s.ChildSince) < longChildAfter {
+		return ""
+	}
+	return " — " + s.Child + " " + statusAge(s.ChildSince, now)
+}
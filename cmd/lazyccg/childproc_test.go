@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestParseProcStat(t *testing.T) {
+	stat := "4242 (tmux: server (1)) S 1 4242 4242 0 -1 4194560 1052 0 0 0 12 4 0 0 20 0 1 0 987654 22056960 1024 18446744073709551615"
+	ppid, start, ok := parseProcStat(stat)
+	if !ok || ppid != 1 || start != 987654 {
+		t.Errorf("parseProcStat() = %d, %d, %v; want 1, 987654", ppid, start, ok)
+	}
+	if _, _, ok := parseProcStat("4242 (sh"); ok {
+		t.Error("parseProcStat() read a cut-off line")
+	}
+}
+
+func TestToolChild(t *testing.T) {
+	t0 := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	procs := []procEntry{
+		{Pid: 10, PPid: 1, Cmdline: []string{"claude"}, Start: t0},
+		{Pid: 11, PPid: 10, Cmdline: []string{"npx", "-y", "@modelcontextprotocol/server-github"}, Start: t0},
+		// npx runs the MCP server through a shell
+		{Pid: 15, PPid: 11, Cmdline: []string{"sh", "-c", "mcp-server-github"}, Start: t0},
+		{Pid: 16, PPid: 15, Cmdline: []string{"node", "/home/me/.npm/_npx/3b2a/node_modules/.bin/mcp-server-github"}, Start: t0},
+		{Pid: 12, PPid: 10, Cmdline: []string{"/bin/bash", "-c", "-l", "eval 'python3 -m pytest -x'"}, Start: t0.Add(time.Minute)},
+		{Pid: 13, PPid: 12, Cmdline: []string{"/usr/bin/python3", "-m", "pytest", "-x"}, Start: t0.Add(time.Minute)},
+		{Pid: 14, PPid: 13, Cmdline: []string{"/usr/bin/python3", "-c", "worker"}, Start: t0.Add(2 * time.Minute)},
+		{Pid: 20, PPid: 1, Cmdline: []string{"codex"}, Start: t0},
+	}
+	c, ok := toolChild(procs, []int{10})
+	if !ok || c.Name != "pytest" || !c.Since.Equal(t0.Add(time.Minute)) {
+		t.Errorf("toolChild(claude) = %+v, %v; want pytest since 14:01", c, ok)
+	}
+	if c, ok := toolChild(procs, []int{20}); ok {
+		t.Errorf("toolChild(codex) = %+v; want none, it runs nothing", c)
+	}
+	// Between commands only the MCP server runs
+	if c, ok := toolChild(procs[:4], []int{10}); ok {
+		t.Errorf("toolChild(claude, idle) = %+v; want none, the MCP server isn't a command", c)
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		cmdline []string
+		want    string
+	}{
+		{[]string{"/usr/bin/python3", "-m", "pytest", "-x"}, "pytest"},
+		{[]string{"node", "/src/web/node_modules/.bin/jest"}, "jest"},
+		{[]string{"go", "test", "./..."}, "go test"},
+		{[]string{"cargo", "--locked", "build"}, "cargo"},
+		{[]string{"/usr/local/bin/rspec"}, "rspec"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := commandName(tt.cmdline); got != tt.want {
+			t.Errorf("commandName(%q) = %q, want %q", tt.cmdline, got, tt.want)
+		}
+	}
+}
+
+func TestBusyTime(t *testing.T) {
+	var b busyTime
+	b = b.add(session{Status: agentstatus.Running}, 10*time.Second)
+	b = b.add(session{Status: agentstatus.Running, Child: "pytest"}, 30*time.Second)
+	b = b.add(session{Status: agentstatus.Idle}, time.Minute)
+	if b.Thinking != 10*time.Second || b.Tools != 30*time.Second {
+		t.Errorf("busyTime = %+v, want 10s thinking and 30s in tools", b)
+	}
+
+	now := time.Date(2026, 10, 16, 14, 12, 0, 0, time.UTC)
+	s := session{Status: agentstatus.Running, Child: "pytest", ChildSince: now.Add(-12 * time.Minute)}
+	if got := childNote(s, now); got != " — pytest 12m" {
+		t.Errorf("childNote() = %q", got)
+	}
+	s.ChildSince = now.Add(-5 * time.Second)
+	if got := childNote(s, now); got != "" {
+		t.Errorf("childNote() = %q for a command just started, want none", got)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "fmt"
+
+// commandNames are the commands runSubcommand runs; `demo` runs the
+// dashboard instead.
+var commandNames = []string{
+	"capture-fixture", "list", "status", "search", "diff", "report",
+	"profile", "open", "toggle", "launch", "export-session", "daemon",
+}
+
+// runSubcommand dispatches `lazyccg [flags] <command> [args]`.
+// choice is how lazyccg was told to read sessions, for the commands that
+// pass it on.
+func runSubcommand(args []string, choice backendChoice, prefixes []string, maxLines int) error {
+	switch args[0] {
+	case "capture-fixture":
+		return runCaptureFixture(args[1:], prefixes, maxLines)
+	case "list", "status":
+		return runListing(args[0], args[1:], prefixes, maxLines)
+	case "search":
+		return runSearch(args[1:])
+	case "diff":
+		return runDiff(args[1:])
+	case "report":
+		return runReport(args[1:])
+	case "profile":
+		return runProfile(args[1:])
+	case "open":
+		return runOpen(args[1:])
+	case "toggle":
+		return runToggle(args[1:])
+	case "launch":
+		return runLaunch(args[1:])
+	case "export-session":
+		return runExportSession(args[1:], prefixes)
+	case "daemon":
+		return runDaemon(args[1:], choice, prefixes, maxLines)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// config holds user settings loaded from config.yaml in the config dir.
+type config struct {
+	// StatusCommands maps an AI name (e.g. "claude") to a shell command
+	// whose stdout is used as the session status.
+	StatusCommands map[string]string `yaml:"status_commands"`
+	// CaptureCommands maps an AI name to a shell command whose stdout is
+	// read as the session's output instead of its screen, e.g. tailing a
+	// log the agent writes.
+	CaptureCommands map[string]string `yaml:"capture_commands"`
+
+	// Agents adds agent CLIs to the built-in ones, or changes how those are
+	// detected and tagged; see agentInfo.
+	Agents []agentInfo `yaml:"agents"`
+
+	// Hooks takes status events from Claude Code's hooks.
+	Hooks hooksConfig `yaml:"hooks"`
+
+	// AgentLogs maps an AI name to globs of the session logs it writes,
+	// overriding the defaults for codex and gemini; [] stops reading them.
+	AgentLogs map[string][]string `yaml:"agent_logs"`
+
+	// Lookback is how many lines up the screen the parsers look for their
+	// phrases; unset keeps each parser's own, 10 to 15. An agent's lookback
+	// overrides it.
+	Lookback int `yaml:"lookback"`
+
+	// Preview shows the last output line under each session at startup.
+	Preview bool `yaml:"preview"`
+	// CurrentTask shows what each session is working on under it. Off by
+	// default: the extra line doubles each row's height.
+	CurrentTask bool `yaml:"current_task"`
+
+	// Pager is the shell command v pipes a session's scrollback into;
+	// unset uses $PAGER, else less -R. PagerWindow opens it in a window
+	// of its own instead of lazyccg's terminal.
+	Pager       string `yaml:"pager"`
+	PagerWindow bool   `yaml:"pager_window"`
+
+	// Follow always snaps the Output panel to the newest output.
+	Follow bool `yaml:"follow"`
+
+	// ActivityWindow is how long after its screen last changed a session
+	// counts as RUNNING (default 5s).
+	ActivityWindow time.Duration `yaml:"activity_window"`
+	// StatusSettle is how many captures in a row a status read off the
+	// screen must be seen on before it shows (default 2); see settle.
+	StatusSettle int `yaml:"status_settle"`
+
+	// SplitThinking shows agents' reasoning in a pane of its own under their
+	// responses (toggle with T).
+	SplitThinking bool `yaml:"split_thinking"`
+
+	// TimeFormat is "absolute" (clock time, default) or "relative" ("3m ago").
+	TimeFormat string `yaml:"time_format"`
+	// Locale overrides $LC_ALL/$LC_TIME/$LANG for time display, e.g. "ja_JP".
+	Locale string `yaml:"locale"`
+
+	// History records session transcripts for archive search.
+	History bool `yaml:"history"`
+
+	// StatusOrder orders the Status panel; statuses not listed follow.
+	StatusOrder []string `yaml:"status_order"`
+	// HiddenStatuses are left out of the Status panel.
+	HiddenStatuses []string `yaml:"hidden_statuses"`
+
+	// TitleWidth is the width of session titles in columns, or "auto" to
+	// fit the Sessions panel; unset cuts them at 20 characters.
+	TitleWidth string `yaml:"title_width"`
+	// TitleScroll scrolls the selected session's title when it's too long,
+	// instead of cutting it.
+	TitleScroll bool `yaml:"title_scroll"`
+
+	// TitleSync keeps kitty window titles set to TitleTemplate, which may
+	// use {ai}, {repo}, {branch}, {task}, and {status}.
+	TitleSync     bool   `yaml:"title_sync"`
+	TitleTemplate string `yaml:"title_template"`
+
+	// IncludeSelf lists the window lazyccg itself runs in, which is left
+	// out by default.
+	IncludeSelf bool `yaml:"include_self"`
+
+	// Approvals maps an AI name to the answers offered by the approval
+	// popup when none can be read from the prompt on screen.
+	Approvals map[string][]approvalOption `yaml:"approvals"`
+
+	// LowPower is "on", "off" (default), or "auto" to poll and render less
+	// while on battery.
+	LowPower string `yaml:"low_power"`
+
+	// SSHProbe looks for agents on the far end of windows running ssh.
+	SSHProbe sshProbeConfig `yaml:"ssh_probe"`
+
+	// Launch sets up the environment of agents lazyccg starts.
+	Launch launchConfig `yaml:"launch"`
+
+	// StatusRules are tried before detection profiles and the built-in
+	// parsers, highest priority first; see statusRule.
+	StatusRules []statusRule `yaml:"status_rules"`
+	// Ignore are patterns for lines status inference skips; see
+	// compileIgnore.
+	Ignore    []string `yaml:"ignore"`
+	ignoreRes []*regexp.Regexp
+	// Intents label sessions by keywords in their first prompt, before the
+	// built-in labels; see intentRule.
+	Intents []intentRule `yaml:"intents"`
+
+	// Theme is the color palette: default, high-contrast, deuteranopia, or
+	// protanopia.
+	Theme string `yaml:"theme"`
+	// StatusSymbols marks statuses with a symbol (▶ ✋ ✔ ✖) as well as a
+	// color. Unset follows the theme: on for all but the default.
+	StatusSymbols *bool `yaml:"status_symbols"`
+
+	// StaleAfter marks sessions WAITING longer than this as STALE (default
+	// 15m).
+	StaleAfter time.Duration `yaml:"stale_after"`
+
+	// Waiting configures the daily total of time sessions spent WAITING.
+	Waiting waitingConfig `yaml:"waiting"`
+}
+
+var cfg config
+
+// configDir returns $XDG_CONFIG_HOME/lazyccg, falling back to ~/.config/lazyccg.
+func configDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "lazyccg")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "lazyccg")
+}
+
+func defaultConfigPath() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+// loadConfig reads the config file at path. A missing file is not an error.
+func loadConfig(path string) (config, error) {
+	var c config
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := validateTitleWidth(c.TitleWidth); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	if c.Lookback < 0 {
+		return c, fmt.Errorf("%s: lookback: %d is negative", path, c.Lookback)
+	}
+	if err := c.Launch.validate(); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := compileAgents(&c); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := compileStatusRules(c.StatusRules); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := compileIgnore(&c); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateIntents(c.Intents); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := loadConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("missing config should not fail: %v", err)
+	}
+	if len(c.StatusCommands) != 0 {
+		t.Errorf("expected empty config, got %+v", c)
+	}
+
+	path := filepath.Join(dir, "config.yaml")
+	data := "status_commands:\n  claude: ~/bin/claude-status\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if got := c.StatusCommands["claude"]; got != "~/bin/claude-status" {
+		t.Errorf("StatusCommands[claude] = %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("status_commands: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(path); err == nil {
+		t.Error("invalid YAML should fail")
+	}
+}
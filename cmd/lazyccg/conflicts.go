@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/atani/lazyccg/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fileConflict is a file edited by more than one live session.
+type fileConflict struct {
+	Path     string
+	Sessions []session
+}
+
+// findConflicts cross-references the files sessions edited and returns the
+// paths edited by two or more of them, sorted by path. Exited sessions are
+// no longer editing anything and are left out.
+func findConflicts(sessions []session) []fileConflict {
+	editors := make(map[string][]session)
+	for _, s := range sessions {
+		if s.Status == "EXITED" {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, path := range s.Edited {
+			if !filepath.IsAbs(path) {
+				if s.Cwd == "" {
+					continue
+				}
+				path = filepath.Join(s.Cwd, path)
+			}
+			path = filepath.Clean(path)
+			if !seen[path] {
+				seen[path] = true
+				editors[path] = append(editors[path], s)
+			}
+		}
+	}
+	var conflicts []fileConflict
+	for path, ss := range editors {
+		if len(ss) > 1 {
+			conflicts = append(conflicts, fileConflict{Path: path, Sessions: ss})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+	return conflicts
+}
+
+// conflictWindows returns the windows involved in any conflict.
+func conflictWindows(conflicts []fileConflict) map[int]bool {
+	windows := make(map[int]bool)
+	for _, c := range conflicts {
+		for _, s := range c.Sessions {
+			windows[s.WindowID] = true
+		}
+	}
+	return windows
+}
+
+// newConflicts returns the paths in next that were not conflicting in prev.
+func newConflicts(prev, next []fileConflict) []string {
+	old := make(map[string]bool)
+	for _, c := range prev {
+		old[c.Path] = true
+	}
+	var paths []string
+	for _, c := range next {
+		if !old[c.Path] {
+			paths = append(paths, c.Path)
+		}
+	}
+	return paths
+}
+
+func conflictNotice(paths []string) string {
+	if len(paths) == 1 {
+		return fmt.Sprintf("conflict: %s edited by several sessions (c: view)", shortenHome(paths[0]))
+	}
+	return fmt.Sprintf("conflict: %d files edited by several sessions (c: view)", len(paths))
+}
+
+func (m model) renderConflictScreen(width, height int) string {
+	title := fmt.Sprintf("Conflicts %d file(s)", len(m.conflicts))
+	innerWidth := width - 2
+	var lines []string
+	for _, c := range m.conflicts {
+		lines = append(lines, statusWaiting.Render(" ⚠ "+ui.TruncateLeft(shortenHome(c.Path), innerWidth-3)))
+		for _, s := range c.Sessions {
+			line := fmt.Sprintf("     %s (%s)  %s", s.Title, shortAI(s.AI), m.formatStatus(s.Status))
+			lines = append(lines, ui.Truncate(line, innerWidth))
+		}
+		lines = append(lines, "")
+	}
+	if len(lines) == 0 {
+		lines = append(lines, helpDescStyle.Render(" no two sessions are editing the same file"))
+	}
+	start := m.conflictScroll
+	if start > len(lines) {
+		start = len(lines)
+	}
+	return drawBox(title, lines[start:], width, height, yellow)
+}
+
+func (m model) updateConflicts(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "c":
+		m.conflictsOpen = false
+	case "up", "k":
+		if m.conflictScroll > 0 {
+			m.conflictScroll--
+		}
+	case "down", "j":
+		m.conflictScroll++
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindConflicts(t *testing.T) {
+	sessions := []session{
+		{WindowID: 1, Cwd: "/src/api", Status: "RUNNING", Edited: []string{"main.go", "go.mod"}},
+		{WindowID: 2, Cwd: "/src/api/cmd", Status: "IDLE", Edited: []string{"../main.go", "/src/api/main.go"}},
+		{WindowID: 3, Cwd: "/src/api", Status: "EXITED", Edited: []string{"go.mod"}},
+		{WindowID: 4, Cwd: "/src/web", Status: "RUNNING", Edited: []string{"go.mod"}},
+	}
+	conflicts := findConflicts(sessions)
+	if len(conflicts) != 1 || conflicts[0].Path != "/src/api/main.go" {
+		t.Fatalf("findConflicts() = %+v, want only /src/api/main.go", conflicts)
+	}
+	if n := len(conflicts[0].Sessions); n != 2 {
+		t.Errorf("conflict lists %d sessions, want 2", n)
+	}
+	if got, want := conflictWindows(conflicts), map[int]bool{1: true, 2: true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("conflictWindows() = %v, want %v", got, want)
+	}
+}
+
+func TestNewConflicts(t *testing.T) {
+	prev := []fileConflict{{Path: "/a"}}
+	next := []fileConflict{{Path: "/a"}, {Path: "/b"}}
+	if got := newConflicts(prev, next); !reflect.DeepEqual(got, []string{"/b"}) {
+		t.Errorf("newConflicts() = %v, want [/b]", got)
+	}
+}
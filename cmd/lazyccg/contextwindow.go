@@ -0,0 +1,89 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Codex and Gemini CLI show how much of the context window is left under
+// their prompts, and Claude Code does once it runs low:
+//
+//	? for shortcuts                     87% context left
+//	gemini-2.5-pro (98% context left)
+//	Context left until auto-compact: 12%
+//	Context low (8% remaining) · Run /compact to compact & continue
+//
+// The Sessions panel shows it next to each session, in the warning color
+// once it runs low.
+
+var contextLeftPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(\d{1,3})% context left\b`),
+	regexp.MustCompile(`(?i)context left until auto-compact: (\d{1,3})%`),
+	regexp.MustCompile(`(?i)context low \((\d{1,3})% remaining\)`),
+}
+
+// tokensUsedPattern matches the token count in Codex's footer, e.g.
+// "12.3K tokens used".
+var tokensUsedPattern = regexp.MustCompile(`(?i)\b(\d+(?:[.,]\d+)?)\s*([km]?) tokens used\b`)
+
+// contextLowPercent is the share of the context window left below which a
+// session is marked as running out.
+const contextLowPercent = 20
+
+// contextLeft returns the percentage of the context window left, from the
+// last footer on screen that says.
+func contextLeft(lines []string) (int, bool) {
+	for i := len(lines) - 1; i >= max(len(lines)-contextLines, 0); i-- {
+		for _, re := range contextLeftPatterns {
+			if m := re.FindStringSubmatch(lines[i]); m != nil {
+				n, err := strconv.Atoi(m[1])
+				if err == nil && n <= 100 {
+					return n, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// contextLines is how far up the screen context footers are looked for.
+const contextLines = 15
+
+// tokensUsed returns the token count from a "… tokens used" footer.
+func tokensUsed(lines []string) (int, bool) {
+	for i := len(lines) - 1; i >= max(len(lines)-contextLines, 0); i-- {
+		m := tokensUsedPattern.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		n, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(m[2]) {
+		case "k":
+			n *= 1_000
+		case "m":
+			n *= 1_000_000
+		}
+		return int(n), true
+	}
+	return 0, false
+}
+
+// contextGauge renders what's left of s's context window for the Sessions
+// panel, e.g. " ctx 12%", or "" when unknown.
+func contextGauge(s session) string {
+	if !s.ContextKnown {
+		return ""
+	}
+	text := " ctx " + strconv.Itoa(s.ContextLeft) + "%"
+	switch {
+	case s.ContextLeft < contextLowPercent/2:
+		return statusExited.Bold(true).Render(text)
+	case s.ContextLeft < contextLowPercent:
+		return statusWaiting.Render(text)
+	}
+	return helpDescStyle.Render(text)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestContextLeft(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  int
+		ok    bool
+	}{
+		{"codex footer", []string{"▌ Ask Codex", "", "⏎ send   ⌃J newline   ⌃T transcript      87% context left"}, 87, true},
+		{"gemini footer", []string{"~/src/app   no sandbox   gemini-2.5-pro (98% context left)"}, 98, true},
+		{"claude auto-compact", []string{"> ", "  Context left until auto-compact: 12%"}, 12, true},
+		{"claude low", []string{"Context low (8% remaining) · Run /compact to compact & continue"}, 8, true},
+		{"last footer wins", []string{"90% context left", "output", "40% context left"}, 40, true},
+		{"no footer", []string{"> ", "? for shortcuts"}, 0, false},
+		{"not a percentage", []string{"250% context left"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := contextLeft(tt.lines)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("contextLeft() = %d, %v, want %d, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTokensUsed(t *testing.T) {
+	tests := []struct {
+		line string
+		want int
+		ok   bool
+	}{
+		{"12.3K tokens used · 87% context left", 12300, true},
+		{"1,024 tokens used", 1024, true},
+		{"2M tokens used", 2000000, true},
+		{"no tokens here", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tokensUsed([]string{tt.line})
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("tokensUsed(%q) = %d, %v, want %d, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	"github.com/mattn/go-runewidth"
+)
+
+// A program waiting for input leaves the cursor at the end of its prompt.
+// Where the terminal can say where the cursor is, a screen the parsers
+// can't place is read by the line under it: a visible cursor right after
+// "Enter a name:", "(Pdb)", or "Continue [y/N]" is a prompt, however it
+// ends.
+
+// screenCursor is where a window's cursor is.
+type screenCursor struct {
+	Known   bool
+	Visible bool
+	Line    string // the screen line it is on
+	Col     int    // its column in cells, from 0
+}
+
+// cursorBackend is implemented by backends that can tell where a window's
+// cursor is.
+type cursorBackend interface {
+	// CaptureWithCursor returns the screen's text and its cursor.
+	CaptureWithCursor(windowID int) (string, screenCursor, error)
+}
+
+// kittyCursor matches what get-text --add-cursor appends to the text: the
+// cursor's visibility, position, and maybe shape.
+var kittyCursor = regexp.MustCompile(`\x1b\[\?25([hl])\x1b\[(\d+);(\d+)H(?:\x1b\[\d* q)?\s*$`)
+
+// splitCursor takes the cursor kitty added off the end of text.
+func splitCursor(text string) (string, screenCursor) {
+	m := kittyCursor.FindStringSubmatchIndex(text)
+	if m == nil {
+		return text, screenCursor{}
+	}
+	row, _ := strconv.Atoi(text[m[4]:m[5]])
+	col, _ := strconv.Atoi(text[m[6]:m[7]])
+	c := screenCursor{Known: true, Visible: text[m[2]:m[3]] == "h", Col: col - 1}
+	text = text[:m[0]]
+	if lines := strings.Split(text, "\n"); row >= 1 && row <= len(lines) {
+		c.Line = lines[row-1]
+	}
+	return text, c
+}
+
+// promptEnds are how prompts end, before the cursor.
+const promptEnds = ">:?$%#❯›»])"
+
+// promptAtCursor returns the prompt the cursor waits at: text before it
+// that ends like a prompt, with nothing but box borders after it.
+func promptAtCursor(c screenCursor) (string, bool) {
+	if !c.Known || !c.Visible {
+		return "", false
+	}
+	before, after := splitAtCell(c.Line, c.Col)
+	const borders = "│┃|╰╯ "
+	before = strings.Trim(before, borders)
+	if strings.Trim(after, borders) != "" || before == "" {
+		return "", false
+	}
+	last, _ := lastRune(before)
+	if !strings.ContainsRune(promptEnds, last) {
+		return "", false
+	}
+	return before, true
+}
+
+// cursorStatus is the status of a screen whose cursor waits at a prompt:
+// NEEDS_INPUT when the prompt asks something, else IDLE.
+func cursorStatus(c screenCursor) (agentstatus.Status, string, bool) {
+	prompt, ok := promptAtCursor(c)
+	if !ok {
+		return "", "", false
+	}
+	lower := strings.ToLower(prompt)
+	if strings.HasSuffix(lower, "?") || strings.Contains(lower, "[y/n]") || strings.Contains(lower, "(y/n)") {
+		return agentstatus.NeedsInput, "cursor at a question: " + strconv.Quote(prompt), true
+	}
+	return agentstatus.Idle, "cursor at a prompt: " + strconv.Quote(prompt), true
+}
+
+// splitAtCell splits line at column col, counting wide characters as two.
+func splitAtCell(line string, col int) (string, string) {
+	width := 0
+	for i, r := range line {
+		if width >= col {
+			return line[:i], line[i:]
+		}
+		width += runewidth.RuneWidth(r)
+	}
+	return line, ""
+}
+
+func lastRune(s string) (rune, bool) {
+	r := []rune(s)
+	if len(r) == 0 {
+		return 0, false
+	}
+	return r[len(r)-1], true
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestSplitCursor(t *testing.T) {
+	text, c := splitCursor("output\nEnter a name: \n\x1b[?25h\x1b[2;15H\x1b[2 q")
+	if text != "output\nEnter a name: \n" {
+		t.Errorf("text = %q, want the cursor taken off", text)
+	}
+	if !c.Known || !c.Visible || c.Line != "Enter a name: " || c.Col != 14 {
+		t.Errorf("cursor = %+v, want visible on line 2 at column 14", c)
+	}
+	if _, c := splitCursor("no cursor here\n"); c.Known {
+		t.Errorf("cursor = %+v for text without one", c)
+	}
+	if _, c := splitCursor("x\x1b[?25l\x1b[1;2H"); !c.Known || c.Visible {
+		t.Errorf("cursor = %+v, want known and hidden", c)
+	}
+}
+
+func TestCursorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor screenCursor
+		want   agentstatus.Status
+		ok     bool
+	}{
+		{"colon prompt", screenCursor{Known: true, Visible: true, Line: "Enter a name: ", Col: 14}, agentstatus.Idle, true},
+		{"pdb", screenCursor{Known: true, Visible: true, Line: "(Pdb) ", Col: 6}, agentstatus.Idle, true},
+		{"question", screenCursor{Known: true, Visible: true, Line: "Overwrite config.yaml? ", Col: 23}, agentstatus.NeedsInput, true},
+		{"yes or no", screenCursor{Known: true, Visible: true, Line: "Continue [y/N] ", Col: 15}, agentstatus.NeedsInput, true},
+		{"in a box", screenCursor{Known: true, Visible: true, Line: "│ ❯                 │", Col: 4}, agentstatus.Idle, true},
+		{"wide characters", screenCursor{Known: true, Visible: true, Line: "名前を入力: ", Col: 12}, agentstatus.Idle, true},
+		{"text after the cursor", screenCursor{Known: true, Visible: true, Line: "$ make test", Col: 2}, "", false},
+		{"after output", screenCursor{Known: true, Visible: true, Line: "Downloading 40 files", Col: 20}, "", false},
+		{"hidden", screenCursor{Known: true, Line: "> ", Col: 2}, "", false},
+		{"unknown", screenCursor{}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, ok := cursorStatus(tt.cursor)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("cursorStatus() = %s, %v, want %s, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// `lazyccg daemon` watches sessions without the dashboard, for running
+// lazyccg as a background service: it polls the terminal, records history
+// and runs Lua event handlers as configured, takes hook and bell events,
+// and answers /healthz. `lazyccg daemon install` writes a systemd user unit
+// (Linux) or a launchd agent (macOS) that starts it at login, and starts
+// it; `lazyccg daemon uninstall` stops and removes it.
+//
+// The daemon listens on its own address, so the dashboard can keep the
+// hook listener's; point hooks at whichever should have them.
+//
+// With -backend auto the daemon looks for terminals again on every poll:
+// it usually starts at login, before any terminal does.
+//
+// While it runs, the daemon records transcripts and runs Lua event
+// handlers, not the dashboard: it holds a lock on daemon.lock in the data
+// dir, and a dashboard that finds it held leaves both alone, so nothing is
+// recorded or notified twice.
+
+const (
+	defaultDaemonListen = "127.0.0.1:7788"
+	defaultDaemonPoll   = 2 * time.Second
+
+	systemdUnitName = "lazyccg.service"
+	launchdLabel    = "com.github.atani.lazyccg"
+)
+
+// serviceSpec is how the service manager runs the daemon.
+type serviceSpec struct {
+	Args []string // the command line, the binary first
+	Log  string   // file the daemon's output is appended to
+	Path string   // $PATH to run with; service managers start with a bare one
+}
+
+// servicePaths returns where the service file for goos goes and where the
+// daemon logs: a systemd user unit and the data dir on Linux, a launch
+// agent and ~/Library/Logs on macOS.
+func servicePaths(goos, home string) (unit, log string, err error) {
+	switch goos {
+	case "linux":
+		dir := os.Getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			dir = filepath.Join(home, ".config")
+		}
+		return filepath.Join(dir, "systemd", "user", systemdUnitName), filepath.Join(dataDir(), "daemon.log"), nil
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"),
+			filepath.Join(home, "Library", "Logs", "lazyccg", "daemon.log"), nil
+	}
+	return "", "", fmt.Errorf("daemon install supports Linux (systemd) and macOS (launchd), not %s", goos)
+}
+
+// systemdUnit renders a systemd user unit running spec.
+func systemdUnit(spec serviceSpec) string {
+	quoted := make([]string, len(spec.Args))
+	for i, arg := range spec.Args {
+		quoted[i] = systemdQuote(arg)
+	}
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=lazyccg: watch AI agent sessions\n\n")
+	b.WriteString("[Service]\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(quoted, " "))
+	if spec.Path != "" {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote("PATH="+spec.Path))
+	}
+	fmt.Fprintf(&b, "StandardOutput=append:%s\n", spec.Log)
+	fmt.Fprintf(&b, "StandardError=append:%s\n", spec.Log)
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote quotes arg for ExecStart and Environment when it needs it.
+func systemdQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\$%;") {
+		return arg
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `$$`, `%`, `%%`)
+	return `"` + r.Replace(arg) + `"`
+}
+
+// launchdPlist renders a launch agent running spec.
+func launchdPlist(spec serviceSpec) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t<string>%s</string>\n", launchdLabel)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range spec.Args {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", html.EscapeString(arg))
+	}
+	b.WriteString("\t</array>\n")
+	if spec.Path != "" {
+		fmt.Fprintf(&b, "\t<key>EnvironmentVariables</key>\n\t<dict>\n\t\t<key>PATH</key>\n\t\t<string>%s</string>\n\t</dict>\n", html.EscapeString(spec.Path))
+	}
+	fmt.Fprintf(&b, "\t<key>StandardOutPath</key>\n\t<string>%s</string>\n", html.EscapeString(spec.Log))
+	fmt.Fprintf(&b, "\t<key>StandardErrorPath</key>\n\t<string>%s</string>\n", html.EscapeString(spec.Log))
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// daemonArgs is the command line that starts the daemon reading sessions
+// as choice says.
+func daemonArgs(exe string, choice backendChoice, listen string, poll time.Duration) []string {
+	args := []string{exe}
+	if choice.Name != "" && choice.Name != "auto" {
+		args = append(args, "-backend", choice.Name)
+	}
+	if choice.KittySocket != "" {
+		args = append(args, "-kitty-socket", choice.KittySocket)
+	}
+	if choice.SSH != "" {
+		args = append(args, "-ssh", choice.SSH)
+	}
+	return append(args, "daemon", "-listen", listen, "-poll", poll.String())
+}
+
+// runDaemon implements `lazyccg daemon [install|uninstall]`.
+func runDaemon(args []string, choice backendChoice, prefixes []string, maxLines int) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "install":
+			return runDaemonInstall(args[1:], choice)
+		case "uninstall":
+			return runDaemonUninstall(args[1:])
+		}
+	}
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	listen := fs.String("listen", defaultDaemonListen, "address to take hook events and answer /healthz on")
+	poll := fs.Duration("poll", defaultDaemonPoll, "poll interval")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: lazyccg daemon [-listen addr] [-poll interval] | install | uninstall")
+	}
+	var detect *autoDetection
+	if choice.Name == "auto" && choice.SSH == "" {
+		detect = &autoDetection{kittySocket: resolveKittySocket(choice.KittySocket)}
+		// What lazyccg started with
+		env := currentAutoEnv()
+		names, socket := detectBackends(env, detect.kittySocket)
+		detect.found = detectionKey(names, socket, env)
+	}
+	return serveDaemon(*listen, *poll, detect, prefixes, maxLines)
+}
+
+func daemonLockPath() string {
+	return filepath.Join(dataDir(), "daemon.lock")
+}
+
+// lockDaemon takes the daemon lock at path, failing when another daemon
+// has it. The lock goes when the file is closed or the process exits.
+func lockDaemon(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("another lazyccg daemon is running (%s is locked)", path)
+	}
+	return f, nil
+}
+
+// daemonRunning reports whether a daemon holds the lock at path.
+func daemonRunning(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return errors.Is(syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB), syscall.EWOULDBLOCK)
+}
+
+// autoDetection is -backend auto in the daemon, which looks for terminals
+// on every poll and switches backends when it finds others.
+type autoDetection struct {
+	kittySocket string
+	found       string // what was found last, as detectionKey says
+}
+
+// detectionKey says what detectBackends found in env, including kitty's
+// sockets, so a kitty started later is picked up.
+func detectionKey(names []string, kittySocket string, env autoEnv) string {
+	key := strings.Join(names, ",") + " " + kittySocket
+	if kittySocket == "auto" {
+		key += " " + strings.Join(env.kittySockets, ",")
+	}
+	return key
+}
+
+// redetect returns the backends found in env when they differ from last
+// time, or nil.
+func (d *autoDetection) redetect(env autoEnv) (Backend, error) {
+	names, socket := detectBackends(env, d.kittySocket)
+	key := detectionKey(names, socket, env)
+	if key == d.found {
+		return nil, nil
+	}
+	b, err := newDetectedBackend(names, socket)
+	if err != nil {
+		return nil, err
+	}
+	d.found = key
+	return withLocalTmux(b), nil
+}
+
+// serveDaemon polls sessions every poll and hands their events on, until
+// it is told to stop. detect is nil unless the backend is detected.
+func serveDaemon(listen string, poll time.Duration, detect *autoDetection, prefixes []string, maxLines int) error {
+	lock, err := lockDaemon(daemonLockPath())
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+	shutdownCtx = ctx
+	if err := serveHooks(listen); err != nil {
+		return err
+	}
+	bus = newEventBus()
+	subscribeAll(bus, func() bool { return true })
+	// The service manager keeps stderr in the daemon's log
+	bus.Subscribe(func(events []sessionEvent) { logDaemonEvents(os.Stderr, events) })
+	defer shutdown()
+	fmt.Fprintf(os.Stderr, "%s daemon %s watching %s, listening on %s\n", time.Now().Format(time.DateTime), version, backend.Name(), listen)
+
+	state := newPollState()
+	var last []session
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		if detect != nil {
+			b, err := detect.redetect(currentAutoEnv())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s detect: %v\n", time.Now().Format(time.DateTime), err)
+			} else if b != nil {
+				// The old one's connections and helpers go with it
+				closeBackend(backend)
+				backend = b
+				fmt.Fprintf(os.Stderr, "%s watching %s\n", time.Now().Format(time.DateTime), b.Name())
+			}
+		}
+		state.base = poll
+		sessions, next, err := loadSessions(prefixes, maxLines, state)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s poll: %v\n", time.Now().Format(time.DateTime), err)
+		} else {
+			bus.Publish(diffSessions(last, sessions))
+			last, state = sessions, next
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// logDaemonEvents writes sessions coming, going, and changing status to w;
+// new output would flood the log.
+func logDaemonEvents(w io.Writer, events []sessionEvent) {
+	now := time.Now().Format(time.DateTime)
+	for _, e := range events {
+		if e.Kind == outputAppended {
+			continue
+		}
+		fmt.Fprintf(w, "%s %s w%d %q%s\n", now, e.Kind, e.Session.WindowID, e.Session.Title, eventDetail(e))
+	}
+}
+
+// runDaemonInstall implements `lazyccg daemon install`. The daemon reads
+// sessions as lazyccg was told to, unless install's flags say otherwise.
+func runDaemonInstall(args []string, choice backendChoice) error {
+	fs := flag.NewFlagSet("daemon install", flag.ContinueOnError)
+	fs.StringVar(&choice.Name, "backend", choice.Name, "terminal the daemon reads sessions from")
+	fs.StringVar(&choice.KittySocket, "kitty-socket", choice.KittySocket, "kitty socket the daemon talks to (default: found in /tmp)")
+	listen := fs.String("listen", defaultDaemonListen, "address the daemon takes hook events and answers /healthz on")
+	poll := fs.Duration("poll", defaultDaemonPoll, "poll interval")
+	printOnly := fs.Bool("print", false, "print the service file instead of installing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	unit, log, err := servicePaths(runtime.GOOS, home)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	spec := serviceSpec{
+		Args: daemonArgs(exe, choice, *listen, *poll),
+		Log:  log,
+		Path: os.Getenv("PATH"),
+	}
+	content := systemdUnit(spec)
+	if runtime.GOOS == "darwin" {
+		content = launchdPlist(spec)
+	}
+	if *printOnly {
+		fmt.Print(content)
+		return nil
+	}
+
+	for _, dir := range []string{filepath.Dir(unit), filepath.Dir(log)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(unit, []byte(content), 0o644); err != nil {
+		return err
+	}
+	if runtime.GOOS == "darwin" {
+		// Reloaded if it was installed before
+		exec.Command("launchctl", "unload", unit).Run()
+		err = runQuiet("launchctl", "load", "-w", unit)
+	} else {
+		err = runQuiet("systemctl", "--user", "daemon-reload")
+		if err == nil {
+			err = runQuiet("systemctl", "--user", "enable", "--now", systemdUnitName)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("wrote %s, but starting it failed: %w", unit, err)
+	}
+	fmt.Printf("installed %s\nlogging to %s\nhealth: http://%s/healthz\n", unit, log, *listen)
+	return nil
+}
+
+// runDaemonUninstall implements `lazyccg daemon uninstall`.
+func runDaemonUninstall(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: lazyccg daemon uninstall")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	unit, _, err := servicePaths(runtime.GOOS, home)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "darwin" {
+		exec.Command("launchctl", "unload", "-w", unit).Run()
+	} else {
+		exec.Command("systemctl", "--user", "disable", "--now", systemdUnitName).Run()
+	}
+	if err := os.Remove(unit); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if runtime.GOOS != "darwin" {
+		exec.Command("systemctl", "--user", "daemon-reload").Run()
+	}
+	fmt.Println("removed", unit)
+	return nil
+}
+
+// runQuiet runs a command, returning what it printed as the error if it
+// fails.
+func runQuiet(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil && len(out) > 0 {
+		return fmt.Errorf("%s: %s", name, strings.TrimSpace(string(out)))
+	}
+	return err
+}
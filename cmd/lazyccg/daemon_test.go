@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestDaemonArgs(t *testing.T) {
+	got := daemonArgs("/usr/local/bin/lazyccg", backendChoice{Name: "auto"}, defaultDaemonListen, defaultDaemonPoll)
+	want := []string{"/usr/local/bin/lazyccg", "daemon", "-listen", "127.0.0.1:7788", "-poll", "2s"}
+	if !slices.Equal(got, want) {
+		t.Errorf("daemonArgs() = %q, want %q", got, want)
+	}
+	got = daemonArgs("/usr/local/bin/lazyccg", backendChoice{Name: "kitty", KittySocket: "unix:/tmp/kitty"}, "127.0.0.1:9000", 5*time.Second)
+	want = []string{"/usr/local/bin/lazyccg", "-backend", "kitty", "-kitty-socket", "unix:/tmp/kitty", "daemon", "-listen", "127.0.0.1:9000", "-poll", "5s"}
+	if !slices.Equal(got, want) {
+		t.Errorf("daemonArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestAutoDetection(t *testing.T) {
+	env := func(vars map[string]string) autoEnv {
+		return autoEnv{getenv: func(key string) string { return vars[key] }}
+	}
+	// Started at login, before any terminal
+	nothing := env(nil)
+	names, socket := detectBackends(nothing, "")
+	d := &autoDetection{found: detectionKey(names, socket, nothing)}
+	if b, err := d.redetect(nothing); b != nil || err != nil {
+		t.Errorf("nothing new: redetect() = %v, %v, want nil", b, err)
+	}
+	// A terminal came up
+	wezterm := env(map[string]string{"WEZTERM_PANE": "0"})
+	if b, err := d.redetect(wezterm); err != nil || b == nil || b.Name() != "wezterm" {
+		t.Errorf("wezterm started: redetect() = %v, %v, want wezterm", b, err)
+	}
+	if b, _ := d.redetect(wezterm); b != nil {
+		t.Errorf("wezterm still there: redetect() = %v, want nil", b)
+	}
+
+	// Another kitty instance counts as a change
+	one := autoEnv{kittySockets: []string{"unix:/tmp/kitty-1"}}
+	two := autoEnv{kittySockets: []string{"unix:/tmp/kitty-1", "unix:/tmp/kitty-2"}}
+	if detectionKey([]string{"kitty"}, "auto", one) == detectionKey([]string{"kitty"}, "auto", two) {
+		t.Error("a second kitty socket doesn't change the detection key")
+	}
+}
+
+func TestServicePaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "/home/me/.local/share")
+	unit, log, err := servicePaths("linux", "/home/me")
+	if err != nil || unit != "/home/me/.config/systemd/user/lazyccg.service" || log != "/home/me/.local/share/lazyccg/daemon.log" {
+		t.Errorf("servicePaths(linux) = %s, %s, %v", unit, log, err)
+	}
+	unit, log, err = servicePaths("darwin", "/Users/me")
+	if err != nil || unit != "/Users/me/Library/LaunchAgents/com.github.atani.lazyccg.plist" || log != "/Users/me/Library/Logs/lazyccg/daemon.log" {
+		t.Errorf("servicePaths(darwin) = %s, %s, %v", unit, log, err)
+	}
+	if _, _, err := servicePaths("windows", `C:\Users\me`); err == nil {
+		t.Error("servicePaths(windows) should fail")
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	spec := serviceSpec{
+		Args: []string{"/opt/my tools/lazyccg", "-kitty-socket", "unix:/tmp/kitty", "daemon", "-listen", "127.0.0.1:7788", "-poll", "2s"},
+		Log:  "/home/me/.local/share/lazyccg/daemon.log",
+		Path: "/usr/local/bin:/usr/bin",
+	}
+	unit := systemdUnit(spec)
+	for _, want := range []string{
+		"[Service]\n",
+		`ExecStart="/opt/my tools/lazyccg" -kitty-socket unix:/tmp/kitty daemon -listen 127.0.0.1:7788 -poll 2s` + "\n",
+		"Environment=PATH=/usr/local/bin:/usr/bin\n",
+		"StandardOutput=append:/home/me/.local/share/lazyccg/daemon.log\n",
+		"StandardError=append:/home/me/.local/share/lazyccg/daemon.log\n",
+		"Restart=on-failure\n",
+		"[Install]\nWantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit has no %q:\n%s", want, unit)
+		}
+	}
+
+	if got := systemdQuote(`100%$HOME"`); got != `"100%%$$HOME\""` {
+		t.Errorf("systemdQuote() = %s", got)
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	spec := serviceSpec{
+		Args: []string{"/opt/homebrew/bin/lazyccg", "daemon", "-listen", "127.0.0.1:7788"},
+		Log:  "/Users/me/Library/Logs/lazyccg/daemon.log",
+		Path: "/opt/homebrew/bin:/usr/bin&more",
+	}
+	plist := launchdPlist(spec)
+	// Well-formed, with the PATH escaped
+	d := xml.NewDecoder(strings.NewReader(plist))
+	var strs []string
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		if cd, ok := tok.(xml.CharData); ok && strings.TrimSpace(string(cd)) != "" {
+			strs = append(strs, string(cd))
+		}
+	}
+	for _, want := range []string{
+		"com.github.atani.lazyccg",
+		"/opt/homebrew/bin/lazyccg", "daemon", "-listen", "127.0.0.1:7788",
+		"/opt/homebrew/bin:/usr/bin&more",
+		"/Users/me/Library/Logs/lazyccg/daemon.log",
+		"StandardOutPath", "StandardErrorPath", "RunAtLoad", "KeepAlive",
+	} {
+		if !slices.Contains(strs, want) {
+			t.Errorf("plist has no %q:\n%s", want, plist)
+		}
+	}
+	if !strings.HasPrefix(plist, "<?xml") {
+		t.Errorf("plist = %s", plist)
+	}
+}
+
+func TestLogDaemonEvents(t *testing.T) {
+	s := session{WindowID: 3, Title: "api", Status: agentstatus.Waiting}
+	var b bytes.Buffer
+	logDaemonEvents(&b, []sessionEvent{
+		{Kind: statusChanged, Session: s, OldStatus: agentstatus.Running},
+		{Kind: outputAppended, Session: s, Lines: []string{"hello"}},
+	})
+	if out := b.String(); !strings.Contains(out, `status w3 "api": RUNNING -> WAITING`) || strings.Count(out, "\n") != 1 {
+		t.Errorf("logDaemonEvents() wrote %q; want the status change only", out)
+	}
+}
+
+func TestDaemonLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lazyccg", "daemon.lock")
+	if daemonRunning(path) {
+		t.Error("daemonRunning() before any daemon")
+	}
+	lock, err := lockDaemon(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !daemonRunning(path) {
+		t.Error("daemonRunning() = false while the lock is held")
+	}
+	if _, err := lockDaemon(path); err == nil {
+		t.Error("a second daemon took the lock")
+	}
+	lock.Close()
+	if daemonRunning(path) {
+		t.Error("daemonRunning() after the daemon stopped")
+	}
+}
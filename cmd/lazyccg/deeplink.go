@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// sessionLink is how other tools (Alfred workflows, notes) can jump straight
+// to a session's window: a lazyccg:// URL handled by `lazyccg open`, and the
+// equivalent terminal command.
+type sessionLink struct {
+	URL     string
+	Command string
+}
+
+// deepLink returns the link for windowID in b. Backends whose window IDs
+// only mean something inside this process (iTerm2, screen) have none.
+func deepLink(b Backend, windowID int) (sessionLink, bool) {
+	q := url.Values{"window": {strconv.Itoa(windowID)}}
+	var argv []string
+	var host string
+	switch b := b.(type) {
+	case nestedTmuxBackend:
+		if windowID < nestedIDBase {
+			return deepLink(b.Backend, windowID)
+		}
+		_, pane, err := b.pane(windowID)
+		if err != nil {
+			return sessionLink{}, false
+		}
+		return deepLink(b.tmux, pane)
+	case multiBackend:
+		inner, id, err := b.instance(windowID)
+		if err != nil {
+			return sessionLink{}, false
+		}
+		return deepLink(inner, id)
+	case kittyBackend:
+		q.Set("backend", "kitty")
+		if b.socket != "" {
+			q.Set("socket", b.socket)
+		}
+		argv = append([]string{b.program()}, b.args("focus-window", "--match", fmt.Sprintf("id:%d", windowID))...)
+		host = b.run.host
+	case tmuxBackend:
+		q.Set("backend", "tmux")
+		target := tmuxTarget(windowID)
+		argv = []string{"tmux", "switch-client", "-t", target, ";", "select-window", "-t", target, ";", "select-pane", "-t", target}
+		host = b.run.host
+	case weztermBackend:
+		q.Set("backend", "wezterm")
+		argv = []string{"wezterm", "cli", "activate-pane", "--pane-id", strconv.Itoa(windowID)}
+		host = b.run.host
+	default:
+		return sessionLink{}, false
+	}
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	command := strings.Join(quoted, " ")
+	if host != "" {
+		q.Set("host", host)
+		command = "ssh " + shellQuote(host) + " " + shellQuote(command)
+	}
+	u := url.URL{Scheme: "lazyccg", Host: "focus", RawQuery: q.Encode()}
+	return sessionLink{URL: u.String(), Command: command}, true
+}
+
+// parseDeepLink returns the backend and window a lazyccg:// URL points at.
+func parseDeepLink(link string) (Backend, int, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, 0, err
+	}
+	if u.Scheme != "lazyccg" || u.Host != "focus" {
+		return nil, 0, fmt.Errorf("not a lazyccg://focus link: %s", link)
+	}
+	q := u.Query()
+	windowID, err := strconv.Atoi(q.Get("window"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("bad window in %s", link)
+	}
+	name := q.Get("backend")
+	var b Backend
+	if host := q.Get("host"); host != "" {
+		b, err = newSSHBackend(host, name, q.Get("socket"))
+	} else {
+		b, err = newBackend(name, q.Get("socket"))
+	}
+	return b, windowID, err
+}
+
+// runOpen handles `lazyccg open <link>`, e.g. as the lazyccg:// URL handler.
+func runOpen(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: lazyccg open lazyccg://focus?...")
+	}
+	b, windowID, err := parseDeepLink(args[0])
+	if err != nil {
+		return err
+	}
+	return b.Focus(windowID)
+}
+
+type copiedMsg struct {
+	what string
+}
+
+// copyCmd puts text on the clipboard with OSC 52, which works over SSH and
+// inside tmux and screen.
+func copyCmd(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		termenv.Copy(text)
+		return copiedMsg{what: what}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestDeepLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       Backend
+		id      int
+		url     string
+		command string
+	}{
+		{
+			name:    "kitty",
+			b:       kittyBackend{socket: "unix:/tmp/kitty-42"},
+			id:      7,
+			url:     "lazyccg://focus?backend=kitty&socket=unix%3A%2Ftmp%2Fkitty-42&window=7",
+			command: "kitty @ --to unix:/tmp/kitty-42 focus-window --match id:7",
+		},
+		{
+			name:    "tmux over ssh",
+			b:       tmuxBackend{run: commandRunner{host: "devbox"}},
+			id:      3,
+			url:     "lazyccg://focus?backend=tmux&host=devbox&window=3",
+			command: `ssh devbox 'tmux switch-client -t %3 '\'';'\'' select-window -t %3 '\'';'\'' select-pane -t %3'`,
+		},
+		{
+			name:    "second kitty instance",
+			b:       newMultiKittyBackend([]string{"unix:/tmp/kitty-1", "unix:/tmp/kitty-2"}),
+			id:      instanceStride + 5,
+			url:     "lazyccg://focus?backend=kitty&socket=unix%3A%2Ftmp%2Fkitty-2&window=5",
+			command: "kitty @ --to unix:/tmp/kitty-2 focus-window --match id:5",
+		},
+	}
+	for _, tt := range tests {
+		link, ok := deepLink(tt.b, tt.id)
+		if !ok {
+			t.Errorf("%s: no link", tt.name)
+			continue
+		}
+		if link.URL != tt.url {
+			t.Errorf("%s: URL = %s, want %s", tt.name, link.URL, tt.url)
+		}
+		if link.Command != tt.command {
+			t.Errorf("%s: Command = %s, want %s", tt.name, link.Command, tt.command)
+		}
+	}
+	if _, ok := deepLink(newScreenBackend(), 1); ok {
+		t.Error("screen windows should have no link")
+	}
+}
+
+func TestParseDeepLink(t *testing.T) {
+	link, _ := deepLink(tmuxBackend{}, 3)
+	b, id, err := parseDeepLink(link.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Name() != "tmux" || id != 3 {
+		t.Errorf("parseDeepLink() = %s, %d", b.Name(), id)
+	}
+	for _, bad := range []string{"https://focus?window=1", "lazyccg://focus?backend=tmux", "lazyccg://focus?backend=xterm&window=1"} {
+		if _, _, err := parseDeepLink(bad); err == nil {
+			t.Errorf("parseDeepLink(%q) succeeded", bad)
+		}
+	}
+}
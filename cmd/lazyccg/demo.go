@@ -0,0 +1,17 @@
+package main
+
+import (
+	_ "embed"
+)
+
+// `lazyccg demo` runs the dashboard against a bundled mock fixture, so the
+// UI can be explored (or screenshotted) without any agents running.
+
+//go:embed demo/sessions.json
+var demoFixture []byte
+
+const demoNotice = "demo: these sessions are simulated, so try anything (q quits)"
+
+func newDemoBackend() (Backend, error) {
+	return parseMockFixture(demoFixture)
+}
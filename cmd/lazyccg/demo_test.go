@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+)
+
+func TestDemoFixture(t *testing.T) {
+	b, err := newDemoBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := b.(mockBackend)
+	osWindows, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	agents := agentsession.Find(osWindows, []string{"codex", "claude", "gemini"})
+	if len(agents) != 4 {
+		t.Fatalf("demo has %d agents, want 4", len(agents))
+	}
+
+	// Each agent's last frame, as if the demo had run for a while
+	var sessions []session
+	for _, a := range agents {
+		frames := m.streams[a.Window.ID].Frames
+		lines := frames[len(frames)-1].Lines
+		status, _, _, _ := detectStatus(a.AI, lines)
+		sessions = append(sessions, session{WindowID: a.Window.ID, Status: status, Cwd: a.Window.Cwd, Edited: editedPaths(frameLines(frames))})
+	}
+	if sessions[3].Status != "NEEDS_APPROVAL" {
+		t.Errorf("infra session ends %s, want NEEDS_APPROVAL", sessions[3].Status)
+	}
+	if menu, ok := parseMenu(m.streams[5].Frames[1].Lines); !ok || len(menu.Options) != 2 {
+		t.Errorf("infra approval menu = %+v, %v", menu, ok)
+	}
+	if conflicts := findConflicts(sessions); len(conflicts) != 1 {
+		t.Errorf("demo conflicts = %+v, want the one file two agents edit", conflicts)
+	}
+}
+
+func frameLines(frames []mockFrame) []string {
+	var lines []string
+	for _, f := range frames {
+		lines = append(lines, f.Lines...)
+	}
+	return lines
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atani/lazyccg/internal/ui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderDetailPanel shows everything lazyccg knows about the selected
+// session in place of the Output panel.
+func (m model) renderDetailPanel(width, height int) string {
+	filtered := m.filteredSessions()
+	if len(filtered) == 0 || m.selected >= len(filtered) {
+		return drawBox("Detail", []string{helpDescStyle.Render(" (no session)")}, width, height, gray)
+	}
+	s := filtered[m.selected]
+	innerWidth := width - 2
+
+	var content []string
+	field := func(label, value string) {
+		if value == "" {
+			return
+		}
+		label = fmt.Sprintf(" %-10s", label)
+		content = append(content, helpDescStyle.Render(label)+ui.Truncate(value, innerWidth-len(label)))
+	}
+	section := func(title string) {
+		content = append(content, "", " "+titleStyle.Render(title))
+	}
+
+	field("Title", s.Title)
+	field("AI", strings.ToUpper(s.AI))
+	content = append(content, helpDescStyle.Render(fmt.Sprintf(" %-10s", "Status"))+statusStyle(s.Status).Render(statusLabel(s.Status))+
+		helpDescStyle.Render(statusBasis(s, time.Now())))
+	if v := sessionVitals(s, staleAfter(), time.Now()); len(v.Reasons) > 0 {
+		field("Health", fmt.Sprintf("%s %d · %s", healthEmoji(v.Score), v.Score, strings.Join(v.Reasons, ", ")))
+	} else {
+		field("Health", fmt.Sprintf("%s %d", healthEmoji(v.Score), v.Score))
+	}
+	field("Cwd", shortenHome(s.Cwd))
+	field("Window", fmt.Sprintf("%d (tab %d)", s.WindowID, s.TabID))
+	if s.Nested != "" {
+		field("Tmux", fmt.Sprintf("session %s in window %d", s.Nested, s.Parent))
+	}
+	if link, ok := deepLink(backend, s.WindowID); ok {
+		field("Link", link.URL)
+		field("Jump", link.Command)
+	}
+	if !s.LastActive.IsZero() {
+		field("Active", timeFmt.Ago(s.LastActive, time.Now()))
+	}
+	field("Prompt", s.Prompt)
+	if s.Tokens > 0 {
+		field("Tokens", compactCount(s.Tokens))
+	}
+	if s.ContextKnown {
+		field("Context", fmt.Sprintf("%d%% left", s.ContextLeft))
+	}
+	if now := time.Now(); s.Limit.Resets.After(now) {
+		field("Limit", s.Limit.describe(now))
+	}
+	field("Busy", s.Busy.String())
+	if s.Child != "" {
+		field("Command", s.Child+" for "+statusAge(s.ChildSince, time.Now()))
+	}
+	field("Action", s.LastAction)
+	field("Error", s.ErrorLine)
+	field("Exit", s.ExitHint)
+
+	section("Tool calls")
+	if s.Tools.total() == 0 {
+		content = append(content, helpDescStyle.Render("   none seen yet"))
+	} else {
+		for _, kind := range toolKindOrder {
+			if n := s.Tools[kind]; n > 0 {
+				ts := toolStyles[kind]
+				content = append(content, "   "+ts.style.Render(fmt.Sprintf("%s %-7s %d", ts.icon, kind, n)))
+			}
+		}
+	}
+
+	section("Files")
+	switch {
+	case m.detailWindow != s.WindowID:
+		content = append(content, helpDescStyle.Render("   loading…"))
+	case len(m.detailFiles) == 0:
+		content = append(content, helpDescStyle.Render("   no changes"))
+	}
+	if m.detailWindow == s.WindowID {
+		for i, f := range m.detailFiles {
+			git := f.Git
+			if git == "" {
+				git = "-"
+			}
+			edited := " "
+			if f.Edited {
+				edited = toolStyles["edit"].icon
+			}
+			line := fmt.Sprintf("   %-2s %s %s", git, edited, ui.TruncateLeft(f.Name, innerWidth-9))
+			if m.focusedPanel == 2 && i == m.fileSelected {
+				line = selectedStyle.Render(line + strings.Repeat(" ", max(0, innerWidth-lipgloss.Width(line))))
+			}
+			content = append(content, line)
+		}
+	}
+
+	borderColor := gray
+	if m.focusedPanel == 2 {
+		borderColor = cyan
+	}
+	return drawBox("Detail", content, width, height, borderColor)
+}
+
+// statusBasis describes how a session's status was determined, e.g.
+// " (output, 80%, 3m ago)".
+func statusBasis(s session, now time.Time) string {
+	if s.StatusSource == "" {
+		return ""
+	}
+	basis := fmt.Sprintf(" (%s, %.0f%%", s.StatusSource, s.Confidence*100)
+	if !s.StatusSince.IsZero() {
+		basis += ", " + timeFmt.Ago(s.StatusSince, now)
+	}
+	return basis + ")"
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// With -backend auto (the default) lazyccg picks its backends from the
+// environment: the terminal it runs in, and the sockets of terminals it can
+// reach from outside. Every terminal found is watched. tmux and screen are
+// only used when no terminal with remote control is, since agents in tmux
+// inside a terminal window are found by looking inside that window.
+
+// autoEnv is what detection looks at.
+type autoEnv struct {
+	getenv        func(string) string
+	kittySockets  []string // found in the temp dir
+	vscodeSockets bool     // the VS Code extension is listening
+	tmuxServer    bool     // a tmux server is running for this user
+}
+
+func currentAutoEnv() autoEnv {
+	vscode, _ := filepath.Glob(filepath.Join(os.TempDir(), vscodeSocketGlob))
+	return autoEnv{
+		getenv:        os.Getenv,
+		kittySockets:  discoverKittySockets(),
+		vscodeSockets: len(vscode) > 0,
+		tmuxServer:    tmuxServerRunning(),
+	}
+}
+
+// tmuxServerRunning reports whether tmux's default socket exists.
+func tmuxServerRunning() bool {
+	dir := os.Getenv("TMUX_TMPDIR")
+	if dir == "" {
+		dir = "/tmp"
+	}
+	_, err := os.Stat(filepath.Join(dir, fmt.Sprintf("tmux-%d", os.Getuid()), "default"))
+	return err == nil
+}
+
+// detectBackends returns the backends to watch and the kitty socket to use.
+// kittySocket is the socket from -kitty-socket or kitty's environment, or
+// "".
+func detectBackends(env autoEnv, kittySocket string) ([]string, string) {
+	var names []string
+	switch {
+	case kittySocket != "", env.getenv("KITTY_WINDOW_ID") != "":
+		// A known socket, or inside kitty, where `kitty @` finds it
+		names = append(names, "kitty")
+	case len(env.kittySockets) > 0:
+		names = append(names, "kitty")
+		kittySocket = "auto"
+	}
+	if env.getenv("WEZTERM_UNIX_SOCKET") != "" || env.getenv("WEZTERM_PANE") != "" {
+		names = append(names, "wezterm")
+	}
+	if env.getenv("TERM_PROGRAM") == "iTerm.app" {
+		names = append(names, "iterm2")
+	}
+	if env.vscodeSockets {
+		names = append(names, "vscode")
+	}
+	if len(names) > 0 {
+		return names, kittySocket
+	}
+	// Terminals without remote control, e.g. Alacritty or Ghostty
+	if env.getenv("TMUX") != "" || env.tmuxServer {
+		names = append(names, "tmux")
+	}
+	if env.getenv("STY") != "" {
+		names = append(names, "screen")
+	}
+	if len(names) == 0 {
+		names = append(names, "ps")
+	}
+	return names, kittySocket
+}
+
+// newAutoBackend watches every backend detectBackends finds.
+func newAutoBackend(kittySocket string) (Backend, error) {
+	return newDetectedBackend(detectBackends(currentAutoEnv(), kittySocket))
+}
+
+// newDetectedBackend watches the backends called names.
+func newDetectedBackend(names []string, kittySocket string) (Backend, error) {
+	if len(names) == 1 {
+		return newBackend(names[0], kittySocket)
+	}
+	var m multiBackend
+	for _, name := range names {
+		b, err := newBackend(name, kittySocket)
+		if err != nil {
+			return nil, err
+		}
+		if inner, ok := b.(multiBackend); ok {
+			// Several kitty instances; IDs are offset once, at this level
+			m.instances = append(m.instances, inner.instances...)
+			m.names = append(m.names, inner.names...)
+			continue
+		}
+		m.instances = append(m.instances, b)
+		m.names = append(m.names, name)
+	}
+	return m, nil
+}
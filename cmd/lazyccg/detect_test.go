@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectBackends(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		found      autoEnv
+		kitty      string
+		want       []string
+		wantSocket string
+	}{
+		{name: "inside kitty", env: map[string]string{"KITTY_WINDOW_ID": "3", "TMUX": "/tmp/tmux-1/default"}, want: []string{"kitty"}},
+		{name: "known kitty socket", kitty: "unix:/tmp/kitty", want: []string{"kitty"}, wantSocket: "unix:/tmp/kitty"},
+		{name: "kitty sockets found", found: autoEnv{kittySockets: []string{"unix:/tmp/kitty-1", "unix:/tmp/kitty-2"}}, want: []string{"kitty"}, wantSocket: "auto"},
+		{name: "wezterm and vscode", env: map[string]string{"WEZTERM_PANE": "0"}, found: autoEnv{vscodeSockets: true}, want: []string{"wezterm", "vscode"}},
+		{name: "iterm2", env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: []string{"iterm2"}},
+		{name: "alacritty and tmux", env: map[string]string{"ALACRITTY_WINDOW_ID": "1", "TMUX": "/tmp/tmux-1/default"}, want: []string{"tmux"}},
+		{name: "tmux server", found: autoEnv{tmuxServer: true}, want: []string{"tmux"}},
+		{name: "screen", env: map[string]string{"STY": "123.pts-0"}, want: []string{"screen"}},
+		{name: "nothing", want: []string{"ps"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.found
+			env.getenv = func(key string) string { return tt.env[key] }
+			got, socket := detectBackends(env, tt.kitty)
+			if !reflect.DeepEqual(got, tt.want) || socket != tt.wantSocket {
+				t.Errorf("detectBackends() = %v, %q, want %v, %q", got, socket, tt.want, tt.wantSocket)
+			}
+		})
+	}
+}
+
+func TestNewSSHBackendAuto(t *testing.T) {
+	b, err := newSSHBackend("devbox", "auto", "")
+	if err != nil || b.Name() != "tmux" {
+		t.Errorf("auto over ssh = %v, %v, want tmux", b, err)
+	}
+	b, err = newSSHBackend("devbox", "auto", "unix:/tmp/kitty")
+	if err != nil || b.Name() != "kitty" {
+		t.Errorf("auto over ssh with a kitty socket = %v, %v, want kitty", b, err)
+	}
+}
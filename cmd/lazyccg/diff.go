@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atani/lazyccg/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A transcript only ever grows, so the difference between a session at two
+// points in time is what it recorded in between: the output that appeared
+// and the status changes. `lazyccg diff` and t on the archive screen show
+// it, to answer "what did the agent do between 2pm and 3pm".
+
+// timeSpan is the time from From up to To.
+type timeSpan struct {
+	From, To time.Time
+}
+
+// spanTimeLayouts are the ways a time can be given; the clock-only ones are
+// taken to be today.
+var spanTimeLayouts = []string{"15:04", "15:04:05", "2006-01-02T15:04", "2006-01-02T15:04:05", time.RFC3339}
+
+// parseSpanTime reads a time in one of spanTimeLayouts, in now's zone.
+func parseSpanTime(s string, now time.Time) (time.Time, error) {
+	for _, layout := range spanTimeLayouts {
+		t, err := time.ParseInLocation(layout, s, now.Location())
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(layout, "2006") {
+			y, mo, d := now.Date()
+			t = time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("can't read time %q: use 14:00, 14:00:30, or 2006-01-02T14:00", s)
+}
+
+// parseTimeSpan reads "FROM TO", or "FROM" for from then until now.
+func parseTimeSpan(s string, now time.Time) (timeSpan, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return timeSpan{}, fmt.Errorf("give a start time and optionally an end time, e.g. \"14:00 15:00\"")
+	}
+	from, err := parseSpanTime(fields[0], now)
+	if err != nil {
+		return timeSpan{}, err
+	}
+	to := now
+	if len(fields) == 2 {
+		if to, err = parseSpanTime(fields[1], now); err != nil {
+			return timeSpan{}, err
+		}
+	}
+	if !to.After(from) {
+		return timeSpan{}, fmt.Errorf("%s is not after %s", fields[len(fields)-1], fields[0])
+	}
+	return timeSpan{From: from, To: to}, nil
+}
+
+func (s timeSpan) String() string {
+	if s.From.Format("20060102") == s.To.Format("20060102") {
+		return s.From.Format("2006-01-02 15:04") + "–" + s.To.Format("15:04")
+	}
+	return s.From.Format("2006-01-02 15:04") + "–" + s.To.Format("2006-01-02 15:04")
+}
+
+// Between returns the output and status records made during span.
+func (t transcript) Between(span timeSpan) []historyRecord {
+	var out []historyRecord
+	for _, r := range t.Records {
+		if (r.Type == "lines" || r.Type == "status") && !r.Time.Before(span.From) && r.Time.Before(span.To) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// diffArchive returns the transcripts matching query that recorded
+// anything during span, each with what it recorded; an empty query takes
+// them all.
+func diffArchive(dir, query string, span timeSpan, limit int) ([]archiveResult, error) {
+	results, err := searchArchive(dir, query, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var out []archiveResult
+	for _, r := range results {
+		if added := r.Transcript.Between(span); len(added) > 0 {
+			out = append(out, archiveResult{Transcript: r.Transcript, Added: added})
+			if limit > 0 && len(out) >= limit {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// diffLines renders what a transcript recorded: "+ " before each new
+// output line, and status changes with their time.
+func diffLines(records []historyRecord) []string {
+	var lines []string
+	for _, r := range records {
+		switch r.Type {
+		case "lines":
+			for _, line := range r.Lines {
+				lines = append(lines, "+ "+line)
+			}
+		case "status":
+			status := r.Status.String()
+			if r.ExitCode != nil {
+				status += fmt.Sprintf(" (%d)", *r.ExitCode)
+			}
+			lines = append(lines, fmt.Sprintf("~ %s %s", r.Time.Local().Format("15:04:05"), status))
+		}
+	}
+	return lines
+}
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	from := fs.String("from", "", "start of the span: 14:00, 14:00:30, or 2006-01-02T14:00")
+	to := fs.String("to", "", "end of the span (default now)")
+	limit := fs.Int("limit", 20, "maximum number of sessions to show (0 = no limit)")
+	dir := fs.String("dir", historyDir(), "transcript directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" {
+		return fmt.Errorf("usage: lazyccg diff -from TIME [-to TIME] [-limit N] [query]")
+	}
+	span, err := parseTimeSpan(strings.TrimSpace(*from+" "+*to), time.Now())
+	if err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+
+	results, err := diffArchive(*dir, query, span, *limit)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Printf("no sessions recorded anything %s\n", span)
+		return nil
+	}
+	for _, r := range results {
+		fmt.Println(archiveHeader(r.Transcript))
+		for _, line := range diffLines(r.Added) {
+			fmt.Println("  " + line)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// Archive screen.
+
+func archiveDiffCmd(query string, span timeSpan) tea.Cmd {
+	return func() tea.Msg {
+		results, err := diffArchive(historyDir(), query, span, 50)
+		return archiveResultMsg{query: query, span: &span, results: results, err: err}
+	}
+}
+
+// archiveDiffLines renders a diff result for the archive screen, truncated
+// to width.
+func archiveDiffLines(r archiveResult, width int) []string {
+	var lines []string
+	for _, line := range diffLines(r.Added) {
+		style := statusRunning
+		if strings.HasPrefix(line, "~") {
+			style = statusWaiting
+		}
+		lines = append(lines, style.Render(ui.Truncate("  "+line, width)))
+	}
+	return lines
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTimeSpan(t *testing.T) {
+	now := time.Date(2025, 3, 4, 16, 30, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 4, h, m, 0, 0, time.UTC) }
+	tests := []struct {
+		in      string
+		want    timeSpan
+		wantErr bool
+	}{
+		{"14:00 15:00", timeSpan{at(14, 0), at(15, 0)}, false},
+		{"14:00", timeSpan{at(14, 0), now}, false},
+		{"2025-03-03T23:00 01:00", timeSpan{time.Date(2025, 3, 3, 23, 0, 0, 0, time.UTC), at(1, 0)}, false},
+		{"15:00 14:00", timeSpan{}, true},
+		{"2pm", timeSpan{}, true},
+		{"", timeSpan{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeSpan(tt.in, now)
+		if (err != nil) != tt.wantErr || !got.From.Equal(tt.want.From) || !got.To.Equal(tt.want.To) {
+			t.Errorf("parseTimeSpan(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDiffArchive(t *testing.T) {
+	dir := t.TempDir()
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 4, h, m, 0, 0, time.UTC) }
+	appendRecords(filepath.Join(dir, "20250304-130000-claude-w1.jsonl"), []historyRecord{
+		{Type: "meta", Time: at(13, 0), AI: "claude", Title: "api", Cwd: "/src/api"},
+		{Type: "lines", Time: at(13, 30), Lines: []string{"● Read(main.go)"}},
+		{Type: "lines", Time: at(14, 10), Lines: []string{"● Edit(main.go)", "● Bash(go test ./...)"}},
+		{Type: "status", Time: at(14, 50), Status: "IDLE"},
+		{Type: "lines", Time: at(15, 5), Lines: []string{"● Bash(git push)"}},
+	})
+	appendRecords(filepath.Join(dir, "20250304-090000-codex-w2.jsonl"), []historyRecord{
+		{Type: "meta", Time: at(9, 0), AI: "codex", Title: "web", Cwd: "/src/web"},
+		{Type: "lines", Time: at(9, 5), Lines: []string{"• Working"}},
+	})
+
+	results, err := diffArchive(dir, "", timeSpan{at(14, 0), at(15, 0)}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Transcript.AI != "claude" {
+		t.Fatalf("diffArchive() = %d results, want only the claude session", len(results))
+	}
+	want := []string{"+ ● Edit(main.go)", "+ ● Bash(go test ./...)", "~ " + at(14, 50).Local().Format("15:04:05") + " IDLE"}
+	if got := diffLines(results[0].Added); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffLines() = %q, want %q", got, want)
+	}
+
+	if results, _ := diffArchive(dir, "web", timeSpan{at(14, 0), at(15, 0)}, 0); len(results) != 0 {
+		t.Errorf("diffArchive(web) = %d results, want none: it was quiet then", len(results))
+	}
+}
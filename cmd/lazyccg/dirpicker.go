@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/atani/lazyccg/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// O opens a directory picker to launch an agent in: the directories zoxide
+// knows, most used first, and the projects under launch.projects, narrowed
+// down by typing a fuzzy query.
+
+// dirPicker is the open directory picker.
+type dirPicker struct {
+	dirs     []string // candidates, best first
+	loaded   bool
+	query    []rune
+	selected int // index into matches()
+	agent    string
+}
+
+// launchAgents are the agents the picker cycles through with tab.
+func launchAgents() []string {
+	agents := []string{"claude", "codex", "gemini"}
+	var extra []string
+	for name := range profiles.byName {
+		if !slices.Contains(agents, name) {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return append(agents, extra...)
+}
+
+// newDirPicker opens the picker for the configured agent, or else agent.
+func newDirPicker(agent string) *dirPicker {
+	if cfg.Launch.Agent != "" {
+		agent = cfg.Launch.Agent
+	}
+	if agent == "" {
+		agent = "claude"
+	}
+	return &dirPicker{agent: agent}
+}
+
+type launchDirsMsg struct{ dirs []string }
+
+// loadLaunchDirsCmd lists the candidate directories.
+func loadLaunchDirsCmd() tea.Cmd {
+	return func() tea.Msg {
+		return launchDirsMsg{dirs: launchDirs(zoxideDirs(), cfg.Launch.Projects)}
+	}
+}
+
+// zoxideDirs lists zoxide's directories, highest score first, or nothing
+// when zoxide isn't installed.
+func zoxideDirs() []string {
+	if _, err := exec.LookPath("zoxide"); err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "zoxide", "query", "--list").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+// launchDirs merges zoxide's directories with the directories directly
+// under each projects root, without duplicates.
+func launchDirs(zoxide, roots []string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if dir != "" && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range zoxide {
+		add(dir)
+	}
+	for _, root := range roots {
+		root = expandHome(root)
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+				add(filepath.Join(root, e.Name()))
+			}
+		}
+	}
+	return dirs
+}
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
+
+// fuzzyScore matches query against candidate as a subsequence, ignoring
+// case. Runs of consecutive letters, and matches in the last path element,
+// score higher.
+func fuzzyScore(candidate, query string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	c := []rune(strings.ToLower(candidate))
+	q := []rune(strings.ToLower(query))
+	base := len([]rune(filepath.Dir(candidate))) + 1
+	score, qi, prev := 0, 0, -2
+	for ci := 0; ci < len(c) && qi < len(q); ci++ {
+		if c[ci] != q[qi] {
+			continue
+		}
+		score++
+		if ci == prev+1 {
+			score += 2
+		}
+		if ci >= base {
+			score++
+		}
+		if ci == 0 || !unicode.IsLetter(c[ci-1]) && !unicode.IsDigit(c[ci-1]) {
+			score++ // start of a word
+		}
+		prev = ci
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+// matches returns the candidates matching the query, best first.
+func (p *dirPicker) matches() []string {
+	query := string(p.query)
+	type match struct {
+		dir   string
+		score int
+	}
+	var found []match
+	for _, dir := range p.dirs {
+		if score, ok := fuzzyScore(dir, query); ok {
+			found = append(found, match{dir, score})
+		}
+	}
+	// Stable: equal scores keep zoxide's order
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+	out := make([]string, len(found))
+	for i, f := range found {
+		out[i] = f.dir
+	}
+	return out
+}
+
+func (m model) updateDirPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.picker
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.picker = nil
+	case tea.KeyUp:
+		if p.selected > 0 {
+			p.selected--
+		}
+	case tea.KeyDown:
+		if p.selected < len(p.matches())-1 {
+			p.selected++
+		}
+	case tea.KeyTab:
+		agents := launchAgents()
+		i := 0
+		for j, a := range agents {
+			if a == p.agent {
+				i = j + 1
+			}
+		}
+		p.agent = agents[i%len(agents)]
+	case tea.KeyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.selected = 0
+		}
+	case tea.KeySpace:
+		p.query = append(p.query, ' ')
+		p.selected = 0
+	case tea.KeyRunes:
+		p.query = append(p.query, msg.Runes...)
+		p.selected = 0
+	case tea.KeyEnter:
+		matches := p.matches()
+		if p.selected >= len(matches) {
+			return m, nil
+		}
+		m.picker = nil
+		return m, launchInCmd(launchSpec{Agent: profiles.agent(p.agent), Dir: matches[p.selected]})
+	}
+	return m, nil
+}
+
+// launchInCmd starts spec in the first terminal that can open windows.
+func launchInCmd(spec launchSpec) tea.Cmd {
+	return func() tea.Msg {
+		l, ok := defaultLauncher(backend)
+		if !ok {
+			return launchedMsg{err: fmt.Errorf("%s can't open windows", backend.Name())}
+		}
+		return launchedMsg{title: launchTitle(spec), err: launch(l, spec)}
+	}
+}
+
+func (m model) renderDirPicker(width, height int) string {
+	p := m.picker
+	innerWidth := width - 2
+	content := []string{
+		helpKeyStyle.Render(" > ") + string(p.query) + "█",
+		"",
+	}
+	matches := p.matches()
+	switch {
+	case !p.loaded:
+		content = append(content, helpDescStyle.Render(" loading…"))
+	case len(p.dirs) == 0:
+		content = append(content, helpDescStyle.Render(" no directories: install zoxide or set launch.projects"))
+	case len(matches) == 0:
+		content = append(content, helpDescStyle.Render(" no match"))
+	}
+	rows := max(1, height-2-len(content))
+	start := 0
+	if p.selected >= rows {
+		start = p.selected - rows + 1
+	}
+	for i := start; i < len(matches) && i < start+rows; i++ {
+		line := " " + ui.TruncateLeft(shortenHome(matches[i]), innerWidth-1)
+		if i == p.selected {
+			line = selectedStyle.Render(line + strings.Repeat(" ", max(0, innerWidth-lipgloss.Width(line))))
+		}
+		content = append(content, line)
+	}
+	return drawBox(fmt.Sprintf("Launch %s in…", p.agent), content, width, height, cyan)
+}
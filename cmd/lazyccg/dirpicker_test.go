@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		candidate, query string
+		ok               bool
+	}{
+		{"/src/lazyccg", "lzc", true},
+		{"/src/lazyccg", "LAZY", true},
+		{"/src/lazyccg", "gcz", false},
+		{"/src/api", "", true},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.candidate, tt.query); ok != tt.ok {
+			t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tt.candidate, tt.query, ok, tt.ok)
+		}
+	}
+	// The project name beats a match spread over the path
+	inName, _ := fuzzyScore("/work/client/api", "api")
+	spread, _ := fuzzyScore("/a/projects/infra", "api")
+	if inName <= spread {
+		t.Errorf("fuzzyScore() = %d for a match in the name, %d for a spread one", inName, spread)
+	}
+}
+
+func TestLaunchDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"api", "web", ".cache"} {
+		os.Mkdir(filepath.Join(root, dir), 0o755)
+	}
+	os.WriteFile(filepath.Join(root, "notes.txt"), nil, 0o644)
+
+	zoxide := []string{filepath.Join(root, "web"), "/home/me/dotfiles"}
+	got := launchDirs(zoxide, []string{root, filepath.Join(root, "missing")})
+	want := []string{filepath.Join(root, "web"), "/home/me/dotfiles", filepath.Join(root, "api")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("launchDirs() = %q, want %q", got, want)
+	}
+}
+
+func TestDirPicker(t *testing.T) {
+	m := model{picker: newDirPicker("codex")}
+	m.picker.dirs = []string{"/src/web", "/src/api", "/src/apiary"}
+	m.picker.loaded = true
+
+	next, _ := m.updateDirPicker(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("api")})
+	m = next.(model)
+	if got := m.picker.matches(); !reflect.DeepEqual(got, []string{"/src/api", "/src/apiary"}) {
+		t.Errorf("matches() = %q", got)
+	}
+	next, _ = m.updateDirPicker(tea.KeyMsg{Type: tea.KeyTab})
+	if m = next.(model); m.picker.agent != "gemini" {
+		t.Errorf("tab switched to %q, want gemini", m.picker.agent)
+	}
+	next, cmd := m.updateDirPicker(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = next.(model); m.picker != nil || cmd == nil {
+		t.Error("enter should close the picker and launch")
+	}
+}
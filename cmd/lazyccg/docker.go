@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dockerBackend finds agents running in containers through the Docker Engine
+// API. Each running container becomes a tab with one window; its processes
+// come from `docker top` and its output from the container logs. There is
+// no terminal window to focus or rename, but text can be sent to a container
+// started with stdin open (docker run -i).
+type dockerBackend struct {
+	network string // "unix" or "tcp"
+	address string
+	client  *http.Client
+	ids     *windowIDs // keyed by container ID
+}
+
+const (
+	defaultDockerHost = "unix:///var/run/docker.sock"
+	dockerTimeout     = 5 * time.Second
+	// dockerLogTail is how many log lines a screen capture reads.
+	dockerLogTail = 200
+)
+
+var errDockerUnsupported = errors.New("not supported for docker containers")
+
+// newDockerBackend connects to host, a DOCKER_HOST style address
+// (unix:///path or tcp://host:port); "" means $DOCKER_HOST or the default
+// socket.
+func newDockerBackend(host string) (dockerBackend, error) {
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	if host == "" {
+		host = defaultDockerHost
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return dockerBackend{}, fmt.Errorf("docker host %q: %w", host, err)
+	}
+	d := dockerBackend{ids: newWindowIDs()}
+	switch u.Scheme {
+	case "unix":
+		d.network, d.address = "unix", u.Path
+	case "tcp", "http":
+		d.network, d.address = "tcp", u.Host
+	default:
+		return dockerBackend{}, fmt.Errorf("unsupported docker host %q (want unix:// or tcp://)", host)
+	}
+	d.client = &http.Client{
+		Timeout: dockerTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, d.network, d.address)
+			},
+		},
+	}
+	return d, nil
+}
+
+func (dockerBackend) Name() string { return "docker" }
+
+// Limitations says what the dashboard can't do with this backend.
+func (dockerBackend) Limitations() string { return "containers: no focus or rename" }
+
+// get decodes the JSON response to an Engine API request into v.
+func (d dockerBackend) get(path string, v any) error {
+	body, err := d.getBody(path)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+func (d dockerBackend) getBody(path string) (io.ReadCloser, error) {
+	// The host part is ignored; requests go to d.address
+	resp, err := d.client.Get("http://docker" + path)
+	if err != nil {
+		return nil, fmt.Errorf("docker: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return nil, fmt.Errorf("docker: %s", apiErr.Message)
+	}
+	return resp.Body, nil
+}
+
+type dockerContainer struct {
+	ID    string   `json:"Id"`
+	Names []string `json:"Names"`
+	Image string   `json:"Image"`
+}
+
+// name returns the container's name without the leading slash.
+func (c dockerContainer) name() string {
+	if len(c.Names) == 0 {
+		return c.ID[:min(12, len(c.ID))]
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+type dockerTop struct {
+	Titles    []string   `json:"Titles"`
+	Processes [][]string `json:"Processes"`
+}
+
+func (d dockerBackend) List() ([]kittyOSWindow, error) {
+	var containers []dockerContainer
+	if err := d.get("/containers/json", &containers); err != nil {
+		return nil, err
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].name() < containers[j].name() })
+	var tabs []kittyTab
+	for _, c := range containers {
+		var top dockerTop
+		if err := d.get("/containers/"+url.PathEscape(c.ID)+"/top?ps_args="+url.QueryEscape("-o pid,args"), &top); err != nil {
+			// The container may have stopped since it was listed
+			continue
+		}
+		id := d.ids.id(c.ID)
+		win := kittyWindow{ID: id, Title: c.name(), ForegroundProcesses: dockerProcesses(top)}
+		tabs = append(tabs, kittyTab{ID: id, Title: c.name(), Windows: []kittyWindow{win}})
+	}
+	if len(tabs) == 0 {
+		return nil, nil
+	}
+	return []kittyOSWindow{{Tabs: tabs}}, nil
+}
+
+// dockerProcesses reads pids and command lines out of a `docker top`
+// listing, whatever column order ps produced.
+func dockerProcesses(top dockerTop) []foregroundProcess {
+	pidCol, argsCol := -1, -1
+	for i, title := range top.Titles {
+		switch strings.ToUpper(title) {
+		case "PID":
+			pidCol = i
+		case "COMMAND", "CMD", "ARGS":
+			argsCol = i
+		}
+	}
+	if pidCol < 0 || argsCol < 0 {
+		return nil
+	}
+	var procs []foregroundProcess
+	for _, row := range top.Processes {
+		if len(row) <= pidCol || len(row) <= argsCol {
+			continue
+		}
+		pid, err := strconv.Atoi(row[pidCol])
+		if err != nil {
+			continue
+		}
+		procs = append(procs, foregroundProcess{Pid: pid, Cmdline: strings.Fields(row[argsCol])})
+	}
+	return procs
+}
+
+// CaptureText returns the end of the container's logs; extent "all" returns
+// all of them.
+func (d dockerBackend) CaptureText(windowID int, extent string) (string, error) {
+	id, err := d.ids.key(windowID)
+	if err != nil {
+		return "", err
+	}
+	tail := strconv.Itoa(dockerLogTail)
+	if extent == "all" {
+		tail = "all"
+	}
+	body, err := d.getBody("/containers/" + url.PathEscape(id) + "/logs?stdout=1&stderr=1&tail=" + tail)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return string(demuxDockerLogs(data)), nil
+}
+
+// demuxDockerLogs strips the 8-byte stream headers Docker puts in front of
+// each chunk of a container started without a TTY. Output of a container
+// with a TTY has no headers and is returned as is.
+func demuxDockerLogs(data []byte) []byte {
+	var out []byte
+	for len(data) > 0 {
+		if len(data) < 8 || data[0] > 2 || data[1] != 0 || data[2] != 0 || data[3] != 0 {
+			if out == nil {
+				return data // not multiplexed
+			}
+			return append(out, data...)
+		}
+		size := int(binary.BigEndian.Uint32(data[4:8]))
+		data = data[8:]
+		size = min(size, len(data))
+		out = append(out, data[:size]...)
+		data = data[size:]
+	}
+	return out
+}
+
+func (dockerBackend) Focus(windowID int) error { return errDockerUnsupported }
+
+func (dockerBackend) Rename(windowID int, title string) error { return errDockerUnsupported }
+
+// SendText writes text to the container's stdin through an attach
+// connection.
+func (d dockerBackend) SendText(windowID int, text string) error {
+	id, err := d.ids.key(windowID)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout(d.network, d.address, dockerTimeout)
+	if err != nil {
+		return fmt.Errorf("docker: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dockerTimeout))
+
+	// attach hijacks the connection, so it is spoken by hand rather than
+	// through http.Client
+	req := "POST /containers/" + url.PathEscape(id) + "/attach?stream=1&stdin=1 HTTP/1.1\r\n" +
+		"Host: docker\r\nConnection: Upgrade\r\nUpgrade: tcp\r\nContent-Length: 0\r\n\r\n"
+	if _, err := io.WriteString(conn, req); err != nil {
+		return fmt.Errorf("docker attach: %w", err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		return fmt.Errorf("docker attach: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("docker attach: %s", resp.Status)
+	}
+	_, err = io.WriteString(conn, text)
+	return err
+}
+
+// Scroll is not supported: logs have no scroll position.
+func (dockerBackend) Scroll(windowID, lines int) error { return errDockerUnsupported }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func dockerFrame(stream byte, s string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(s)))
+	return append(header, s...)
+}
+
+func TestDemuxDockerLogs(t *testing.T) {
+	muxed := append(dockerFrame(1, "Reading files...\n"), dockerFrame(2, "Waiting for approval\n")...)
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"multiplexed", muxed, "Reading files...\nWaiting for approval\n"},
+		{"tty", []byte("plain output\n> "), "plain output\n> "},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(demuxDockerLogs(tt.in)); got != tt.want {
+				t.Errorf("demuxDockerLogs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerProcesses(t *testing.T) {
+	top := dockerTop{
+		Titles:    []string{"PID", "COMMAND"},
+		Processes: [][]string{{"4242", "node /usr/local/bin/codex --full-auto"}, {"x", "bad"}},
+	}
+	procs := dockerProcesses(top)
+	if len(procs) != 1 || procs[0].Pid != 4242 || strings.Join(procs[0].Cmdline, " ") != "node /usr/local/bin/codex --full-auto" {
+		t.Errorf("dockerProcesses() = %+v", procs)
+	}
+	if procs := dockerProcesses(dockerTop{Titles: []string{"UID"}}); procs != nil {
+		t.Errorf("dockerProcesses() without PID column = %+v, want none", procs)
+	}
+}
+
+func TestDockerBackend(t *testing.T) {
+	attached := make(chan string, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"Id":"abc123","Names":["/sandbox"],"Image":"codex"}]`))
+	})
+	mux.HandleFunc("/containers/abc123/top", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Titles":["PID","COMMAND"],"Processes":[["77","codex"]]}`))
+	})
+	mux.HandleFunc("/containers/abc123/logs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("tail") != "200" {
+			t.Errorf("logs tail = %q, want 200", r.URL.Query().Get("tail"))
+		}
+		w.Write(dockerFrame(1, "Allow command?\n"))
+	})
+	mux.HandleFunc("/containers/abc123/attach", func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		rw.Flush()
+		line, _ := bufio.NewReader(rw).ReadString('\n')
+		attached <- line
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	d, err := newDockerBackend("tcp://" + srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	osWindows, err := d.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	win := osWindows[0].Tabs[0].Windows[0]
+	if win.Title != "sandbox" || len(win.ForegroundProcesses) != 1 || win.ForegroundProcesses[0].Pid != 77 {
+		t.Fatalf("List() window = %+v", win)
+	}
+
+	text, err := d.CaptureText(win.ID, "")
+	if err != nil || text != "Allow command?\n" {
+		t.Errorf("CaptureText() = %q, %v", text, err)
+	}
+	if err := d.Focus(win.ID); err != errDockerUnsupported {
+		t.Errorf("Focus() error = %v, want errDockerUnsupported", err)
+	}
+
+	if err := d.SendText(win.ID, "y\n"); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+	if got := <-attached; got != "y\n" {
+		t.Errorf("attached stdin got %q, want %q", got, "y\n")
+	}
+}
+
+func TestNewDockerBackendHost(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "")
+	d, err := newDockerBackend("")
+	if err != nil || d.network != "unix" || d.address != "/var/run/docker.sock" {
+		t.Errorf("default host = %s %s, %v", d.network, d.address, err)
+	}
+	if _, err := newDockerBackend("ssh://devbox"); err == nil {
+		t.Error("ssh:// hosts should be rejected")
+	}
+}
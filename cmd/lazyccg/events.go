@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// Each poll is compared with the one before it and what changed is
+// published as session events. The dashboard, history, mirrors, scripts,
+// and the debug log all work from events rather than from whole snapshots.
+
+type sessionEventKind int
+
+const (
+	sessionAdded   sessionEventKind = iota
+	sessionRemoved                  // Session is the last one seen
+	sessionUpdated                  // the title, prompt, intent, or exit code changed
+	statusChanged
+	outputAppended
+)
+
+func (k sessionEventKind) String() string {
+	switch k {
+	case sessionAdded:
+		return "added"
+	case sessionRemoved:
+		return "removed"
+	case sessionUpdated:
+		return "updated"
+	case statusChanged:
+		return "status"
+	case outputAppended:
+		return "output"
+	}
+	return "unknown"
+}
+
+type sessionEvent struct {
+	Kind      sessionEventKind
+	Session   session
+	OldStatus agentstatus.Status // statusChanged
+	Lines     []string           // outputAppended: lines new since the last poll
+	Snoozed   bool               // the session is snoozed and shouldn't notify
+}
+
+// diffSessions returns the events that turn prev into next. A window whose
+// agent changed is reported as the old session removed and a new one added.
+// A new session's output is reported as appended, but its status isn't a
+// change.
+func diffSessions(prev, next []session) []sessionEvent {
+	old := make(map[int]session, len(prev))
+	for _, s := range prev {
+		old[s.WindowID] = s
+	}
+	var events []sessionEvent
+	seen := make(map[int]bool, len(next))
+	for _, s := range next {
+		seen[s.WindowID] = true
+		p, ok := old[s.WindowID]
+		if ok && p.AI != s.AI {
+			events = append(events, sessionEvent{Kind: sessionRemoved, Session: p})
+			ok = false
+		}
+		if !ok {
+			events = append(events, sessionEvent{Kind: sessionAdded, Session: s})
+			if len(s.Lines) > 0 {
+				events = append(events, sessionEvent{Kind: outputAppended, Session: s, Lines: s.Lines})
+			}
+			continue
+		}
+		if s.Title != p.Title || (s.Prompt != "" && s.Prompt != p.Prompt) || s.Intent != p.Intent || s.ExitKnown != p.ExitKnown {
+			events = append(events, sessionEvent{Kind: sessionUpdated, Session: s})
+		}
+		if added := appendedLines(p.Lines, s.Lines); len(added) > 0 {
+			events = append(events, sessionEvent{Kind: outputAppended, Session: s, Lines: added})
+		}
+		if s.Status != p.Status {
+			events = append(events, sessionEvent{Kind: statusChanged, Session: s, OldStatus: p.Status})
+		}
+	}
+	for _, s := range prev {
+		if !seen[s.WindowID] {
+			events = append(events, sessionEvent{Kind: sessionRemoved, Session: s})
+		}
+	}
+	return events
+}
+
+// markSnoozed flags the events of snoozed sessions.
+func markSnoozed(events []sessionEvent, snoozed map[int]snooze) {
+	for i := range events {
+		_, events[i].Snoozed = snoozed[events[i].Session.WindowID]
+	}
+}
+
+// eventBus delivers each poll's events to its subscribers, in order, on a
+// goroutine of its own so slow subscribers (disk, scripts) don't hold up
+// the dashboard.
+type eventBus struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	subs   []func([]sessionEvent)
+	queue  [][]sessionEvent
+	closed bool
+	done   chan struct{}
+}
+
+// bus is nil until main sets it up; publishing to a nil bus does nothing.
+var bus *eventBus
+
+func newEventBus() *eventBus {
+	b := &eventBus{done: make(chan struct{})}
+	b.cond = sync.NewCond(&b.mu)
+	go b.run()
+	return b
+}
+
+// Subscribe adds fn to the subscribers. Subscribe before publishing; fn is
+// called with each batch of events.
+func (b *eventBus) Subscribe(fn func([]sessionEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, fn)
+}
+
+// Publish queues a batch of events for delivery.
+func (b *eventBus) Publish(events []sessionEvent) {
+	if b == nil || len(events) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.queue = append(b.queue, events)
+	b.cond.Signal()
+}
+
+func (b *eventBus) run() {
+	defer close(b.done)
+	for {
+		b.mu.Lock()
+		for len(b.queue) == 0 && !b.closed {
+			b.cond.Wait()
+		}
+		if len(b.queue) == 0 {
+			b.mu.Unlock()
+			return
+		}
+		events := b.queue[0]
+		b.queue = b.queue[1:]
+		subs := b.subs
+		b.mu.Unlock()
+		for _, fn := range subs {
+			fn(events)
+		}
+	}
+}
+
+// Close delivers the events already published and stops the bus.
+func (b *eventBus) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.closed = true
+	b.cond.Signal()
+	b.mu.Unlock()
+	<-b.done
+}
+
+// subscribeAll connects history, mirrors, scripts, and the debug log to b.
+// History and scripts only get the events published while owned() holds,
+// so that a dashboard and the daemon don't both record and notify.
+func subscribeAll(b *eventBus, owned func() bool) {
+	b.Subscribe(func(events []sessionEvent) {
+		if owned() {
+			recordHistory(events)
+		}
+	})
+	b.Subscribe(mirrors.Record)
+	b.Subscribe(func(events []sessionEvent) {
+		if !owned() {
+			return
+		}
+		for _, e := range events {
+			// Snoozed sessions don't notify
+			if e.Kind == statusChanged && !e.Snoozed {
+				scripts.StatusChanged(e.Session, e.OldStatus)
+			}
+		}
+	})
+	if debugLog != nil {
+		b.Subscribe(logEvents)
+	}
+}
+
+// logEvents writes events to the debug log.
+func logEvents(events []sessionEvent) {
+	now := time.Now().Format("15:04:05")
+	for _, e := range events {
+		fmt.Fprintf(debugLog, "[%s] event %s w%d %q%s\n", now, e.Kind, e.Session.WindowID, e.Session.Title, eventDetail(e))
+	}
+}
+
+func eventDetail(e sessionEvent) string {
+	switch e.Kind {
+	case statusChanged:
+		return fmt.Sprintf(": %s -> %s (%s)", e.OldStatus, e.Session.Status, e.Session.StatusSource)
+	case outputAppended:
+		return fmt.Sprintf(": %d line(s)", len(e.Lines))
+	case sessionAdded:
+		return ": " + e.Session.AI
+	case sessionUpdated:
+		if e.Session.Prompt != "" {
+			return ": " + strings.TrimSpace(e.Session.Prompt)
+		}
+	}
+	return ""
+}
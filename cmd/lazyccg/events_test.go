@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSessions(t *testing.T) {
+	prev := []session{
+		{WindowID: 1, AI: "claude", Title: "api", Status: "RUNNING", Lines: []string{"a", "> "}},
+		{WindowID: 2, AI: "codex", Title: "web", Status: "IDLE"},
+		{WindowID: 3, AI: "claude", Title: "old"},
+	}
+	next := []session{
+		{WindowID: 1, AI: "claude", Title: "api", Prompt: "fix it", Status: "WAITING", Lines: []string{"a", "b", "> "}},
+		{WindowID: 2, AI: "gemini", Title: "web", Status: "IDLE"}, // another agent in the same window
+		{WindowID: 4, AI: "claude", Title: "new", Lines: []string{"hi"}},
+	}
+	var got []string
+	for _, e := range diffSessions(prev, next) {
+		got = append(got, e.Kind.String()+" "+e.Session.AI+" "+e.Session.Title)
+	}
+	want := []string{
+		"updated claude api",
+		"output claude api",
+		"status claude api",
+		"removed codex web",
+		"added gemini web",
+		"added claude new",
+		"output claude new",
+		"removed claude old",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSessions() =\n%v\nwant\n%v", got, want)
+	}
+
+	events := diffSessions(prev[:1], next[:1])
+	if e := events[1]; !reflect.DeepEqual(e.Lines, []string{"b"}) {
+		t.Errorf("appended lines = %v, want [b]", e.Lines)
+	}
+	if e := events[2]; e.OldStatus != "RUNNING" {
+		t.Errorf("old status = %s, want RUNNING", e.OldStatus)
+	}
+	if events := diffSessions(next, next); len(events) != 0 {
+		t.Errorf("diffSessions() of an unchanged poll = %v", events)
+	}
+}
+
+func TestUpdateUnreadSnoozed(t *testing.T) {
+	prev := []session{{WindowID: 1, Status: "RUNNING"}}
+	next := []session{{WindowID: 1, Status: "WAITING"}}
+	events := diffSessions(prev, next)
+	markSnoozed(events, map[int]snooze{1: {}})
+	if got := updateUnread(nil, events, next); len(got) != 0 {
+		t.Errorf("updateUnread() for a snoozed session = %v, want none", got)
+	}
+}
+
+func TestEventBus(t *testing.T) {
+	b := newEventBus()
+	var got []string
+	b.Subscribe(func(events []sessionEvent) {
+		for _, e := range events {
+			got = append(got, e.Session.Title)
+		}
+	})
+	b.Publish(diffSessions(nil, []session{{WindowID: 1, Title: "one"}}))
+	b.Publish(nil)
+	b.Publish(diffSessions(nil, []session{{WindowID: 2, Title: "two"}}))
+	b.Close()
+	b.Publish(diffSessions(nil, []session{{WindowID: 3, Title: "late"}}))
+
+	if !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("delivered %v, want [one two] in order", got)
+	}
+	var nilBus *eventBus
+	nilBus.Publish(diffSessions(nil, []session{{WindowID: 1}}))
+	nilBus.Close()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// An exited agent's exit code comes from kitty's shell integration, which
+// reports the status of the last command once the shell is back at its
+// prompt, or else from an exit summary naming the agent, or kitty's
+// "[Process exited N]", in the last lines on screen. A code once known is kept,
+// so a later command in the window doesn't replace it.
+
+// exitCodePattern matches an exit summary, e.g. "codex exited with code 1";
+// the line must name the agent too, so a failing test's "exit status 2"
+// left in the output doesn't count.
+var exitCodePattern = regexp.MustCompile(`(?i)\bexit(?:ed)?\b(?: with)?(?: code| status)?:? \(?(-?\d+)\)?(?:\W|$)`)
+
+// processExitedPattern matches the line kitty prints when a window's
+// program ends and the window is held open, e.g. "[Process exited 0]".
+var processExitedPattern = regexp.MustCompile(`^\[Process exited (-?\d+)\]$`)
+
+// exitLookback is how many lines from the end an exit summary is looked for
+// in: the agent's last words before the shell prompt.
+const exitLookback = 3
+
+// exitCode returns the exit code of ai's agent that ran in win, from the
+// shell's report or an exit summary just above the prompt.
+func exitCode(ai string, win kittyWindow, lines []string) (int, bool) {
+	if win.AtPrompt && win.LastCmdExitStatus != nil {
+		return *win.LastCmdExitStatus, true
+	}
+	named := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(ai) + `\b`)
+	for i := len(lines) - 1; i >= max(len(lines)-exitLookback, 0); i-- {
+		line := strings.TrimSpace(lines[i])
+		m := processExitedPattern.FindStringSubmatch(line)
+		if m == nil && named.MatchString(line) {
+			m = exitCodePattern.FindStringSubmatch(line)
+		}
+		if m != nil {
+			code, err := strconv.Atoi(m[1])
+			return code, err == nil
+		}
+	}
+	return 0, false
+}
+
+// exitHint describes an exited agent, with its exit code when known.
+func exitHint(ai string, code int, known bool) string {
+	if known {
+		return fmt.Sprintf("%s exited (%d)", ai, code)
+	}
+	return ai + " exited"
+}
+
+// withExit fills in how s's agent exited: its code, kept from prev once
+// known, and what went wrong when it failed.
+func withExit(s, prev session, win kittyWindow, lines []string) session {
+	s.ExitCode, s.ExitKnown = prev.ExitCode, prev.ExitKnown
+	if !s.ExitKnown {
+		s.ExitCode, s.ExitKnown = exitCode(s.AI, win, lines)
+	}
+	s.ExitHint = exitHint(s.AI, s.ExitCode, s.ExitKnown)
+	if s.ExitKnown && s.ExitCode != 0 {
+		s.ErrorLine = agentstatus.ErrorExcerpt(lines)
+	}
+	return s
+}
+
+// formatExited renders an exited session's status with its exit code, e.g.
+// "EXITED (1)", in the error style when it failed.
+func (m model) formatExited(s session) string {
+	if !s.ExitKnown {
+		return m.formatStatus(s.Status)
+	}
+	label := fmt.Sprintf("EXITED (%d)", s.ExitCode)
+	style := helpDescStyle
+	if s.ExitCode != 0 {
+		style = statusStyle(agentstatus.Error)
+	}
+	return style.Render(statusSymbolCell(s.Status) + label)
+}
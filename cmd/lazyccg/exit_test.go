@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestExitCode(t *testing.T) {
+	one := 1
+	tests := []struct {
+		name  string
+		win   kittyWindow
+		lines []string
+		want  int
+		known bool
+	}{
+		{name: "exit code", lines: []string{"Bye!", "codex exited with code 1", "$ "}, want: 1, known: true},
+		{name: "exit status", lines: []string{"error: Codex exit status 2", "% "}, want: 2, known: true},
+		{name: "kitty hold message", lines: []string{"[Process exited 0]"}, want: 0, known: true},
+		{name: "without the agent's name", lines: []string{"FAIL: exit status 2", "% "}, known: false},
+		{name: "a test's log", lines: []string{"test_exit.py: exited with code 1", "codex: 3 tests failed", "% "}, known: false},
+		{name: "inside a word", lines: []string{"codex: see the sysexit 2 table", "% "}, known: false},
+		{name: "above the last lines", lines: []string{"codex exited with code 1", "$ ls", "go.mod", "main.go", "$ "}, known: false},
+		{name: "shell integration", win: kittyWindow{AtPrompt: true, LastCmdExitStatus: &one}, lines: []string{"Goodbye", "$ "}, want: 1, known: true},
+		{name: "not back at the prompt", win: kittyWindow{LastCmdExitStatus: &one}, lines: []string{"Goodbye"}, known: false},
+		{name: "no code", lines: []string{"Goodbye", "$ "}, known: false},
+		{name: "empty", lines: nil, known: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, known := exitCode("codex", tt.win, tt.lines); got != tt.want || known != tt.known {
+				t.Errorf("exitCode() = %d, %v; want %d, %v", got, known, tt.want, tt.known)
+			}
+		})
+	}
+}
+
+func TestWithExit(t *testing.T) {
+	lines := []string{"panic: runtime error: index out of range [3] with length 3", "codex exited with status 2", "$ "}
+	s := withExit(session{AI: "codex"}, session{}, kittyWindow{}, lines)
+	if !s.ExitKnown || s.ExitCode != 2 || s.ExitHint != "codex exited (2)" {
+		t.Errorf("withExit() = %d, %v, %q; want code 2", s.ExitCode, s.ExitKnown, s.ExitHint)
+	}
+	if s.ErrorLine != "panic: runtime error: index out of range [3] with length 3" {
+		t.Errorf("ErrorLine = %q, want the panic", s.ErrorLine)
+	}
+
+	// A command run in the window later doesn't replace the agent's code
+	zero := 0
+	later := withExit(session{AI: "codex"}, s, kittyWindow{AtPrompt: true, LastCmdExitStatus: &zero}, []string{"$ ls", "$ "})
+	if later.ExitCode != 2 || later.ExitHint != "codex exited (2)" {
+		t.Errorf("later withExit() = %d, %q; want the agent's code 2 kept", later.ExitCode, later.ExitHint)
+	}
+
+	if s := withExit(session{AI: "claude"}, session{}, kittyWindow{}, []string{"Goodbye", "$ "}); s.ExitKnown || s.ExitHint != "claude exited" || s.ErrorLine != "" {
+		t.Errorf("withExit() without a code = %+v", s)
+	}
+}
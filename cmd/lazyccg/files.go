@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// touchedFile is a file a session changed, per git or its own edit calls.
+type touchedFile struct {
+	Path   string // absolute
+	Name   string // relative to the session cwd when inside it
+	Git    string // porcelain status such as "M" or "??"; "" if clean
+	Edited bool   // seen in an edit tool call
+}
+
+// editSuffixPattern matches the "(+3 -1)" Codex prints after an edited path.
+var editSuffixPattern = regexp.MustCompile(`\s+\([+-]\d+.*\)$`)
+
+// editedPaths returns the paths named by edit tool calls in lines.
+func editedPaths(lines []string) []string {
+	var paths []string
+	for _, line := range lines {
+		call, ok := parseToolCall(line)
+		if !ok || call.Kind != "edit" {
+			continue
+		}
+		if path := strings.TrimSpace(editSuffixPattern.ReplaceAllString(call.Arg, "")); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// appendUnique appends the values not already in list.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// parseGitStatus parses `git status --porcelain` output into paths relative
+// to the repository root and their status.
+func parseGitStatus(out string) map[string]string {
+	files := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		status, path := strings.TrimSpace(line[:2]), line[3:]
+		if i := strings.Index(path, " -> "); i >= 0 {
+			path = path[i+4:] // renamed: keep the new name
+		}
+		files[strings.Trim(path, `"`)] = status
+	}
+	return files
+}
+
+// touchedFiles merges uncommitted changes in the repository containing cwd
+// with the paths the session edited, sorted by name.
+func touchedFiles(cwd string, edited []string) []touchedFile {
+	byPath := make(map[string]*touchedFile)
+	add := func(path string) *touchedFile {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cwd, path)
+		}
+		path = filepath.Clean(path)
+		f, ok := byPath[path]
+		if !ok {
+			f = &touchedFile{Path: path, Name: path}
+			if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				f.Name = rel
+			} else {
+				f.Name = shortenHome(path)
+			}
+			byPath[path] = f
+		}
+		return f
+	}
+
+	if root, status := gitStatus(cwd); root != "" {
+		for path, status := range parseGitStatus(status) {
+			add(filepath.Join(root, path)).Git = status
+		}
+	}
+	for _, path := range edited {
+		add(path).Edited = true
+	}
+
+	files := make([]touchedFile, 0, len(byPath))
+	for _, f := range byPath {
+		files = append(files, *f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
+
+// gitStatus returns the root of the repository containing cwd and its
+// `git status --porcelain`, at most gitStatusTTL old. root is "" outside a
+// repository.
+func gitStatus(cwd string) (root, status string) {
+	if cwd == "" {
+		return "", ""
+	}
+	out, _ := gitStatusCache.get(cwd, gitStatusTTL, func() (string, error) {
+		top, err := exec.Command("git", "-C", cwd, "rev-parse", "--show-toplevel").Output()
+		if err != nil {
+			return gitStatusNone, nil
+		}
+		status, err := exec.Command("git", "-C", cwd, "status", "--porcelain").Output()
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(top)) + "\n" + string(status), nil
+	})
+	if out == gitStatusNone {
+		return "", ""
+	}
+	root, status, _ = strings.Cut(out, "\n")
+	return root, status
+}
+
+type filesMsg struct {
+	windowID int
+	files    []touchedFile
+}
+
+func filesCmd(s session) tea.Cmd {
+	return func() tea.Msg {
+		return filesMsg{windowID: s.WindowID, files: touchedFiles(s.Cwd, s.Edited)}
+	}
+}
+
+type editorClosedMsg struct {
+	err error
+}
+
+// editorCmd opens path in $VISUAL or $EDITOR (vi if neither is set),
+// suspending the dashboard until the editor exits.
+func editorCmd(path string) tea.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), path)
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		return editorClosedMsg{err: err}
+	})
+}
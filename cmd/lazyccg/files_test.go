@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditedPaths(t *testing.T) {
+	lines := []string{
+		"⏺ Update(main.go)",
+		"● Bash(go test ./...)",
+		"• Edited internal/ui/view.go (+3 -1)",
+		"✔ WriteFile docs/notes.md",
+		"plain output",
+	}
+	want := []string{"main.go", "internal/ui/view.go", "docs/notes.md"}
+	if got := editedPaths(lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("editedPaths() = %v, want %v", got, want)
+	}
+}
+
+func TestParseGitStatus(t *testing.T) {
+	out := " M main.go\n?? new.txt\nR  old.go -> renamed.go\nA  \"with space.go\"\n"
+	want := map[string]string{"main.go": "M", "new.txt": "??", "renamed.go": "R", "with space.go": "A"}
+	if got := parseGitStatus(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGitStatus() = %v, want %v", got, want)
+	}
+}
+
+func TestTouchedFilesWithoutGit(t *testing.T) {
+	dir := t.TempDir()
+	files := touchedFiles(dir, []string{"b.go", "a.go", dir + "/a.go"})
+	if len(files) != 2 {
+		t.Fatalf("touchedFiles() returned %d files, want 2: %+v", len(files), files)
+	}
+	if files[0].Name != "a.go" || !files[0].Edited || files[0].Git != "" {
+		t.Errorf("unexpected first file %+v", files[0])
+	}
+}
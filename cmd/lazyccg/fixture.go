@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// Status fixtures are plain text files: a "# key: value" header followed by
+// the captured lines. TestInferStatusFixtures checks every file in
+// testdata/status against its agent's parser in the status package.
+const fixtureDir = "cmd/lazyccg/testdata/status"
+
+type statusFixture struct {
+	AI     string
+	Status agentstatus.Status
+	Lines  []string
+}
+
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`xox[abprs]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`\b[0-9a-fA-F]{32,}\b`),
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// redactLines removes the home directory, email addresses, and token-like
+// strings so captures can be shared publicly.
+func redactLines(lines []string, home string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if home != "" && home != "/" {
+			line = strings.ReplaceAll(line, home, "~")
+		}
+		line = emailPattern.ReplaceAllString(line, "<email>")
+		for _, re := range secretPatterns {
+			line = re.ReplaceAllString(line, "<redacted>")
+		}
+		out[i] = line
+	}
+	return out
+}
+
+func writeFixture(w io.Writer, f statusFixture) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# ai: %s\n", f.AI)
+	fmt.Fprintf(bw, "# status: %s\n", f.Status)
+	for _, line := range f.Lines {
+		fmt.Fprintln(bw, line)
+	}
+	return bw.Flush()
+}
+
+func parseFixture(r io.Reader) (statusFixture, error) {
+	var f statusFixture
+	scanner := bufio.NewScanner(r)
+	inHeader := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inHeader && strings.HasPrefix(line, "# ") {
+			key, value, ok := strings.Cut(strings.TrimPrefix(line, "# "), ":")
+			if ok {
+				switch strings.TrimSpace(key) {
+				case "ai":
+					f.AI = strings.TrimSpace(value)
+				case "status":
+					f.Status = agentstatus.Parse(value)
+				}
+				continue
+			}
+		}
+		inHeader = false
+		f.Lines = append(f.Lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return f, err
+	}
+	if f.Status == "" {
+		return f, fmt.Errorf("fixture has no status header")
+	}
+	return f, nil
+}
+
+func runCaptureFixture(args []string, prefixes []string, maxLines int) error {
+	fs := flag.NewFlagSet("capture-fixture", flag.ContinueOnError)
+	windowID := fs.Int("window", 0, "kitty window id of the session (default: choose interactively)")
+	status := fs.String("status", "", "expected status (default: ask)")
+	out := fs.String("out", fixtureDir, "directory to write the fixture to")
+	name := fs.String("name", "", "fixture file name (default: <ai>-<status>-<timestamp>.txt)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sessions, _, err := loadSessions(prefixes, maxLines, newPollState())
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		return fmt.Errorf("no sessions found")
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+	var selected *session
+	if *windowID != 0 {
+		for i := range sessions {
+			if sessions[i].WindowID == *windowID {
+				selected = &sessions[i]
+			}
+		}
+		if selected == nil {
+			return fmt.Errorf("no session with window id %d", *windowID)
+		}
+	} else {
+		for i, s := range sessions {
+			fmt.Printf("  [%d] %s %-7s %s\n", i+1, shortAI(s.AI), s.Status, s.Title)
+		}
+		answer, err := prompt(stdin, "session number: ")
+		if err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(sessions) {
+			return fmt.Errorf("invalid session number %q", answer)
+		}
+		selected = &sessions[n-1]
+	}
+
+	if *status == "" {
+		answer, err := prompt(stdin, fmt.Sprintf("expected status (detected %s): ", selected.Status))
+		if err != nil {
+			return err
+		}
+		if answer == "" {
+			answer = selected.Status.String()
+		}
+		*status = answer
+	}
+
+	home, _ := os.UserHomeDir()
+	fixture := statusFixture{
+		AI:     selected.AI,
+		Status: agentstatus.Parse(*status),
+		Lines:  redactLines(selected.Lines, home),
+	}
+
+	fileName := *name
+	if fileName == "" {
+		fileName = fmt.Sprintf("%s-%s-%s.txt", fixture.AI, strings.ToLower(fixture.Status.String()), time.Now().Format("20060102-150405"))
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(*out, fileName)
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := writeFixture(file, fixture); err != nil {
+		return err
+	}
+	fmt.Println("wrote", path)
+	fmt.Println("review it for anything sensitive before committing")
+	return nil
+}
+
+func prompt(r *bufio.Reader, label string) (string, error) {
+	fmt.Print(label)
+	line, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
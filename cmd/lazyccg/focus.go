@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// `lazyccg -focus api` finds the session for "api" and focuses its window,
+// for shell aliases like `alias agent='lazyccg -focus'`. With -focus-tui
+// the dashboard opens with the session selected instead.
+
+// Match ranks, best first.
+const (
+	matchExact = iota
+	matchPrefix
+	matchSubstring
+	noMatch
+)
+
+// sessionMatch ranks how well query names s: by its title, project, or
+// directory name, case-insensitively.
+func sessionMatch(s session, query string) int {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return noMatch
+	}
+	best := noMatch
+	for _, name := range []string{s.Title, s.Project, filepath.Base(s.Cwd)} {
+		name = strings.ToLower(name)
+		if name == "" || name == "." {
+			continue
+		}
+		rank := noMatch
+		switch {
+		case name == query:
+			rank = matchExact
+		case strings.HasPrefix(name, query):
+			rank = matchPrefix
+		case strings.Contains(name, query):
+			rank = matchSubstring
+		}
+		best = min(best, rank)
+	}
+	return best
+}
+
+// findSession returns the index of the session query names best. Among
+// equally good matches a session waiting on the user wins, then the first.
+func findSession(sessions []session, query string) (int, bool) {
+	found, best := -1, noMatch
+	for i, s := range sessions {
+		rank := sessionMatch(s, query)
+		if rank == noMatch {
+			continue
+		}
+		if rank < best || rank == best && s.Status.IsWaiting() && !sessions[found].Status.IsWaiting() {
+			found, best = i, rank
+		}
+	}
+	return found, found >= 0
+}
+
+// runFocus implements -focus: focus the session query names and exit.
+func runFocus(query string, prefixes []string, maxLines int) error {
+	sessions, _, err := loadSessions(prefixes, maxLines, newPollState())
+	if err != nil {
+		return err
+	}
+	i, ok := findSession(sessions, query)
+	if !ok {
+		return fmt.Errorf("no session matches %q", query)
+	}
+	s := sessions[i]
+	if err := backend.Focus(s.WindowID); err != nil {
+		return err
+	}
+	fmt.Printf("focused %s (%s) in %s\n", s.Title, s.AI, shortenHome(s.Cwd))
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestFindSession(t *testing.T) {
+	sessions := []session{
+		{Title: "claude api-gateway", Project: "api-gateway", Cwd: "/src/api-gateway", Status: "RUNNING"},
+		{Title: "web", Project: "web", Cwd: "/src/web", Status: "IDLE"},
+		{Title: "codex api", Project: "api", Cwd: "/src/api", Status: "RUNNING"},
+		{Title: "claude api", Project: "api", Cwd: "/src/api", Status: "NEEDS_APPROVAL"},
+	}
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"api", 3},      // exact project; the one waiting wins
+		{"API-gate", 0}, // prefix, any case
+		{"eb", 1},       // substring
+		{"codex api", 2},
+		{"infra", -1},
+		{"", -1},
+	}
+	for _, tt := range tests {
+		got, ok := findSession(sessions, tt.query)
+		if !ok {
+			got = -1
+		}
+		if got != tt.want {
+			t.Errorf("findSession(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
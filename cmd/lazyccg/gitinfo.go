@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type gitInfo struct {
+	Repo   string // basename of the repository root
+	Branch string
+}
+
+// lookupGitInfo returns the repository and branch for dir, cached for
+// gitInfoTTL since it is asked for on every poll.
+func lookupGitInfo(dir string) gitInfo {
+	if dir == "" {
+		return gitInfo{}
+	}
+	info, _ := gitInfoCache.get(dir, gitInfoTTL, func() (gitInfo, error) {
+		var info gitInfo
+		if out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output(); err == nil {
+			info.Repo = filepath.Base(strings.TrimSpace(string(out)))
+			if out, err := exec.Command("git", "-C", dir, "branch", "--show-current").Output(); err == nil {
+				info.Branch = strings.TrimSpace(string(out))
+			}
+		}
+		return info, nil
+	})
+	return info
+}
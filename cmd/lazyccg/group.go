@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// Sessions can be grouped by project, host, AI, or intent (g cycles through
+// them).
+// A collapsed group shows as a single header row; selecting it selects the
+// group's first session. Which grouping is used and which groups are
+// collapsed is kept across restarts. Enter on a collapsed group's header
+// narrows the panel to that group, opened, until esc.
+
+// groupings are the ways to group sessions; "" lists them ungrouped.
+var groupings = []string{"", "project", "host", "ai", "intent"}
+
+// groupName returns the group s belongs to when grouping by by.
+func groupName(s session, by string) string {
+	switch by {
+	case "project":
+		if s.Project != "" {
+			return s.Project
+		}
+		if s.Cwd != "" {
+			return filepath.Base(s.Cwd)
+		}
+		return "(no directory)"
+	case "host":
+		if s.Instance != "" {
+			return s.Instance
+		}
+		return "local"
+	case "ai":
+		return s.AI
+	case "intent":
+		if s.Intent != "" {
+			return s.Intent
+		}
+		return "(no intent)"
+	}
+	return ""
+}
+
+// projectName names the project a directory belongs to: its git
+// repository, or else the directory itself.
+func projectName(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	if repo := lookupGitInfo(dir).Repo; repo != "" {
+		return repo
+	}
+	return filepath.Base(dir)
+}
+
+type sessionGroup struct {
+	Name     string
+	Sessions []session
+}
+
+// groupSessions splits sessions into groups by name, keeping their order
+// within each group.
+func groupSessions(sessions []session, by string) []sessionGroup {
+	var groups []sessionGroup
+	index := make(map[string]int)
+	for _, s := range sessions {
+		name := groupName(s, by)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, sessionGroup{Name: name})
+		}
+		groups[i].Sessions = append(groups[i].Sessions, s)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// viewState is the part of the UI kept across restarts.
+type viewState struct {
+	path      string              // "" keeps it in memory
+	Grouping  string              `json:"grouping"`
+	Collapsed map[string][]string `json:"collapsed"`        // grouping -> collapsed groups
+	Sort      string              `json:"sort,omitempty"`   // "health" puts the sickest first
+	Locked    []int               `json:"locked,omitempty"` // windows locked with l
+}
+
+func viewStatePath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "view.json")
+}
+
+// loadViewState reads the saved state from path; a missing or unreadable
+// file gives the defaults.
+func loadViewState(path string) viewState {
+	v := viewState{path: path}
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &v)
+		}
+	}
+	if !slices.Contains(groupings, v.Grouping) {
+		v.Grouping = ""
+	}
+	if v.Sort != "health" {
+		v.Sort = ""
+	}
+	if v.Collapsed == nil {
+		v.Collapsed = make(map[string][]string)
+	}
+	return v
+}
+
+// save writes the state; errors are ignored, it's only a convenience.
+func (v viewState) save() {
+	if v.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(v.path), 0o755) == nil {
+		os.WriteFile(v.path, data, 0o600)
+	}
+}
+
+func (v viewState) collapsed(group string) bool {
+	return slices.Contains(v.Collapsed[v.Grouping], group)
+}
+
+// toggle collapses or expands group under the current grouping.
+func (v *viewState) toggle(group string) {
+	if v.Collapsed == nil {
+		v.Collapsed = make(map[string][]string)
+	}
+	names := v.Collapsed[v.Grouping]
+	if i := slices.Index(names, group); i >= 0 {
+		names = slices.Delete(slices.Clone(names), i, i+1)
+	} else {
+		names = append(slices.Clone(names), group)
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		delete(v.Collapsed, v.Grouping)
+	} else {
+		v.Collapsed[v.Grouping] = names
+	}
+	v.save()
+}
+
+func (v viewState) locked(windowID int) bool {
+	return slices.Contains(v.Locked, windowID)
+}
+
+// setLocked locks or unlocks a window.
+func (v *viewState) setLocked(windowID int, locked bool) {
+	if i := slices.Index(v.Locked, windowID); i >= 0 && !locked {
+		v.Locked = slices.Delete(slices.Clone(v.Locked), i, i+1)
+	} else if i < 0 && locked {
+		v.Locked = append(slices.Clone(v.Locked), windowID)
+		slices.Sort(v.Locked)
+	} else {
+		return
+	}
+	v.save()
+}
+
+// pruneLocked forgets the locks of windows that are gone, so a new window
+// given the same ID doesn't start out locked.
+func (v *viewState) pruneLocked(sessions []session) {
+	kept := slices.DeleteFunc(slices.Clone(v.Locked), func(id int) bool {
+		return !slices.ContainsFunc(sessions, func(s session) bool { return s.WindowID == id })
+	})
+	if len(kept) == len(v.Locked) {
+		return
+	}
+	v.Locked = kept
+	v.save()
+}
+
+// cycleGrouping switches to the next grouping.
+func (v *viewState) cycleGrouping() {
+	i := slices.Index(groupings, v.Grouping)
+	v.Grouping = groupings[(i+1)%len(groupings)]
+	v.save()
+}
+
+// toggleSort switches between agent order and sickest first.
+func (v *viewState) toggleSort() {
+	if v.Sort == "health" {
+		v.Sort = ""
+	} else {
+		v.Sort = "health"
+	}
+	v.save()
+}
+
+// visibleSessions lists the sessions of groups in order, with only the
+// first session of each collapsed group, which stands for the group. The
+// open group is listed in full even when collapsed.
+func (v viewState) visibleSessions(groups []sessionGroup, open string) []session {
+	var out []session
+	for _, g := range groups {
+		if g.Name != open && v.collapsed(g.Name) {
+			out = append(out, g.Sessions[0])
+			continue
+		}
+		out = append(out, g.Sessions...)
+	}
+	return out
+}
+
+// groupHeader renders a group's header row, with how many of its sessions
+// are in each status, in the order of statuses: "▾ api (2▶ 1✋)".
+func groupHeader(g sessionGroup, collapsed bool, statuses []agentstatus.Status) string {
+	arrow := "▾"
+	if collapsed {
+		arrow = "▸"
+	}
+	count := make(map[agentstatus.Status]int)
+	for _, s := range g.Sessions {
+		count[s.Status]++
+	}
+	var counts []string
+	for _, status := range statuses {
+		if n := count[status]; n > 0 {
+			symbol, ok := statusSymbol[status]
+			if !ok {
+				symbol = " " + status.String()
+			}
+			counts = append(counts, statusStyle(status).Render(fmt.Sprintf("%d%s", n, symbol)))
+		}
+	}
+	return helpKeyStyle.Render(fmt.Sprintf(" %s %s (", arrow, g.Name)) + strings.Join(counts, " ") + helpKeyStyle.Render(")")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGroupSessions(t *testing.T) {
+	sessions := []session{
+		{WindowID: 1, AI: "claude", Cwd: "/src/web", Project: "web", Intent: "bugfix"},
+		{WindowID: 2, AI: "codex", Cwd: "/src/api/internal", Project: "api", Instance: "devbox", Intent: "bugfix"},
+		{WindowID: 3, AI: "claude", Cwd: "/src/api"},
+	}
+	names := func(groups []sessionGroup) map[string][]int {
+		out := make(map[string][]int)
+		for _, g := range groups {
+			for _, s := range g.Sessions {
+				out[g.Name] = append(out[g.Name], s.WindowID)
+			}
+		}
+		return out
+	}
+	tests := []struct {
+		by   string
+		want map[string][]int
+	}{
+		{"project", map[string][]int{"api": {2, 3}, "web": {1}}},
+		{"host", map[string][]int{"devbox": {2}, "local": {1, 3}}},
+		{"ai", map[string][]int{"claude": {1, 3}, "codex": {2}}},
+		{"intent", map[string][]int{"bugfix": {1, 2}, "(no intent)": {3}}},
+	}
+	for _, tt := range tests {
+		if got := names(groupSessions(sessions, tt.by)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("groupSessions(%s) = %v, want %v", tt.by, got, tt.want)
+		}
+	}
+	if g := groupSessions(sessions, "project"); g[0].Name != "api" {
+		t.Errorf("groups should be sorted by name, got %s first", g[0].Name)
+	}
+}
+
+func TestViewStatePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lazyccg", "view.json")
+	v := loadViewState(path)
+	v.cycleGrouping()
+	v.toggle("api")
+	v.toggle("web")
+	v.toggle("web")
+	v.toggleSort()
+	v.setLocked(12, true)
+	v.setLocked(14, true)
+
+	again := loadViewState(path)
+	if !again.locked(12) || !again.locked(14) {
+		t.Errorf("reloaded locks = %v, want 12 and 14", again.Locked)
+	}
+	again.pruneLocked([]session{{WindowID: 12}})
+	if again := loadViewState(path); !again.locked(12) || again.locked(14) {
+		t.Errorf("locks after window 14 closed = %v, want 12", again.Locked)
+	}
+
+	if again.Grouping != "project" || !again.collapsed("api") || again.collapsed("web") || again.Sort != "health" {
+		t.Errorf("reloaded state = %+v", again)
+	}
+	groups := groupSessions([]session{{WindowID: 1, Project: "api"}, {WindowID: 2, Project: "api"}, {WindowID: 3, Project: "web"}}, "project")
+	var ids []int
+	for _, s := range again.visibleSessions(groups, "") {
+		ids = append(ids, s.WindowID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 3}) {
+		t.Errorf("visible sessions = %v, want one row for the collapsed api group", ids)
+	}
+	if got := again.visibleSessions(groups, "api"); len(got) != 3 {
+		t.Errorf("visible sessions with api open = %v, want all three", got)
+	}
+	// Collapsed groups are per grouping
+	again.cycleGrouping()
+	if again.collapsed("api") {
+		t.Error("api is collapsed under host grouping")
+	}
+
+	bad := filepath.Join(t.TempDir(), "view.json")
+	(viewState{path: bad, Grouping: "color"}).save()
+	if v := loadViewState(bad); v.Grouping != "" {
+		t.Errorf("unknown grouping loaded as %q", v.Grouping)
+	}
+}
+
+func TestGroupFilterKeepsCollapsed(t *testing.T) {
+	m := model{
+		pollEvery: time.Second,
+		poll:      newPollState(),
+		unread:    make(map[int]int),
+		renamed:   make(map[int]bool),
+		scroll:    make(map[int]scrollState),
+	}
+	sessions := []session{
+		{TabID: 1, WindowID: 1, Title: "api", AI: "claude", Status: "RUNNING", Cwd: "/src/api"},
+		{TabID: 2, WindowID: 2, Title: "api-2", AI: "codex", Status: "IDLE", Cwd: "/src/api"},
+	}
+	m = driveModel(m, tea.WindowSizeMsg{Width: 100, Height: 24}, sessionsMsg{sessions: sessions}, key("g"), key(" "), key("enter"))
+	if m.groupFilter != "api" || len(m.filteredSessions()) != 2 {
+		t.Fatalf("after Enter on the header: filter %q, %d rows; want api's two sessions", m.groupFilter, len(m.filteredSessions()))
+	}
+	if !m.view.collapsed("api") {
+		t.Error("Enter on the header expanded the saved group")
+	}
+	m = driveModel(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if len(m.filteredSessions()) != 1 {
+		t.Errorf("after Esc: %d rows; want the collapsed group's header", len(m.filteredSessions()))
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/atani/lazyccg/internal/ui"
+)
+
+// backendHealth is how polling one backend, or one instance of several, is
+// going.
+type backendHealth struct {
+	Name     string
+	Failures int // consecutive failed polls
+	LastErr  error
+	LastOK   time.Time // last successful poll
+	LastTry  time.Time
+}
+
+// state is "connected", "erroring" or "waiting" (not polled yet).
+func (h backendHealth) state() string {
+	switch {
+	case h.Failures > 0:
+		return "erroring"
+	case h.LastOK.IsZero():
+		return "waiting"
+	}
+	return "connected"
+}
+
+// nextRetry is when a failing backend is polled again.
+func (h backendHealth) nextRetry() time.Time {
+	if h.Failures == 0 {
+		return time.Time{}
+	}
+	return h.LastTry.Add(retryBackoff(time.Second, h.Failures))
+}
+
+// healthTracker records the result of each poll per backend. Polls run in
+// tea.Cmds, the health panel reads from the UI, hence the lock.
+type healthTracker struct {
+	mu   sync.Mutex
+	rows []*backendHealth // in order of first poll
+}
+
+var health = &healthTracker{}
+
+func (t *healthTracker) row(name string) *backendHealth {
+	for _, h := range t.rows {
+		if h.Name == name {
+			return h
+		}
+	}
+	h := &backendHealth{Name: name}
+	t.rows = append(t.rows, h)
+	return h
+}
+
+// record notes a poll of name that ended in err at now.
+func (t *healthTracker) record(name string, err error, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.row(name)
+	h.LastTry = now
+	if err != nil {
+		h.Failures++
+		h.LastErr = err
+		return
+	}
+	h.Failures = 0
+	h.LastErr = nil
+	h.LastOK = now
+}
+
+// due reports whether name may be polled at now, or is backing off after
+// failures.
+func (t *healthTracker) due(name string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, h := range t.rows {
+		if h.Name == name {
+			return !now.Before(h.nextRetry())
+		}
+	}
+	return true
+}
+
+func (t *healthTracker) snapshot() []backendHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rows := make([]backendHealth, len(t.rows))
+	for i, h := range t.rows {
+		rows[i] = *h
+	}
+	return rows
+}
+
+// healthLines renders the backend section of the stats screen.
+func healthLines(rows []backendHealth, width int, now time.Time) []string {
+	lines := []string{helpDescStyle.Render(fmt.Sprintf(" %-24s %-10s %-12s  %s", "BACKEND", "STATE", "LAST OK", "ERROR"))}
+	for _, h := range rows {
+		lastOK := "never"
+		if !h.LastOK.IsZero() {
+			lastOK = timeFmt.Ago(h.LastOK, now)
+		}
+		line := fmt.Sprintf(" %-24s %-10s %-12s", ui.Truncate(h.Name, 24), h.state(), lastOK)
+		if h.Failures == 0 {
+			lines = append(lines, line)
+			continue
+		}
+		retry := "retrying"
+		if wait := h.nextRetry().Sub(now); wait > 0 {
+			retry = fmt.Sprintf("retry in %s", wait.Round(time.Second))
+		}
+		line = fmt.Sprintf("%s  %d× %v (%s)", line, h.Failures, h.LastErr, retry)
+		lines = append(lines, statusWaiting.Render(ui.Truncate(line, width)))
+	}
+	return lines
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atani/lazyccg/pkg/kitty"
+)
+
+func TestHealthTracker(t *testing.T) {
+	var tr healthTracker
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if !tr.due("kitty", now) {
+		t.Error("a backend never polled should be due")
+	}
+	tr.record("kitty", nil, now)
+	tr.record("tmux", errors.New("no server"), now)
+	tr.record("tmux", errors.New("no server"), now.Add(time.Second))
+
+	rows := tr.snapshot()
+	if len(rows) != 2 || rows[0].state() != "connected" || rows[1].state() != "erroring" {
+		t.Fatalf("snapshot() = %+v", rows)
+	}
+	// Two failures: retry 4s after the last
+	if tr.due("tmux", now.Add(3*time.Second)) || !tr.due("tmux", now.Add(5*time.Second)) {
+		t.Errorf("tmux retry at %s, want 4s after the last try", rows[1].nextRetry())
+	}
+	lines := healthLines(rows, 200, now.Add(2*time.Second))
+	if !strings.Contains(lines[2], "2× no server (retry in 3s)") {
+		t.Errorf("health line = %q", lines[2])
+	}
+
+	// Recovers on the next good poll
+	tr.record("tmux", nil, now.Add(5*time.Second))
+	if h := tr.snapshot()[1]; h.state() != "connected" || h.LastErr != nil || !tr.due("tmux", now.Add(5*time.Second)) {
+		t.Errorf("after recovering: %+v", h)
+	}
+}
+
+func TestKittyRelocate(t *testing.T) {
+	dir := t.TempDir()
+	listen := func(name string) string {
+		ln, err := net.Listen("unix", filepath.Join(dir, name))
+		if err != nil {
+			t.Skipf("unix sockets unavailable: %v", err)
+		}
+		t.Cleanup(func() { ln.Close() })
+		return "unix:" + ln.Addr().String()
+	}
+	old := "unix:" + filepath.Join(dir, "kitty-100") // kitty quit
+	k := newKittyBackend(old)
+	if !k.follow {
+		t.Fatal("a pid-suffixed socket should be followed")
+	}
+	restarted := listen("kitty-200")
+	if !k.relocate([]string{old, restarted, "unix:" + filepath.Join(dir, "kitty-300")}) {
+		t.Fatal("relocate() = false, want the restarted kitty")
+	}
+	if got := k.rc.Socket(); got != restarted {
+		t.Errorf("socket = %s, want %s", got, restarted)
+	}
+	// The current socket answers again: stay
+	if k.relocate([]string{listen("kitty-400")}) {
+		t.Error("relocate() moved away from a live socket")
+	}
+
+	// Two other kittys: can't tell which one replaced it
+	rc, _ := kitty.NewClient(old)
+	k.rc = rc
+	if k.relocate([]string{restarted, listen("kitty-500")}) {
+		t.Error("relocate() picked one of two kittys")
+	}
+	if newKittyBackend("unix:/tmp/mykitty").follow {
+		t.Error("a fixed socket name should not be followed")
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// Transcripts are JSONL files, one per session, in the history dir. The first
+// record is "meta"; "lines" records hold output that appeared since the
+// previous poll and "status" records hold status changes.
+type historyRecord struct {
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	AI       string    `json:"ai,omitempty"`
+	Title    string    `json:"title,omitempty"`
+	Cwd      string    `json:"cwd,omitempty"`
+	WindowID int       `json:"window_id,omitempty"`
+	Prompt   string    `json:"prompt,omitempty"`
+	Intent   string    `json:"intent,omitempty"`
+	Lines    []string  `json:"lines,omitempty"`
+
+	Status     agentstatus.Status `json:"status,omitempty"`
+	Source     agentstatus.Source `json:"source,omitempty"`     // how the status was determined
+	Confidence float64            `json:"confidence,omitempty"` // 0 to 1
+	ExitCode   *int               `json:"exit_code,omitempty"`  // the agent's, once it exited
+}
+
+// dataDir returns $XDG_DATA_HOME/lazyccg, falling back to ~/.local/share/lazyccg.
+func dataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "lazyccg")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "lazyccg")
+}
+
+func historyDir() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "transcripts")
+}
+
+type transcriptWriter struct {
+	path string
+}
+
+// historyRecorder writes session events to per-session transcripts.
+type historyRecorder struct {
+	mu   sync.Mutex
+	dir  string
+	open map[int]*transcriptWriter // windowID -> current transcript
+}
+
+var history *historyRecorder
+
+func newHistoryRecorder(dir string) *historyRecorder {
+	return &historyRecorder{dir: dir, open: make(map[int]*transcriptWriter)}
+}
+
+// Record writes a poll's events. A session that is added starts a new
+// transcript, so a window that reappears later gets one of its own.
+func (h *historyRecorder) Record(events []sessionEvent) error {
+	if h == nil || len(events) == 0 {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := os.MkdirAll(h.dir, 0o700); err != nil {
+		return err
+	}
+	now := time.Now()
+	var order []*transcriptWriter
+	records := make(map[*transcriptWriter][]historyRecord)
+	for _, e := range events {
+		s := e.Session
+		t := h.open[s.WindowID]
+		if e.Kind == sessionRemoved {
+			delete(h.open, s.WindowID)
+			continue
+		}
+		if e.Kind == sessionAdded {
+			t = &transcriptWriter{
+				path: filepath.Join(h.dir, fmt.Sprintf("%s-%s-w%d.jsonl", now.Format("20060102-150405"), s.AI, s.WindowID)),
+			}
+			h.open[s.WindowID] = t
+			order = append(order, t)
+		}
+		if t == nil {
+			// Recording started after the session was added
+			continue
+		}
+		var r historyRecord
+		switch e.Kind {
+		case sessionAdded:
+			records[t] = append(records[t], historyRecord{
+				Type: "meta", Time: now, AI: s.AI, Title: s.Title, Cwd: s.Cwd, WindowID: s.WindowID, Prompt: s.Prompt, Intent: s.Intent,
+			})
+			r = historyRecord{Type: "status", Time: now, Status: s.Status, Source: s.StatusSource, Confidence: s.Confidence, ExitCode: recordedExit(s)}
+		case sessionUpdated:
+			r = historyRecord{Type: "meta", Time: now, Title: s.Title, Prompt: s.Prompt, Intent: s.Intent, ExitCode: recordedExit(s)}
+		case outputAppended:
+			r = historyRecord{Type: "lines", Time: now, Lines: e.Lines}
+		case statusChanged:
+			r = historyRecord{Type: "status", Time: now, Status: s.Status, Source: s.StatusSource, Confidence: s.Confidence, ExitCode: recordedExit(s)}
+		}
+		if _, ok := records[t]; !ok {
+			order = append(order, t)
+		}
+		records[t] = append(records[t], r)
+	}
+	for _, t := range order {
+		if err := appendRecords(t.path, records[t]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordedExit is s's exit code for its transcript, or nil while it runs or
+// when the code isn't known.
+func recordedExit(s session) *int {
+	if s.Status != agentstatus.Exited || !s.ExitKnown {
+		return nil
+	}
+	code := s.ExitCode
+	return &code
+}
+
+func appendRecords(path string, records []historyRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendedLines returns the lines of cur that were not in prev. Agent TUIs
+// keep their input box and footer at the bottom, so new output isn't simply
+// a suffix; lines are matched as a multiset instead. A new frame of a
+// spinner or progress bar isn't new output.
+func appendedLines(prev, cur []string) []string {
+	count := make(map[string]int, len(prev))
+	for _, line := range prev {
+		count[agentstatus.FrameKey(line)]++
+	}
+	var added []string
+	for _, line := range cur {
+		if key := agentstatus.FrameKey(line); count[key] > 0 {
+			count[key]--
+			continue
+		}
+		added = append(added, line)
+	}
+	return added
+}
+
+// transcript is a transcript file read back from disk.
+type transcript struct {
+	Path     string
+	Started  time.Time
+	AI       string
+	Title    string
+	Cwd      string
+	WindowID int
+	Prompt   string
+	Intent   string
+	Records  []historyRecord
+}
+
+func readTranscript(path string) (transcript, error) {
+	t := transcript{Path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		return t, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r historyRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue // tolerate a partially written last line
+		}
+		if r.Type == "meta" {
+			if t.Started.IsZero() {
+				t.Started, t.AI, t.Cwd, t.WindowID = r.Time, r.AI, r.Cwd, r.WindowID
+			}
+			if r.Title != "" {
+				t.Title = r.Title
+			}
+			if r.Prompt != "" {
+				t.Prompt = r.Prompt
+			}
+			if r.Intent != "" && t.Intent == "" {
+				t.Intent = r.Intent
+			}
+		}
+		t.Records = append(t.Records, r)
+	}
+	return t, scanner.Err()
+}
+
+// Lines returns all recorded output in order.
+func (t transcript) Lines() []string {
+	var lines []string
+	for _, r := range t.Records {
+		if r.Type == "lines" {
+			lines = append(lines, r.Lines...)
+		}
+	}
+	return lines
+}
+
+// listTranscripts returns transcript paths in dir, newest first.
+func listTranscripts(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	return paths, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppendedLines(t *testing.T) {
+	prev := []string{"a", "b", "> ", "footer"}
+	cur := []string{"b", "c", "d", "> ", "footer"}
+	if got := appendedLines(prev, cur); !reflect.DeepEqual(got, []string{"c", "d"}) {
+		t.Errorf("appendedLines() = %v, want [c d]", got)
+	}
+	if got := appendedLines(nil, []string{"x"}); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("appendedLines(nil) = %v, want [x]", got)
+	}
+	// The spinner ticked; nothing was written
+	prev = []string{"> fix it", "✻ Crunching… (12s · esc to interrupt)"}
+	cur = []string{"> fix it", "✶ Crunching… (13s · esc to interrupt)"}
+	if got := appendedLines(prev, cur); len(got) != 0 {
+		t.Errorf("appendedLines() = %v for a new spinner frame, want none", got)
+	}
+}
+
+func TestHistoryRecorder(t *testing.T) {
+	dir := t.TempDir()
+	h := newHistoryRecorder(dir)
+
+	s := session{WindowID: 7, AI: "claude", Title: "api", Cwd: "/src/api", Status: "RUNNING", Lines: []string{"one", "> "}}
+	if err := h.Record(diffSessions(nil, []session{s})); err != nil {
+		t.Fatal(err)
+	}
+	prev := s
+	s.Lines = []string{"one", "two", "> "}
+	s.Status = "IDLE"
+	if err := h.Record(diffSessions([]session{prev}, []session{s})); err != nil {
+		t.Fatal(err)
+	}
+	prev = s
+	s.Status, s.ExitCode, s.ExitKnown = "EXITED", 1, true
+	if err := h.Record(diffSessions([]session{prev}, []session{s})); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := listTranscripts(dir)
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("listTranscripts() = %v, %v", paths, err)
+	}
+	tr, err := readTranscript(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.AI != "claude" || tr.Title != "api" || tr.WindowID != 7 {
+		t.Errorf("transcript meta = %+v", tr)
+	}
+	if got := tr.Lines(); !reflect.DeepEqual(got, []string{"one", "> ", "two"}) {
+		t.Errorf("Lines() = %v", got)
+	}
+	var statuses []string
+	for _, r := range tr.Records {
+		if r.Type == "status" {
+			statuses = append(statuses, r.Status.String())
+		}
+	}
+	if got := diffLines(tr.Records[len(tr.Records)-1:]); len(got) != 1 || !strings.HasSuffix(got[0], " EXITED (1)") {
+		t.Errorf("diffLines() of the exit = %q, want it with its exit code", got)
+	}
+	if !reflect.DeepEqual(statuses, []string{"RUNNING", "IDLE", "EXITED"}) {
+		t.Errorf("statuses = %v", statuses)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// Claude Code runs hooks on its own events. With hooks.listen set, lazyccg
+// takes those events over HTTP and uses them as the status of the session
+// that sent them, instead of reading its screen. Sessions that send none
+// keep text inference.
+//
+// A hook posts the JSON Claude Code passes it on stdin, with the window it
+// runs in in the URL, $KITTY_WINDOW_ID or, with the tmux backend, $TMUX_PANE:
+//
+//	curl -s -m 1 --data-binary @- "http://127.0.0.1:7787/hook?window=$KITTY_WINDOW_ID"
+
+// hooksConfig configures the hook listener.
+type hooksConfig struct {
+	// Listen is the address to take hook events on, e.g. "127.0.0.1:7787";
+	// "" turns the listener off.
+	Listen string `yaml:"listen"`
+}
+
+// hookEvent is what lazyccg keeps of a hook's input.
+type hookEvent struct {
+	Name    string `json:"hook_event_name"` // "Stop", "Notification", "PreToolUse", ...
+	Session string `json:"session_id"`
+	Cwd     string `json:"cwd"`
+	Message string `json:"message"` // Notification's text
+	Tool    string `json:"tool_name"`
+
+	Window int       `json:"-"`
+	Named  bool      `json:"-"` // the hook said its window; tmux's first pane is 0
+	At     time.Time `json:"-"`
+}
+
+// status is the status a session is in after e, or false for events that
+// don't tell, e.g. SessionStart.
+func (e hookEvent) status() (agentstatus.Status, bool) {
+	switch e.Name {
+	case "UserPromptSubmit", "PreToolUse", "PostToolUse", "SubagentStop", "PreCompact":
+		return agentstatus.Running, true
+	case "Stop":
+		return agentstatus.Idle, true
+	case "Notification":
+		message := strings.ToLower(e.Message)
+		switch {
+		case strings.Contains(message, "permission"):
+			// "Claude needs your permission to use Bash"
+			return agentstatus.NeedsApproval, true
+		case strings.Contains(message, "waiting for your input"):
+			return agentstatus.NeedsInput, true
+		}
+		return agentstatus.Waiting, true
+	}
+	return "", false
+}
+
+// why describes e, e.g. "Stop hook at 14:03:05".
+func (e hookEvent) why() string {
+	why := fmt.Sprintf("%s hook at %s", e.Name, e.At.Format("15:04:05"))
+	if e.Message != "" {
+		why += fmt.Sprintf(": %q", e.Message)
+	}
+	return why
+}
+
+// hookTracker keeps the last event of each session. The listener writes to
+// it, polls read from it, hence the lock.
+type hookTracker struct {
+	mu       sync.Mutex
+	byWindow map[int]hookEvent    // events that named their window
+	byCwd    map[string]hookEvent // events that didn't, by directory
+}
+
+var hooks = newHookTracker()
+
+func newHookTracker() *hookTracker {
+	return &hookTracker{byWindow: make(map[int]hookEvent), byCwd: make(map[string]hookEvent)}
+}
+
+// record notes e, or forgets its session when it ended.
+func (t *hookTracker) record(e hookEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e.Name == "SessionEnd" {
+		if e.Named {
+			delete(t.byWindow, e.Window)
+		}
+		delete(t.byCwd, e.Cwd)
+		return
+	}
+	if _, ok := e.status(); !ok {
+		return
+	}
+	if e.Named {
+		t.byWindow[e.Window] = e
+	} else if e.Cwd != "" {
+		t.byCwd[e.Cwd] = e
+	}
+}
+
+// last returns the last event from the window, or from an agent in cwd
+// that didn't name its window.
+func (t *hookTracker) last(window int, cwd string) (hookEvent, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e, ok := t.byWindow[window]; ok {
+		return e, true
+	}
+	e, ok := t.byCwd[cwd]
+	return e, ok && cwd != ""
+}
+
+// hookStatus returns the status the hooks give a session whose screen last
+// changed at moved; an interrupted turn sends no Stop, see reportedStatus.
+func hookStatus(e hookEvent, moved, now time.Time) (agentstatus.Status, bool) {
+	status, _ := e.status()
+	return reportedStatus(status, e.At, moved, now)
+}
+
+// parseHookWindow reads the window a hook names: a kitty window id, or a
+// tmux pane ("%3"). It is false when s names none.
+func parseHookWindow(s string) (int, bool) {
+	id, err := strconv.Atoi(strings.TrimPrefix(s, "%"))
+	if err != nil || id < 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// hookHandler records the events posted to it.
+func hookHandler(t *hookTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST a hook's input", http.StatusMethodNotAllowed)
+			return
+		}
+		var e hookEvent
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e.Window, e.Named = parseHookWindow(r.URL.Query().Get("window"))
+		e.At = time.Now()
+		t.record(e)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// healthzHandler answers that the listener is up, for scripts and
+// monitors to check before relying on the hooks, e.g.
+//
+//	{"status":"ok","version":"v0.9.0","uptime":"3h12m","sessions":2}
+//
+// where sessions are those whose last hook event is kept.
+func healthzHandler(t *hookTracker, started time.Time) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "GET the listener's health", http.StatusMethodNotAllowed)
+			return
+		}
+		t.mu.Lock()
+		sessions := len(t.byWindow) + len(t.byCwd)
+		t.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Status   string `json:"status"`
+			Version  string `json:"version"`
+			Uptime   string `json:"uptime"`
+			Sessions int    `json:"sessions"`
+		}{"ok", version, time.Since(started).Truncate(time.Second).String(), sessions})
+	})
+}
+
+// serveHooks listens for hook events on addr until shutdownCtx is canceled.
+func serveHooks(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("hooks: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/hook", hookHandler(hooks))
+	mux.Handle("/bell", bellHandler(bells))
+	mux.Handle("/healthz", healthzHandler(hooks, time.Now()))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 2 * time.Second}
+	go func() {
+		<-shutdownCtx.Done()
+		srv.Close()
+	}()
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) && debugLog != nil {
+			fmt.Fprintf(debugLog, "[%s] hooks: %v\n", time.Now().Format("15:04:05"), err)
+		}
+	}()
+	return nil
+}
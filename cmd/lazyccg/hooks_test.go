@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestHookEventStatus(t *testing.T) {
+	tests := []struct {
+		event hookEvent
+		want  agentstatus.Status
+		ok    bool
+	}{
+		{hookEvent{Name: "PreToolUse", Tool: "Bash"}, agentstatus.Running, true},
+		{hookEvent{Name: "UserPromptSubmit"}, agentstatus.Running, true},
+		{hookEvent{Name: "Stop"}, agentstatus.Idle, true},
+		{hookEvent{Name: "Notification", Message: "Claude needs your permission to use Bash"}, agentstatus.NeedsApproval, true},
+		{hookEvent{Name: "Notification", Message: "Claude is waiting for your input"}, agentstatus.NeedsInput, true},
+		{hookEvent{Name: "Notification", Message: "Something else"}, agentstatus.Waiting, true},
+		{hookEvent{Name: "SessionStart"}, "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := tt.event.status(); got != tt.want || ok != tt.ok {
+			t.Errorf("%s %q: status() = %q, %v; want %q, %v", tt.event.Name, tt.event.Message, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHookHandler(t *testing.T) {
+	tracker := newHookTracker()
+	srv := httptest.NewServer(hookHandler(tracker))
+	defer srv.Close()
+
+	post := func(query, body string) int {
+		resp, err := http.Post(srv.URL+"/hook"+query, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("?window=%257", `{"hook_event_name":"PreToolUse","cwd":"/src/a","tool_name":"Edit"}`); code != http.StatusNoContent {
+		t.Fatalf("POST = %d", code)
+	}
+	if code := post("?window=12", `{"hook_event_name":"Notification","cwd":"/src/b","message":"Claude needs your permission to use Bash"}`); code != http.StatusNoContent {
+		t.Fatalf("POST = %d", code)
+	}
+	post("", `{"hook_event_name":"Stop","cwd":"/src/c"}`)
+	if code := post("", `not json`); code != http.StatusBadRequest {
+		t.Errorf("bad JSON: %d, want 400", code)
+	}
+
+	if e, ok := tracker.last(12, "/elsewhere"); !ok || e.Name != "Notification" {
+		t.Errorf("last(12) = %+v, %v; want the Notification", e, ok)
+	}
+	if e, ok := tracker.last(99, "/src/c"); !ok || e.Name != "Stop" {
+		t.Errorf("last(99, /src/c) = %+v, %v; want the Stop found by directory", e, ok)
+	}
+	if _, ok := tracker.last(99, ""); ok {
+		t.Error("last(99, \"\") found an event")
+	}
+
+	post("?window=12", `{"hook_event_name":"SessionEnd"}`)
+	if _, ok := tracker.last(12, ""); ok {
+		t.Error("an ended session's event is still kept")
+	}
+}
+
+func TestHealthzHandler(t *testing.T) {
+	tracker := newHookTracker()
+	tracker.record(hookEvent{Name: "Stop", Window: 3, Named: true})
+	srv := httptest.NewServer(healthzHandler(tracker, time.Now().Add(-time.Minute)))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var health struct {
+		Status   string
+		Uptime   string
+		Sessions int
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || health.Status != "ok" || health.Sessions != 1 || health.Uptime != "1m0s" {
+		t.Errorf("GET /healthz = %d %+v, want 200 ok with 1 session up 1m0s", resp.StatusCode, health)
+	}
+
+	post, err := http.Post(srv.URL+"/healthz", "text/plain", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	post.Body.Close()
+	if post.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /healthz = %d, want 405", post.StatusCode)
+	}
+}
+
+func TestParseHookWindow(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"7", 7, true},
+		{"%3", 3, true},
+		{"%0", 0, true}, // tmux's first pane
+		{"", 0, false},
+		{"x", 0, false},
+		{"12%3", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseHookWindow(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("parseHookWindow(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHookStatus(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	running := hookEvent{Name: "PreToolUse", At: now.Add(-time.Minute)}
+	if got, ok := hookStatus(running, now.Add(-time.Second), now); !ok || got != agentstatus.Running {
+		t.Errorf("hookStatus() = %q, %v for a moving screen; want RUNNING", got, ok)
+	}
+	// Interrupted with esc: no Stop comes, and the screen went still
+	if got, ok := hookStatus(running, now.Add(-time.Minute), now); ok {
+		t.Errorf("hookStatus() = %q for a still screen; want text inference", got)
+	}
+	stop := hookEvent{Name: "Stop", At: now.Add(-time.Hour)}
+	if got, ok := hookStatus(stop, time.Time{}, now); !ok || got != agentstatus.Idle {
+		t.Errorf("hookStatus() = %q, %v; want IDLE however old", got, ok)
+	}
+}
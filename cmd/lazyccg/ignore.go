@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Lines matching an ignore pattern are left out of status inference, so
+// text that merely mentions a status doesn't set it: an agent editing code
+// with "waiting for approval" in a string, or a log line saying "done".
+//
+//	ignore:
+//	  - '^\s*\d+\s*[+-]'            # diff lines, e.g. "  42 + // waiting…"
+//	  - 'waitingForApproval|isDone'
+//
+// Patterns are regular expressions, matched ignoring case. The lines are
+// still shown; only the parsers, profiles, and status rules skip them.
+
+// compileIgnore validates the config's ignore patterns.
+func compileIgnore(c *config) error {
+	c.ignoreRes = nil
+	for i, pattern := range c.Ignore {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return fmt.Errorf("ignore[%d]: %w", i, err)
+		}
+		c.ignoreRes = append(c.ignoreRes, re)
+	}
+	return nil
+}
+
+// withoutIgnored returns lines without those an ignore pattern matches.
+func withoutIgnored(lines []string, ignore []*regexp.Regexp) []string {
+	if len(ignore) == 0 {
+		return lines
+	}
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !matchesAny(line, ignore) {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+func matchesAny(line string, res []*regexp.Regexp) bool {
+	for _, re := range res {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
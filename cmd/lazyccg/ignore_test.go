@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestWithoutIgnored(t *testing.T) {
+	c := config{Ignore: []string{`^\s*\d+\s*[+-]`, `isDone`}}
+	if err := compileIgnore(&c); err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{"⏺ Update(status.go)", `  42 + 	if strings.Contains(line, "waiting for approval") {`, "  43   return ISDONE", "  44   return nil", "> "}
+	want := []string{"⏺ Update(status.go)", "  44   return nil", "> "}
+	if got := withoutIgnored(lines, c.ignoreRes); !slices.Equal(got, want) {
+		t.Errorf("withoutIgnored() = %q, want %q", got, want)
+	}
+	if got := withoutIgnored(lines, nil); !slices.Equal(got, lines) {
+		t.Errorf("withoutIgnored(nil) = %q, want the lines as they are", got)
+	}
+
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config{}
+	if status, _, _, _ := detectStatus("mystery", lines); status != agentstatus.Waiting {
+		t.Fatalf("detectStatus() = %s without ignore patterns, want the diff line to read as WAITING", status)
+	}
+	cfg = c
+	if status, _, _, _ := detectStatus("mystery", lines); status != agentstatus.Idle {
+		t.Errorf("detectStatus() = %s, want IDLE with the diff line ignored", status)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	os.WriteFile(path, []byte("ignore: ['(']\n"), 0o644)
+	if _, err := loadConfig(path); err == nil {
+		t.Error("loadConfig() took a bad ignore pattern")
+	}
+}
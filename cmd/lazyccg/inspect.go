@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/atani/lazyccg/internal/ui"
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The inspect screen (i) shows why the selected session has its status:
+// the rule, pattern, or event that gave it, what each layer of text
+// inference says on its own, and the lines they looked at. It's for
+// telling misclassifications apart and tuning status_rules and profiles
+// without running --debug.
+
+// inspectLines is how many of the last lines the inspect screen shows.
+const inspectLines = 12
+
+// statusLayer is what one layer of text inference says about a screen.
+type statusLayer struct {
+	Name   string
+	Status agentstatus.Status // "" when the layer has nothing to say
+	Why    string
+}
+
+// statusLayers runs each layer of text inference on lines by itself, in
+// the order detectStatus tries them.
+func statusLayers(ai string, lines []string) []statusLayer {
+	lines = withoutIgnored(lines, cfg.ignoreRes)
+	rules, rulesWhy := matchStatusRules(cfg.StatusRules, ai, lines)
+	profile, profileWhy := profiles.detectStatus(ai, lines)
+	parser, _, parserWhy := agentstatus.ExplainAgentWithin(agents.parser(ai), lines, agents.lookback(ai))
+	return []statusLayer{
+		{"status rules", rules, rulesWhy},
+		{"profile", profile, profileWhy},
+		{"parser", parser, ai + " parser, " + parserWhy},
+	}
+}
+
+func (m model) renderInspectScreen(width, height int) string {
+	var s session
+	found := false
+	for _, candidate := range m.sessions {
+		if candidate.WindowID == m.inspectWindow {
+			s, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		return drawBox("Inspect", []string{helpDescStyle.Render(" the session is gone")}, width, height, cyan)
+	}
+	innerWidth := width - 2
+
+	var lines []string
+	field := func(label, value string) {
+		label = fmt.Sprintf(" %-10s", label)
+		lines = append(lines, helpDescStyle.Render(label)+ui.Truncate(value, innerWidth-len(label)))
+	}
+	lines = append(lines, helpDescStyle.Render(fmt.Sprintf(" %-10s", "Status"))+statusStyle(s.Status).Render(statusLabel(s.Status))+
+		helpDescStyle.Render(statusBasis(s, time.Now())))
+	field("Why", s.StatusWhy)
+
+	lines = append(lines, "", " "+titleStyle.Render("Each layer on its own"))
+	for _, l := range statusLayers(s.AI, s.Lines) {
+		label := fmt.Sprintf("   %-13s", l.Name)
+		if l.Status == "" {
+			lines = append(lines, helpDescStyle.Render(label+"no match"))
+			continue
+		}
+		status := statusStyle(l.Status).Render(fmt.Sprintf("%-15s", l.Status))
+		lines = append(lines, helpDescStyle.Render(label)+status+ui.Truncate(l.Why, innerWidth-len(label)-15))
+	}
+
+	lines = append(lines, "", " "+titleStyle.Render("Last lines"))
+	start := max(len(s.Lines)-inspectLines, 0)
+	for i, line := range s.Lines[start:] {
+		number := helpDescStyle.Render(fmt.Sprintf("   %3d ", start+i+1))
+		lines = append(lines, number+ui.Truncate(line, innerWidth-7))
+	}
+	if len(s.Lines) == 0 {
+		lines = append(lines, helpDescStyle.Render("   no output"))
+	}
+
+	title := fmt.Sprintf("Inspect %s (%s)", s.Title, shortAI(s.AI))
+	scroll := min(m.inspectScroll, len(lines))
+	return drawBox(title, lines[scroll:], width, height, cyan)
+}
+
+func (m model) updateInspect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "i":
+		m.inspectOpen = false
+	case "up", "k":
+		if m.inspectScroll > 0 {
+			m.inspectScroll--
+		}
+	case "down", "j":
+		m.inspectScroll++
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
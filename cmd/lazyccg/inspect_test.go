@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestStatusLayers(t *testing.T) {
+	saved, savedProfiles := cfg, profiles
+	defer func() { cfg, profiles = saved, savedProfiles }()
+	rule := statusRule{profileRule: profileRule{Match: `^\$ $`, Status: agentstatus.Idle}}
+	cfg = config{StatusRules: []statusRule{rule}}
+	if err := compileStatusRules(cfg.StatusRules); err != nil {
+		t.Fatal(err)
+	}
+	profiles = profileSet{}
+
+	lines := []string{"Do you want to proceed?", "❯ 1. Yes", "  2. No", "$ "}
+	layers := statusLayers("claude", lines)
+	if len(layers) != 3 {
+		t.Fatalf("statusLayers() = %d layers, want 3", len(layers))
+	}
+	tests := []struct {
+		layer   statusLayer
+		name    string
+		status  agentstatus.Status
+		mention string
+	}{
+		{layers[0], "status rules", agentstatus.Idle, `/^\$ $/ matched "$"`},
+		{layers[1], "profile", "", ""},
+		{layers[2], "parser", agentstatus.NeedsApproval, "claude parser, permission menu"},
+	}
+	for _, tt := range tests {
+		if tt.layer.Name != tt.name || tt.layer.Status != tt.status || !strings.Contains(tt.layer.Why, tt.mention) {
+			t.Errorf("layer = %+v, want %s saying %q because of %q", tt.layer, tt.name, tt.status, tt.mention)
+		}
+	}
+
+	// The first layer with something to say is the one detectStatus uses
+	status, source, _, why := detectStatus("claude", lines)
+	if status != agentstatus.Idle || source != agentstatus.SourceRule || why != layers[0].Why {
+		t.Errorf("detectStatus() = %s, %s, %q", status, source, why)
+	}
+}
+
+func TestActivityWhy(t *testing.T) {
+	if got := activityWhy(agentstatus.Running, "prompt on the last line"); !strings.Contains(got, "screen changed") {
+		t.Errorf("activityWhy(RUNNING) = %q", got)
+	}
+	if got := activityWhy(agentstatus.Idle, `interrupt hint: "esc to interrupt"`); !strings.HasSuffix(got, `interrupt hint: "esc to interrupt"`) {
+		t.Errorf("activityWhy(IDLE) = %q, want the text's reason kept", got)
+	}
+}
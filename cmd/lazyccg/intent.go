@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Sessions are labeled with what they were asked to do, by keywords in
+// their first prompt: a bugfix, a feature, a refactor, or research. The
+// label shows in the Sessions panel (#bugfix), sessions can be grouped by
+// it, and `lazyccg report` totals agent time by it. The prompt comes from
+// the agent's session log where there is one, else from the screen.
+//
+//	intents:
+//	  - name: docs
+//	    keywords: [readme, docs, document, changelog]
+//	  - name: bugfix
+//	    keywords: [fix, bug, flaky]
+//
+// Rules in the config come before the built-in ones, and replace a built-in
+// one of the same name. The rule with the most keywords in the prompt wins;
+// on a tie, the first. A keyword matches whole words, with an -s, -es, -d,
+// -ed, or -ing ending.
+
+// intentRule labels prompts containing any of Keywords with Name.
+type intentRule struct {
+	Name     string   `yaml:"name"`
+	Keywords []string `yaml:"keywords"`
+}
+
+// defaultIntents are the built-in labels.
+var defaultIntents = []intentRule{
+	{Name: "bugfix", Keywords: []string{"fix", "bug", "broken", "crash", "error", "fail", "failing", "regression", "panic", "wrong"}},
+	{Name: "refactor", Keywords: []string{"refactor", "clean up", "cleanup", "rename", "extract", "simplify", "restructure", "reorganize", "tidy", "deduplicate"}},
+	{Name: "research", Keywords: []string{"why", "how does", "how do", "explain", "investigate", "look into", "find out", "what is", "understand", "compare", "research"}},
+	{Name: "feature", Keywords: []string{"add", "implement", "support", "create", "build", "new", "introduce", "allow", "feature"}},
+}
+
+// keywordEndings are the endings a keyword may have in a prompt.
+var keywordEndings = []string{"", "s", "es", "d", "ed", "ing"}
+
+// validateIntents checks the config's intent rules.
+func validateIntents(rules []intentRule) error {
+	for i, r := range rules {
+		if r.Name == "" {
+			return fmt.Errorf("intents[%d]: no name", i)
+		}
+		if len(r.Keywords) == 0 {
+			return fmt.Errorf("intents[%d] (%s): no keywords", i, r.Name)
+		}
+	}
+	return nil
+}
+
+// intentRules are the config's rules followed by the built-in ones they
+// don't replace.
+func intentRules() []intentRule {
+	rules := slices.Clone(cfg.Intents)
+	for _, r := range defaultIntents {
+		if !slices.ContainsFunc(cfg.Intents, func(c intentRule) bool { return c.Name == r.Name }) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// classifyIntent labels prompt by rules; "" when no keyword is in it.
+func classifyIntent(prompt string, rules []intentRule) string {
+	words := strings.FieldsFunc(strings.ToLower(prompt), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	// Padded so keywords only match whole words
+	text := " " + strings.Join(words, " ") + " "
+	best, bestHits := "", 0
+	for _, r := range rules {
+		hits := 0
+		for _, kw := range r.Keywords {
+			kw = strings.Join(strings.Fields(strings.ToLower(kw)), " ")
+			if slices.ContainsFunc(keywordEndings, func(end string) bool {
+				return strings.Contains(text, " "+kw+end+" ")
+			}) {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = r.Name, hits
+		}
+	}
+	return best
+}
+
+// sessionIntent labels a session by its first prompt: the one its log
+// recorded, else the one on screen. Once labeled it keeps its label, since
+// the prompt on screen scrolls away.
+func sessionIntent(prev session, known bool, logPrompt, screenPrompt string) string {
+	if known && prev.Intent != "" {
+		return prev.Intent
+	}
+	prompt := logPrompt
+	if prompt == "" {
+		prompt = screenPrompt
+	}
+	return classifyIntent(prompt, intentRules())
+}
@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func TestClassifyIntent(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   string
+	}{
+		{"Fix the crash when the config file is empty", "bugfix"},
+		{"The login test is failing on CI", "bugfix"},
+		{"Add dark mode support to the settings page", "feature"},
+		{"Refactor the poller and extract the capture loop", "refactor"},
+		{"Why does the cache miss so often? Investigate", "research"},
+		{"Explain how does the scheduler pick windows", "research"},
+		// "prefix" is not "fix", nor "address" "add"
+		{"Update the prefix in the address book", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := classifyIntent(tt.prompt, defaultIntents); got != tt.want {
+			t.Errorf("classifyIntent(%q) = %q, want %q", tt.prompt, got, tt.want)
+		}
+	}
+}
+
+func TestIntentRules(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg.Intents = []intentRule{
+		{Name: "docs", Keywords: []string{"readme", "document"}},
+		{Name: "bugfix", Keywords: []string{"flaky"}},
+	}
+	rules := intentRules()
+	if len(rules) != len(defaultIntents)+1 || rules[0].Name != "docs" {
+		t.Fatalf("intentRules() = %+v; want docs first and bugfix replaced", rules)
+	}
+	tests := map[string]string{
+		"Document the new flags in the README": "docs",
+		"Deflake the flaky upload test":        "bugfix",
+		"Fix the typo":                         "", // the built-in bugfix rule is gone
+		"Add retries":                          "feature",
+	}
+	for prompt, want := range tests {
+		if got := classifyIntent(prompt, rules); got != want {
+			t.Errorf("classifyIntent(%q) = %q, want %q", prompt, got, want)
+		}
+	}
+
+	if err := validateIntents([]intentRule{{Name: "docs"}}); err == nil {
+		t.Error("validateIntents() took a rule without keywords")
+	}
+	if err := validateIntents([]intentRule{{Keywords: []string{"docs"}}}); err == nil {
+		t.Error("validateIntents() took a rule without a name")
+	}
+}
+
+func TestSessionIntent(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg.Intents = nil
+
+	if got := sessionIntent(session{}, false, "Fix the build", "Add a flag"); got != "bugfix" {
+		t.Errorf("sessionIntent() = %q; want the log's prompt to win", got)
+	}
+	if got := sessionIntent(session{}, false, "", "Add a flag"); got != "feature" {
+		t.Errorf("sessionIntent() = %q; want the screen's prompt without a log", got)
+	}
+	if got := sessionIntent(session{Intent: "research"}, true, "", "Add a flag"); got != "research" {
+		t.Errorf("sessionIntent() = %q; want the label kept", got)
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// iTerm2 is driven through its Python API: a small helper script using the
+// iterm2 package (pip3 install iterm2) talks to iTerm2 over its websocket.
+// The API must be enabled under Settings > General > Magic. iTerm2 windows
+// map onto the kitty layout like WezTerm's: windows, tabs, and split
+// sessions as windows.
+//
+// One helper keeps running with its connection open, rather than a python3
+// and a handshake for every listing and capture on every poll.
+
+// itermHelper is run as `python3 -c itermHelper`. It reads one request per
+// line, {"cmd": "capture", "args": [...]}, and answers each with a line of
+// its own, {"out": ...} or {"err": ...}. It exits when stdin closes.
+const itermHelper = `
+import asyncio, json, sys
+import iterm2
+
+async def handle(connection, app, cmd, args):
+    if cmd == "list":
+        out = []
+        for w in app.terminal_windows:
+            for t in w.tabs:
+                for s in t.sessions:
+                    out.append({
+                        "window_id": w.window_id,
+                        "tab_id": t.tab_id,
+                        "session_id": s.session_id,
+                        "title": s.name or "",
+                        "tty": await s.async_get_variable("tty") or "",
+                        "cwd": await s.async_get_variable("path") or "",
+                    })
+        return json.dumps(out)
+    s = app.get_session_by_id(args[0])
+    if s is None:
+        raise LookupError("no iTerm2 session " + args[0])
+    if cmd == "capture":
+        async with iterm2.Transaction(connection):
+            info = await s.async_get_line_info()
+            if args[1] == "all":
+                first, count = info.overflow, info.scrollback_buffer_height + info.mutable_area_height
+            else:
+                first, count = info.overflow + info.scrollback_buffer_height, info.mutable_area_height
+            contents = await s.async_get_contents(first, count)
+        return "\n".join(line.string for line in contents)
+    elif cmd == "focus":
+        await app.async_activate()
+        await s.async_activate(select_tab=True, order_window_front=True)
+    elif cmd == "rename":
+        await s.async_set_name(args[1])
+    elif cmd == "send":
+        await s.async_send_text(args[1])
+    return ""
+
+async def main(connection):
+    # The app follows layout changes itself while the connection is open
+    app = await iterm2.async_get_app(connection)
+    loop = asyncio.get_running_loop()
+    while True:
+        line = await loop.run_in_executor(None, sys.stdin.readline)
+        if not line:
+            return
+        req = json.loads(line)
+        try:
+            reply = {"out": await handle(connection, app, req["cmd"], req["args"])}
+        except Exception as e:
+            reply = {"err": str(e)}
+        print(json.dumps(reply), flush=True)
+
+iterm2.run_until_complete(main)
+`
+
+// itermBackend drives iTerm2. Sessions are addressed by UUID.
+type itermBackend struct {
+	ids    *windowIDs
+	helper *itermHelperProc
+}
+
+func newItermBackend() itermBackend {
+	return itermBackend{ids: newWindowIDs(), helper: &itermHelperProc{command: func() *exec.Cmd {
+		return exec.Command("python3", "-c", itermHelper)
+	}}}
+}
+
+func (itermBackend) Name() string { return "iterm2" }
+
+// Close stops the helper.
+func (b itermBackend) Close() error {
+	b.helper.mu.Lock()
+	defer b.helper.mu.Unlock()
+	if b.helper.cmd != nil {
+		b.helper.stopLocked(nil)
+	}
+	return nil
+}
+
+// itermHelperTimeout is how long the helper may take to answer a request
+// before it is restarted.
+const itermHelperTimeout = 10 * time.Second
+
+// itermHelperProc is the running helper. It is started on the first
+// request, and again after it dies or hangs.
+type itermHelperProc struct {
+	command func() *exec.Cmd
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	stderr bytes.Buffer // read only once cmd has exited
+}
+
+type itermRequest struct {
+	Cmd  string   `json:"cmd"`
+	Args []string `json:"args"`
+}
+
+type itermReply struct {
+	Out string `json:"out"`
+	Err string `json:"err"`
+}
+
+// request sends one command to the helper and returns its output.
+func (h *itermHelperProc) request(command string, args ...string) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cmd == nil {
+		if err := h.startLocked(); err != nil {
+			return nil, err
+		}
+	}
+	req, err := json.Marshal(itermRequest{Cmd: command, Args: args})
+	if err != nil {
+		return nil, err
+	}
+	proc := h.cmd.Process
+	timer := time.AfterFunc(itermHelperTimeout, func() { proc.Kill() })
+	defer timer.Stop()
+	var reply itermReply
+	if _, err = h.stdin.Write(append(req, '\n')); err == nil {
+		var line []byte
+		if line, err = h.stdout.ReadBytes('\n'); err == nil {
+			err = json.Unmarshal(line, &reply)
+		}
+	}
+	if err != nil {
+		return nil, h.stopLocked(err)
+	}
+	if reply.Err != "" {
+		return nil, errors.New(reply.Err)
+	}
+	return []byte(reply.Out), nil
+}
+
+func (h *itermHelperProc) startLocked() error {
+	cmd := h.command()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	h.stderr.Reset()
+	cmd.Stderr = &h.stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	h.cmd, h.stdin, h.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+// stopLocked ends the helper after err, adding the last line of its
+// stderr, which says why it failed (e.g. the iterm2 package is missing or
+// the API is disabled).
+func (h *itermHelperProc) stopLocked(err error) error {
+	h.stdin.Close()
+	h.cmd.Process.Kill()
+	h.cmd.Wait()
+	h.cmd = nil
+	if msg := strings.TrimSpace(h.stderr.String()); msg != "" && err != nil {
+		lines := strings.Split(msg, "\n")
+		err = fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+	}
+	return err
+}
+
+// itermSession is one session as listed by the helper.
+type itermSession struct {
+	WindowID  string `json:"window_id"`
+	TabID     string `json:"tab_id"`
+	SessionID string `json:"session_id"`
+	Title     string `json:"title"`
+	TTY       string `json:"tty"`
+	Cwd       string `json:"cwd"`
+}
+
+func (b itermBackend) List() ([]kittyOSWindow, error) {
+	out, err := b.helper.request("list")
+	if err != nil {
+		return nil, fmt.Errorf("iterm2 list: %w", err)
+	}
+	var sessions []itermSession
+	if err := json.Unmarshal(out, &sessions); err != nil {
+		return nil, fmt.Errorf("json parse: %w", err)
+	}
+	ps, err := runPs(commandRunner{}, "-A", "-o", "tty=,pid=,args=")
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	return b.windows(sessions, parseTTYProcesses(string(ps))), nil
+}
+
+// windows groups sessions into windows and tabs, matching processes by tty
+// as for WezTerm.
+func (b itermBackend) windows(sessions []itermSession, byTTY map[string][]foregroundProcess) []kittyOSWindow {
+	var osWindows []kittyOSWindow
+	windowIndex := make(map[string]int)
+	for _, s := range sessions {
+		idx, ok := windowIndex[s.WindowID]
+		if !ok {
+			idx = len(osWindows)
+			windowIndex[s.WindowID] = idx
+			osWindows = append(osWindows, kittyOSWindow{})
+		}
+		ow := &osWindows[idx]
+		tabID, err := strconv.Atoi(s.TabID)
+		if err != nil {
+			tabID = b.ids.id("tab:" + s.TabID)
+		}
+		var tab *kittyTab
+		for i := range ow.Tabs {
+			if ow.Tabs[i].ID == tabID {
+				tab = &ow.Tabs[i]
+			}
+		}
+		if tab == nil {
+			ow.Tabs = append(ow.Tabs, kittyTab{ID: tabID})
+			tab = &ow.Tabs[len(ow.Tabs)-1]
+		}
+		win := kittyWindow{ID: b.ids.id(s.SessionID), Title: s.Title, Cwd: s.Cwd}
+		for _, proc := range byTTY[strings.TrimPrefix(s.TTY, "/dev/")] {
+			proc.Cwd = s.Cwd
+			win.ForegroundProcesses = append(win.ForegroundProcesses, proc)
+		}
+		tab.Windows = append(tab.Windows, win)
+	}
+	return osWindows
+}
+
+func (b itermBackend) session(windowID int, command string, args ...string) ([]byte, error) {
+	uuid, err := b.ids.key(windowID)
+	if err != nil {
+		return nil, err
+	}
+	out, err := b.helper.request(command, append([]string{uuid}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("iterm2 %s: %w", command, err)
+	}
+	return out, nil
+}
+
+func (b itermBackend) CaptureText(windowID int, extent string) (string, error) {
+	if extent == "" {
+		extent = "screen"
+	}
+	out, err := b.session(windowID, "capture", extent)
+	return string(out), err
+}
+
+func (b itermBackend) Focus(windowID int) error {
+	_, err := b.session(windowID, "focus")
+	return err
+}
+
+func (b itermBackend) Rename(windowID int, title string) error {
+	_, err := b.session(windowID, "rename", title)
+	return err
+}
+
+func (b itermBackend) SendText(windowID int, text string) error {
+	_, err := b.session(windowID, "send", text)
+	return err
+}
+
+var errItermUnsupported = errors.New("not supported by iTerm2's Python API")
+
+// Scroll is not supported: the Python API cannot scroll a session.
+func (itermBackend) Scroll(windowID, lines int) error {
+	return errItermUnsupported
+}
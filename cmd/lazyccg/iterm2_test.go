@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+)
+
+func TestItermWindows(t *testing.T) {
+	b := newItermBackend()
+	sessions := []itermSession{
+		{WindowID: "pty-A", TabID: "3", SessionID: "UUID-1", Title: "claude", TTY: "/dev/ttys004", Cwd: "/src/api"},
+		{WindowID: "pty-A", TabID: "3", SessionID: "UUID-2", Title: "zsh", TTY: "/dev/ttys005", Cwd: "/src/api"},
+		{WindowID: "pty-B", TabID: "7", SessionID: "UUID-3", TTY: "/dev/ttys006", Cwd: "/tmp"},
+	}
+	byTTY := parseTTYProcesses("ttys004 100 -zsh\nttys004 101 node /opt/bin/claude\nttys005 200 -zsh\n")
+
+	osWindows := b.windows(sessions, byTTY)
+	if len(osWindows) != 2 || len(osWindows[0].Tabs) != 1 || len(osWindows[0].Tabs[0].Windows) != 2 {
+		t.Fatalf("unexpected layout: %+v", osWindows)
+	}
+	if osWindows[1].Tabs[0].ID != 7 {
+		t.Errorf("tab ID = %d, want 7", osWindows[1].Tabs[0].ID)
+	}
+	win := osWindows[0].Tabs[0].Windows[0]
+	if ai, ok := agentsession.DetectAI(win, []string{"claude"}); !ok || ai != "claude" {
+		t.Errorf("agentsession.DetectAI() = %q, %v; want claude", ai, ok)
+	}
+
+	// IDs stay the same across polls and map back to the session UUID
+	again := b.windows(sessions[2:], byTTY)
+	id := again[0].Tabs[0].Windows[0].ID
+	if id != osWindows[1].Tabs[0].Windows[0].ID {
+		t.Errorf("session ID changed between polls: %d", id)
+	}
+	if uuid, err := b.ids.key(id); err != nil || uuid != "UUID-3" {
+		t.Errorf("key(%d) = %q, %v", id, uuid, err)
+	}
+	if _, err := b.ids.key(99); err == nil {
+		t.Error("unknown ID should fail")
+	}
+}
+
+func TestItermHelperStaysRunning(t *testing.T) {
+	starts := 0
+	h := &itermHelperProc{command: func() *exec.Cmd {
+		starts++
+		// Answers with how many requests it has read
+		return exec.Command("sh", "-c", `n=0; while read -r line; do n=$((n+1)); echo "{\"out\":\"$n\"}"; done`)
+	}}
+	for want := 1; want <= 3; want++ {
+		out, err := h.request("capture", "UUID-1", "screen")
+		if err != nil || string(out) != strconv.Itoa(want) {
+			t.Fatalf("request %d = %q, %v", want, out, err)
+		}
+	}
+	if starts != 1 {
+		t.Errorf("helper started %d times, want once for all requests", starts)
+	}
+	b := itermBackend{helper: h}
+	b.Close()
+	if h.cmd != nil {
+		t.Error("Close left the helper running")
+	}
+}
+
+func TestItermHelperFails(t *testing.T) {
+	starts := 0
+	h := &itermHelperProc{command: func() *exec.Cmd {
+		starts++
+		return exec.Command("sh", "-c", `echo "ModuleNotFoundError: No module named 'iterm2'" >&2; exit 1`)
+	}}
+	if _, err := h.request("list"); err == nil || !strings.Contains(err.Error(), "No module named 'iterm2'") {
+		t.Errorf("request() error = %v, want the helper's stderr", err)
+	}
+	// The next request starts it again
+	h.request("list")
+	if starts != 2 {
+		t.Errorf("helper started %d times, want 2", starts)
+	}
+
+	h = &itermHelperProc{command: func() *exec.Cmd {
+		return exec.Command("sh", "-c", `while read -r line; do echo '{"err":"no iTerm2 session UUID-9"}'; done`)
+	}}
+	if _, err := h.request("focus", "UUID-9"); err == nil || err.Error() != "no iTerm2 session UUID-9" {
+		t.Errorf("request() error = %v, want the helper's error", err)
+	}
+}
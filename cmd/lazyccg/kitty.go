@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/atani/lazyccg/pkg/kitty"
+)
+
+// kitty's `kitty @ ls` layout (OS windows > tabs > windows) doubles as the
+// layout every backend reports.
+type (
+	kittyOSWindow     = kitty.OSWindow
+	kittyTab          = kitty.Tab
+	kittyWindow       = kitty.Window
+	foregroundProcess = kitty.ForegroundProcess
+)
+
+// kittyBackend talks to kitty over its remote control protocol: natively
+// when the socket is known, otherwise through `kitty @`.
+type kittyBackend struct {
+	socket string        // e.g. unix:/tmp/kitty; "" lets kitty find it
+	rc     *kitty.Client // nil when socket is "" or not one we can dial
+	run    commandRunner // runs `kitty @` when rc is nil
+	cli    string        // "kitten" or "kitty"; "" means kitty
+	follow bool          // move to a restarted kitty's socket (see relocate)
+}
+
+func newKittyBackend(socket string) kittyBackend {
+	k := kittyBackend{socket: socket, cli: kittyCLI(exec.LookPath), follow: kittyPIDSocket.MatchString(socket)}
+	if socket != "" {
+		k.rc, _ = kitty.NewClient(socket)
+	}
+	return k
+}
+
+// Close closes the remote control connection.
+func (k kittyBackend) Close() error {
+	if k.rc == nil {
+		return nil
+	}
+	return k.rc.Close()
+}
+
+// Remote returns the host of a TCP socket, or "" for a local kitty.
+func (k kittyBackend) Remote() string {
+	if k.rc == nil || k.rc.Network() != "tcp" {
+		return k.run.host
+	}
+	return k.rc.Address()
+}
+
+// match addresses one window in a remote control payload.
+type kittyMatch struct {
+	Match string `json:"match"`
+}
+
+func matchWindow(windowID int) kittyMatch {
+	return kittyMatch{Match: fmt.Sprintf("id:%d", windowID)}
+}
+
+func (kittyBackend) Name() string { return "kitty" }
+
+// kittyCLI picks the program that runs remote control commands: kitten,
+// the newer and faster entry point, when it is installed, or else kitty.
+func kittyCLI(lookPath func(string) (string, error)) string {
+	if _, err := lookPath("kitten"); err == nil {
+		return "kitten"
+	}
+	return "kitty"
+}
+
+// program returns the program that runs remote control commands.
+func (k kittyBackend) program() string {
+	if k.cli == "" {
+		return "kitty"
+	}
+	return k.cli
+}
+
+// command runs `<program> @ [--to socket] <command...>`.
+func (k kittyBackend) command(command ...string) *exec.Cmd {
+	return k.run.command(k.program(), k.args(command...)...)
+}
+
+// args builds `@ [--to socket] <command...>` arguments.
+func (k kittyBackend) args(command ...string) []string {
+	args := []string{"@"}
+	if k.socket != "" {
+		args = append(args, "--to", k.socket)
+	}
+	return append(args, command...)
+}
+
+func (k kittyBackend) List() ([]kittyOSWindow, error) {
+	var out []byte
+	var err error
+	if k.rc != nil {
+		var data string
+		data, err = k.rc.CallString("ls", nil)
+		if err != nil && k.follow && k.relocate(discoverKittySockets()) {
+			data, err = k.rc.CallString("ls", nil)
+		}
+		out = []byte(data)
+	} else {
+		out, err = k.command("ls").Output()
+	}
+
+	if debugLog != nil {
+		fmt.Fprintf(debugLog, "[%s] kitty ls socket=%q err=%v out_len=%d\n",
+			time.Now().Format("15:04:05"), k.socket, err, len(out))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("kitty @ ls: %w", err)
+	}
+	osWindows, err := kitty.ParseLayout(out)
+	if err != nil {
+		return nil, fmt.Errorf("json parse: %w", err)
+	}
+	return k.run.mark(osWindows), nil
+}
+
+// kittyPIDSocket matches a socket kitty named after its pid, as it does
+// with `listen_on unix:/tmp/kitty`.
+var kittyPIDSocket = regexp.MustCompile(`^unix:.*/kitty-[0-9]+$`)
+
+// relocate finds a restarted kitty once k's socket stops answering. The new
+// kitty has a new pid and so listens on a new socket; k moves to it when
+// exactly one of sockets answers, and otherwise keeps retrying its own.
+func (k kittyBackend) relocate(sockets []string) bool {
+	current := k.rc.Socket()
+	if unixSocketAnswers(current) {
+		return false
+	}
+	var live []string
+	for _, socket := range sockets {
+		if socket != current && kittyPIDSocket.MatchString(socket) && unixSocketAnswers(socket) {
+			live = append(live, socket)
+		}
+	}
+	if len(live) != 1 || k.rc.Retarget(live[0]) != nil {
+		return false
+	}
+	if debugLog != nil {
+		fmt.Fprintf(debugLog, "[%s] kitty moved from %s to %s\n", time.Now().Format("15:04:05"), current, live[0])
+	}
+	return true
+}
+
+func unixSocketAnswers(socket string) bool {
+	network, address, _ := strings.Cut(socket, ":")
+	if network != "unix" {
+		return false
+	}
+	conn, err := net.DialTimeout("unix", address, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func (k kittyBackend) CaptureText(windowID int, extent string) (string, error) {
+	if k.rc != nil {
+		if extent == "" {
+			extent = "screen"
+		}
+		return k.rc.CallString("get-text", struct {
+			kittyMatch
+			Extent string `json:"extent"`
+		}{matchWindow(windowID), extent})
+	}
+	command := []string{"get-text", "--match", fmt.Sprintf("id:%d", windowID)}
+	if extent != "" {
+		command = append(command, "--extent", extent)
+	}
+	out, err := k.command(command...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// CaptureWithCursor captures the screen with get-text --add-cursor.
+func (k kittyBackend) CaptureWithCursor(windowID int) (string, screenCursor, error) {
+	var text string
+	var err error
+	if k.rc != nil {
+		text, err = k.rc.CallString("get-text", struct {
+			kittyMatch
+			Extent    string `json:"extent"`
+			AddCursor bool   `json:"add_cursor"`
+		}{matchWindow(windowID), "screen", true})
+	} else {
+		var out []byte
+		out, err = k.command("get-text", "--match", fmt.Sprintf("id:%d", windowID), "--add-cursor").Output()
+		text = string(out)
+	}
+	if err != nil {
+		return "", screenCursor{}, err
+	}
+	text, cursor := splitCursor(text)
+	return text, cursor, nil
+}
+
+func (k kittyBackend) Focus(windowID int) error {
+	if k.rc != nil {
+		_, err := k.rc.Call("focus-window", matchWindow(windowID))
+		return err
+	}
+	return k.command("focus-window", "--match", fmt.Sprintf("id:%d", windowID)).Run()
+}
+
+func (k kittyBackend) Rename(windowID int, title string) error {
+	if k.rc != nil {
+		_, err := k.rc.Call("set-window-title", struct {
+			kittyMatch
+			Title string `json:"title"`
+		}{matchWindow(windowID), title})
+		return err
+	}
+	return k.command("set-window-title", "--match", fmt.Sprintf("id:%d", windowID), title).Run()
+}
+
+func (k kittyBackend) SendText(windowID int, text string) error {
+	if k.rc != nil {
+		_, err := k.rc.Call("send-text", struct {
+			kittyMatch
+			Data string `json:"data"`
+		}{matchWindow(windowID), "text:" + text})
+		return err
+	}
+	return k.command("send-text", "--match", fmt.Sprintf("id:%d", windowID), text).Run()
+}
+
+func (k kittyBackend) Scroll(windowID, lines int) error {
+	if k.rc != nil {
+		type scroll struct {
+			kittyMatch
+			Amount [2]any `json:"amount"`
+		}
+		if _, err := k.rc.Call("scroll-window", scroll{matchWindow(windowID), [2]any{"end", nil}}); err != nil {
+			return err
+		}
+		if lines == 0 {
+			return nil
+		}
+		_, err := k.rc.Call("scroll-window", scroll{matchWindow(windowID), [2]any{-lines, "l"}})
+		return err
+	}
+	match := fmt.Sprintf("id:%d", windowID)
+	if err := k.command("scroll-window", "--match", match, "end").Run(); err != nil {
+		return err
+	}
+	if lines == 0 {
+		return nil
+	}
+	return k.command("scroll-window", "--match", match, fmt.Sprintf("%dl-", lines)).Run()
+}
+
+// Launch opens an OS window running argv.
+func (k kittyBackend) Launch(title string, argv []string) error {
+	if k.rc != nil {
+		_, err := k.rc.Call("launch", struct {
+			Args  []string `json:"args"`
+			Type  string   `json:"type"`
+			Title string   `json:"window_title"`
+		}{argv, "os-window", title})
+		return err
+	}
+	return k.command(append([]string{"launch", "--type=os-window", "--title", title}, argv...)...).Run()
+}
+
+// resolveKittySocket picks the kitty socket path from flag, environment, or auto-detect
+func resolveKittySocket(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv("KITTY_LISTEN_ON"); env != "" {
+		return env
+	}
+	// Try to auto-detect socket path from KITTY_PID
+	if pid := os.Getenv("KITTY_PID"); pid != "" {
+		socketPath := fmt.Sprintf("/tmp/kitty-%s", pid)
+		if _, err := os.Stat(socketPath); err == nil {
+			return "unix:" + socketPath
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atani/lazyccg/pkg/kitty"
+)
+
+// serveKittyRC answers each request on one connection with reply(req).
+func serveKittyRC(t *testing.T, reply func(kitty.Request) kitty.Response) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kitty.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					raw, err := kitty.ReadMessage(r)
+					if err != nil {
+						return
+					}
+					var req kitty.Request
+					json.Unmarshal(raw, &req)
+					kitty.WriteMessage(conn, reply(req))
+				}
+			}()
+		}
+	}()
+	return "unix:" + path
+}
+
+func TestKittyRCList(t *testing.T) {
+	socket := serveKittyRC(t, func(req kitty.Request) kitty.Response {
+		if req.Cmd != "ls" {
+			return kitty.Response{Error: "unexpected " + req.Cmd}
+		}
+		data, _ := json.Marshal(`[{"tabs":[{"id":1,"title":"t","windows":[{"id":7,"title":"claude \\ x"}]}]}]`)
+		return kitty.Response{OK: true, Data: data}
+	})
+
+	k := newKittyBackend(socket)
+	if k.rc == nil {
+		t.Fatal("expected a native connection for a unix socket")
+	}
+	defer k.rc.Close()
+	osWindows, err := k.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got := osWindows[0].Tabs[0].Windows[0]; got.ID != 7 || got.Title != `claude \ x` {
+		t.Errorf("unexpected window %+v", got)
+	}
+}
+
+func TestKittyRCError(t *testing.T) {
+	socket := serveKittyRC(t, func(req kitty.Request) kitty.Response {
+		return kitty.Response{Error: "No matching windows"}
+	})
+	k := newKittyBackend(socket)
+	defer k.rc.Close()
+	if err := k.Focus(3); err == nil || err.Error() != "No matching windows" {
+		t.Errorf("Focus() error = %v, want kitty's error", err)
+	}
+}
+
+func TestKittyRemote(t *testing.T) {
+	if got := newKittyBackend("tcp:workstation:5000").Remote(); got != "workstation:5000" {
+		t.Errorf("Remote() = %q, want workstation:5000", got)
+	}
+	if got := newKittyBackend("unix:/tmp/kitty").Remote(); got != "" {
+		t.Errorf("Remote() = %q for a unix socket, want empty", got)
+	}
+}
+
+func TestKittyCLI(t *testing.T) {
+	found := func(names ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, n := range names {
+				if n == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		}
+	}
+	if got := kittyCLI(found("kitty", "kitten")); got != "kitten" {
+		t.Errorf("kittyCLI() = %q, want kitten", got)
+	}
+	if got := kittyCLI(found("kitty")); got != "kitty" {
+		t.Errorf("kittyCLI() without kitten = %q, want kitty", got)
+	}
+	k := kittyBackend{socket: "unix:/tmp/kitty", cli: "kitten"}
+	if got := k.command("ls").Args; strings.Join(got, " ") != "kitten @ --to unix:/tmp/kitty ls" {
+		t.Errorf("command() = %v", got)
+	}
+}
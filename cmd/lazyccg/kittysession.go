@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+)
+
+// `lazyccg export-session` writes a kitty session file that starts the
+// agents running now again, in the same OS windows, tabs, and directories,
+// with the same arguments:
+//
+//	lazyccg export-session -o ~/fleet.kitty
+//	kitty --session ~/fleet.kitty
+//
+// Only agent windows are kept; tabs without one are left out.
+
+// agentCommand returns the command line that starts ai the way it runs in
+// win: the agent's name followed by the arguments it was given. Agents run
+// by an interpreter, e.g. node …/@openai/codex/bin/codex, are started by
+// name as well; wrappers found by the config's match patterns are started
+// as they were.
+func agentCommand(win kittyWindow, ai string, prefixes []string) []string {
+	for _, proc := range win.ForegroundProcesses {
+		for i := range proc.Cmdline {
+			single := kittyWindow{ForegroundProcesses: []foregroundProcess{{Cmdline: proc.Cmdline[i : i+1]}}}
+			if name, ok := agentsession.DetectAI(single, prefixes); ok {
+				return append([]string{name}, proc.Cmdline[i+1:]...)
+			}
+		}
+	}
+	for _, proc := range win.ForegroundProcesses {
+		if _, ok := agents.match(kittyWindow{ForegroundProcesses: []foregroundProcess{proc}}); ok {
+			return proc.Cmdline
+		}
+	}
+	return []string{ai}
+}
+
+// kittySessionQuote joins args into a session file line, which kitty
+// splits like a POSIX shell.
+func kittySessionQuote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// kittySession returns a kitty session file starting the agent windows of
+// osWindows, with how many it starts and how many it left out: agents on
+// another host or in tmux can't be started by this kitty.
+func kittySession(osWindows []kittyOSWindow, prefixes []string, now time.Time) (text string, written, skipped int) {
+	var w strings.Builder
+	fmt.Fprintf(&w, "# Agents running at %s, written by lazyccg export-session.\n", now.Format("2006-01-02 15:04"))
+	fmt.Fprintln(&w, "# Start them again with: kitty --session <this file>")
+	firstOS := true
+	for _, ow := range osWindows {
+		firstTab := true
+		for _, tab := range ow.Tabs {
+			var lines []string
+			for _, win := range tab.Windows {
+				ai, ok := detectAgent(win, prefixes)
+				if !ok {
+					continue
+				}
+				if ow.Remote || win.Nested != "" {
+					skipped++
+					continue
+				}
+				if !cfg.IncludeSelf && isSelfWindow(win, selfPid) {
+					continue
+				}
+				launch := []string{"launch"}
+				if title := agentsession.SanitizeLine(win.Title); title != "" {
+					launch = append(launch, "--title", title)
+				}
+				if win.Cwd != "" {
+					launch = append(launch, "--cwd", win.Cwd)
+				}
+				command := agentCommand(win, profiles.agent(ai), prefixes)
+				lines = append(lines, kittySessionQuote(append(launch, command...)))
+				written++
+			}
+			if len(lines) == 0 {
+				continue
+			}
+			if firstTab && !firstOS {
+				fmt.Fprintln(&w, "\nnew_os_window")
+			}
+			firstTab, firstOS = false, false
+			fmt.Fprintln(&w)
+			fmt.Fprintln(&w, strings.TrimSpace("new_tab "+agentsession.SanitizeLine(tab.Title)))
+			if tab.Layout != "" {
+				fmt.Fprintln(&w, "layout "+tab.Layout)
+			}
+			for _, line := range lines {
+				fmt.Fprintln(&w, line)
+			}
+		}
+	}
+	return w.String(), written, skipped
+}
+
+// kittyOnly reports whether b reads kitty alone, with tmux inside it or
+// not: a session file can't start windows of other terminals.
+func kittyOnly(b Backend) bool {
+	switch b := b.(type) {
+	case kittyBackend:
+		return true
+	case nestedTmuxBackend:
+		return kittyOnly(b.Backend)
+	case multiBackend:
+		for _, inner := range b.instances {
+			if !kittyOnly(inner) {
+				return false
+			}
+		}
+		return len(b.instances) > 0
+	}
+	return false
+}
+
+func runExportSession(args []string, prefixes []string) error {
+	fs := flag.NewFlagSet("export-session", flag.ContinueOnError)
+	out := fs.String("o", "", "file to write (default: standard output)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !kittyOnly(backend) {
+		return fmt.Errorf("export-session writes kitty session files and needs the kitty backend, not %s (try -backend kitty)", backend.Name())
+	}
+	osWindows, err := listWindows()
+	if err != nil {
+		return err
+	}
+	text, written, skipped := kittySession(osWindows, prefixes, time.Now())
+	if written == 0 {
+		return errors.New("no agents running to export")
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "left out %d agent(s) on another host or in tmux\n", skipped)
+	}
+	if *out == "" {
+		fmt.Print(text)
+		return nil
+	}
+	if err := os.WriteFile(expandHome(*out), []byte(text), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d agent(s) to %s; start them with: kitty --session %s\n", written, *out, shellQuote(*out))
+	return nil
+}
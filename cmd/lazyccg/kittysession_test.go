@@ -0,0 +1,90 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAgentCommand(t *testing.T) {
+	prefixes := []string{"claude", "codex"}
+	tests := []struct {
+		cmdline []string
+		want    []string
+	}{
+		{[]string{"/usr/local/bin/claude", "--model", "opus"}, []string{"claude", "--model", "opus"}},
+		{[]string{"node", "/opt/lib/node_modules/@openai/codex/bin/codex", "--full-auto"}, []string{"codex", "--full-auto"}},
+		{nil, []string{"claude"}}, // command line unknown
+	}
+	for _, tt := range tests {
+		win := kittyWindow{ForegroundProcesses: []foregroundProcess{{Cmdline: tt.cmdline}}}
+		if got := agentCommand(win, "claude", prefixes); !slices.Equal(got, tt.want) {
+			t.Errorf("agentCommand(%q) = %q, want %q", tt.cmdline, got, tt.want)
+		}
+	}
+}
+
+func TestKittySession(t *testing.T) {
+	agent := func(id int, title, cwd string, cmdline ...string) kittyWindow {
+		return kittyWindow{ID: id, Title: title, Cwd: cwd, ForegroundProcesses: []foregroundProcess{{Pid: 1000 + id, Cmdline: cmdline}}}
+	}
+	osWindows := []kittyOSWindow{
+		{Tabs: []kittyTab{
+			{Title: "api", Layout: "tall", Windows: []kittyWindow{
+				agent(1, "claude api", "/src/api", "claude", "--resume"),
+				agent(2, "shell", "/src/api", "zsh"),
+			}},
+			{Title: "notes", Windows: []kittyWindow{agent(3, "vim", "/notes", "vim")}},
+		}},
+		{Tabs: []kittyTab{
+			{Title: "web", Windows: []kittyWindow{
+				agent(4, "it's codex", "/src/my web", "codex"),
+				{ID: 5, Nested: "work", ForegroundProcesses: []foregroundProcess{{Cmdline: []string{"claude"}}}},
+			}},
+		}},
+		{Remote: true, Tabs: []kittyTab{{Windows: []kittyWindow{agent(6, "remote", "/home", "claude")}}}},
+	}
+	text, written, skipped := kittySession(osWindows, []string{"claude", "codex"}, time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC))
+	if written != 2 || skipped != 2 {
+		t.Errorf("kittySession() wrote %d and left out %d, want 2 and 2", written, skipped)
+	}
+	want := `# Agents running at 2026-10-16 09:30, written by lazyccg export-session.
+# Start them again with: kitty --session <this file>
+
+new_tab api
+layout tall
+launch --title 'claude api' --cwd /src/api claude --resume
+
+new_os_window
+
+new_tab web
+launch --title 'it'\''s codex' --cwd '/src/my web' codex
+`
+	if text != want {
+		t.Errorf("kittySession() =\n%s\nwant\n%s", text, want)
+	}
+	if strings.Contains(text, "notes") {
+		t.Error("kittySession() kept a tab without agents")
+	}
+}
+
+func TestKittyOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Backend
+		want bool
+	}{
+		{"kitty", newKittyBackend(""), true},
+		{"kitty with tmux inside", withNestedTmux(newKittyBackend("")), true},
+		{"several kittys", newMultiKittyBackend([]string{"unix:/tmp/kitty-1", "unix:/tmp/kitty-2"}), true},
+		{"kitty and wezterm", multiBackend{instances: []Backend{newKittyBackend(""), weztermBackend{}}}, false},
+		{"tmux", tmuxBackend{}, false},
+		{"wezterm with tmux inside", withNestedTmux(weztermBackend{}), false},
+	}
+	for _, tt := range tests {
+		if got := kittyOnly(tt.b); got != tt.want {
+			t.Errorf("kittyOnly(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
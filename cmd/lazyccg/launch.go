@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Agents started by lazyccg (`lazyccg launch`, or D on a session) get the
+// environment they'd have if started by hand in their directory: the
+// directory's direnv and mise environments are loaded first, then the
+// variables from the config and the agent's profile are set on top.
+
+// launchConfig configures launched agents.
+type launchConfig struct {
+	// Env is set for every launched agent.
+	Env map[string]string `yaml:"env"`
+	// Activate lists the tools that load the directory's environment:
+	// direnv and mise. Unset means both, when installed; [] means none.
+	Activate []string `yaml:"activate"`
+	// Agent is the agent the directory picker (O) starts with; by default
+	// the selected session's, or claude.
+	Agent string `yaml:"agent"`
+	// Projects are directories whose subdirectories the picker offers, on
+	// top of zoxide's, e.g. [~/src].
+	Projects []string `yaml:"projects"`
+}
+
+// activations are the shell snippets that load a directory's environment,
+// skipped when the tool isn't installed.
+var activations = map[string]string{
+	"direnv": `command -v direnv >/dev/null && eval "$(direnv export bash 2>/dev/null)"`,
+	"mise":   `command -v mise >/dev/null && eval "$(mise env -s bash 2>/dev/null)"`,
+}
+
+var defaultActivations = []string{"direnv", "mise"}
+
+func (c launchConfig) validate() error {
+	for _, tool := range c.Activate {
+		if _, ok := activations[tool]; !ok {
+			return fmt.Errorf("launch.activate: unknown tool %q (direnv or mise)", tool)
+		}
+	}
+	return nil
+}
+
+// launchSpec is one agent to start.
+type launchSpec struct {
+	Agent   string
+	Dir     string
+	Command []string          // defaults to the agent name
+	Env     map[string]string // on top of the config's and profile's
+}
+
+// argv returns the command that starts the agent in its directory with its
+// environment. bash runs the activation snippets, since direnv and mise
+// print bash; `env` sets the variables after them so they win.
+func (spec launchSpec) argv(c launchConfig, p *profile) []string {
+	command := spec.Command
+	if len(command) == 0 && p != nil {
+		command = p.Command
+	}
+	if len(command) == 0 {
+		command = []string{spec.Agent}
+	}
+	env := make(map[string]string)
+	for _, layer := range []map[string]string{c.Env, profileEnv(p), spec.Env} {
+		for k, v := range layer {
+			env[k] = v
+		}
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	activate := c.Activate
+	if activate == nil {
+		activate = defaultActivations
+	}
+	script := []string{`cd -- "$1" || exit`, "shift"}
+	for _, tool := range activate {
+		script = append(script, activations[tool])
+	}
+	script = append(script, `exec "$@"`)
+
+	argv := []string{"bash", "-c", strings.Join(script, "; "), "lazyccg-launch", spec.Dir}
+	if len(keys) > 0 {
+		argv = append(argv, "env")
+		for _, k := range keys {
+			argv = append(argv, k+"="+env[k])
+		}
+	}
+	return append(argv, command...)
+}
+
+func profileEnv(p *profile) map[string]string {
+	if p == nil {
+		return nil
+	}
+	return p.Env
+}
+
+// launchTitle names the new window, e.g. "claude api".
+func launchTitle(spec launchSpec) string {
+	return spec.Agent + " " + filepath.Base(spec.Dir)
+}
+
+// launch starts spec in a new window of the terminal l belongs to.
+func launch(l windowLauncher, spec launchSpec) error {
+	return l.Launch(launchTitle(spec), spec.argv(cfg.Launch, profiles.byName[spec.Agent]))
+}
+
+// defaultLauncher returns the launcher of the first terminal b watches that
+// can open windows.
+func defaultLauncher(b Backend) (windowLauncher, bool) {
+	switch b := b.(type) {
+	case nestedTmuxBackend:
+		return defaultLauncher(b.Backend)
+	case multiBackend:
+		for _, inner := range b.instances {
+			if l, ok := defaultLauncher(inner); ok {
+				return l, true
+			}
+		}
+	case windowLauncher:
+		return b, true
+	}
+	return nil, false
+}
+
+type launchedMsg struct {
+	title string
+	err   error
+}
+
+// launchHereCmd starts another s.AI in s's directory, next to s.
+func launchHereCmd(s session) tea.Cmd {
+	return func() tea.Msg {
+		if s.Cwd == "" {
+			return launchedMsg{err: errors.New("the session's directory is unknown")}
+		}
+		l, ok := launcherFor(backend, s.WindowID, false)
+		if !ok {
+			return launchedMsg{err: fmt.Errorf("%s can't open windows", backend.Name())}
+		}
+		spec := launchSpec{Agent: s.AI, Dir: s.Cwd}
+		return launchedMsg{title: launchTitle(spec), err: launch(l, spec)}
+	}
+}
+
+// envFlags collects repeated -env KEY=VALUE flags.
+type envFlags map[string]string
+
+func (e envFlags) String() string { return "" }
+
+func (e envFlags) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("want KEY=VALUE, got %q", s)
+	}
+	e[k] = v
+	return nil
+}
+
+// runLaunch implements `lazyccg launch`.
+func runLaunch(args []string) error {
+	fs := flag.NewFlagSet("launch", flag.ContinueOnError)
+	env := envFlags{}
+	fs.Var(env, "env", "set an environment variable, KEY=VALUE (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return errors.New("usage: lazyccg launch [-env KEY=VALUE]... <agent> [dir]")
+	}
+	dir := "."
+	if fs.NArg() == 2 {
+		dir = fs.Arg(1)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	l, ok := defaultLauncher(backend)
+	if !ok {
+		return fmt.Errorf("%s can't open windows", backend.Name())
+	}
+	spec := launchSpec{Agent: profiles.agent(fs.Arg(0)), Dir: dir, Env: env}
+	if err := launch(l, spec); err != nil {
+		return err
+	}
+	fmt.Println("launched", launchTitle(spec))
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLaunchArgv(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	// A direnv that exports one variable, and no mise
+	bin := t.TempDir()
+	direnv := "#!/bin/sh\necho 'export FROM_DIRENV=yes; export LAYER=direnv'\n"
+	if err := os.WriteFile(filepath.Join(bin, "direnv"), []byte(direnv), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	spec := launchSpec{
+		Agent:   "claude",
+		Dir:     dir,
+		Command: []string{"sh", "-c", `echo "$PWD $FROM_DIRENV $LAYER $TOKEN"`},
+		Env:     map[string]string{"LAYER": "flag"},
+	}
+	c := launchConfig{Env: map[string]string{"LAYER": "config", "TOKEN": "t"}}
+	p := &profile{Name: "claude", Env: map[string]string{"LAYER": "profile"}}
+
+	tests := []struct {
+		name string
+		c    launchConfig
+		p    *profile
+		spec launchSpec
+		want string
+	}{
+		{"layers", c, p, spec, dir + " yes flag t"},
+		{"profile over config", c, p, launchSpec{Agent: "claude", Dir: dir, Command: spec.Command}, dir + " yes profile t"},
+		{"no activation", launchConfig{Activate: []string{}}, nil, spec, dir + "  flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argv := tt.spec.argv(tt.c, tt.p)
+			out, err := exec.Command(argv[0], argv[1:]...).Output()
+			if err != nil {
+				t.Fatalf("%q: %v", argv, err)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("launched agent saw %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	argv := launchSpec{Agent: "aider", Dir: dir}.argv(launchConfig{}, &profile{Command: []string{"aider", "--no-git"}})
+	if got := strings.Join(argv[len(argv)-2:], " "); got != "aider --no-git" {
+		t.Errorf("command = %q, want the profile's", got)
+	}
+}
+
+func TestLaunchConfigValidate(t *testing.T) {
+	if err := (launchConfig{Activate: []string{"direnv", "mise"}}).validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (launchConfig{Activate: []string{"asdf"}}).validate(); err == nil {
+		t.Error("validate() should reject an unknown tool")
+	}
+	env := envFlags{}
+	if err := env.Set("A=b=c"); err != nil || env["A"] != "b=c" {
+		t.Errorf("Set(A=b=c) = %v, %v", env, err)
+	}
+	if err := env.Set("=x"); err == nil {
+		t.Error("Set(=x) should fail")
+	}
+}
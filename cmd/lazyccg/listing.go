@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// `lazyccg list` and `lazyccg status` print the sessions and the count of
+// each status once, for scripts and shell prompts. -format picks how:
+//
+//	table  aligned columns, for people (default)
+//	tsv    tab-separated with a header, for fish, awk, and cut
+//	json   an array of objects
+//	nuon   a nushell table: lazyccg list -format nuon | from nuon
+var outputFormats = []string{"table", "tsv", "json", "nuon"}
+
+// records is rows of values under named columns. Values are strings, ints,
+// or float64s.
+type records struct {
+	Columns []string
+	Rows    [][]any
+}
+
+func (r records) write(w io.Writer, format string) error {
+	switch format {
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(r.Columns, "\t")))
+		for _, row := range r.Rows {
+			fmt.Fprintln(tw, strings.Join(r.cells(row, plainCell), "\t"))
+		}
+		return tw.Flush()
+	case "tsv":
+		fmt.Fprintln(w, strings.Join(r.Columns, "\t"))
+		for _, row := range r.Rows {
+			fmt.Fprintln(w, strings.Join(r.cells(row, plainCell), "\t"))
+		}
+		return nil
+	case "json":
+		objects := make([]map[string]any, len(r.Rows))
+		for i, row := range r.Rows {
+			objects[i] = make(map[string]any, len(row))
+			for j, v := range row {
+				objects[i][r.Columns[j]] = v
+			}
+		}
+		data, err := json.MarshalIndent(objects, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "nuon":
+		// [[col, col]; [value, value], ...]; strings quoted as in JSON
+		if len(r.Rows) == 0 {
+			_, err := fmt.Fprintln(w, "[]")
+			return err
+		}
+		rows := make([]string, len(r.Rows))
+		for i, row := range r.Rows {
+			rows[i] = "[" + strings.Join(r.cells(row, nuonCell), ", ") + "]"
+		}
+		header := make([]string, len(r.Columns))
+		for i, c := range r.Columns {
+			header[i] = nuonCell(c)
+		}
+		_, err := fmt.Fprintf(w, "[[%s]; %s]\n", strings.Join(header, ", "), strings.Join(rows, ", "))
+		return err
+	}
+	return fmt.Errorf("unknown format %q (%s)", format, strings.Join(outputFormats, ", "))
+}
+
+func (r records) cells(row []any, cell func(any) string) []string {
+	out := make([]string, len(row))
+	for i, v := range row {
+		out[i] = cell(v)
+	}
+	return out
+}
+
+// plainCell writes v on one line without tabs, for table and tsv.
+func plainCell(v any) string {
+	s := fmt.Sprint(v)
+	if f, ok := v.(float64); ok {
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// nuonCell writes v as a nuon value.
+func nuonCell(v any) string {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, _ := json.Marshal(fmt.Sprint(v))
+	return string(data)
+}
+
+// sessionRecords is the sessions as rows, one per session.
+func sessionRecords(sessions []session) records {
+	r := records{Columns: []string{"window", "tab", "ai", "status", "title", "project", "cwd", "source", "confidence", "instance", "tokens", "action", "intent"}}
+	for _, s := range sessions {
+		r.Rows = append(r.Rows, []any{
+			s.WindowID, s.TabID, s.AI, s.Status.String(), s.Title, s.Project, s.Cwd,
+			string(s.StatusSource), s.Confidence, s.Instance, s.Tokens, s.LastAction, s.Intent,
+		})
+	}
+	return r
+}
+
+// statusRecords counts the sessions in each status, in the Status panel's
+// order.
+func statusRecords(sessions []session) records {
+	counts := make(map[string]int)
+	for _, s := range sessions {
+		counts[s.Status.String()]++
+	}
+	r := records{Columns: []string{"status", "count"}}
+	for _, status := range (model{sessions: sessions}).availableStatuses() {
+		r.Rows = append(r.Rows, []any{status.String(), counts[status.String()]})
+	}
+	return r
+}
+
+// runListing implements `lazyccg list` and `lazyccg status`.
+func runListing(name string, args []string, prefixes []string, maxLines int) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	format := fs.String("format", "table", "output format: "+strings.Join(outputFormats, ", "))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sessions, _, err := loadSessions(prefixes, maxLines, newPollState())
+	if err != nil {
+		return err
+	}
+	r := sessionRecords(sessions)
+	if name == "status" {
+		r = statusRecords(sessions)
+	}
+	return r.write(os.Stdout, *format)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestRecordsWrite(t *testing.T) {
+	sessions := []session{
+		{WindowID: 3, TabID: 1, AI: "claude", Status: agentstatus.Running, Title: "api\tserver", Cwd: "/src/api", StatusSource: agentstatus.SourceActivity, Confidence: 0.8},
+		{WindowID: 5, TabID: 2, AI: "codex", Status: agentstatus.NeedsApproval, Title: `say "hi"`, Cwd: "/src/web", Tokens: 1200, Intent: "feature"},
+		{WindowID: 7, TabID: 2, AI: "codex", Status: agentstatus.Running, Title: "web"},
+	}
+
+	tests := []struct {
+		format string
+		r      records
+		want   string
+	}{
+		{"tsv", statusRecords(sessions), "status\tcount\nRUNNING\t2\nNEEDS_APPROVAL\t1\n"},
+		{"nuon", statusRecords(sessions), `[["status", "count"]; ["RUNNING", 2], ["NEEDS_APPROVAL", 1]]` + "\n"},
+		{"nuon", statusRecords(nil), "[]\n"},
+		{"table", statusRecords(sessions), "STATUS          COUNT\nRUNNING         2\nNEEDS_APPROVAL  1\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := tt.r.write(&b, tt.format); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s:\n%s\nwant\n%s", tt.format, b.String(), tt.want)
+		}
+	}
+
+	var b bytes.Buffer
+	sessionRecords(sessions).write(&b, "tsv")
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "3\t1\tclaude\tRUNNING\tapi server\t") {
+		t.Errorf("tsv keeps one row per line and no tabs in values, got:\n%s", b.String())
+	}
+
+	b.Reset()
+	sessionRecords(sessions).write(&b, "nuon")
+	if !strings.Contains(b.String(), `[5, 2, "codex", "NEEDS_APPROVAL", "say \"hi\"", "", "/src/web", "", 0, "", 1200, "", "feature"]`) {
+		t.Errorf("nuon row not written as a list of values:\n%s", b.String())
+	}
+
+	b.Reset()
+	sessionRecords(sessions).write(&b, "json")
+	var objects []map[string]any
+	if err := json.Unmarshal(b.Bytes(), &objects); err != nil || len(objects) != 3 || objects[0]["confidence"] != 0.8 {
+		t.Errorf("json = %s (%v)", b.String(), err)
+	}
+
+	if err := statusRecords(sessions).write(&b, "yaml"); err == nil {
+		t.Error("write() took an unknown format")
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+// l locks the selected session against the dashboard actions that change
+// its window, for agents where a stray key would do harm: y can't answer
+// its prompts, nor r rename it. A locked session shows 🔒 in the Sessions
+// panel until l unlocks it or its window closes. Locks are saved with the
+// view, so they last across restarts.
+
+// lockIcon marks a locked session.
+const lockIcon = "🔒"
+
+// toggleLock locks or unlocks s.
+func (m *model) toggleLock(s session) {
+	if m.view.locked(s.WindowID) {
+		m.view.setLocked(s.WindowID, false)
+		m.notice = "unlocked " + s.Title
+		return
+	}
+	m.view.setLocked(s.WindowID, true)
+	m.notice = lockIcon + " locked " + s.Title
+}
+
+// guarded reports whether s is locked, telling the user so.
+func (m *model) guarded(s session, action string) bool {
+	if !m.view.locked(s.WindowID) {
+		return false
+	}
+	m.notice = lockIcon + " " + s.Title + " is locked; l unlocks it to " + action
+	return true
+}
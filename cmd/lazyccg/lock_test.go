@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestLockGuardsSession(t *testing.T) {
+	m := model{pollEvery: time.Second, poll: newPollState(), unread: make(map[int]int), renamed: make(map[int]bool), scroll: make(map[int]scrollState)}
+	m = driveModel(m, tea.WindowSizeMsg{Width: 100, Height: 24}, sessionsMsg{sessions: snapshotSessions()}, key("down"), key("l"))
+	if !m.view.locked(12) {
+		t.Fatalf("l didn't lock the selected session: %v", m.view.Locked)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "WAITING "+lockIcon) {
+		t.Error("the locked session has no lock in the Sessions panel")
+	}
+
+	m = driveModel(m, key("y"))
+	if m.approval != nil || !strings.Contains(m.notice, "is locked") {
+		t.Errorf("y on a locked session: approval %v, notice %q; want it refused", m.approval, m.notice)
+	}
+	m = driveModel(m, key("r"))
+	if m.renaming {
+		t.Error("r renames a locked session")
+	}
+
+	m = driveModel(m, key("l"), key("y"))
+	if m.view.locked(12) || m.approval == nil {
+		t.Errorf("after unlocking, locked %v and approval %v; want y to answer again", m.view.Locked, m.approval)
+	}
+}
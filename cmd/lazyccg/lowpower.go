@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Low-power mode polls lowPowerPollFactor times less often, stops speeding
+// up captures of streaming sessions, and caps the frame rate. Mode "auto"
+// turns it on while running on battery.
+
+const (
+	lowPowerPollFactor = 4
+	lowPowerFPS        = 10
+	powerCheckInterval = time.Minute
+)
+
+// parseLowPowerMode validates a -low-power / low_power value.
+func parseLowPowerMode(s string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(s)); mode {
+	case "", "off":
+		return "off", nil
+	case "on", "auto":
+		return mode, nil
+	}
+	return "", fmt.Errorf("low power mode %q: want on, off or auto", s)
+}
+
+// pollInterval is how often sessions are polled, stretched in low-power
+// mode.
+func (m model) pollInterval() time.Duration {
+	if m.lowPower {
+		return m.pollEvery * lowPowerPollFactor
+	}
+	return m.pollEvery
+}
+
+// onBattery reports whether the machine is running on battery. Unknown
+// power sources (desktops, errors) count as mains.
+func onBattery() bool {
+	if out, err := exec.Command("pmset", "-g", "batt").Output(); err == nil {
+		return strings.Contains(string(out), "'Battery Power'")
+	}
+	return sysfsOnBattery("/sys/class/power_supply")
+}
+
+// sysfsOnBattery reads Linux power supplies: on battery when no mains
+// adapter is online and a battery is discharging.
+func sysfsOnBattery(dir string) bool {
+	supplies, _ := filepath.Glob(filepath.Join(dir, "*"))
+	discharging := false
+	for _, supply := range supplies {
+		kind := readSysfs(filepath.Join(supply, "type"))
+		switch kind {
+		case "Mains", "USB":
+			if readSysfs(filepath.Join(supply, "online")) == "1" {
+				return false
+			}
+		case "Battery":
+			if readSysfs(filepath.Join(supply, "status")) == "Discharging" {
+				discharging = true
+			}
+		}
+	}
+	return discharging
+}
+
+func readSysfs(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+type powerMsg struct {
+	onBattery bool
+}
+
+// powerCmd checks the power source after delay.
+func powerCmd(delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return powerMsg{onBattery: onBattery()}
+	})
+}
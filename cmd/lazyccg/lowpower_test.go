@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSysfsOnBattery(t *testing.T) {
+	supply := func(dir, name string, files map[string]string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for file, value := range files {
+			if err := os.WriteFile(filepath.Join(path, file), []byte(value+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	unplugged := t.TempDir()
+	supply(unplugged, "AC", map[string]string{"type": "Mains", "online": "0"})
+	supply(unplugged, "BAT0", map[string]string{"type": "Battery", "status": "Discharging"})
+	if !sysfsOnBattery(unplugged) {
+		t.Error("unplugged laptop should be on battery")
+	}
+
+	plugged := t.TempDir()
+	supply(plugged, "AC", map[string]string{"type": "Mains", "online": "1"})
+	supply(plugged, "BAT0", map[string]string{"type": "Battery", "status": "Charging"})
+	if sysfsOnBattery(plugged) {
+		t.Error("plugged-in laptop should not be on battery")
+	}
+
+	if sysfsOnBattery(t.TempDir()) {
+		t.Error("desktop without power supplies should not be on battery")
+	}
+}
+
+func TestLowPowerMode(t *testing.T) {
+	for in, want := range map[string]string{"": "off", "ON": "on", " auto ": "auto"} {
+		if got, err := parseLowPowerMode(in); err != nil || got != want {
+			t.Errorf("parseLowPowerMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := parseLowPowerMode("sometimes"); err == nil {
+		t.Error("unknown mode should fail")
+	}
+
+	m := model{pollEvery: time.Second}
+	if m.pollInterval() != time.Second {
+		t.Errorf("pollInterval() = %s", m.pollInterval())
+	}
+	m.lowPower = true
+	if m.pollInterval() != lowPowerPollFactor*time.Second {
+		t.Errorf("low-power pollInterval() = %s", m.pollInterval())
+	}
+}
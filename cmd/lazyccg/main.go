@@ -1,96 +1,140 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/atani/lazyccg/internal/ui"
+	agentsession "github.com/atani/lazyccg/pkg/session"
+	agentstatus "github.com/atani/lazyccg/pkg/status"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	cyan     = lipgloss.Color("86")
-	darkCyan = lipgloss.Color("30")
-	white    = lipgloss.Color("255")
-	gray     = lipgloss.Color("240")
-	green    = lipgloss.Color("78")
-	yellow   = lipgloss.Color("220")
-
-	titleStyle    = lipgloss.NewStyle().Foreground(cyan).Bold(true)
-	selectedStyle = lipgloss.NewStyle().Background(darkCyan).Foreground(white)
-
-	statusRunning = lipgloss.NewStyle().Foreground(green)
-	statusIdle    = lipgloss.NewStyle().Foreground(gray)
-	statusWaiting = lipgloss.NewStyle().Foreground(yellow)
-	statusDone    = lipgloss.NewStyle().Foreground(cyan)
-
-	helpKeyStyle  = lipgloss.NewStyle().Foreground(cyan)
-	helpDescStyle = lipgloss.NewStyle().Foreground(gray)
-)
-
 type session struct {
-	TabID      int
-	WindowID   int
-	Title      string
-	AI         string
-	Status     string
-	Lines      []string
-	Updated    time.Time
-	Cwd        string
-	OutputHash string // hash of output to detect changes
+	TabID       int
+	WindowID    int
+	Title       string
+	AI          string
+	Status      agentstatus.Status
+	Lines       []string
+	Updated     time.Time
+	Cwd         string
+	OutputHash  string // hash of output to detect changes
+	Prompt      string // user prompt the session was given, if known
+	Intent      string // what the first prompt asked for, e.g. "bugfix"; see classifyIntent
+	Task        string // what it is working on: its spinner's task or last prompt
+	DuplicateOf int    // window ID of another session given the same prompt
+	ExitHint    string // e.g. "codex exited (0)" once the agent has exited
+	ExitCode    int    // the exited agent's exit code, when ExitKnown
+	ExitKnown   bool
+	Child       string     // the command a RUNNING agent waits on, e.g. "pytest"
+	ChildSince  time.Time  // when it started
+	Busy        busyTime   // time spent RUNNING, thinking or in commands
+	ErrorLine   string     // what went wrong, when Status is ERROR or the agent failed
+	LastActive  time.Time  // when the output last changed
+	Tools       toolStats  // tool calls seen since lazyccg started watching
+	Edited      []string   // paths named by edit tool calls, in order seen
+	Menu        *agentMenu // numbered menu the agent is showing, if any
+	Instance    string     // kitty instance, when watching several
+	Parent      int        // window running the tmux this session is in
+	Nested      string     // that tmux session
+	Project     string     // git repository or directory name, for grouping
+	Tokens      int        // tokens used, from the agent's session log
+	LastAction  string     // last tool call, from the agent's session log
+
+	ContextLeft  int  // percent of the context window left, from the agent's footer
+	ContextKnown bool // whether the footer showed ContextLeft
+
+	Limit usageLimit // when the agent's usage limit resets, if it hit one
+
+	StatusSince  time.Time          // when the session entered Status
+	StatusSource agentstatus.Source // how Status was determined
+	Confidence   float64            // how sure Status is, 0 to 1
+	StatusWhy    string             // what gave Status, e.g. the rule and line that matched
 }
 
 type model struct {
-	width          int
-	height         int
-	selected       int
-	sessions       []session
-	err            error
-	pollEvery      time.Duration
-	prefixes       []string
-	maxLines       int
-	lastUpdate     time.Time
-	renaming       bool
-	renameInput    []rune
-	focusedPanel   int    // 0=Sessions, 1=Status
-	statusFilter   string // "" = no filter
-	statusSelected int
-	prevHashes   map[int]string // windowID -> previous output hash
-	stableCount  map[int]int    // windowID -> consecutive unchanged polls
+	width             int
+	height            int
+	selected          int
+	sessions          []session
+	err               error
+	pollEvery         time.Duration
+	prefixes          []string
+	maxLines          int
+	lastUpdate        time.Time
+	renaming          bool
+	renameInput       []rune
+	focusedPanel      int                // 0=Sessions, 1=Status, 2=Detail
+	statusFilter      agentstatus.Status // "" = no filter
+	groupFilter       string             // group the Sessions panel is narrowed to; "" = all
+	statusSelected    int
+	poll              pollState   // carried between polls for status detection
+	notice            string      // transient message shown in the help bar
+	showPreview       bool        // show last output line under each session
+	unread            map[int]int // windowID -> unacknowledged status changes
+	searching         bool
+	searchInput       []rune
+	search            *outputSearch       // last output search, if any
+	failures          int                 // consecutive failed polls
+	nextRetry         time.Time           // no polling before this while failing
+	renamed           map[int]bool        // windows renamed by hand, skipped by title sync
+	scroll            map[int]scrollState // windowID -> Output viewport when scrolled up
+	follow            bool                // always snap the Output panel to the bottom
+	toolsOnly         bool                // Output panel shows only tool calls
+	splitThinking     bool                // Output panel shows reasoning in its own pane
+	showDetail        bool                // Detail panel replaces the Output panel
+	limits            string              // what the backend can't do
+	powerMode         string              // low-power mode: on, off, or auto
+	lowPower          bool                // low-power mode is in effect
+	detailFiles       []touchedFile       // files touched by the session in the Detail panel
+	detailWindow      int                 // window detailFiles belong to
+	fileSelected      int
+	conflicts         []fileConflict // files edited by more than one session
+	conflictsOpen     bool           // conflict screen is shown
+	conflictScroll    int
+	approval          *approvalPrompt  // open approval popup
+	snoozed           map[int]snooze   // windowID -> snooze; hidden until it expires
+	woken             map[int]bool     // snoozes that expired, highlighted until acknowledged
+	scrollback        map[int][]string // windowID -> whole scrollback shown instead of polled output
+	remote            string           // remote terminal address, shown as a badge
+	refreshing        bool             // a poll is in flight
+	statsOpen         bool             // internal stats screen is shown
+	statsScroll       int
+	inspectOpen       bool // inspect screen is shown
+	inspectWindow     int  // window of the session inspected
+	inspectScroll     int
+	snoozePicking     bool
+	snoozeChoice      int
+	archiveOpen       bool // archive search screen is shown
+	archiveTyping     bool
+	archiveInput      []rune
+	archiveQuery      string
+	archiveResults    []archiveResult
+	archiveScroll     int
+	archiveSpan       *timeSpan // the results are what was recorded then
+	archiveSpanTyping bool
+	waiting           *waitTracker // today's WAITING total
+	view              viewState    // grouping, kept across restarts
+	picker            *dirPicker   // open directory picker for launching
+	selectQuery       string       // from -focus-tui; selects its session once listed
+	marqueeWindow     int          // window whose title is scrolling
+	marqueeStep       int
 }
 
 type tickMsg time.Time
 
-type kittyOSWindow struct {
-	Tabs []kittyTab `json:"tabs"`
-}
-
-type kittyTab struct {
-	ID      int           `json:"id"`
-	Title   string        `json:"title"`
-	Windows []kittyWindow `json:"windows"`
-}
-
-type kittyWindow struct {
-	ID                  int                 `json:"id"`
-	Title               string              `json:"title"`
-	Cwd                 string              `json:"cwd"`
-	ForegroundProcesses []foregroundProcess `json:"foreground_processes"`
-}
-
-type foregroundProcess struct {
-	Pid     int      `json:"pid"`
-	Cwd     string   `json:"cwd"`
-	Cmdline []string `json:"cmdline"`
-}
-
 var debugMode bool
 
 // Version information (set by goreleaser ldflags)
@@ -102,12 +146,22 @@ var (
 
 func main() {
 	pollEvery := flag.Duration("poll", 1*time.Second, "poll interval")
-	prefixes := flag.String("prefixes", "codex,claude,gemini", "comma-separated process names to detect")
+	lowPowerFlag := flag.String("low-power", "", "poll less and render less: on, off, or auto (on battery); overrides low_power in the config")
+	prefixes := flag.String("prefixes", "", "comma-separated process names to detect (default: every known agent)")
 	maxLines := flag.Int("max-lines", 200, "max lines to keep per session")
 	debug := flag.Bool("debug", false, "dump debug info and exit")
 	noAltScreen := flag.Bool("no-alt-screen", false, "run without alt screen (for debugging)")
-	kittySocket := flag.String("kitty-socket", "", "kitty socket path (e.g., unix:/tmp/mykitty)")
+	kittySocket := flag.String("kitty-socket", "", "kitty socket path (e.g., unix:/tmp/mykitty); comma-separated or \"auto\" to watch several instances")
+	backendFlag := flag.String("backend", "auto", "terminal to read sessions from: auto (detect), kitty, tmux, wezterm, iterm2, screen, docker, vscode, ps (process list only), or mock (see -mock-file)")
+	flag.StringVar(&mockFile, "mock-file", "", "fixture of windows and scripted output for -backend mock")
+	sshHosts := flag.String("ssh", "", "comma-separated hosts to read sessions from over ssh (\"local\" is this machine)")
 	showVersion := flag.Bool("version", false, "show version information")
+	configPath := flag.String("config", defaultConfigPath(), "config file path")
+	syncTitles := flag.Bool("sync-titles", false, "keep kitty window titles set to computed session names")
+	recordHistoryFlag := flag.Bool("history", false, "record session transcripts for `lazyccg search`")
+	cleanup := flag.Bool("cleanup", false, "remove mirror files and ssh sockets left by lazyccg runs that crashed, and exit")
+	focusQuery := flag.String("focus", "", "focus the session whose title, repository, or directory matches, and exit")
+	focusTUI := flag.Bool("focus-tui", false, "with -focus, open the dashboard with the session selected instead")
 	flag.Parse()
 
 	if *showVersion {
@@ -115,30 +169,97 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *cleanup {
+		if err := runCleanup(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	debugMode = *debug
 
-	// Set kitty socket path from flag, environment, or auto-detect
-	kittySocketPath = *kittySocket
-	if kittySocketPath == "" {
-		kittySocketPath = os.Getenv("KITTY_LISTEN_ON")
+	// `lazyccg demo` runs the dashboard; other commands run and exit
+	demo := flag.Arg(0) == "demo"
+
+	var err error
+	if demo {
+		backend, err = newDemoBackend()
+	} else if hosts := parseHosts(*sshHosts); len(hosts) > 0 {
+		backend, err = newSSHBackends(hosts, *backendFlag, *kittySocket)
+	} else {
+		backend, err = localBackend(*backendFlag, *kittySocket)
 	}
-	if kittySocketPath == "" {
-		// Try to auto-detect socket path from KITTY_PID
-		if pid := os.Getenv("KITTY_PID"); pid != "" {
-			socketPath := fmt.Sprintf("/tmp/kitty-%s", pid)
-			if _, err := os.Stat(socketPath); err == nil {
-				kittySocketPath = "unix:" + socketPath
-			}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	cfg, err = loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		os.Exit(1)
+	}
+	if *syncTitles {
+		cfg.TitleSync = true
+	}
+	if *recordHistoryFlag {
+		cfg.History = true
+	}
+	if demo {
+		// Leave the user's transcripts and terminal titles alone
+		cfg.History, cfg.TitleSync = false, false
+	}
+	if cfg.History {
+		history = newHistoryRecorder(historyDir())
+	}
+	timeFmt = newTimeFormatter(cfg.Locale, cfg.TimeFormat)
+	if err := applyTheme(cfg.Theme, cfg.StatusSymbols); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *lowPowerFlag != "" {
+		cfg.LowPower = *lowPowerFlag
+	}
+	powerMode, err := parseLowPowerMode(cfg.LowPower)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if cfg.SSHProbe.Enabled {
+		sshProbes = newSSHProber(cfg.SSHProbe)
+	}
+	loadScripts()
+	loadPlugins()
+	agents = newAgentRegistry(cfg.Agents)
+	agentPrefixes := profiles.prefixes(agents.processes())
+	if *prefixes != "" {
+		agentPrefixes = profiles.prefixes(agentsession.ParsePrefixes(*prefixes))
+	}
+
+	if args := flag.Args(); len(args) > 0 && !demo {
+		choice := backendChoice{Name: *backendFlag, KittySocket: *kittySocket, SSH: *sshHosts}
+		if err := runSubcommand(args, choice, agentPrefixes, *maxLines); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
 	}
 
 	if debugMode {
-		runDebug(parsePrefixes(*prefixes), *maxLines)
+		runDebug(agentPrefixes, *maxLines)
+		return
+	}
+
+	if *focusQuery != "" && !*focusTUI {
+		if err := runFocus(*focusQuery, agentPrefixes, *maxLines); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		return
 	}
 
 	// Enable debug logging to file
-	var err error
 	debugLog, err = os.Create("/tmp/lazyccg-tui.log")
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "failed to create debug log:", err)
@@ -148,20 +269,65 @@ func main() {
 	}
 
 	m := model{
-		pollEvery:   *pollEvery,
-		prefixes:    parsePrefixes(*prefixes),
-		maxLines:    *maxLines,
-		prevHashes:  make(map[int]string),
-		stableCount: make(map[int]int),
-	}
-
-	var p *tea.Program
-	if *noAltScreen {
-		p = tea.NewProgram(m)
+		pollEvery:     *pollEvery,
+		prefixes:      agentPrefixes,
+		maxLines:      *maxLines,
+		poll:          newPollState(),
+		showPreview:   cfg.Preview,
+		unread:        make(map[int]int),
+		renamed:       make(map[int]bool),
+		scroll:        make(map[int]scrollState),
+		follow:        cfg.Follow,
+		splitThinking: cfg.SplitThinking,
+		remote:        backendRemote(backend),
+		limits:        backendLimitations(backend),
+		powerMode:     powerMode,
+		lowPower:      powerMode == "on" || (powerMode == "auto" && onBattery()),
+		selectQuery:   *focusQuery,
+	}
+	if demo {
+		m.notice = demoNotice
+		m.waiting = loadWaitTracker("", time.Now())
+		m.view = loadViewState("")
 	} else {
-		p = tea.NewProgram(m, tea.WithAltScreen())
+		m.waiting = loadWaitTracker(waitingPath(), time.Now())
+		m.view = loadViewState(viewStatePath())
+	}
+
+	var opts []tea.ProgramOption
+	if !*noAltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if m.lowPower {
+		// The frame rate is fixed for the life of the program, so with
+		// "auto" it's capped only when started on battery
+		opts = append(opts, tea.WithFPS(lowPowerFPS))
+	}
+	// kitty closing the window sends SIGHUP; bubbletea handles SIGINT and
+	// SIGTERM itself
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGHUP)
+	shutdownCtx = ctx
+	if cfg.Hooks.Listen != "" && !demo {
+		if err := serveHooks(cfg.Hooks.Listen); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 	}
-	if _, err := p.Run(); err != nil {
+	opts = append(opts, tea.WithContext(ctx))
+
+	bus = newEventBus()
+	// The daemon records and notifies while it runs
+	subscribeAll(bus, func() bool { return !daemonRunning(daemonLockPath()) })
+	p := tea.NewProgram(m, opts...)
+	_, err = p.Run()
+	stop()
+	m.waiting.save()
+	shutdown()
+	if errors.Is(err, tea.ErrProgramKilled) {
+		// Hung up
+		return
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -170,26 +336,12 @@ func main() {
 func runDebug(prefixes []string, maxLines int) {
 	fmt.Println("=== lazyccg debug ===")
 	fmt.Println("prefixes:", prefixes)
-	fmt.Println("kittySocketPath:", kittySocketPath)
+	fmt.Println("backend:", backend.Name())
 	fmt.Println()
 
-	// Get raw kitty output (use kittySocketPath like kittyList does)
-	args := []string{"@"}
-	if kittySocketPath != "" {
-		args = append(args, "--to", kittySocketPath)
-	}
-	args = append(args, "ls")
-	cmd := exec.Command("kitty", args...)
-	rawOut, err := cmd.Output()
+	osWindows, err := listWindows()
 	if err != nil {
-		fmt.Println("kitty @ ls error:", err)
-		return
-	}
-
-	// Parse and show structure
-	var osWindows []kittyOSWindow
-	if err := json.Unmarshal(rawOut, &osWindows); err != nil {
-		fmt.Println("JSON parse error:", err)
+		fmt.Println("list windows error:", err)
 		return
 	}
 
@@ -206,15 +358,15 @@ func runDebug(prefixes []string, maxLines int) {
 					fmt.Printf("        [%d] pid=%d cmdline=%v\n", l, proc.Pid, proc.Cmdline)
 				}
 				// Check if this window matches
-				ai, ok := extractAI(win, prefixes)
-				fmt.Printf("      extractAI result: ai=%q ok=%v\n", ai, ok)
+				ai, ok := detectAgent(win, prefixes)
+				fmt.Printf("      DetectAI result: ai=%q ok=%v\n", ai, ok)
 			}
 		}
 	}
 	fmt.Println()
 
 	// Load sessions
-	sessions, _, _, err := loadSessions(prefixes, maxLines, make(map[int]string), make(map[int]int))
+	sessions, _, err := loadSessions(prefixes, maxLines, newPollState())
 	if err != nil {
 		fmt.Println("loadSessions error:", err)
 	} else {
@@ -226,16 +378,29 @@ func runDebug(prefixes []string, maxLines int) {
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(m.refreshCmd(), tick(m.pollEvery))
+	cmds := []tea.Cmd{m.refreshCmd(), tick(m.pollInterval())}
+	if m.powerMode == "auto" {
+		cmds = append(cmds, powerCmd(powerCheckInterval))
+	}
+	if cfg.TitleScroll {
+		cmds = append(cmds, marqueeTick())
+	}
+	return tea.Batch(cmds...)
 }
 
 type renameResultMsg struct {
 	err error
 }
 
+type scriptsReloadedMsg struct {
+	count int
+	err   error
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.notice = ""
 		if m.renaming {
 			switch msg.Type {
 			case tea.KeyEnter:
@@ -245,6 +410,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					newTitle := string(m.renameInput)
 					m.renaming = false
 					m.renameInput = nil
+					m.renamed[windowID] = true
 					return m, renameCmd(windowID, newTitle)
 				}
 				m.renaming = false
@@ -264,19 +430,89 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.archiveOpen {
+			return m.updateArchive(msg)
+		}
+		if m.conflictsOpen {
+			return m.updateConflicts(msg)
+		}
+		if m.approval != nil {
+			return m.updateApproval(msg)
+		}
+		if m.snoozePicking {
+			return m.updateSnoozePicker(msg)
+		}
+		if m.picker != nil {
+			return m.updateDirPicker(msg)
+		}
+		if m.statsOpen {
+			return m.updateStats(msg)
+		}
+		if m.inspectOpen {
+			return m.updateInspect(msg)
+		}
+
+		if m.searching {
+			switch msg.Type {
+			case tea.KeyEnter:
+				query := strings.TrimSpace(string(m.searchInput))
+				filtered := m.filteredSessions()
+				m.searching = false
+				m.searchInput = nil
+				if query != "" && m.selected >= 0 && m.selected < len(filtered) {
+					return m, searchCmd(filtered[m.selected].WindowID, query)
+				}
+			case tea.KeyEsc:
+				m.searching = false
+				m.searchInput = nil
+			case tea.KeyBackspace:
+				if len(m.searchInput) > 0 {
+					m.searchInput = m.searchInput[:len(m.searchInput)-1]
+				}
+			case tea.KeySpace:
+				m.searchInput = append(m.searchInput, ' ')
+			case tea.KeyRunes:
+				m.searchInput = append(m.searchInput, msg.Runes...)
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		case "tab":
-			m.focusedPanel = (m.focusedPanel + 1) % 2
+			panels := 2
+			if m.showDetail {
+				panels = 3
+			}
+			m.focusedPanel = (m.focusedPanel + 1) % panels
 		case "esc":
 			m.statusFilter = ""
+			m.groupFilter = ""
 			m.focusedPanel = 0
+			m.search = nil
+			m.showDetail = false
 		case "enter":
-			if m.focusedPanel == 0 {
+			if m.focusedPanel == 2 {
+				if m.fileSelected < len(m.detailFiles) {
+					return m, editorCmd(m.detailFiles[m.fileSelected].Path)
+				}
+			} else if m.focusedPanel == 0 {
 				filtered := m.filteredSessions()
+				if s, ok := m.selectedSession(); ok && m.view.Grouping != "" && m.groupCollapsed(groupName(s, m.view.Grouping)) {
+					// On a group's header: show only that group, opened
+					// for now; it stays collapsed once the filter is cleared
+					m.groupFilter = groupName(s, m.view.Grouping)
+					m.selected = 0
+					return m, nil
+				}
 				if len(filtered) > 0 && m.selected >= 0 && m.selected < len(filtered) {
-					return m, focusCmd(filtered[m.selected].WindowID)
+					windowID := filtered[m.selected].WindowID
+					m.acknowledge(windowID)
+					if m.search != nil && m.search.windowID == windowID && len(m.search.matches) > 0 {
+						return m, focusAndScrollCmd(windowID, m.search.linesFromBottom())
+					}
+					return m, focusCmd(windowID)
 				}
 			} else {
 				statuses := m.availableStatuses()
@@ -291,16 +527,183 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.focusedPanel = 0
 				}
 			}
+		case "R":
+			return m, reloadScriptsCmd()
+		case "S":
+			m.statsOpen = true
+			m.statsScroll = 0
+		case "c":
+			m.conflictsOpen = true
+			m.conflictScroll = 0
+		case "i":
+			if s, ok := m.selectedSession(); ok && m.focusedPanel == 0 {
+				m.inspectOpen = true
+				m.inspectWindow = s.WindowID
+				m.inspectScroll = 0
+			}
+		case "z":
+			if _, ok := m.selectedSession(); ok && m.focusedPanel == 0 {
+				m.snoozePicking = true
+				m.snoozeChoice = 0
+			}
+		case "Z":
+			if n := len(m.snoozed); n > 0 {
+				m.snoozed = nil
+				m.notice = fmt.Sprintf("woke %d snoozed session(s)", n)
+			}
+		case "y":
+			if s, ok := m.selectedSession(); ok && m.focusedPanel == 0 && !m.guarded(s, "answer") {
+				if m.approval = newApprovalPrompt(s); m.approval == nil {
+					m.notice = "no menu or approval prompt found"
+				}
+			}
+		case "F":
+			m.archiveOpen = true
+			m.archiveTyping = true
+			m.archiveInput = nil
+		case "/":
+			if m.focusedPanel == 0 && len(m.filteredSessions()) > 0 {
+				m.searching = true
+				m.searchInput = nil
+			}
+		case "n", "N":
+			if m.search != nil {
+				// n walks back in time, N forward
+				delta := -1
+				if msg.String() == "N" {
+					delta = 1
+				}
+				search := *m.search
+				search.step(delta)
+				m.search = &search
+			}
+		case "g":
+			if m.focusedPanel == 0 {
+				m.view.cycleGrouping()
+				m.groupFilter = ""
+				m.selected = 0
+			}
+		case "H":
+			if m.focusedPanel == 0 {
+				m.view.toggleSort()
+				m.selected = 0
+				if m.view.Sort == "health" {
+					m.notice = "sickest sessions first"
+				} else {
+					m.notice = "sessions in agent order"
+				}
+			}
+		case " ":
+			if s, ok := m.selectedSession(); ok && m.focusedPanel == 0 && m.view.Grouping != "" {
+				name := groupName(s, m.view.Grouping)
+				m.view.toggle(name)
+				// Keep the cursor on the group
+				for i, s := range m.filteredSessions() {
+					if groupName(s, m.view.Grouping) == name {
+						m.selected = i
+						break
+					}
+				}
+			}
+		case "p":
+			m.showPreview = !m.showPreview
+		case "d":
+			m.showDetail = !m.showDetail
+			if !m.showDetail {
+				if m.focusedPanel == 2 {
+					m.focusedPanel = 0
+				}
+			} else if s, ok := m.selectedSession(); ok {
+				m.fileSelected = 0
+				return m, filesCmd(s)
+			}
+		case "M":
+			if s, ok := m.selectedSession(); ok && m.focusedPanel == 0 {
+				return m, mirrorCmd(s)
+			}
+		case "v":
+			if s, ok := m.selectedSession(); ok && m.focusedPanel == 0 {
+				return m, pagerCaptureCmd(s)
+			}
+		case "w":
+			if s, ok := m.selectedSession(); ok && m.focusedPanel == 0 {
+				return m, m.toggleScrollback(s)
+			}
+		case "O":
+			var agent string
+			if s, ok := m.selectedSession(); ok {
+				agent = s.AI
+			}
+			m.picker = newDirPicker(agent)
+			return m, loadLaunchDirsCmd()
+		case "D":
+			if s, ok := m.selectedSession(); ok && m.focusedPanel == 0 {
+				return m, launchHereCmd(s)
+			}
+		case "L", "J":
+			if s, ok := m.selectedSession(); ok && m.showDetail {
+				link, ok := deepLink(backend, s.WindowID)
+				if !ok {
+					m.notice = backend.Name() + " windows have no deep link"
+				} else if msg.String() == "L" {
+					return m, copyCmd("link", link.URL)
+				} else {
+					return m, copyCmd("jump command", link.Command)
+				}
+			}
+		case "t":
+			m.toolsOnly = !m.toolsOnly
+			m.scroll = make(map[int]scrollState)
+		case "T":
+			m.splitThinking = !m.splitThinking
+			if m.splitThinking {
+				m.notice = "reasoning split off"
+			} else {
+				m.notice = "reasoning inline"
+			}
+		case "pgup", "ctrl+u":
+			m.scrollOutput(-m.outputRows())
+		case "pgdown", "ctrl+d":
+			m.scrollOutput(m.outputRows())
+		case "G":
+			filtered := m.filteredSessions()
+			if m.selected >= 0 && m.selected < len(filtered) {
+				delete(m.scroll, filtered[m.selected].WindowID)
+			}
+		case "f":
+			m.follow = !m.follow
+			if m.follow {
+				m.scroll = make(map[int]scrollState)
+				m.notice = "follow on"
+			} else {
+				m.notice = "follow off"
+			}
+		case "a":
+			filtered := m.filteredSessions()
+			if m.focusedPanel == 0 && m.selected >= 0 && m.selected < len(filtered) {
+				m.acknowledge(filtered[m.selected].WindowID)
+			}
+		case "A":
+			m.unread = make(map[int]int)
+			m.notice = "acknowledged all sessions"
+		case "l":
+			if s, ok := m.selectedSession(); ok && m.focusedPanel == 0 {
+				m.toggleLock(s)
+			}
 		case "r":
 			if m.focusedPanel == 0 {
 				filtered := m.filteredSessions()
-				if len(filtered) > 0 && m.selected >= 0 && m.selected < len(filtered) {
+				if len(filtered) > 0 && m.selected >= 0 && m.selected < len(filtered) && !m.guarded(filtered[m.selected], "rename") {
 					m.renaming = true
 					m.renameInput = []rune(filtered[m.selected].Title)
 				}
 			}
 		case "up", "k":
-			if m.focusedPanel == 0 {
+			if m.focusedPanel == 2 {
+				if m.fileSelected > 0 {
+					m.fileSelected--
+				}
+			} else if m.focusedPanel == 0 {
 				if m.selected > 0 {
 					m.selected--
 				}
@@ -313,7 +716,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case "down", "j":
-			if m.focusedPanel == 0 {
+			if m.focusedPanel == 2 {
+				if m.fileSelected < len(m.detailFiles)-1 {
+					m.fileSelected++
+				}
+			} else if m.focusedPanel == 0 {
 				filtered := m.filteredSessions()
 				if m.selected < len(filtered)-1 {
 					m.selected++
@@ -330,12 +737,89 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+	case powerMsg:
+		if msg.onBattery != m.lowPower {
+			m.lowPower = msg.onBattery
+			if m.lowPower {
+				m.notice = "on battery: low-power mode"
+			} else {
+				m.notice = "on mains: low-power mode off"
+			}
+		}
+		return m, powerCmd(powerCheckInterval)
+	case marqueeMsg:
+		if !m.lowPower {
+			m.stepMarquee()
+		}
+		return m, marqueeTick()
 	case tickMsg:
-		return m, tea.Batch(m.refreshCmd(), tick(m.pollEvery))
+		if m.failures > 0 && time.Time(msg).Before(m.nextRetry) {
+			// Backing off after a failed poll
+			return m, tick(m.pollInterval())
+		}
+		next := tick(m.poll.nextTick(m.pollInterval()))
+		if m.refreshing {
+			// A slow poll is still running; don't pile up more
+			return m, next
+		}
+		m.refreshing = true
+		return m, tea.Batch(m.refreshCmd(), next)
+	case refreshErrorMsg:
+		m.refreshing = false
+		m.err = msg.err
+		m.failures++
+		m.nextRetry = time.Now().Add(retryBackoff(m.pollInterval(), m.failures))
+		if debugLog != nil {
+			fmt.Fprintf(debugLog, "[%s] refresh failed (%d in a row): %v\n",
+				time.Now().Format("15:04:05"), m.failures, msg.err)
+		}
 	case sessionsMsg:
+		m.refreshing = false
+		m.err = nil
+		m.failures = 0
+		snoozed, woke := updateSnoozes(m.snoozed, msg.sessions, time.Now())
+		if len(woke) > 0 {
+			woken := make(map[int]bool, len(m.woken)+len(woke))
+			for id := range m.woken {
+				woken[id] = true
+			}
+			for _, s := range woke {
+				woken[s.WindowID] = true
+			}
+			m.woken = woken
+			m.notice = fmt.Sprintf("snooze over: %s", woke[0].Title)
+		}
+		m.snoozed = snoozed
+		if nag := m.waiting.update(msg.sessions, m.snoozed, cfg.Waiting.Nag, time.Now()); nag != "" {
+			m.notice = nag
+		}
+		events := diffSessions(m.sessions, msg.sessions)
+		markSnoozed(events, m.snoozed)
+		bus.Publish(events)
+		m.unread = updateUnread(m.unread, events, msg.sessions)
+		var cmd tea.Cmd
+		conflicts := findConflicts(msg.sessions)
+		if paths := newConflicts(m.conflicts, conflicts); len(paths) > 0 {
+			m.notice = conflictNotice(paths)
+		}
+		m.conflicts = conflicts
+		selected, wasSelected := m.selectedSession()
 		m.sessions = msg.sessions
-		m.prevHashes = msg.hashes
-		m.stableCount = msg.stableCounts
+		m.view.pruneLocked(m.sessions)
+		m.reanchorScroll()
+		m.poll = msg.poll
+		if wasSelected {
+			// Rows move, e.g. sorted by health; the cursor stays on its session
+			if i := slices.IndexFunc(m.filteredSessions(), func(s session) bool { return s.WindowID == selected.WindowID }); i >= 0 {
+				m.selected = i
+			}
+		}
+		if m.selectQuery != "" {
+			if i, ok := findSession(m.filteredSessions(), m.selectQuery); ok {
+				m.selected = i
+				m.selectQuery = ""
+			}
+		}
 		if m.selected >= len(m.sessions) {
 			m.selected = len(m.sessions) - 1
 			if m.selected < 0 {
@@ -343,6 +827,87 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		m.lastUpdate = time.Now()
+		if cfg.TitleSync {
+			cmd = tea.Batch(cmd, syncTitlesCmd(m.sessions, cfg.TitleTemplate, m.titleSyncSkips()))
+		}
+		if s, ok := m.selectedSession(); ok && m.showDetail {
+			cmd = tea.Batch(cmd, filesCmd(s))
+		}
+		return m, cmd
+	case filesMsg:
+		if msg.windowID != m.detailWindow {
+			m.fileSelected = 0
+		}
+		m.detailWindow = msg.windowID
+		m.detailFiles = msg.files
+		if m.fileSelected >= len(m.detailFiles) {
+			m.fileSelected = 0
+		}
+	case mirrorStartedMsg:
+		if msg.err != nil {
+			m.notice = "mirror: " + msg.err.Error()
+		} else {
+			m.notice = "mirroring " + msg.title
+		}
+	case launchDirsMsg:
+		if m.picker != nil {
+			m.picker.dirs, m.picker.loaded = msg.dirs, true
+		}
+	case launchedMsg:
+		if msg.err != nil {
+			m.notice = "launch: " + msg.err.Error()
+		} else {
+			m.notice = "launched " + msg.title
+		}
+	case copiedMsg:
+		m.notice = "copied " + msg.what
+	case approvalSentMsg:
+		if msg.err != nil {
+			m.notice = "approve failed: " + msg.err.Error()
+		} else {
+			m.notice = fmt.Sprintf("sent %q", msg.label)
+		}
+	case pagerTextMsg:
+		if msg.err != nil {
+			m.notice = "pager: " + msg.err.Error()
+		} else if cfg.PagerWindow {
+			return m, pagerWindowCmd(msg.title, msg.text, msg.windowID)
+		} else {
+			return m, pagerCmd(msg.text)
+		}
+	case scrollbackMsg:
+		m.showScrollback(msg)
+	case pagerClosedMsg:
+		if msg.err != nil {
+			m.notice = "pager: " + msg.err.Error()
+		}
+	case editorClosedMsg:
+		if msg.err != nil {
+			m.notice = "editor: " + msg.err.Error()
+		}
+	case archiveResultMsg:
+		if msg.err != nil {
+			m.notice = "archive search failed: " + msg.err.Error()
+		}
+		m.archiveQuery = msg.query
+		m.archiveSpan = msg.span
+		m.archiveResults = msg.results
+		m.archiveScroll = 0
+	case searchResultMsg:
+		if msg.err != nil {
+			m.notice = "search failed: " + msg.err.Error()
+		} else if len(msg.search.matches) == 0 {
+			m.notice = fmt.Sprintf("no matches for %q", msg.search.query)
+			m.search = nil
+		} else {
+			m.search = msg.search
+		}
+	case scriptsReloadedMsg:
+		if msg.err != nil {
+			m.notice = msg.err.Error()
+		} else {
+			m.notice = fmt.Sprintf("reloaded %d script(s)", msg.count)
+		}
 	case renameResultMsg:
 		if msg.err != nil {
 			m.err = msg.err
@@ -356,12 +921,115 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// outputLines returns the lines the Output panel shows for s.
+func (m model) outputLines(s session) []string {
+	lines := s.Lines
+	if full, ok := m.scrollback[s.WindowID]; ok {
+		lines = full
+	}
+	if m.toolsOnly {
+		return toolCallLines(lines)
+	}
+	return lines
+}
+
+// outputRows is the number of output lines the Output panel can show.
+func (m model) outputRows() int {
+	rows := m.height - 2 - 3
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// scrollOutput moves the selected session's Output viewport by delta lines.
+// Reaching the bottom drops the saved offset so the panel tails again.
+func (m *model) scrollOutput(delta int) {
+	filtered := m.filteredSessions()
+	if m.selected < 0 || m.selected >= len(filtered) {
+		return
+	}
+	s := filtered[m.selected]
+	lines := m.outputLines(s)
+	rows := m.outputRows()
+	maxTop := len(lines) - rows
+	if maxTop < 0 {
+		maxTop = 0
+	}
+	top := maxTop
+	if st, ok := m.scroll[s.WindowID]; ok {
+		top = st.top
+	}
+	top += delta
+	if top >= maxTop {
+		delete(m.scroll, s.WindowID)
+		return
+	}
+	m.scroll[s.WindowID] = newScrollState(lines, top)
+}
+
+// reanchorScroll keeps scrolled-up viewports on the same content after a
+// refresh, or snaps everything to the bottom in follow mode.
+func (m *model) reanchorScroll() {
+	scroll := make(map[int]scrollState)
+	if !m.follow {
+		for _, s := range m.sessions {
+			if st, ok := m.scroll[s.WindowID]; ok {
+				scroll[s.WindowID] = st.reanchor(m.outputLines(s))
+			}
+		}
+	}
+	m.scroll = scroll
+}
+
+// acknowledge clears the attention state of a session without changing its status.
+func (m *model) acknowledge(windowID int) {
+	unread := make(map[int]int, len(m.unread))
+	for id, n := range m.unread {
+		if id != windowID {
+			unread[id] = n
+		}
+	}
+	m.unread = unread
+	if m.woken[windowID] {
+		woken := make(map[int]bool, len(m.woken))
+		for id := range m.woken {
+			if id != windowID {
+				woken[id] = true
+			}
+		}
+		m.woken = woken
+	}
+}
+
+// selectedSession returns the session under the cursor, if any.
+func (m model) selectedSession() (session, bool) {
+	filtered := m.filteredSessions()
+	if m.selected < 0 || m.selected >= len(filtered) {
+		return session{}, false
+	}
+	return filtered[m.selected], true
+}
+
+// filteredSessions lists the sessions the cursor moves through: without
+// snoozed ones or those filtered out, and in group order when grouping.
 func (m model) filteredSessions() []session {
+	if m.view.Grouping != "" {
+		return m.view.visibleSessions(m.sessionGroups(), m.groupFilter)
+	}
+	return m.statusFiltered()
+}
+
+func (m model) statusFiltered() []session {
+	sessions := withoutSnoozed(m.sessions, m.snoozed)
+	if m.view.Sort == "health" {
+		sessions = sortBySickness(sessions, staleAfter(), time.Now())
+	}
 	if m.statusFilter == "" {
-		return m.sessions
+		return sessions
 	}
 	var filtered []session
-	for _, s := range m.sessions {
+	for _, s := range sessions {
 		if s.Status == m.statusFilter {
 			filtered = append(filtered, s)
 		}
@@ -369,19 +1037,59 @@ func (m model) filteredSessions() []session {
 	return filtered
 }
 
-func (m model) availableStatuses() []string {
-	statusOrder := []string{"RUNNING", "IDLE", "WAITING", "DONE"}
-	statusCount := make(map[string]int)
+// groupCollapsed reports whether group shows as its header alone. The group
+// the panel is narrowed to is shown opened, whatever is saved for it.
+func (m model) groupCollapsed(group string) bool {
+	return group != m.groupFilter && m.view.collapsed(group)
+}
+
+// sessionGroups groups the filtered sessions, or returns nil when not
+// grouping.
+func (m model) sessionGroups() []sessionGroup {
+	if m.view.Grouping == "" {
+		return nil
+	}
+	groups := groupSessions(m.statusFiltered(), m.view.Grouping)
+	if m.groupFilter != "" {
+		groups = slices.DeleteFunc(groups, func(g sessionGroup) bool { return g.Name != m.groupFilter })
+	}
+	return groups
+}
+
+var defaultStatusOrder = []agentstatus.Status{
+	agentstatus.Running, agentstatus.Idle, agentstatus.Waiting,
+	agentstatus.NeedsApproval, agentstatus.NeedsInput, agentstatus.Error,
+	agentstatus.RateLimited, agentstatus.Done, agentstatus.Exited,
+}
+
+// availableStatuses lists statuses present in the sessions, in the configured
+// order and without the configured hidden statuses.
+func (m model) availableStatuses() []agentstatus.Status {
+	statusOrder := defaultStatusOrder
+	if len(cfg.StatusOrder) > 0 {
+		statusOrder = parseStatuses(cfg.StatusOrder)
+	}
+	statusCount := make(map[agentstatus.Status]int)
 	for _, s := range m.sessions {
 		statusCount[s.Status]++
 	}
-	var result []string
+	for _, hidden := range cfg.HiddenStatuses {
+		delete(statusCount, agentstatus.Parse(hidden))
+	}
+	var result []agentstatus.Status
 	for _, status := range statusOrder {
 		if statusCount[status] > 0 {
 			result = append(result, status)
+			delete(statusCount, status)
 		}
 	}
-	return result
+	// Custom statuses (e.g. from status commands) follow the built-in ones
+	var extra []agentstatus.Status
+	for status := range statusCount {
+		extra = append(extra, status)
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	return append(result, extra...)
 }
 
 func (m model) View() string {
@@ -389,6 +1097,19 @@ func (m model) View() string {
 		return ""
 	}
 
+	if m.archiveOpen {
+		return m.renderArchiveScreen(m.width, m.height-1) + "\n" + m.renderHelp(m.width)
+	}
+	if m.conflictsOpen {
+		return m.renderConflictScreen(m.width, m.height-1) + "\n" + m.renderHelp(m.width)
+	}
+	if m.statsOpen {
+		return m.renderStatsScreen(m.width, m.height-1) + "\n" + m.renderHelp(m.width)
+	}
+	if m.inspectOpen {
+		return m.renderInspectScreen(m.width, m.height-1) + "\n" + m.renderHelp(m.width)
+	}
+
 	leftWidth := m.width / 2
 	if leftWidth < 35 {
 		leftWidth = 35
@@ -404,7 +1125,18 @@ func (m model) View() string {
 
 	sessions := m.renderSessionsPanel(leftWidth, sessionsHeight)
 	status := m.renderStatusPanel(leftWidth, statusHeight)
-	output := m.renderOutputPanel(rightWidth, outputHeight)
+	var output string
+	if m.approval != nil {
+		output = m.renderApprovalPopup(rightWidth, outputHeight)
+	} else if m.snoozePicking {
+		output = m.renderSnoozePicker(rightWidth, outputHeight)
+	} else if m.picker != nil {
+		output = m.renderDirPicker(rightWidth, outputHeight)
+	} else if m.showDetail {
+		output = m.renderDetailPanel(rightWidth, outputHeight)
+	} else {
+		output = m.renderOutputPanel(rightWidth, outputHeight)
+	}
 
 	left := sessions + "\n" + status
 	content := lipgloss.JoinHorizontal(lipgloss.Top, left, output)
@@ -413,6 +1145,88 @@ func (m model) View() string {
 	return content + "\n" + help
 }
 
+// sessionRow renders s's line in the Sessions panel, and its preview.
+func (m model) sessionRow(s session, tabCount map[int]int, conflicted map[int]bool, width int) []string {
+	name := s.Title
+	if name == "" {
+		name = fmt.Sprintf("tab-%d", s.TabID)
+	}
+	if tabCount[s.TabID] > 1 && s.Cwd != "" {
+		name = fmt.Sprintf("%s/%s", name, filepath.Base(s.Cwd))
+	}
+	if formatted := scripts.FormatTitle(s); formatted != "" {
+		name = formatted
+	}
+	selected, _ := m.selectedSession()
+	name = fitTitle(name, titleWidth(cfg.TitleWidth, width), s.WindowID == selected.WindowID && m.focusedPanel == 0, m.marqueeStep)
+	status := m.formatStatus(s.Status)
+	if s.Status == agentstatus.Exited {
+		status = m.formatExited(s)
+	}
+	line := fmt.Sprintf("%s%s (%s)  %s", sessionMarker(s), name, agents.tag(s.AI), status)
+	now := time.Now()
+	if age := statusAge(s.StatusSince, now); age != "" {
+		line += statusStyle(s.Status).Render(" " + age)
+	}
+	line += statusStyle(s.Status).Render(childNote(s, now))
+	if stale(s, staleAfter(), now) {
+		// Waiting on the user for too long
+		line += statusExited.Bold(true).Render(" STALE")
+	}
+	if v := sessionVitals(s, staleAfter(), now); v.Score < healthyScore {
+		line += fmt.Sprintf(" %s%d", healthEmoji(v.Score), v.Score)
+	}
+	line += contextGauge(s)
+	if m.view.locked(s.WindowID) {
+		line += " " + lockIcon
+	}
+	if s.Intent != "" {
+		line += helpDescStyle.Render(" #" + s.Intent)
+	}
+	if s.Instance != "" {
+		line += helpDescStyle.Render(" @" + s.Instance)
+	}
+	if s.Nested != "" {
+		// Inside tmux in another window
+		line += helpDescStyle.Render(" ⊂" + s.Nested)
+	}
+	if m.woken[s.WindowID] {
+		// Back from a snooze
+		line += statusWaiting.Render(" ⏰")
+	}
+	if ts := timeFmt.Timestamp(s.LastActive, now); ts != "" {
+		line += helpDescStyle.Render(" " + ts)
+	}
+	if n := m.unread[s.WindowID]; n > 0 {
+		line += statusWaiting.Render(fmt.Sprintf(" ●%d", n))
+	}
+	if s.Menu != nil {
+		// Waiting on a menu choice; answer with y
+		line += statusWaiting.Render(" ☰")
+	}
+	if conflicted[s.WindowID] {
+		// Edits a file another session is also editing
+		line += statusWaiting.Render(" ⚠")
+	}
+	if s.DuplicateOf != 0 {
+		// Same prompt as another session: likely launched twice
+		line += statusWaiting.Render(" ≈dup")
+	}
+
+	lines := []string{line}
+	if s.ErrorLine != "" {
+		// What it failed on says more than what it was doing
+		lines = append(lines, statusStyle(s.Status).Render("   ‼ "+ui.Truncate(s.ErrorLine, width-8)))
+	} else if s.Task != "" && showTask() {
+		lines = append(lines, helpDescStyle.Render("   ▸ "+ui.Truncate(s.Task, width-8)))
+	}
+	if m.showPreview {
+		preview := ui.Truncate(agentstatus.LastMeaningfulLine(s.Lines), width-8)
+		lines = append(lines, helpDescStyle.Render("   └ "+preview))
+	}
+	return lines
+}
+
 func (m model) renderSessionsPanel(width, height int) string {
 	tabCount := make(map[int]int)
 	for _, s := range m.sessions {
@@ -420,34 +1234,46 @@ func (m model) renderSessionsPanel(width, height int) string {
 	}
 
 	filtered := m.filteredSessions()
+	conflicted := conflictWindows(m.conflicts)
 	var content []string
 
 	if len(filtered) == 0 {
-		if m.statusFilter != "" {
+		if m.statusFilter != "" || m.groupFilter != "" {
 			content = append(content, helpDescStyle.Render(" (no matching sessions)"))
 		} else {
 			content = append(content, helpDescStyle.Render(" (no sessions)"))
 		}
 	} else {
-		for i, s := range filtered {
-			name := s.Title
-			if name == "" {
-				name = fmt.Sprintf("tab-%d", s.TabID)
+		row := 0
+		add := func(lines ...string) {
+			// Each call is one selectable row
+			for _, line := range lines {
+				if row == m.selected && m.focusedPanel == 0 {
+					lineWidth := lipgloss.Width(line)
+					if innerWidth := width - 2; lineWidth < innerWidth {
+						line = line + strings.Repeat(" ", innerWidth-lineWidth)
+					}
+					line = selectedStyle.Render(line)
+				}
+				content = append(content, line)
 			}
-			if tabCount[s.TabID] > 1 && s.Cwd != "" {
-				name = fmt.Sprintf("%s/%s", name, filepath.Base(s.Cwd))
+			row++
+		}
+		if m.view.Grouping == "" {
+			for _, s := range filtered {
+				add(m.sessionRow(s, tabCount, conflicted, width)...)
 			}
-			name = truncateString(name, 20)
-			line := fmt.Sprintf(" %s (%s)  %s", name, shortAI(s.AI), m.formatStatus(s.Status))
-
-			if i == m.selected && m.focusedPanel == 0 {
-				lineWidth := lipgloss.Width(line)
-				if innerWidth := width - 2; lineWidth < innerWidth {
-					line = line + strings.Repeat(" ", innerWidth-lineWidth)
-				}
-				line = selectedStyle.Render(line)
+		}
+		statuses := m.availableStatuses()
+		for _, g := range m.sessionGroups() {
+			if m.groupCollapsed(g.Name) {
+				add(groupHeader(g, true, statuses))
+				continue
+			}
+			content = append(content, groupHeader(g, false, statuses))
+			for _, s := range g.Sessions {
+				add(m.sessionRow(s, tabCount, conflicted, width)...)
 			}
-			content = append(content, line)
 		}
 	}
 
@@ -457,15 +1283,45 @@ func (m model) renderSessionsPanel(width, height int) string {
 	}
 
 	title := "Sessions"
-	if m.statusFilter != "" {
-		title = fmt.Sprintf("Sessions [%s]", m.statusFilter)
+	if filters := strings.Trim(string(m.statusFilter)+" "+m.groupFilter, " "); filters != "" {
+		title = fmt.Sprintf("Sessions [%s]", filters)
+	}
+	if m.view.Grouping != "" {
+		title += " by " + m.view.Grouping
+	}
+	if m.lowPower {
+		title += " ◌ low-power"
+	}
+	if m.limits != "" {
+		title += " ⚠ " + m.limits
+	}
+	if m.remote != "" {
+		title += " ⇄ remote " + ui.Truncate(m.remote, 24)
+	}
+	if n := totalUnread(m.unread); n > 0 {
+		title += fmt.Sprintf(" ●%d", n)
+	}
+	if n := len(m.conflicts); n > 0 {
+		title += fmt.Sprintf(" ⚠%d", n)
+	}
+	if n := len(m.snoozed); n > 0 {
+		title += fmt.Sprintf(" z%d", n)
+	}
+	now := time.Now()
+	for _, limit := range usageLimits(m.sessions, now) {
+		// Counting down to a usage limit's reset
+		title += " ⟳" + limit.countdown(now)
+	}
+	if d := m.waiting.Today(); d >= time.Minute {
+		// Time agents spent waiting on the user today
+		title += " ⏳" + timeFmt.Duration(d.Truncate(time.Minute))
 	}
 
 	return drawBox(title, content, width, height, borderColor)
 }
 
 func (m model) renderStatusPanel(width, height int) string {
-	statusCount := make(map[string]int)
+	statusCount := make(map[agentstatus.Status]int)
 	for _, s := range m.sessions {
 		statusCount[s.Status]++
 	}
@@ -477,20 +1333,8 @@ func (m model) renderStatusPanel(width, height int) string {
 		content = append(content, helpDescStyle.Render(" (no sessions)"))
 	} else {
 		for i, status := range statuses {
-			text := fmt.Sprintf("%s: %d", status, statusCount[status])
-			var styledText string
-			switch status {
-			case "RUNNING":
-				styledText = statusRunning.Render(text)
-			case "IDLE":
-				styledText = statusIdle.Render(text)
-			case "WAITING":
-				styledText = statusWaiting.Render(text)
-			case "DONE":
-				styledText = statusDone.Render(text)
-			default:
-				styledText = text
-			}
+			text := fmt.Sprintf("%s: %d", statusLabel(status), statusCount[status])
+			styledText := statusStyle(status).Render(text)
 
 			prefix := " "
 			if m.statusFilter == status {
@@ -524,45 +1368,152 @@ func (m model) renderOutputPanel(width, height int) string {
 	if len(filtered) == 0 || m.selected >= len(filtered) {
 		content = append(content, helpDescStyle.Render(" (no output)"))
 	} else {
-		logs := filtered[m.selected].Lines
-		if len(logs) == 0 {
+		s := filtered[m.selected]
+		content = append(content, renderOutputHeader(s, width-2))
+		hints := profiles.hints(s.AI)
+		footer := 0
+		if len(hints) > 0 && height > 5 {
+			footer = 1
+		}
+		logs := m.outputLines(s)
+		if m.search != nil && m.search.windowID == s.WindowID {
+			lines, matchIdx := m.search.context(height - 3 - footer)
+			innerWidth := width - 2
+			for i, line := range lines {
+				line = " " + ui.Truncate(line, innerWidth-1)
+				if i == matchIdx {
+					line = selectedStyle.Render(line)
+				}
+				content = append(content, line)
+			}
+		} else if len(logs) == 0 {
 			content = append(content, helpDescStyle.Render(" (empty)"))
 		} else {
-			availableLines := height - 2
+			availableLines := height - 3 - footer
 			if availableLines < 1 {
 				availableLines = 1
 			}
 			displayLines := logs
-			if len(displayLines) > availableLines {
+			end := len(logs)
+			if st, ok := m.scroll[s.WindowID]; ok {
+				displayLines, _ = st.visible(logs, availableLines)
+				_, end, _ = st.window(len(logs), availableLines)
+			} else if len(displayLines) > availableLines {
 				displayLines = displayLines[len(displayLines)-availableLines:]
 			}
 			innerWidth := width - 2
-			for _, line := range displayLines {
-				if lipgloss.Width(line) > innerWidth {
-					line = truncateString(line, innerWidth)
+			var split []string
+			splitOK := false
+			if m.splitThinking && !m.toolsOnly {
+				split, splitOK = renderSplitOutput(logs, end, availableLines, innerWidth)
+			}
+			if splitOK {
+				content = append(content, split...)
+			} else {
+				for _, line := range displayLines {
+					content = append(content, renderOutputLine(line, innerWidth))
 				}
-				content = append(content, " "+line)
 			}
 		}
+		if footer > 0 {
+			// Pinned to the bottom of the panel
+			for len(content) < height-3 {
+				content = append(content, "")
+			}
+			content = append(content[:height-3], renderHints(hints, width-2))
+		}
+	}
+
+	title := "Output"
+	if m.toolsOnly {
+		title = "Output [tools]"
+	}
+	if s, ok := m.selectedSession(); ok {
+		if _, full := m.scrollback[s.WindowID]; full {
+			title = "Output [scrollback]"
+			if m.toolsOnly {
+				title = "Output [scrollback, tools]"
+			}
+		}
+	}
+	if m.search != nil {
+		title = fmt.Sprintf("Output [/%s %d/%d]", m.search.query, m.search.current+1, len(m.search.matches))
+	}
+	return drawBox(title, content, width, height, gray)
+}
+
+// renderOutputHeader renders the pinned line identifying whose output is shown.
+func renderOutputHeader(s session, width int) string {
+	title := s.Title
+	if title == "" {
+		title = fmt.Sprintf("tab-%d", s.TabID)
+	}
+	sep := helpDescStyle.Render(" · ")
+	header := sessionMarker(s) + titleStyle.Render(ui.Truncate(title, width/2)) +
+		sep + strings.ToUpper(s.AI) +
+		sep + statusStyle(s.Status).Render(statusLabel(s.Status))
+	if s.Cwd != "" {
+		remaining := width - lipgloss.Width(header) - lipgloss.Width(sep)
+		if remaining > 3 {
+			header += sep + helpDescStyle.Render(ui.TruncateLeft(shortenHome(s.Cwd), remaining))
+		}
 	}
+	return header
+}
 
-	return drawBox("Output", content, width, height, gray)
+// renderHints renders an agent's keys and commands, e.g. "shift+tab modes",
+// as a footer line: the key, then what it does. Those that don't fit in
+// width are left off.
+func renderHints(hints []string, width int) string {
+	line := ""
+	for _, hint := range hints {
+		key, desc, _ := strings.Cut(hint, " ")
+		item := helpKeyStyle.Render(key)
+		if desc != "" {
+			item += helpDescStyle.Render(" " + desc)
+		}
+		if line != "" {
+			item = helpDescStyle.Render(" · ") + item
+		}
+		if lipgloss.Width(line+item)+1 > width {
+			break
+		}
+		line += item
+	}
+	return " " + line
 }
 
-func (m model) formatStatus(status string) string {
-	padded := fmt.Sprintf("%-7s", status)
+func statusStyle(status agentstatus.Status) lipgloss.Style {
 	switch status {
-	case "RUNNING":
-		return statusRunning.Render(padded)
-	case "IDLE":
-		return statusIdle.Render(padded)
-	case "WAITING":
-		return statusWaiting.Render(padded)
-	case "DONE":
-		return statusDone.Render(padded)
+	case agentstatus.Running:
+		return statusRunning
+	case agentstatus.Idle:
+		return statusIdle
+	case agentstatus.Waiting:
+		return statusWaiting
+	case agentstatus.NeedsApproval:
+		return statusApproval
+	case agentstatus.NeedsInput:
+		return statusInput
+	case agentstatus.Error:
+		return statusError
+	case agentstatus.RateLimited:
+		return statusLimited
+	case agentstatus.Done:
+		return statusDone
+	case agentstatus.Exited:
+		return statusExited
 	default:
-		return padded
+		return lipgloss.NewStyle()
+	}
+}
+
+func (m model) formatStatus(status agentstatus.Status) string {
+	label := status.String()
+	if short, ok := statusColumn[status]; ok {
+		label = short
 	}
+	return statusStyle(status).Render(statusSymbolCell(status) + fmt.Sprintf("%-7s", label))
 }
 
 func (m model) renderHelp(width int) string {
@@ -570,6 +1521,53 @@ func (m model) renderHelp(width int) string {
 		input := string(m.renameInput)
 		return helpKeyStyle.Render("Rename: ") + input + "█" + helpDescStyle.Render(" (enter: confirm, esc: cancel)")
 	}
+	if m.archiveOpen {
+		if m.archiveSpanTyping {
+			return helpKeyStyle.Render("Recorded between: ") + string(m.archiveInput) + "█" + helpDescStyle.Render(" (e.g. 14:00 15:00; enter: show, esc: back)")
+		}
+		if m.archiveTyping {
+			return helpKeyStyle.Render("Search archive: ") + string(m.archiveInput) + "█" + helpDescStyle.Render(" (enter: search, esc: close)")
+		}
+		return strings.Join([]string{
+			helpKeyStyle.Render("↑↓") + helpDescStyle.Render(": scroll"),
+			helpKeyStyle.Render("/") + helpDescStyle.Render(": new search"),
+			helpKeyStyle.Render("t") + helpDescStyle.Render(": between times"),
+			helpKeyStyle.Render("esc") + helpDescStyle.Render(": close"),
+		}, "  ")
+	}
+	if m.conflictsOpen || m.statsOpen || m.inspectOpen {
+		return strings.Join([]string{
+			helpKeyStyle.Render("↑↓") + helpDescStyle.Render(": scroll"),
+			helpKeyStyle.Render("esc") + helpDescStyle.Render(": close"),
+		}, "  ")
+	}
+	if m.approval != nil {
+		return strings.Join([]string{
+			helpKeyStyle.Render("↑↓") + helpDescStyle.Render(": nav"),
+			helpKeyStyle.Render("enter/1-9") + helpDescStyle.Render(": send"),
+			helpKeyStyle.Render("esc") + helpDescStyle.Render(": cancel"),
+		}, "  ")
+	}
+	if m.picker != nil {
+		return strings.Join([]string{
+			helpKeyStyle.Render("type") + helpDescStyle.Render(": filter"),
+			helpKeyStyle.Render("↑↓") + helpDescStyle.Render(": nav"),
+			helpKeyStyle.Render("tab") + helpDescStyle.Render(": agent"),
+			helpKeyStyle.Render("enter") + helpDescStyle.Render(": launch"),
+			helpKeyStyle.Render("esc") + helpDescStyle.Render(": cancel"),
+		}, "  ")
+	}
+	if m.snoozePicking {
+		return strings.Join([]string{
+			helpKeyStyle.Render("↑↓") + helpDescStyle.Render(": nav"),
+			helpKeyStyle.Render("enter/1-3") + helpDescStyle.Render(": snooze"),
+			helpKeyStyle.Render("esc") + helpDescStyle.Render(": cancel"),
+		}, "  ")
+	}
+	if m.searching {
+		input := string(m.searchInput)
+		return helpKeyStyle.Render("Search: ") + input + "█" + helpDescStyle.Render(" (enter: search scrollback, esc: cancel)")
+	}
 
 	var items []string
 	if m.focusedPanel == 0 {
@@ -577,9 +1575,38 @@ func (m model) renderHelp(width int) string {
 			helpKeyStyle.Render("↑↓") + helpDescStyle.Render(": nav"),
 			helpKeyStyle.Render("enter") + helpDescStyle.Render(": focus"),
 			helpKeyStyle.Render("r") + helpDescStyle.Render(": rename"),
+			helpKeyStyle.Render("a/A") + helpDescStyle.Render(": ack"),
+			helpKeyStyle.Render("y") + helpDescStyle.Render(": answer"),
+			helpKeyStyle.Render("z") + helpDescStyle.Render(": snooze"),
+			helpKeyStyle.Render("l") + helpDescStyle.Render(": lock"),
+			helpKeyStyle.Render("/") + helpDescStyle.Render(": search"),
+			helpKeyStyle.Render("d") + helpDescStyle.Render(": detail"),
+			helpKeyStyle.Render("i") + helpDescStyle.Render(": inspect"),
+			helpKeyStyle.Render("M") + helpDescStyle.Render(": mirror"),
+			helpKeyStyle.Render("v") + helpDescStyle.Render(": pager"),
+			helpKeyStyle.Render("D") + helpDescStyle.Render(": launch here"),
+			helpKeyStyle.Render("O") + helpDescStyle.Render(": launch in…"),
+			helpKeyStyle.Render("g") + helpDescStyle.Render(": group"),
+			helpKeyStyle.Render("H") + helpDescStyle.Render(": by health"),
 			helpKeyStyle.Render("tab") + helpDescStyle.Render(": filter"),
 			helpKeyStyle.Render("q") + helpDescStyle.Render(": quit"),
 		}
+		if m.view.Grouping != "" {
+			items = append(items, helpKeyStyle.Render("space")+helpDescStyle.Render(": collapse"))
+		}
+		if m.showDetail {
+			items = append(items, helpKeyStyle.Render("L/J")+helpDescStyle.Render(": copy link/jump"))
+		}
+		if len(m.conflicts) > 0 {
+			items = append(items, statusWaiting.Render("c")+helpDescStyle.Render(": conflicts"))
+		}
+	} else if m.focusedPanel == 2 {
+		items = []string{
+			helpKeyStyle.Render("↑↓") + helpDescStyle.Render(": nav"),
+			helpKeyStyle.Render("enter") + helpDescStyle.Render(": open in editor"),
+			helpKeyStyle.Render("esc") + helpDescStyle.Render(": back"),
+			helpKeyStyle.Render("q") + helpDescStyle.Render(": quit"),
+		}
 	} else {
 		items = []string{
 			helpKeyStyle.Render("↑↓") + helpDescStyle.Render(": nav"),
@@ -591,8 +1618,24 @@ func (m model) renderHelp(width int) string {
 
 	help := strings.Join(items, "  ")
 
-	if !m.lastUpdate.IsZero() {
-		updated := helpDescStyle.Render(m.lastUpdate.Format("15:04:05"))
+	if m.failures > 0 {
+		wait := time.Until(m.nextRetry).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		indicator := statusWaiting.Render(fmt.Sprintf("reconnecting… (retry in %s)", wait))
+		padding := width - lipgloss.Width(help) - lipgloss.Width(indicator) - 2
+		if padding > 0 {
+			help += strings.Repeat(" ", padding) + indicator
+		}
+	} else if m.notice != "" {
+		notice := helpKeyStyle.Render(m.notice)
+		padding := width - lipgloss.Width(help) - lipgloss.Width(notice) - 2
+		if padding > 0 {
+			help += strings.Repeat(" ", padding) + notice
+		}
+	} else if !m.lastUpdate.IsZero() {
+		updated := helpDescStyle.Render(timeFmt.Timestamp(m.lastUpdate, time.Now()))
 		padding := width - lipgloss.Width(help) - lipgloss.Width(updated) - 2
 		if padding > 0 {
 			help += strings.Repeat(" ", padding) + updated
@@ -603,20 +1646,27 @@ func (m model) renderHelp(width int) string {
 }
 
 type sessionsMsg struct {
-	sessions    []session
-	hashes      map[int]string
-	stableCounts map[int]int
+	sessions []session
+	poll     pollState
 }
 
 func (m model) refreshCmd() tea.Cmd {
-	prevHashes := m.prevHashes
-	stableCount := m.stableCount
+	prev := m.poll
+	prev.base = m.pollInterval()
+	prev.lowPower = m.lowPower
 	return func() tea.Msg {
-		sessions, hashes, counts, err := loadSessions(m.prefixes, m.maxLines, prevHashes, stableCount)
+		sessions, poll, err := loadSessions(m.prefixes, m.maxLines, prev)
 		if err != nil {
-			return err
+			return refreshErrorMsg{err: err}
 		}
-		return sessionsMsg{sessions: sessions, hashes: hashes, stableCounts: counts}
+		return sessionsMsg{sessions: sessions, poll: poll}
+	}
+}
+
+func reloadScriptsCmd() tea.Cmd {
+	return func() tea.Msg {
+		n, err := scripts.Load()
+		return scriptsReloadedMsg{count: n, err: err}
 	}
 }
 
@@ -629,13 +1679,7 @@ func focusCmd(windowID int) tea.Cmd {
 		if windowID == 0 {
 			return nil
 		}
-		args := []string{"@"}
-		if kittySocketPath != "" {
-			args = append(args, "--to", kittySocketPath)
-		}
-		args = append(args, "focus-window", "--match", fmt.Sprintf("id:%d", windowID))
-
-		if err := exec.Command("kitty", args...).Run(); err != nil {
+		if err := backend.Focus(windowID); err != nil {
 			return err
 		}
 		return nil
@@ -647,13 +1691,7 @@ func renameCmd(windowID int, title string) tea.Cmd {
 		if windowID == 0 {
 			return renameResultMsg{err: nil}
 		}
-		args := []string{"@"}
-		if kittySocketPath != "" {
-			args = append(args, "--to", kittySocketPath)
-		}
-		args = append(args, "set-window-title", "--match", fmt.Sprintf("id:%d", windowID), title)
-
-		if err := exec.Command("kitty", args...).Run(); err != nil {
+		if err := backend.Rename(windowID, title); err != nil {
 			return renameResultMsg{err: err}
 		}
 		return renameResultMsg{err: nil}
@@ -662,26 +1700,81 @@ func renameCmd(windowID int, title string) tea.Cmd {
 
 var debugLog *os.File
 
-func loadSessions(prefixes []string, maxLines int, prevHashes map[int]string, prevStable map[int]int) ([]session, map[int]string, map[int]int, error) {
+// pollState is what one poll remembers for the next.
+type pollState struct {
+	hashes  map[int]string    // windowID -> output hash
+	stable  map[int]int       // windowID -> consecutive unchanged polls
+	agents  map[int]string    // windowID -> AI that was last seen running there
+	changed map[int]time.Time // windowID -> when the output last changed
+	moved   map[int]time.Time // windowID -> when the screen last differed from the capture before
+	lines   map[int][]string  // windowID -> captured lines
+	tools   map[int]toolStats // windowID -> tool calls seen so far
+	edits   map[int][]string  // windowID -> paths edited so far
+	// lowPower keeps streaming sessions at the base interval
+	lowPower bool
+
+	// Capture scheduling, see scheduler.go
+	base        time.Duration         // poll interval
+	interval    map[int]time.Duration // windowID -> capture interval
+	due         map[int]time.Time     // windowID -> next capture
+	captures    map[int]int           // windowID -> captures so far
+	captured    map[int]time.Time     // windowID -> last capture
+	sessions    map[int]session       // windowID -> last session, reused until due
+	pending     map[int]pendingStatus // windowID -> status not settled yet
+	polls       int                   // polls so far
+	took        time.Duration         // how long the last poll took
+	capturedNow int                   // windows captured by the last poll
+}
+
+func newPollState() pollState {
+	return pollState{
+		hashes:  make(map[int]string),
+		stable:  make(map[int]int),
+		agents:  make(map[int]string),
+		changed: make(map[int]time.Time),
+		moved:   make(map[int]time.Time),
+		lines:   make(map[int][]string),
+		tools:   make(map[int]toolStats),
+		edits:   make(map[int][]string),
+
+		interval: make(map[int]time.Duration),
+		due:      make(map[int]time.Time),
+		captures: make(map[int]int),
+		captured: make(map[int]time.Time),
+		sessions: make(map[int]session),
+		pending:  make(map[int]pendingStatus),
+	}
+}
+
+func loadSessions(prefixes []string, maxLines int, prev pollState) ([]session, pollState, error) {
 	if debugLog != nil {
 		fmt.Fprintf(debugLog, "[%s] loadSessions called, prefixes=%v\n", time.Now().Format("15:04:05"), prefixes)
 	}
 
-	osWindows, err := kittyList()
+	osWindows, err := listWindows()
+	health.record(backend.Name(), err, time.Now())
 	if err != nil {
 		if debugLog != nil {
-			fmt.Fprintf(debugLog, "[%s] kittyList error: %v\n", time.Now().Format("15:04:05"), err)
+			fmt.Fprintf(debugLog, "[%s] %s list error: %v\n", time.Now().Format("15:04:05"), backend.Name(), err)
 		}
-		return nil, nil, nil, err
+		return nil, pollState{}, err
 	}
 
 	if debugLog != nil {
-		fmt.Fprintf(debugLog, "[%s] kittyList returned %d OS windows\n", time.Now().Format("15:04:05"), len(osWindows))
+		fmt.Fprintf(debugLog, "[%s] %s list returned %d OS windows\n", time.Now().Format("15:04:05"), backend.Name(), len(osWindows))
 	}
 
-	newHashes := make(map[int]string)
-	newStable := make(map[int]int)
+	start := time.Now()
+	next := newPollState()
+	next.base = prev.base
+	if next.base <= 0 {
+		next.base = time.Second
+	}
+	next.polls = prev.polls + 1
 	var sessions []session
+	// Read once a poll, when a session is RUNNING
+	var procs []procEntry
+	procsRead := false
 	for _, ow := range osWindows {
 		for _, tab := range ow.Tabs {
 			for _, win := range tab.Windows {
@@ -689,82 +1782,244 @@ func loadSessions(prefixes []string, maxLines int, prevHashes map[int]string, pr
 					fmt.Fprintf(debugLog, "[%s] checking tab=%q win=%d procs=%d\n",
 						time.Now().Format("15:04:05"), tab.Title, win.ID, len(win.ForegroundProcesses))
 				}
-				ai, ok := extractAI(win, prefixes)
+				if !cfg.IncludeSelf && !ow.Remote && isSelfWindow(win, selfPid) {
+					continue
+				}
+				ai, ok := detectAgent(win, prefixes)
+				if !ok && sshProbes != nil {
+					ai, ok = sshProbes.agent(win, prefixes)
+				}
+				ai = profiles.agent(ai)
+				exited := false
 				if !ok {
+					// A window that ran an agent stays listed after it exits
+					if ai, ok = prev.agents[win.ID]; !ok {
+						continue
+					}
+					exited = true
+				}
+				next.agents[win.ID] = ai
+				hook, hooked := hooks.last(win.ID, win.Cwd)
+				// Hooks only reach the local listener, and an event that didn't name
+				// its window can only be Claude Code's
+				hooked = hooked && ow.Instance == "" && (hook.Named || ai == "claude")
+				rang, _ := bells.last(win.ID)
+				if ow.Instance != "" {
+					rang = time.Time{}
+				}
+				if last, ok := prev.sessions[win.ID]; ok && start.Before(prev.due[win.ID]) && (last.Status == agentstatus.Exited) == exited &&
+					!(hooked && hook.At.After(prev.captured[win.ID])) && !rang.After(prev.captured[win.ID]) {
+					// Not due for capture yet
+					next.carry(prev, win.ID)
+					sessions = append(sessions, last)
 					continue
 				}
-				text, err := kittyGetText(win.ID)
-				if err != nil {
+				text, cursor, err := captureWindow(win, tab.ID, ai, prev.sessions[win.ID].Status, exited)
+				noCapture := errors.Is(err, errNoCapture)
+				if err != nil && !noCapture {
 					if debugLog != nil {
-						fmt.Fprintf(debugLog, "[%s] kittyGetText error win=%d: %v\n",
+						fmt.Fprintf(debugLog, "[%s] capture text error win=%d: %v\n",
 							time.Now().Format("15:04:05"), win.ID, err)
 					}
 					continue
 				}
-				lines := normalizeLines(text, maxLines)
+				// Frames of a spinner or progress bar captured one after another
+				// are one line
+				lines := agentstatus.CollapseFrames(agentsession.NormalizeLines(text, maxLines))
+				next.lines[win.ID] = lines
+				prevLines, known := prev.lines[win.ID]
+				next.moved[win.ID] = prev.moved[win.ID]
+				if known && !slices.Equal(prevLines, lines) {
+					next.moved[win.ID] = start
+				}
+				added := appendedLines(prev.lines[win.ID], lines)
+				next.tools[win.ID] = prev.tools[win.ID].merge(countToolCalls(added))
+				next.edits[win.ID] = appendUnique(prev.edits[win.ID], editedPaths(added)...)
 
-				// Compute hash from last few lines
+				// Compute hash from last few lines. A new spinner frame shows the
+				// agent is alive (moved) but isn't new output: it doesn't make the
+				// session active, or captured more often
 				hashLines := lines
 				if len(hashLines) > 5 {
 					hashLines = hashLines[len(hashLines)-5:]
 				}
-				currentHash := strings.Join(hashLines, "\n")
-				newHashes[win.ID] = currentHash
+				currentHash := strings.Join(agentstatus.FrameKeys(hashLines), "\n")
+				next.hashes[win.ID] = currentHash
 
 				// Track stable (unchanged) count
-				prevHash := prevHashes[win.ID]
+				prevHash := prev.hashes[win.ID]
 				if currentHash == prevHash {
-					newStable[win.ID] = prevStable[win.ID] + 1
+					next.stable[win.ID] = prev.stable[win.ID] + 1
+					next.changed[win.ID] = prev.changed[win.ID]
 				} else {
-					newStable[win.ID] = 0
+					next.stable[win.ID] = 0
+					next.changed[win.ID] = time.Now()
 				}
+				next.interval[win.ID] = nextCaptureInterval(prev.interval[win.ID], next.base, !prev.lowPower && prevHash != "" && currentHash != prevHash)
+				next.due[win.ID] = start.Add(next.interval[win.ID])
+				next.captures[win.ID] = prev.captures[win.ID] + 1
+				next.captured[win.ID] = start
+				next.capturedNow++
 
 				// Determine status
-				var status string
-				recentText := strings.ToLower(strings.Join(hashLines, " "))
-				hasActiveIndicator := strings.Contains(recentText, "ctrl+c to interrupt")
-
-				if hasActiveIndicator {
+				var status agentstatus.Status
+				var source agentstatus.Source
+				var confidence float64
+				var why string
+				hasActiveIndicator := interruptHint(hashLines, lines)
+
+				if exited {
+					status, source, confidence = agentstatus.Exited, agentstatus.SourceProcess, agentstatus.Certain
+					why = "the agent's process is gone"
+				} else if noCapture {
+					// Only the process is known
+					status, source, confidence = agentstatus.Unknown, agentstatus.SourceNone, agentstatus.NoSignals
+					why = "the screen can't be read, only the process"
+				} else if hasActiveIndicator {
 					// Real-time indicator takes priority
-					status = "RUNNING"
-				} else if newStable[win.ID] >= 2 {
-					// Output stable for 2+ polls -> use text-based detection
-					status = inferStatus(lines)
-				} else if prevHash != "" && currentHash != prevHash {
-					// Output just changed -> RUNNING
-					status = "RUNNING"
+					status, source, confidence = agentstatus.Running, agentstatus.SourceOutput, agentstatus.Certain
+					why = `"ctrl+c to interrupt" on screen`
 				} else {
-					// First poll or transitioning -> use text-based detection
-					status = inferStatus(lines)
+					// A changing screen means RUNNING; text tells the rest
+					status, source, confidence, why = detectStatus(ai, lines)
+					if cs, cwhy, ok := cursorStatus(cursor); ok && confidence <= agentstatus.Guess {
+						// Nothing on screen placed it, but the cursor waits at a prompt
+						status, source, confidence, why = cs, agentstatus.SourceOutput, agentstatus.Likely, cwhy
+					}
+					active := activeAt(next.moved[win.ID], start, activityWindow())
+					if as, asrc, ac := withActivity(known, active, status, source, confidence); asrc != source {
+						why = activityWhy(as, why)
+						status, source, confidence = as, asrc, ac
+					}
+					if bs, ok := bellStatus(status, rang, next.moved[win.ID]); ok {
+						status, source, confidence = bs, agentstatus.SourceBell, agentstatus.Likely
+						why = "the bell rang at " + rang.Format("15:04:05") + " and the screen has been still since"
+					}
+					if hooked {
+						if hs, ok := hookStatus(hook, next.moved[win.ID], start); ok {
+							status, source, confidence = hs, agentstatus.SourceHook, agentstatus.Certain
+							why = hook.why()
+						}
+					}
+				}
+				var agentLog agentLog
+				if !exited && ow.Instance == "" {
+					// Logs are on this machine's disk
+					var ok bool
+					if agentLog, ok = agentLogs.lookup(ai, win.ID, win.Cwd, foregroundPids(win), start); ok && !noCapture && !screenOverLog(status) {
+						if ls, ok := reportedStatus(agentLog.status, agentLog.at, next.moved[win.ID], start); ok {
+							status, source, confidence = ls, agentstatus.SourceLog, agentstatus.Certain
+							why = agentLog.why()
+						}
+					}
 				}
 
-				title := win.Title
+				title := agentsession.SanitizeLine(win.Title)
 				if title == "" {
-					title = tab.Title
+					title = agentsession.SanitizeLine(tab.Title)
 				}
 				if title == "" {
 					title = win.Cwd
 				}
-				sessions = append(sessions, session{
-					TabID:      tab.ID,
-					WindowID:   win.ID,
-					Title:      title,
-					AI:         ai,
-					Status:     status,
-					Lines:      lines,
-					Updated:    time.Now(),
-					Cwd:        win.Cwd,
-					OutputHash: currentHash,
-				})
+				s := session{
+					TabID:        tab.ID,
+					WindowID:     win.ID,
+					Title:        title,
+					AI:           ai,
+					Status:       status,
+					Lines:        lines,
+					StatusSource: source,
+					Confidence:   confidence,
+					StatusWhy:    why,
+					Updated:      time.Now(),
+					Cwd:          win.Cwd,
+					OutputHash:   currentHash,
+					Task:         currentTask(lines),
+					LastActive:   next.changed[win.ID],
+					Tools:        next.tools[win.ID],
+					Edited:       next.edits[win.ID],
+					Instance:     ow.Instance,
+					Parent:       win.Parent,
+					Nested:       win.Nested,
+					Project:      projectName(win.Cwd),
+					Tokens:       agentLog.tokens,
+					LastAction:   agentLog.action,
+				}
+				last, seen := prev.sessions[win.ID]
+				seen = seen && last.AI == ai
+				if seen {
+					s.Busy = last.Busy.add(last, start.Sub(prev.captured[win.ID]))
+				}
+				s.Prompt = firstPrompt(last, seen, agentLog.prompt, extractPrompt(lines))
+				s.Intent = sessionIntent(last, seen, agentLog.prompt, s.Prompt)
+				if exited {
+					s = withExit(s, prev.sessions[win.ID], win, lines)
+					// Context is as it was left, not what's on screen now
+					s.ContextLeft, s.ContextKnown = prev.sessions[win.ID].ContextLeft, prev.sessions[win.ID].ContextKnown
+					s.StatusSince = statusSince(prev.sessions[win.ID], s)
+					next.sessions[win.ID] = s
+					sessions = append(sessions, s)
+					continue
+				}
+
+				if menu, ok := parseMenu(lines); ok {
+					s.Menu = &menu
+				}
+				s.ContextLeft, s.ContextKnown = contextLeft(lines)
+				s.Limit = sessionUsageLimit(lines, agentLog.limit, prev.sessions[win.ID], start)
+				if s.Tokens == 0 {
+					s.Tokens, _ = tokensUsed(lines)
+				}
+
+				if scripted := scripts.DetectStatus(s); scripted != "" {
+					s.Status, s.StatusSource, s.Confidence = agentstatus.Parse(scripted), agentstatus.SourceScript, agentstatus.Certain
+					s.StatusWhy = fmt.Sprintf("a Lua detector returned %q", scripted)
+				}
+
+				// External status provider overrides built-in detection
+				if command := cfg.StatusCommands[ai]; command != "" {
+					if external, err := runStatusCommand(command, s); err != nil {
+						if debugLog != nil {
+							fmt.Fprintf(debugLog, "[%s] %v\n", time.Now().Format("15:04:05"), err)
+						}
+					} else if external != "" {
+						s.Status, s.StatusSource, s.Confidence = external, agentstatus.SourceCommand, agentstatus.Certain
+						s.StatusWhy = "status command: " + command
+					}
+				}
+				if s.Status == agentstatus.Error {
+					s.ErrorLine = agentstatus.ErrorExcerpt(lines)
+				}
+				shown, known := prev.sessions[win.ID]
+				var pending pendingStatus
+				if s, pending = settle(s, shown, known && shown.AI == s.AI, prev.pending[win.ID], statusSettle()); pending.Seen > 0 {
+					next.pending[win.ID] = pending
+				}
+				if s.Status == agentstatus.Running && !ow.Remote {
+					// The process tree is on this machine
+					if !procsRead {
+						procs, procsRead = readProcs(), true
+					}
+					if c, ok := toolChild(procs, foregroundPids(win)); ok {
+						s.Child, s.ChildSince = c.Name, c.Since
+					}
+				}
+				s.StatusSince = statusSince(prev.sessions[win.ID], s)
+				next.sessions[win.ID] = s
+				sessions = append(sessions, s)
 			}
 		}
 	}
 
+	markDuplicatePrompts(sessions)
+	agentLogs.forget(next.agents)
+	next.took = time.Since(start)
+
 	if debugLog != nil {
 		fmt.Fprintf(debugLog, "[%s] returning %d sessions\n", time.Now().Format("15:04:05"), len(sessions))
 	}
 
-	return sortSessions(sessions), newHashes, newStable, nil
+	return sortSessions(sessions), next, nil
 }
 
 func sortSessions(sessions []session) []session {
@@ -777,224 +2032,57 @@ func sortSessions(sessions []session) []session {
 	return sessions
 }
 
-func extractAI(win kittyWindow, prefixes []string) (string, bool) {
-	for _, proc := range win.ForegroundProcesses {
-		if len(proc.Cmdline) == 0 {
-			continue
-		}
-		// Check all cmdline elements for AI tool names
-		for _, arg := range proc.Cmdline {
-			argLower := strings.ToLower(arg)
-			for _, p := range prefixes {
-				// Check basename (e.g., /usr/bin/claude -> claude)
-				base := arg
-				if idx := strings.LastIndex(arg, "/"); idx >= 0 {
-					base = arg[idx+1:]
-				}
-				baseLower := strings.ToLower(base)
-				if baseLower == p {
-					return p, true
-				}
-				// Check if path contains the prefix as a path component
-				// e.g., /path/to/@openai/codex/bin/codex
-				if strings.Contains(argLower, "/"+p+"/") || strings.HasSuffix(argLower, "/"+p) {
-					return p, true
-				}
-			}
-		}
-	}
-	return "", false
-}
+// selfPid is lazyccg's own pid, used to leave its window out.
+var selfPid = os.Getpid()
 
-func parsePrefixes(s string) []string {
-	parts := strings.Split(s, ",")
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		p = strings.ToLower(strings.TrimSpace(p))
-		if p != "" {
-			out = append(out, p)
+// isSelfWindow reports whether pid is one of the window's foreground
+// processes, i.e. lazyccg is running in it.
+func isSelfWindow(win kittyWindow, pid int) bool {
+	for _, proc := range win.ForegroundProcesses {
+		if proc.Pid == pid {
+			return true
 		}
 	}
-	return out
+	return false
 }
 
-var kittySocketPath string
-
-func kittyList() ([]kittyOSWindow, error) {
-	args := []string{"@"}
-	if kittySocketPath != "" {
-		args = append(args, "--to", kittySocketPath)
+// statusSince returns when s entered its status: prev's time if prev was
+// already in it, otherwise now.
+func statusSince(prev, s session) time.Time {
+	if prev.Status == s.Status && !prev.StatusSince.IsZero() {
+		return prev.StatusSince
 	}
-	args = append(args, "ls")
-
-	cmd := exec.Command("kitty", args...)
-	out, err := cmd.Output()
-
-	if debugLog != nil {
-		fmt.Fprintf(debugLog, "[kittyList] socket=%q err=%v out_len=%d\n",
-			kittySocketPath, err, len(out))
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("kitty @ ls: %w", err)
-	}
-	var osWindows []kittyOSWindow
-	if err := json.Unmarshal(out, &osWindows); err != nil {
-		return nil, fmt.Errorf("json parse: %w", err)
-	}
-	return osWindows, nil
+	return s.Updated
 }
 
-func kittyGetText(windowID int) (string, error) {
-	args := []string{"@"}
-	if kittySocketPath != "" {
-		args = append(args, "--to", kittySocketPath)
+func parseStatuses(values []string) []agentstatus.Status {
+	out := make([]agentstatus.Status, len(values))
+	for i, v := range values {
+		out[i] = agentstatus.Parse(v)
 	}
-	args = append(args, "get-text", "--match", fmt.Sprintf("id:%d", windowID))
-
-	cmd := exec.Command("kitty", args...)
-	out, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return string(out), nil
+	return out
 }
 
-func normalizeLines(text string, maxLines int) []string {
-	text = strings.ReplaceAll(text, "\r\n", "\n")
-	text = strings.ReplaceAll(text, "\r", "\n")
-	lines := strings.Split(text, "\n")
-	trimmed := make([]string, 0, len(lines))
-	for _, line := range lines {
-		line = strings.TrimRight(line, "\t ")
-		if line == "" {
-			continue
-		}
-		trimmed = append(trimmed, line)
+// shortenHome replaces the home directory prefix of path with ~.
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == "/" {
+		return path
 	}
-	if maxLines > 0 && len(trimmed) > maxLines {
-		trimmed = trimmed[len(trimmed)-maxLines:]
+	if path == home {
+		return "~"
 	}
-	return trimmed
-}
-
-// inferStatus determines status based on output content (used when output hasn't changed)
-func inferStatus(lines []string) string {
-	if len(lines) == 0 {
-		return "IDLE"
-	}
-
-	lastLine := strings.TrimSpace(lines[len(lines)-1])
-	lastLineLower := strings.ToLower(lastLine)
-
-	recentLines := lines
-	if len(lines) > 10 {
-		recentLines = lines[len(lines)-10:]
+	if strings.HasPrefix(path, home+"/") {
+		return "~" + path[len(home):]
 	}
-	recentText := strings.ToLower(strings.Join(recentLines, " "))
-
-	// WAITING: needs user confirmation
-	if strings.Contains(recentText, "waiting") ||
-		strings.Contains(recentText, "approval") ||
-		strings.Contains(recentText, "confirm") ||
-		strings.Contains(recentText, "press enter") {
-		return "WAITING"
-	}
-
-	// DONE: explicit completion signals
-	if strings.Contains(recentText, "completed") ||
-		strings.Contains(recentText, "success") ||
-		strings.Contains(recentText, "task completed") {
-		return "DONE"
-	}
-
-	// RUNNING: explicit progress signals
-	if strings.Contains(recentText, "running") ||
-		strings.Contains(recentText, "processing") ||
-		strings.Contains(recentText, "executing") ||
-		strings.Contains(recentText, "reading files") {
-		return "RUNNING"
-	}
-
-	// IDLE: prompt waiting patterns
-	if lastLine == ">" || lastLine == ">>" ||
-		strings.HasPrefix(lastLine, "> ") ||
-		strings.HasPrefix(lastLine, "$ ") ||
-		strings.HasPrefix(lastLine, "% ") ||
-		strings.HasSuffix(lastLine, " >") ||
-		strings.Contains(lastLineLower, "context left") ||
-		strings.Contains(lastLineLower, "? for shortcuts") ||
-		strings.Contains(recentText, "accept edits") ||
-		strings.Contains(recentText, "crunched for") ||
-		strings.Contains(recentText, "brewed for") ||
-		strings.Contains(recentText, "worked for") {
-		return "IDLE"
-	}
-
-	// Default to IDLE when output hasn't changed
-	return "IDLE"
-}
-
-func truncateString(s string, maxLen int) string {
-	runes := []rune(s)
-	if len(runes) <= maxLen {
-		return s
-	}
-	if maxLen <= 3 {
-		return string(runes[:maxLen])
-	}
-	return string(runes[:maxLen-3]) + "..."
+	return path
 }
 
 func shortAI(ai string) string {
-	switch strings.ToLower(ai) {
-	case "claude":
-		return "CL"
-	case "codex":
-		return "CO"
-	case "gemini":
-		return "GE"
-	default:
-		if len(ai) >= 2 {
-			return strings.ToUpper(ai[:2])
-		}
-		return strings.ToUpper(ai)
-	}
+	return agents.label(ai)
 }
 
+// drawBox frames a panel with title in the title style.
 func drawBox(title string, content []string, width, height int, borderColor lipgloss.Color) string {
-	colorStyle := lipgloss.NewStyle().Foreground(borderColor)
-	titleStyled := titleStyle.Render(title)
-
-	innerWidth := width - 2
-	if innerWidth < 1 {
-		innerWidth = 1
-	}
-
-	titleLen := lipgloss.Width(titleStyled)
-	remainingWidth := width - 3 - titleLen
-	if remainingWidth < 0 {
-		remainingWidth = 0
-	}
-	topLine := colorStyle.Render("╭─") + titleStyled + colorStyle.Render(strings.Repeat("─", remainingWidth)+"╮")
-
-	var lines []string
-	lines = append(lines, topLine)
-
-	for i := 0; i < height-2; i++ {
-		var lineContent string
-		if i < len(content) {
-			lineContent = content[i]
-		}
-		lineWidth := lipgloss.Width(lineContent)
-		padding := innerWidth - lineWidth
-		if padding < 0 {
-			padding = 0
-		}
-		lines = append(lines, colorStyle.Render("│")+lineContent+strings.Repeat(" ", padding)+colorStyle.Render("│"))
-	}
-
-	lines = append(lines, colorStyle.Render("╰"+strings.Repeat("─", innerWidth)+"╯"))
-
-	return strings.Join(lines, "\n")
+	return ui.Box(titleStyle.Render(title), content, width, height, borderColor)
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// agentMenu is a numbered menu an agent is showing, e.g. Claude Code's
+// permission prompts or a /model picker.
+type agentMenu struct {
+	Question string // line above the first option, if any
+	Options  []approvalOption
+	Selected int // option under the agent's cursor
+}
+
+// menuLookback is how many trailing lines are searched for a menu.
+const menuLookback = 15
+
+// "❯ 1. Yes", "  2. Yes, and don't ask again", or several on one line
+var numberedOptionPattern = regexp.MustCompile(`(\d)\.\s+(\S.*?)(?:\s{2,}|\s*│?\s*$)`)
+
+const menuBorderChars = " │┃|"
+
+// parseMenu finds a numbered menu near the end of lines. Options must run
+// 1, 2, 3, ... and there must be at least two; when several lists are
+// visible the last one wins.
+func parseMenu(lines []string) (agentMenu, bool) {
+	start := len(lines) - menuLookback
+	if start < 0 {
+		start = 0
+	}
+	var menu agentMenu
+	for i := start; i < len(lines); i++ {
+		raw := strings.TrimLeft(lines[i], menuBorderChars)
+		line := strings.TrimLeft(raw, " ❯>›")
+		cursor := len(line) < len(raw)
+		for _, m := range numberedOptionPattern.FindAllStringSubmatchIndex(line, -1) {
+			if m[0] > 0 && line[m[0]-1] != ' ' {
+				continue // e.g. "v1. " inside a word
+			}
+			key, label := line[m[2]:m[3]], line[m[4]:m[5]]
+			n, _ := strconv.Atoi(key)
+			switch {
+			case n == len(menu.Options)+1:
+			case n == 1:
+				// A new list starts
+				menu = agentMenu{}
+			default:
+				continue
+			}
+			if n == 1 {
+				menu.Question = menuQuestion(lines[start:i])
+			}
+			if cursor && m[0] == 0 {
+				menu.Selected = n - 1
+			}
+			menu.Options = append(menu.Options, approvalOption{Label: label, Send: key})
+		}
+	}
+	return menu, len(menu.Options) >= 2
+}
+
+// menuQuestion returns the last meaningful line before a menu.
+func menuQuestion(above []string) string {
+	for i := len(above) - 1; i >= 0; i-- {
+		line := strings.Trim(above[i], menuBorderChars+"╭╮╰╯─")
+		if line != "" {
+			return strings.TrimSpace(line)
+		}
+	}
+	return ""
+}
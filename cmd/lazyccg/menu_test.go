@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMenu(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  agentMenu
+		ok    bool
+	}{
+		{
+			name: "model picker with cursor",
+			lines: []string{
+				"╭──────────────────────────────╮",
+				"│ Select model                 │",
+				"│   1. Default (recommended)   │",
+				"│ ❯ 2. Opus                    │",
+				"│   3. Haiku                   │",
+				"╰──────────────────────────────╯",
+			},
+			want: agentMenu{
+				Question: "Select model",
+				Options:  []approvalOption{{Label: "Default (recommended)", Send: "1"}, {Label: "Opus", Send: "2"}, {Label: "Haiku", Send: "3"}},
+				Selected: 1,
+			},
+			ok: true,
+		},
+		{
+			name:  "inline options",
+			lines: []string{"Proceed?", "1. Yes  2. No"},
+			want: agentMenu{
+				Question: "Proceed?",
+				Options:  []approvalOption{{Label: "Yes", Send: "1"}, {Label: "No", Send: "2"}},
+			},
+			ok: true,
+		},
+		{
+			name:  "single option is not a menu",
+			lines: []string{"1. Install dependencies"},
+			ok:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseMenu(tt.lines)
+			if ok != tt.ok {
+				t.Fatalf("parseMenu() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMenu() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewApprovalPromptUsesMenu(t *testing.T) {
+	menu := &agentMenu{Question: "Pick", Options: []approvalOption{{Label: "A", Send: "1"}, {Label: "B", Send: "2"}}, Selected: 1}
+	a := newApprovalPrompt(session{WindowID: 4, Menu: menu})
+	if a == nil || a.question != "Pick" || a.selected != 1 || len(a.options) != 2 {
+		t.Errorf("newApprovalPrompt() = %+v", a)
+	}
+	if a := newApprovalPrompt(session{Lines: []string{"all done"}}); a != nil {
+		t.Errorf("newApprovalPrompt() = %+v, want nil", a)
+	}
+}
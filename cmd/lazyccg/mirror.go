@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A mirror is a read-only, full-size view of one session's output in a
+// window of its own, e.g. on a second monitor. lazyccg appends the output
+// of each capture to a file that a pager follows in the new window.
+
+// mirrorPager follows the mirror file; +F keeps less at the end until
+// Ctrl+C, after which it can be scrolled and searched.
+var mirrorPager = []string{"less", "-R", "+F"}
+
+type mirrorFile struct {
+	path string
+}
+
+// mirrorSet appends new output to the open mirror files.
+type mirrorSet struct {
+	mu   sync.Mutex
+	open map[int]*mirrorFile // windowID -> mirror
+}
+
+var mirrors = &mirrorSet{open: make(map[int]*mirrorFile)}
+
+// Start begins mirroring s and returns the file to follow. Mirroring a
+// session twice reuses its file.
+func (ms *mirrorSet) Start(s session) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if f := ms.open[s.WindowID]; f != nil {
+		return f.path, nil
+	}
+	f, err := os.CreateTemp("", fmt.Sprintf("%s%d-w%d-*.log", mirrorPrefix, os.Getpid(), s.WindowID))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := writeLines(f, s.Lines); err != nil {
+		return "", err
+	}
+	ms.open[s.WindowID] = &mirrorFile{path: f.Name()}
+	return f.Name(), nil
+}
+
+// Record appends what each mirrored session printed since the last poll.
+// A session that went away is noted in its mirror, which then stops.
+func (ms *mirrorSet) Record(events []sessionEvent) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for _, e := range events {
+		mf := ms.open[e.Session.WindowID]
+		if mf == nil {
+			continue
+		}
+		switch e.Kind {
+		case outputAppended:
+			appendMirror(mf.path, e.Lines)
+		case sessionRemoved:
+			appendMirror(mf.path, []string{"", "--- session closed ---"})
+			delete(ms.open, e.Session.WindowID)
+		}
+	}
+}
+
+// Close removes the mirror files. Pagers that have them open keep reading.
+func (ms *mirrorSet) Close() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for id, mf := range ms.open {
+		os.Remove(mf.path)
+		delete(ms.open, id)
+	}
+}
+
+func appendMirror(path string, lines []string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	writeLines(f, lines)
+}
+
+func writeLines(f *os.File, lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := f.WriteString(strings.Join(lines, "\n") + "\n")
+	return err
+}
+
+// windowLauncher is implemented by backends that can open a new window
+// running a command.
+type windowLauncher interface {
+	Launch(title string, argv []string) error
+}
+
+// launcherFor returns the launcher for the terminal windowID lives in. With
+// local, only a terminal on this machine will do.
+func launcherFor(b Backend, windowID int, local bool) (windowLauncher, bool) {
+	switch b := b.(type) {
+	case nestedTmuxBackend:
+		if windowID >= nestedIDBase {
+			return b.tmux, true
+		}
+		return launcherFor(b.Backend, windowID, local)
+	case multiBackend:
+		inner, id, err := b.instance(windowID)
+		if err != nil {
+			return nil, false
+		}
+		return launcherFor(inner, id, local)
+	case windowLauncher:
+		if local && backendRemote(b.(Backend)) != "" {
+			return nil, false
+		}
+		return b, true
+	}
+	return nil, false
+}
+
+type mirrorStartedMsg struct {
+	title string
+	err   error
+}
+
+// mirrorCmd opens a window following s's output.
+func mirrorCmd(s session) tea.Cmd {
+	return func() tea.Msg {
+		// The mirror file is on this machine
+		launcher, ok := launcherFor(backend, s.WindowID, true)
+		if !ok {
+			return mirrorStartedMsg{err: errors.New("this terminal can't open a mirror window")}
+		}
+		path, err := mirrors.Start(s)
+		if err != nil {
+			return mirrorStartedMsg{err: err}
+		}
+		title := "mirror: " + s.Title
+		if err := launcher.Launch(title, append(append([]string{}, mirrorPager...), path)); err != nil {
+			return mirrorStartedMsg{err: err}
+		}
+		return mirrorStartedMsg{title: s.Title}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMirrorRecord(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	ms := &mirrorSet{open: make(map[int]*mirrorFile)}
+	path, err := ms.Start(session{WindowID: 1, Lines: []string{"a", "b", "> "}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := ms.Start(session{WindowID: 1}); again != path {
+		t.Errorf("second Start() = %s, want %s", again, path)
+	}
+
+	prev := []session{{WindowID: 1, Lines: []string{"a", "b", "> "}}}
+	next := []session{{WindowID: 1, Lines: []string{"a", "b", "c", "> "}}, {WindowID: 2, Lines: []string{"x"}}}
+	ms.Record(diffSessions(prev, next))
+	ms.Record(diffSessions(next, nil))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a\nb\n> \nc\n\n--- session closed ---\n"
+	if string(data) != want {
+		t.Errorf("mirror file = %q, want %q", data, want)
+	}
+	if len(ms.open) != 0 {
+		t.Error("closed session is still mirrored")
+	}
+}
+
+func TestLauncherFor(t *testing.T) {
+	if _, ok := launcherFor(withNestedTmux(kittyBackend{}), 3, true); !ok {
+		t.Error("kitty should launch mirrors")
+	}
+	if _, ok := launcherFor(tmuxBackend{run: commandRunner{host: "devbox"}}, 3, true); ok {
+		t.Error("remote tmux can't follow a local mirror file")
+	}
+	if _, ok := launcherFor(weztermBackend{}, 3, true); ok {
+		t.Error("wezterm has no launcher")
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mockBackend serves synthetic sessions from a fixture file, so the UI and
+// status inference can be worked on without a terminal full of agents.
+//
+// The fixture holds a `kitty @ ls` style window list and, per window ID, a
+// scripted output stream: frames shown one after another, each for its hold
+// time. Once the last frame is reached the stream stays there, or starts
+// over when loop is set. The screen is the current frame; the scrollback is
+// every frame shown so far.
+type mockBackend struct {
+	windows []kittyOSWindow
+	streams map[int]mockStream
+	start   time.Time
+	now     func() time.Time
+
+	mu     *sync.Mutex
+	titles map[int]string   // renamed windows
+	typed  map[int][]string // text sent to each window
+}
+
+type mockFixture struct {
+	Windows []kittyOSWindow       `json:"windows"`
+	Output  map[string]mockStream `json:"output"` // keyed by window ID
+}
+
+type mockStream struct {
+	Frames []mockFrame `json:"frames"`
+	Loop   bool        `json:"loop"`
+}
+
+type mockFrame struct {
+	Lines []string `json:"lines"`
+	Hold  string   `json:"hold"` // e.g. "3s"; defaults to mockFrameHold
+	hold  time.Duration
+}
+
+const mockFrameHold = 2 * time.Second
+
+// mockFile is the fixture read by `-backend mock`.
+var mockFile string
+
+func loadMockBackend(path string) (mockBackend, error) {
+	if path == "" {
+		return mockBackend{}, fmt.Errorf("the mock backend needs a fixture: -mock-file path")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mockBackend{}, err
+	}
+	m, err := parseMockFixture(data)
+	if err != nil {
+		return mockBackend{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func parseMockFixture(data []byte) (mockBackend, error) {
+	var f mockFixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return mockBackend{}, err
+	}
+	streams := make(map[int]mockStream, len(f.Output))
+	for key, s := range f.Output {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return mockBackend{}, fmt.Errorf("output %q: window IDs must be numbers", key)
+		}
+		for i := range s.Frames {
+			s.Frames[i].hold = mockFrameHold
+			if s.Frames[i].Hold == "" {
+				continue
+			}
+			d, err := time.ParseDuration(s.Frames[i].Hold)
+			if err != nil || d <= 0 {
+				return mockBackend{}, fmt.Errorf("output %q frame %d: bad hold %q", key, i+1, s.Frames[i].Hold)
+			}
+			s.Frames[i].hold = d
+		}
+		streams[id] = s
+	}
+	return mockBackend{
+		windows: f.Windows,
+		streams: streams,
+		start:   time.Now(),
+		now:     time.Now,
+		mu:      &sync.Mutex{},
+		titles:  make(map[int]string),
+		typed:   make(map[int][]string),
+	}, nil
+}
+
+func (mockBackend) Name() string { return "mock" }
+
+func (m mockBackend) List() ([]kittyOSWindow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	osWindows := make([]kittyOSWindow, len(m.windows))
+	for i, ow := range m.windows {
+		osWindows[i] = ow
+		osWindows[i].Tabs = make([]kittyTab, len(ow.Tabs))
+		for j, tab := range ow.Tabs {
+			osWindows[i].Tabs[j] = tab
+			osWindows[i].Tabs[j].Windows = make([]kittyWindow, len(tab.Windows))
+			for k, win := range tab.Windows {
+				if title, ok := m.titles[win.ID]; ok {
+					win.Title = title
+				}
+				osWindows[i].Tabs[j].Windows[k] = win
+			}
+		}
+	}
+	return osWindows, nil
+}
+
+// frame returns the index of the frame showing at elapsed.
+func (s mockStream) frame(elapsed time.Duration) int {
+	var total time.Duration
+	for _, f := range s.Frames {
+		total += f.hold
+	}
+	if s.Loop && total > 0 {
+		elapsed %= total
+	}
+	for i, f := range s.Frames {
+		if elapsed < f.hold {
+			return i
+		}
+		elapsed -= f.hold
+	}
+	return len(s.Frames) - 1
+}
+
+func (m mockBackend) CaptureText(windowID int, extent string) (string, error) {
+	var lines []string
+	if s, ok := m.streams[windowID]; ok && len(s.Frames) > 0 {
+		cur := s.frame(m.now().Sub(m.start))
+		if extent == "all" {
+			for _, f := range s.Frames[:cur] {
+				lines = append(lines, f.Lines...)
+			}
+		}
+		lines = append(lines, s.Frames[cur].Lines...)
+	}
+	m.mu.Lock()
+	lines = append(lines, m.typed[windowID]...)
+	m.mu.Unlock()
+	return strings.Join(lines, "\n"), nil
+}
+
+func (mockBackend) Focus(windowID int) error { return nil }
+
+func (m mockBackend) Rename(windowID int, title string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.titles[windowID] = title
+	return nil
+}
+
+// SendText echoes the text below the window's output, as a terminal would.
+func (m mockBackend) SendText(windowID int, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(text, "\r\n"), "\n") {
+		m.typed[windowID] = append(m.typed[windowID], "> "+strings.TrimRight(line, "\r"))
+	}
+	return nil
+}
+
+func (mockBackend) Scroll(windowID, lines int) error { return nil }
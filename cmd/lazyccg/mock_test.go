@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMockBackend(t *testing.T) {
+	m, err := loadMockBackend("testdata/mock/sessions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var elapsed time.Duration
+	m.now = func() time.Time { return m.start.Add(elapsed) }
+
+	osWindows, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(osWindows[0].Tabs); n != 2 {
+		t.Fatalf("List() returned %d tabs, want 2", n)
+	}
+
+	tests := []struct {
+		id      int
+		elapsed time.Duration
+		extent  string
+		want    string
+	}{
+		{1, 0, "", "> add retries to the client\nReading files...\nRunning go test ./..."},
+		{1, 4 * time.Second, "", "Edit client.go?\nWaiting for approval (y/n)"},
+		{1, 13 * time.Second, "", "> add retries to the client\nReading files...\nRunning go test ./..."}, // looped
+		{2, time.Minute, "", "Worked for 12s\n? for shortcuts"},                                           // stays on the last frame
+		{2, time.Minute, "all", "Processing request...\nWorked for 12s\n? for shortcuts"},
+		{3, 0, "", ""},
+	}
+	for _, tt := range tests {
+		elapsed = tt.elapsed
+		got, err := m.CaptureText(tt.id, tt.extent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("CaptureText(%d, %q) at %s = %q, want %q", tt.id, tt.extent, tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestMockBackendInteraction(t *testing.T) {
+	m, err := loadMockBackend("testdata/mock/sessions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = func() time.Time { return m.start.Add(5 * time.Second) }
+
+	if err := m.Rename(1, "retries"); err != nil {
+		t.Fatal(err)
+	}
+	osWindows, _ := m.List()
+	if got := osWindows[0].Tabs[0].Windows[0].Title; got != "retries" {
+		t.Errorf("title after Rename = %q, want retries", got)
+	}
+	if m.windows[0].Tabs[0].Windows[0].Title != "api" {
+		t.Error("List should not modify the fixture")
+	}
+
+	m.SendText(1, "y\r")
+	text, _ := m.CaptureText(1, "")
+	if !strings.HasSuffix(text, "\n> y") {
+		t.Errorf("CaptureText after SendText = %q, want the typed text echoed", text)
+	}
+}
+
+func TestParseMockFixtureErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"output": {"one": {"frames": []}}}`,
+		`{"output": {"1": {"frames": [{"hold": "soon"}]}}}`,
+		`{"windows": {}}`,
+	} {
+		if _, err := parseMockFixture([]byte(data)); err == nil {
+			t.Errorf("parseMockFixture(%s) should fail", data)
+		}
+	}
+	if _, err := loadMockBackend(""); err == nil {
+		t.Error("loadMockBackend without a path should fail")
+	}
+}
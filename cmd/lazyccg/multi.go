@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// multiBackend merges several terminals, e.g. kitty instances or SSH hosts.
+// Window and tab IDs are only unique within an instance, so each instance's
+// IDs are offset by instance index × instanceStride.
+type multiBackend struct {
+	instances []Backend
+	names     []string // shown on each session row
+}
+
+const instanceStride = 1 << 20
+
+func newMultiKittyBackend(sockets []string) multiBackend {
+	var m multiBackend
+	for _, socket := range sockets {
+		k := newKittyBackend(socket)
+		// A socket that goes away is one of several; don't move onto another
+		k.follow = false
+		m.instances = append(m.instances, k)
+		m.names = append(m.names, instanceName(socket))
+	}
+	return m
+}
+
+// instanceName shortens a socket address to a row tag, e.g.
+// unix:/tmp/kitty-4242 -> 4242.
+func instanceName(socket string) string {
+	_, address, _ := strings.Cut(socket, ":")
+	name := strings.TrimPrefix(filepath.Base(address), "kitty-")
+	if name == "" {
+		return socket
+	}
+	return name
+}
+
+// discoverKittySockets returns the kitty sockets in /tmp, as created by
+// `listen_on unix:/tmp/kitty` (kitty appends -PID when several run).
+func discoverKittySockets() []string {
+	paths, _ := filepath.Glob(filepath.Join(os.TempDir(), "kitty*"))
+	var sockets []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			sockets = append(sockets, "unix:"+path)
+		}
+	}
+	return sockets
+}
+
+// Name lists the distinct terminals, e.g. "kitty" or "kitty+tmux".
+func (m multiBackend) Name() string {
+	var names []string
+	for _, b := range m.instances {
+		names = appendUnique(names, b.Name())
+	}
+	return strings.Join(names, "+")
+}
+
+// Remote lists the remote instances, if any.
+func (m multiBackend) Remote() string {
+	var remotes []string
+	for _, b := range m.instances {
+		if r := backendRemote(b); r != "" {
+			remotes = append(remotes, r)
+		}
+	}
+	return strings.Join(remotes, ",")
+}
+
+func (m multiBackend) List() ([]kittyOSWindow, error) {
+	var all []kittyOSWindow
+	var errs []error
+	for i, b := range m.instances {
+		label := m.names[i]
+		if name := b.Name(); name != label {
+			label = name + " " + label
+		}
+		if !health.due(label, time.Now()) {
+			// Failing; don't hold up the others by polling it every time
+			errs = append(errs, fmt.Errorf("%s: backing off", m.names[i]))
+			continue
+		}
+		osWindows, err := b.List()
+		health.record(label, err, time.Now())
+		if err != nil {
+			// One instance going away shouldn't hide the others
+			errs = append(errs, fmt.Errorf("%s: %w", m.names[i], err))
+			if debugLog != nil {
+				fmt.Fprintf(debugLog, "[%s] instance %s: %v\n", time.Now().Format("15:04:05"), m.names[i], err)
+			}
+			continue
+		}
+		offset := i * instanceStride
+		for _, ow := range osWindows {
+			ow.Instance = m.names[i]
+			for t := range ow.Tabs {
+				ow.Tabs[t].ID += offset
+				for w := range ow.Tabs[t].Windows {
+					ow.Tabs[t].Windows[w].ID += offset
+				}
+			}
+			all = append(all, ow)
+		}
+	}
+	if len(errs) == len(m.instances) {
+		return nil, errors.Join(errs...)
+	}
+	return all, nil
+}
+
+// instance returns the backend owning a merged window ID and its local ID.
+func (m multiBackend) instance(windowID int) (Backend, int, error) {
+	i := windowID / instanceStride
+	if i < 0 || i >= len(m.instances) {
+		return nil, 0, fmt.Errorf("no instance for window %d", windowID)
+	}
+	return m.instances[i], windowID % instanceStride, nil
+}
+
+func (m multiBackend) CaptureText(windowID int, extent string) (string, error) {
+	k, id, err := m.instance(windowID)
+	if err != nil {
+		return "", err
+	}
+	return k.CaptureText(id, extent)
+}
+
+func (m multiBackend) CaptureWithCursor(windowID int) (string, screenCursor, error) {
+	k, id, err := m.instance(windowID)
+	if err != nil {
+		return "", screenCursor{}, err
+	}
+	if c, ok := k.(cursorBackend); ok {
+		return c.CaptureWithCursor(id)
+	}
+	text, err := k.CaptureText(id, "")
+	return text, screenCursor{}, err
+}
+
+func (m multiBackend) Focus(windowID int) error {
+	k, id, err := m.instance(windowID)
+	if err != nil {
+		return err
+	}
+	return k.Focus(id)
+}
+
+func (m multiBackend) Rename(windowID int, title string) error {
+	k, id, err := m.instance(windowID)
+	if err != nil {
+		return err
+	}
+	return k.Rename(id, title)
+}
+
+func (m multiBackend) SendText(windowID int, text string) error {
+	k, id, err := m.instance(windowID)
+	if err != nil {
+		return err
+	}
+	return k.SendText(id, text)
+}
+
+func (m multiBackend) Scroll(windowID, lines int) error {
+	k, id, err := m.instance(windowID)
+	if err != nil {
+		return err
+	}
+	return k.Scroll(id, lines)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/atani/lazyccg/pkg/kitty"
+)
+
+func TestInstanceName(t *testing.T) {
+	tests := map[string]string{
+		"unix:/tmp/kitty-4242": "4242",
+		"unix:/tmp/work":       "work",
+		"tcp:host:5000":        "host:5000",
+	}
+	for socket, want := range tests {
+		if got := instanceName(socket); got != want {
+			t.Errorf("instanceName(%q) = %q, want %q", socket, got, want)
+		}
+	}
+}
+
+func TestMultiKittyBackend(t *testing.T) {
+	var focused []string
+	serve := func(name string) string {
+		return serveKittyRC(t, func(req kitty.Request) kitty.Response {
+			switch req.Cmd {
+			case "ls":
+				data, _ := json.Marshal(`[{"tabs":[{"id":1,"windows":[{"id":1,"title":"` + name + `"}]}]}]`)
+				return kitty.Response{OK: true, Data: data}
+			case "focus-window":
+				focused = append(focused, name)
+				return kitty.Response{OK: true}
+			}
+			return kitty.Response{Error: "unexpected " + req.Cmd}
+		})
+	}
+	m := newMultiKittyBackend([]string{serve("a"), serve("b")})
+
+	osWindows, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(osWindows) != 2 {
+		t.Fatalf("List() returned %d OS windows, want 2", len(osWindows))
+	}
+	second := osWindows[1].Tabs[0].Windows[0]
+	if second.ID == osWindows[0].Tabs[0].Windows[0].ID {
+		t.Fatal("window IDs from different instances collide")
+	}
+	if err := m.Focus(second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(focused) != 1 || focused[0] != "b" {
+		t.Errorf("focus went to %v, want [b]", focused)
+	}
+}
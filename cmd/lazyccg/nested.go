@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// nestedTmuxBackend finds agents running inside tmux in another terminal.
+// When a window's foreground process is a tmux client, the panes of the
+// session it is attached to are listed as child windows of it. Their IDs
+// start at nestedIDBase so they can't collide with the terminal's own.
+type nestedTmuxBackend struct {
+	Backend
+	tmux tmuxBackend
+	ids  *windowIDs // "parent:pane" -> ID - nestedIDBase
+}
+
+const nestedIDBase = 1 << 30
+
+func withNestedTmux(b Backend) Backend {
+	return nestedTmuxBackend{Backend: b, ids: newWindowIDs()}
+}
+
+func (n nestedTmuxBackend) Remote() string { return backendRemote(n.Backend) }
+
+// runsTmux returns the pids of a window's tmux client processes.
+func runsTmux(win kittyWindow) []int {
+	var pids []int
+	for _, proc := range win.ForegroundProcesses {
+		if len(proc.Cmdline) > 0 && filepath.Base(proc.Cmdline[0]) == "tmux" {
+			pids = append(pids, proc.Pid)
+		}
+	}
+	return pids
+}
+
+func (n nestedTmuxBackend) List() ([]kittyOSWindow, error) {
+	osWindows, err := n.Backend.List()
+	if err != nil {
+		return nil, err
+	}
+	var clients map[int]string
+	var procs *processTable
+	for o := range osWindows {
+		for t := range osWindows[o].Tabs {
+			tab := &osWindows[o].Tabs[t]
+			var windows []kittyWindow
+			for _, win := range tab.Windows {
+				windows = append(windows, win)
+				pids := runsTmux(win)
+				if len(pids) == 0 {
+					continue
+				}
+				if clients == nil {
+					clients = n.clients()
+				}
+				var session string
+				for _, pid := range pids {
+					if s, ok := clients[pid]; ok {
+						session = s
+					}
+				}
+				if session == "" {
+					continue
+				}
+				if procs == nil {
+					ps, err := runPs(n.tmux.run, "-A", "-o", "pid=,ppid=,args=")
+					if err != nil {
+						return osWindows, nil
+					}
+					table := parseProcessTable(string(ps))
+					procs = &table
+				}
+				windows = append(windows, n.children(win, session, *procs)...)
+			}
+			tab.Windows = windows
+		}
+	}
+	return osWindows, nil
+}
+
+// clients maps tmux client pids to the session each is attached to.
+func (n nestedTmuxBackend) clients() map[int]string {
+	clients := make(map[int]string)
+	out, err := n.tmux.run.command("tmux", "list-clients", "-F", "#{client_pid}\t#{session_name}").Output()
+	if err != nil {
+		return clients // no tmux server
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		pid, session, ok := strings.Cut(line, "\t")
+		if id, err := strconv.Atoi(pid); ok && err == nil {
+			clients[id] = session
+		}
+	}
+	return clients
+}
+
+// children lists the panes of session as windows nested in parent.
+func (n nestedTmuxBackend) children(parent kittyWindow, session string, procs processTable) []kittyWindow {
+	out, err := n.tmux.run.command("tmux", "list-panes", "-s", "-t", session, "-F", tmuxPaneFormat).Output()
+	if err != nil {
+		return nil
+	}
+	var children []kittyWindow
+	for _, ow := range parseTmuxPanes(string(out), procs) {
+		for _, tab := range ow.Tabs {
+			for _, pane := range tab.Windows {
+				child := pane
+				child.ID = nestedIDBase + n.ids.id(fmt.Sprintf("%d:%d", parent.ID, pane.ID))
+				child.Parent = parent.ID
+				child.Nested = session
+				if child.Title == "" {
+					child.Title = tab.Title
+				}
+				children = append(children, child)
+			}
+		}
+	}
+	return children
+}
+
+// pane returns the parent window and tmux pane behind a nested window ID.
+func (n nestedTmuxBackend) pane(windowID int) (int, int, error) {
+	key, err := n.ids.key(windowID - nestedIDBase)
+	if err != nil {
+		return 0, 0, err
+	}
+	parent, pane, _ := strings.Cut(key, ":")
+	parentID, err1 := strconv.Atoi(parent)
+	paneID, err2 := strconv.Atoi(pane)
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("bad nested window %q", key)
+	}
+	return parentID, paneID, nil
+}
+
+func (n nestedTmuxBackend) CaptureText(windowID int, extent string) (string, error) {
+	if windowID < nestedIDBase {
+		return n.Backend.CaptureText(windowID, extent)
+	}
+	_, pane, err := n.pane(windowID)
+	if err != nil {
+		return "", err
+	}
+	return n.tmux.CaptureText(pane, extent)
+}
+
+// CaptureWithCursor captures the terminal's own windows with their cursor;
+// panes in tmux are captured without.
+func (n nestedTmuxBackend) CaptureWithCursor(windowID int) (string, screenCursor, error) {
+	if c, ok := n.Backend.(cursorBackend); ok && windowID < nestedIDBase {
+		return c.CaptureWithCursor(windowID)
+	}
+	text, err := n.CaptureText(windowID, "")
+	return text, screenCursor{}, err
+}
+
+// Focus focuses the terminal window running tmux, then the pane in it.
+func (n nestedTmuxBackend) Focus(windowID int) error {
+	if windowID < nestedIDBase {
+		return n.Backend.Focus(windowID)
+	}
+	parent, pane, err := n.pane(windowID)
+	if err != nil {
+		return err
+	}
+	if err := n.Backend.Focus(parent); err != nil {
+		return err
+	}
+	return n.tmux.Focus(pane)
+}
+
+func (n nestedTmuxBackend) Rename(windowID int, title string) error {
+	if windowID < nestedIDBase {
+		return n.Backend.Rename(windowID, title)
+	}
+	_, pane, err := n.pane(windowID)
+	if err != nil {
+		return err
+	}
+	return n.tmux.Rename(pane, title)
+}
+
+func (n nestedTmuxBackend) SendText(windowID int, text string) error {
+	if windowID < nestedIDBase {
+		return n.Backend.SendText(windowID, text)
+	}
+	_, pane, err := n.pane(windowID)
+	if err != nil {
+		return err
+	}
+	return n.tmux.SendText(pane, text)
+}
+
+func (n nestedTmuxBackend) Scroll(windowID, lines int) error {
+	if windowID < nestedIDBase {
+		return n.Backend.Scroll(windowID, lines)
+	}
+	_, pane, err := n.pane(windowID)
+	if err != nil {
+		return err
+	}
+	return n.tmux.Scroll(pane, lines)
+}
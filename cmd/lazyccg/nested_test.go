@@ -0,0 +1,36 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunsTmux(t *testing.T) {
+	win := kittyWindow{ForegroundProcesses: []foregroundProcess{
+		{Pid: 10, Cmdline: []string{"-zsh"}},
+		{Pid: 11, Cmdline: []string{"/opt/homebrew/bin/tmux", "attach", "-t", "work"}},
+	}}
+	if got := runsTmux(win); !reflect.DeepEqual(got, []int{11}) {
+		t.Errorf("runsTmux() = %v, want [11]", got)
+	}
+	if got := runsTmux(kittyWindow{ForegroundProcesses: []foregroundProcess{{Pid: 1, Cmdline: []string{"claude"}}}}); got != nil {
+		t.Errorf("runsTmux() = %v, want none", got)
+	}
+}
+
+func TestNestedPane(t *testing.T) {
+	n := withNestedTmux(kittyBackend{}).(nestedTmuxBackend)
+	id := nestedIDBase + n.ids.id("4:12")
+	parent, pane, err := n.pane(id)
+	if err != nil || parent != 4 || pane != 12 {
+		t.Errorf("pane(%d) = %d, %d, %v; want 4, 12", id, parent, pane, err)
+	}
+	if _, _, err := n.pane(nestedIDBase + 99); err == nil {
+		t.Error("unknown nested window should fail")
+	}
+
+	link, ok := deepLink(n, id)
+	if !ok || link.URL != "lazyccg://focus?backend=tmux&window=12" {
+		t.Errorf("deepLink() = %+v, %v", link, ok)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// v pipes the selected session's whole scrollback into a pager, for its
+// search and navigation: the pager in the config, else $PAGER, else less.
+// It takes over lazyccg's terminal until it quits, or with pager_window
+// opens in a window of its own.
+//
+//	pager: bat --paging=always --plain -l log
+//	pager: fzf --tac --no-sort
+//	pager_window: true
+
+// defaultPager keeps the capture's colors.
+const defaultPager = "less -R"
+
+// pagerCommand is the shell command the capture is piped into.
+func pagerCommand() string {
+	if cfg.Pager != "" {
+		return cfg.Pager
+	}
+	if pager := os.Getenv("PAGER"); pager != "" {
+		return pager
+	}
+	return defaultPager
+}
+
+type pagerTextMsg struct {
+	windowID int
+	title    string
+	text     string
+	err      error
+}
+
+type pagerClosedMsg struct {
+	err error
+}
+
+// pagerCaptureCmd captures s's whole scrollback for the pager.
+func pagerCaptureCmd(s session) tea.Cmd {
+	return func() tea.Msg {
+		text, err := backend.CaptureText(s.WindowID, "all")
+		if err != nil {
+			return pagerTextMsg{err: err}
+		}
+		return pagerTextMsg{windowID: s.WindowID, title: s.Title, text: strings.ReplaceAll(text, "\r\n", "\n")}
+	}
+}
+
+// pagerCmd shows text in the pager, suspending the dashboard until it
+// exits.
+func pagerCmd(text string) tea.Cmd {
+	cmd := exec.Command("sh", "-c", pagerCommand())
+	cmd.Stdin = strings.NewReader(text)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return pagerClosedMsg{err: err}
+	})
+}
+
+// pagerWindowCmd shows text in the pager in a new window, from a
+// temporary file the window removes when the pager exits.
+func pagerWindowCmd(title, text string, windowID int) tea.Cmd {
+	return func() tea.Msg {
+		// The file is on this machine
+		launcher, ok := launcherFor(backend, windowID, true)
+		if !ok {
+			return pagerClosedMsg{err: errors.New("this terminal can't open a pager window")}
+		}
+		f, err := os.CreateTemp("", "lazyccg-pager-*.log")
+		if err != nil {
+			return pagerClosedMsg{err: err}
+		}
+		_, err = f.WriteString(text)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(f.Name())
+			return pagerClosedMsg{err: err}
+		}
+		path := shellQuote(f.Name())
+		script := pagerCommand() + " < " + path + "; rm -f " + path
+		if err := launcher.Launch("pager: "+title, []string{"sh", "-c", script}); err != nil {
+			os.Remove(f.Name())
+			return pagerClosedMsg{err: err}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestPagerCommand(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+
+	t.Setenv("PAGER", "")
+	cfg.Pager = ""
+	if got := pagerCommand(); got != defaultPager {
+		t.Errorf("pagerCommand() = %q with nothing set, want %q", got, defaultPager)
+	}
+	t.Setenv("PAGER", "most")
+	if got := pagerCommand(); got != "most" {
+		t.Errorf("pagerCommand() = %q, want $PAGER", got)
+	}
+	cfg.Pager = "bat --paging=always"
+	if got := pagerCommand(); got != "bat --paging=always" {
+		t.Errorf("pagerCommand() = %q, want the config's pager over $PAGER", got)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// psBackend finds agents from the process list alone, for when no supported
+// terminal is available. Each tty becomes a window. Without a terminal to
+// ask, output can't be captured, so sessions show as UNKNOWN, and windows
+// can't be focused, renamed, or typed into.
+type psBackend struct {
+	ids *windowIDs
+}
+
+func newPsBackend() psBackend {
+	return psBackend{ids: newWindowIDs()}
+}
+
+// errNoCapture is returned by backends that can't read window text.
+var errNoCapture = errors.New("output capture is not supported by this backend")
+
+var errPsUnsupported = errors.New("not supported by the ps backend")
+
+func (psBackend) Name() string { return "ps" }
+
+// Limitations says what the dashboard can't do with this backend.
+func (psBackend) Limitations() string { return "process list only: no output, focus, or rename" }
+
+func (b psBackend) List() ([]kittyOSWindow, error) {
+	out, err := runPs(commandRunner{}, "-A", "-o", "tty=,pid=,args=")
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	return []kittyOSWindow{{Tabs: b.tabs(parseTTYProcesses(string(out)), processCwd)}}, nil
+}
+
+// tabs makes a tab holding one window per tty.
+func (b psBackend) tabs(byTTY map[string][]foregroundProcess, cwd func(pid int) string) []kittyTab {
+	ttys := make([]string, 0, len(byTTY))
+	for tty := range byTTY {
+		ttys = append(ttys, tty)
+	}
+	sort.Strings(ttys)
+	var tabs []kittyTab
+	for _, tty := range ttys {
+		id := b.ids.id(tty)
+		win := kittyWindow{ID: id, Title: tty}
+		for _, proc := range byTTY[tty] {
+			proc.Cwd = cwd(proc.Pid)
+			if proc.Cwd != "" {
+				win.Cwd = proc.Cwd
+			}
+			win.ForegroundProcesses = append(win.ForegroundProcesses, proc)
+		}
+		tabs = append(tabs, kittyTab{ID: id, Title: tty, Windows: []kittyWindow{win}})
+	}
+	return tabs
+}
+
+// processCwd returns a process's working directory where /proc has it.
+func processCwd(pid int) string {
+	cwd, _ := os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid))
+	return cwd
+}
+
+func (psBackend) CaptureText(windowID int, extent string) (string, error) {
+	return "", errNoCapture
+}
+
+func (psBackend) Focus(windowID int) error { return errPsUnsupported }
+
+func (psBackend) Rename(windowID int, title string) error { return errPsUnsupported }
+
+func (psBackend) SendText(windowID int, text string) error { return errPsUnsupported }
+
+func (psBackend) Scroll(windowID, lines int) error { return errPsUnsupported }
+
+// limitedBackend is implemented by backends that can't do everything.
+type limitedBackend interface {
+	// Limitations describes what is missing, for the Sessions title.
+	Limitations() string
+}
+
+// backendLimitations returns what b can't do, or "".
+func backendLimitations(b Backend) string {
+	if nested, ok := b.(nestedTmuxBackend); ok {
+		b = nested.Backend
+	}
+	if l, ok := b.(limitedBackend); ok {
+		return l.Limitations()
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+)
+
+func TestPsBackendTabs(t *testing.T) {
+	b := newPsBackend()
+	byTTY := parseTTYProcesses("pts/3 100 -zsh\npts/3 101 node /usr/local/bin/gemini\npts/1 50 -bash\n")
+	cwds := map[int]string{101: "/src/api"}
+	tabs := b.tabs(byTTY, func(pid int) string { return cwds[pid] })
+
+	if len(tabs) != 2 || tabs[0].Title != "pts/1" || tabs[1].Title != "pts/3" {
+		t.Fatalf("unexpected tabs: %+v", tabs)
+	}
+	win := tabs[1].Windows[0]
+	if win.Cwd != "/src/api" {
+		t.Errorf("Cwd = %q, want /src/api", win.Cwd)
+	}
+	if ai, ok := agentsession.DetectAI(win, []string{"gemini"}); !ok || ai != "gemini" {
+		t.Errorf("agentsession.DetectAI() = %q, %v; want gemini", ai, ok)
+	}
+
+	again := b.tabs(byTTY, func(int) string { return "" })
+	if again[1].Windows[0].ID != win.ID {
+		t.Error("window ID changed between polls")
+	}
+	if _, err := b.CaptureText(win.ID, ""); err != errNoCapture {
+		t.Errorf("CaptureText() error = %v, want errNoCapture", err)
+	}
+	if backendLimitations(withNestedTmux(b)) == "" {
+		t.Error("ps backend should report its limitations")
+	}
+	if backendLimitations(kittyBackend{}) != "" {
+		t.Error("kitty has no limitations")
+	}
+}
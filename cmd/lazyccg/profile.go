@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	"gopkg.in/yaml.v3"
+)
+
+// profile teaches lazyccg about an agent without code changes. Profiles are
+// YAML files in the plugins dir:
+//
+//	name: aider
+//	processes: [aider, aider-chat]
+//	rules:
+//	  - status: WAITING
+//	    match: '\(Y\)es/\(N\)o'
+//	  - status: IDLE
+//	    match: '^> $'
+//	    lookback: 1
+//
+// Rules are tried in order against the last lookback lines (default 10); the
+// first match sets the status, otherwise the built-in detection applies.
+type profile struct {
+	Name      string            `yaml:"name"`
+	Processes []string          `yaml:"processes"` // defaults to [name]
+	Rules     []profileRule     `yaml:"rules"`
+	Capture   string            `yaml:"capture"` // reads output instead of the screen
+	Command   []string          `yaml:"command"` // starts the agent; defaults to [name]
+	Env       map[string]string `yaml:"env"`     // set when lazyccg starts the agent
+	Hints     []string          `yaml:"hints"`   // shown under its output; [] shows none
+}
+
+type profileRule struct {
+	Status   agentstatus.Status `yaml:"status"`
+	Match    string             `yaml:"match"` // case-insensitive regexp
+	Lookback int                `yaml:"lookback"`
+	re       *regexp.Regexp
+}
+
+const defaultProfileLookback = 10
+
+// profileSet holds the loaded profiles by agent name.
+type profileSet struct {
+	byName    map[string]*profile
+	byProcess map[string]string // process name -> agent name
+}
+
+var profiles profileSet
+
+func pluginsDir() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "plugins")
+}
+
+// parseProfile parses and validates one profile.
+func parseProfile(data []byte) (*profile, error) {
+	var p profile
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+	if p.Name == "" {
+		return nil, errors.New("profile has no name")
+	}
+	if len(p.Processes) == 0 {
+		p.Processes = []string{p.Name}
+	}
+	for i := range p.Processes {
+		p.Processes[i] = strings.ToLower(strings.TrimSpace(p.Processes[i]))
+	}
+	for i := range p.Rules {
+		if err := p.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return &p, nil
+}
+
+// compile validates r and compiles its pattern.
+func (r *profileRule) compile() error {
+	if r.Status == "" {
+		return errors.New("no status")
+	}
+	r.Status = agentstatus.Parse(r.Status.String())
+	re, err := regexp.Compile("(?i)" + r.Match)
+	if err != nil {
+		return err
+	}
+	r.re = re
+	if r.Lookback <= 0 {
+		r.Lookback = defaultProfileLookback
+	}
+	return nil
+}
+
+// matches reports whether any of the last Lookback lines matches r.
+func (r profileRule) matches(lines []string) bool {
+	_, ok := r.matchingLine(lines)
+	return ok
+}
+
+// matchingLine returns the last of the last Lookback lines that matches r.
+func (r profileRule) matchingLine(lines []string) (string, bool) {
+	start := max(len(lines)-r.Lookback, 0)
+	for i := len(lines) - 1; i >= start; i-- {
+		if r.re.MatchString(lines[i]) {
+			return lines[i], true
+		}
+	}
+	return "", false
+}
+
+// why says that r matched line, e.g. `/esc to interrupt/ matched "Working…"`.
+func (r profileRule) why(line string) string {
+	return fmt.Sprintf("/%s/ matched %q", r.Match, strings.TrimSpace(line))
+}
+
+// loadProfiles reads every *.yaml and *.yml file in dir. A missing dir
+// yields no profiles.
+func loadProfiles(dir string) (profileSet, error) {
+	set := profileSet{byName: make(map[string]*profile), byProcess: make(map[string]string)}
+	if dir == "" {
+		return set, nil
+	}
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return set, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return set, err
+		}
+		p, err := parseProfile(data)
+		if err != nil {
+			return set, fmt.Errorf("profile %s: %w", filepath.Base(f), err)
+		}
+		set.byName[p.Name] = p
+		for _, proc := range p.Processes {
+			set.byProcess[proc] = p.Name
+		}
+	}
+	return set, nil
+}
+
+// prefixes adds the profiles' process names to the ones to detect.
+func (s profileSet) prefixes(base []string) []string {
+	out := append([]string{}, base...)
+	for proc := range s.byProcess {
+		out = appendUnique(out, proc)
+	}
+	return out
+}
+
+// agent maps a detected process name to its profile's agent name, or else
+// to the known agent it runs.
+func (s profileSet) agent(process string) string {
+	if name, ok := s.byProcess[process]; ok {
+		return name
+	}
+	return agents.name(process)
+}
+
+// hints returns the keys and commands to show under ai's output: its
+// profile's, or else the known agent's.
+func (s profileSet) hints(ai string) []string {
+	if p := s.byName[ai]; p != nil && p.Hints != nil {
+		return p.Hints
+	}
+	return agents.byName[ai].Hints
+}
+
+// capture returns ai's capture command, or "".
+func (s profileSet) capture(ai string) string {
+	if p := s.byName[ai]; p != nil {
+		return p.Capture
+	}
+	return ""
+}
+
+// detectStatus returns the status set by ai's profile rules and why, or "".
+func (s profileSet) detectStatus(ai string, lines []string) (agentstatus.Status, string) {
+	p := s.byName[ai]
+	if p == nil {
+		return "", ""
+	}
+	for i, r := range p.Rules {
+		if line, ok := r.matchingLine(lines); ok {
+			return r.Status, fmt.Sprintf("%s profile rule %d: %s", p.Name, i+1, r.why(line))
+		}
+	}
+	return "", ""
+}
+
+// detectStatus applies the config's status rules, then ai's profile,
+// falling back to ai's parser in the status package, and says where the
+// status came from, how sure it is, and why.
+func detectStatus(ai string, lines []string) (agentstatus.Status, agentstatus.Source, float64, string) {
+	lines = withoutIgnored(lines, cfg.ignoreRes)
+	if status, why := matchStatusRules(cfg.StatusRules, ai, lines); status != "" {
+		return status, agentstatus.SourceRule, agentstatus.Likely, why
+	}
+	if status, why := profiles.detectStatus(ai, lines); status != "" {
+		return status, agentstatus.SourceProfile, agentstatus.Likely, why
+	}
+	status, matched, why := agentstatus.ExplainAgentWithin(agents.parser(ai), lines, agents.lookback(ai))
+	if !matched {
+		return status, agentstatus.SourceOutput, agentstatus.Guess, why
+	}
+	return status, agentstatus.SourceOutput, agentstatus.Likely, ai + " parser, " + why
+}
+
+func loadPlugins() {
+	var err error
+	if profiles, err = loadProfiles(pluginsDir()); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load profiles:", err)
+	}
+}
+
+// runsCommands reports whether p runs commands of its own: a capture
+// command, or the command and environment the agent is launched with.
+func (p *profile) runsCommands() bool {
+	return p.Capture != "" || len(p.Command) > 0 || len(p.Env) > 0
+}
+
+// installProfile downloads the profile at url into dir, named after the
+// profile. A profile that runs commands is refused unless allowCommands,
+// since they run as the user. It returns the installed profile.
+func installProfile(url, dir string, allowCommands bool) (*profile, string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetch %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, "", err
+	}
+	p, err := parseProfile(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", url, err)
+	}
+	if strings.ContainsAny(p.Name, `/\`) || strings.HasPrefix(p.Name, ".") {
+		return nil, "", fmt.Errorf("%s: invalid profile name %q", url, p.Name)
+	}
+	if p.runsCommands() && !allowCommands {
+		return nil, "", fmt.Errorf("%s sets capture, command or env, which run as you; read it, then install it with -allow-commands", url)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(dir, p.Name+".yaml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, "", err
+	}
+	return p, path, nil
+}
+
+func runProfile(args []string) error {
+	const usage = "usage: lazyccg profile install <url> | list"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "install":
+		fs := flag.NewFlagSet("profile install", flag.ContinueOnError)
+		dir := fs.String("dir", pluginsDir(), "plugins directory")
+		allowCommands := fs.Bool("allow-commands", false, "install a profile that sets capture, command or env")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("usage: lazyccg profile install [-dir DIR] [-allow-commands] <url>")
+		}
+		p, path, err := installProfile(fs.Arg(0), *dir, *allowCommands)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("installed %s (%d rule(s)) to %s\n", p.Name, len(p.Rules), path)
+	case "list":
+		var names []string
+		for name := range profiles.byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			fmt.Println("no profiles in", pluginsDir())
+		}
+		for _, name := range names {
+			p := profiles.byName[name]
+			fmt.Printf("%s  processes=%s  rules=%d\n", p.Name, strings.Join(p.Processes, ","), len(p.Rules))
+		}
+	default:
+		return errors.New(usage)
+	}
+	return nil
+}
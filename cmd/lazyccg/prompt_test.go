@@ -0,0 +1,78 @@
+package main
+
+import "testing"
+
+func TestExtractPrompt(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{
+			name:  "claude prompt",
+			lines: []string{"> fix the flaky billing test", "● Reading files", "> "},
+			want:  "fix the flaky billing test",
+		},
+		{
+			name:  "codex prompt",
+			lines: []string{"› add a --json flag to list", "• Working", "› "},
+			want:  "add a --json flag to list",
+		},
+		{
+			name:  "only live input",
+			lines: []string{"output", "> typing something long"},
+			want:  "",
+		},
+		{
+			name:  "too short",
+			lines: []string{"> yes", "output"},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractPrompt(tt.lines); got != tt.want {
+				t.Errorf("extractPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkDuplicatePrompts(t *testing.T) {
+	sessions := []session{
+		{WindowID: 1, Prompt: "Fix the flaky billing test in api/"},
+		{WindowID: 2, Prompt: "fix the flaky billing test in api"},
+		{WindowID: 3, Prompt: "write release notes"},
+		{WindowID: 4},
+	}
+	markDuplicatePrompts(sessions)
+
+	want := map[int]int{1: 2, 2: 1, 3: 0, 4: 0}
+	for _, s := range sessions {
+		if s.DuplicateOf != want[s.WindowID] {
+			t.Errorf("window %d DuplicateOf = %d, want %d", s.WindowID, s.DuplicateOf, want[s.WindowID])
+		}
+	}
+}
+
+func TestFirstPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		prev   session
+		known  bool
+		log    string
+		screen string
+		want   string
+	}{
+		{"from the log", session{}, false, "Fix the flaky billing test", "write release notes", "Fix the flaky billing test"},
+		{"from the screen without a log", session{}, false, "", "write release notes", "write release notes"},
+		{"kept as the screen scrolls", session{Prompt: "Fix the flaky billing test"}, true, "", "now the docs too", "Fix the flaky billing test"},
+		{"not kept from another session", session{Prompt: "Fix the flaky billing test"}, false, "", "write release notes", "write release notes"},
+	}
+	for _, tt := range tests {
+		if got := firstPrompt(tt.prev, tt.known, tt.log, tt.screen); got != tt.want {
+			t.Errorf("%s: firstPrompt() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
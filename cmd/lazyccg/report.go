@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/atani/lazyccg/internal/ui"
+)
+
+// sessionReport summarizes one recorded transcript.
+type sessionReport struct {
+	Transcript transcript
+	Duration   time.Duration
+	Tools      toolStats
+	Intent     string // recorded, or else read from the prompt; "" when neither tells
+}
+
+// intentTotal is the agent time spent on one intent.
+type intentTotal struct {
+	Intent   string
+	Sessions int
+	Duration time.Duration
+}
+
+// intentTotals adds up reports by intent, the most time first.
+func intentTotals(reports []sessionReport) []intentTotal {
+	byIntent := make(map[string]*intentTotal)
+	var totals []*intentTotal
+	for _, r := range reports {
+		name := r.Intent
+		if name == "" {
+			name = "(none)"
+		}
+		t, ok := byIntent[name]
+		if !ok {
+			t = &intentTotal{Intent: name}
+			byIntent[name] = t
+			totals = append(totals, t)
+		}
+		t.Sessions++
+		t.Duration += r.Duration
+	}
+	sort.SliceStable(totals, func(i, j int) bool { return totals[i].Duration > totals[j].Duration })
+	out := make([]intentTotal, len(totals))
+	for i, t := range totals {
+		out[i] = *t
+	}
+	return out
+}
+
+func buildReports(dir string, since time.Time) ([]sessionReport, error) {
+	paths, err := listTranscripts(dir)
+	if err != nil {
+		return nil, err
+	}
+	var reports []sessionReport
+	for _, path := range paths {
+		t, err := readTranscript(path)
+		if err != nil || t.Started.Before(since) {
+			continue
+		}
+		r := sessionReport{Transcript: t, Tools: countToolCalls(t.Lines()), Intent: t.Intent}
+		if r.Intent == "" {
+			// Recorded before sessions were labeled
+			r.Intent = classifyIntent(t.Prompt, intentRules())
+		}
+		if n := len(t.Records); n > 0 {
+			r.Duration = t.Records[n-1].Time.Sub(t.Started)
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	since := fs.Duration("since", 24*time.Hour, "include sessions started within this duration")
+	dir := fs.String("dir", historyDir(), "transcript directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	reports, err := buildReports(*dir, time.Now().Add(-*since))
+	if err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		fmt.Println("no recorded sessions (enable history with -history or `history: true`)")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tAI\tTITLE\tINTENT\tDURATION\tTOOL CALLS")
+	total := make(toolStats)
+	for _, r := range reports {
+		t := r.Transcript
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Started.Local().Format("01-02 15:04"), strings.ToUpper(t.AI), ui.Truncate(t.Title, 30),
+			r.Intent, timeFmt.Duration(r.Duration), r.Tools)
+		total = total.merge(r.Tools)
+	}
+	fmt.Fprintf(w, "\t\t%d session(s)\t\t\t%s\n", len(reports), total)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Where the agents' time went
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INTENT\tSESSIONS\tDURATION")
+	for _, t := range intentTotals(reports) {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", t.Intent, t.Sessions, timeFmt.Duration(t.Duration))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildReports(t *testing.T) {
+	dir := t.TempDir()
+	h := newHistoryRecorder(dir)
+	s := session{WindowID: 1, AI: "claude", Title: "api", Intent: "bugfix", Lines: []string{"● Bash(make)", "● Edit(a.go)"}}
+	if err := h.Record(diffSessions(nil, []session{s})); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := buildReports(dir, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("buildReports() returned %d reports, want 1", len(reports))
+	}
+	if got := reports[0].Tools.String(); got != "1 edit · 1 shell" {
+		t.Errorf("report tools = %q", got)
+	}
+	if reports[0].Intent != "bugfix" {
+		t.Errorf("report intent = %q, want the recorded bugfix", reports[0].Intent)
+	}
+
+	if reports, _ := buildReports(dir, time.Now().Add(time.Hour)); len(reports) != 0 {
+		t.Errorf("sessions before since should be skipped, got %d", len(reports))
+	}
+}
+
+func TestIntentTotals(t *testing.T) {
+	reports := []sessionReport{
+		{Intent: "feature", Duration: 10 * time.Minute},
+		{Intent: "bugfix", Duration: 40 * time.Minute},
+		{Duration: 5 * time.Minute},
+		{Intent: "feature", Duration: 20 * time.Minute},
+	}
+	want := []intentTotal{
+		{Intent: "bugfix", Sessions: 1, Duration: 40 * time.Minute},
+		{Intent: "feature", Sessions: 2, Duration: 30 * time.Minute},
+		{Intent: "(none)", Sessions: 1, Duration: 5 * time.Minute},
+	}
+	if got := intentTotals(reports); !reflect.DeepEqual(got, want) {
+		t.Errorf("intentTotals() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/atani/lazyccg/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Capture scheduling: a session whose output is changing is captured more
+// often, halving its interval on each change down to fastCaptureInterval;
+// once it goes quiet the interval doubles back to the poll interval. The
+// dashboard ticks at the shortest interval any session needs, so without
+// streaming sessions it polls exactly as often as -poll says.
+
+const (
+	minCaptureInterval = 200 * time.Millisecond
+	fastCaptureDivisor = 4 // fastest interval is the poll interval / 4
+)
+
+// fastCaptureInterval is the shortest capture interval for a poll interval.
+func fastCaptureInterval(base time.Duration) time.Duration {
+	return max(base/fastCaptureDivisor, minCaptureInterval)
+}
+
+// nextCaptureInterval halves interval when the output changed and doubles
+// it when it didn't, within [fastCaptureInterval(base), base].
+func nextCaptureInterval(interval, base time.Duration, changed bool) time.Duration {
+	if interval <= 0 {
+		interval = base
+	}
+	if changed {
+		interval /= 2
+	} else {
+		interval *= 2
+	}
+	return min(max(interval, fastCaptureInterval(base)), base)
+}
+
+// nextTick returns how long to wait before the next poll.
+func (p pollState) nextTick(base time.Duration) time.Duration {
+	d := base
+	for _, interval := range p.interval {
+		d = min(d, interval)
+	}
+	return max(d, fastCaptureInterval(base))
+}
+
+// carry copies window id's state from prev when it isn't due for capture.
+func (p pollState) carry(prev pollState, id int) {
+	p.hashes[id] = prev.hashes[id]
+	p.stable[id] = prev.stable[id]
+	p.changed[id] = prev.changed[id]
+	p.moved[id] = prev.moved[id]
+	p.lines[id] = prev.lines[id]
+	p.tools[id] = prev.tools[id]
+	p.edits[id] = prev.edits[id]
+	p.interval[id] = prev.interval[id]
+	p.due[id] = prev.due[id]
+	p.captures[id] = prev.captures[id]
+	p.captured[id] = prev.captured[id]
+	p.sessions[id] = prev.sessions[id]
+	if pending, ok := prev.pending[id]; ok {
+		p.pending[id] = pending
+	}
+}
+
+// Internal stats screen.
+
+func (m model) renderStatsScreen(width, height int) string {
+	p := m.poll
+	innerWidth := width - 2
+	lines := []string{
+		fmt.Sprintf(" backend %s   poll %s   fastest %s   next tick %s",
+			backend.Name(), m.pollInterval(), fastCaptureInterval(m.pollInterval()), p.nextTick(m.pollInterval())),
+		fmt.Sprintf(" polls %d   last poll took %s   captured %d of %d windows",
+			p.polls, p.took.Round(time.Millisecond), p.capturedNow, len(p.sessions)),
+		"",
+	}
+	lines = append(lines, healthLines(health.snapshot(), innerWidth, time.Now())...)
+	lines = append(lines, "",
+		helpDescStyle.Render(fmt.Sprintf(" %-24s %-6s %-8s %9s %9s  %s", "SESSION", "AI", "STATUS", "INTERVAL", "CAPTURES", "LAST CAPTURE")),
+	)
+
+	ids := make([]int, 0, len(p.sessions))
+	for id := range p.sessions {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	now := time.Now()
+	for _, id := range ids {
+		s := p.sessions[id]
+		interval := p.interval[id]
+		line := ui.Truncate(fmt.Sprintf(" %-24s %-6s %-8s %9s %9d  %s",
+			ui.Truncate(s.Title, 24), shortAI(s.AI), s.Status, interval, p.captures[id], timeFmt.Ago(p.captured[id], now)), innerWidth)
+		if interval < m.pollInterval() {
+			// Streaming: captured faster than the poll interval
+			line = statusRunning.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	start := min(m.statsScroll, len(lines))
+	return drawBox("Stats", lines[start:], width, height, cyan)
+}
+
+func (m model) updateStats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "S":
+		m.statsOpen = false
+	case "up", "k":
+		if m.statsScroll > 0 {
+			m.statsScroll--
+		}
+	case "down", "j":
+		m.statsScroll++
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextCaptureInterval(t *testing.T) {
+	base := time.Second
+	tests := []struct {
+		name     string
+		interval time.Duration
+		changed  bool
+		want     time.Duration
+	}{
+		{"first change", 0, true, 500 * time.Millisecond},
+		{"streaming", 500 * time.Millisecond, true, 250 * time.Millisecond},
+		{"floor", 250 * time.Millisecond, true, 250 * time.Millisecond},
+		{"decay", 250 * time.Millisecond, false, 500 * time.Millisecond},
+		{"ceiling", time.Second, false, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextCaptureInterval(tt.interval, base, tt.changed); got != tt.want {
+				t.Errorf("nextCaptureInterval() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got := fastCaptureInterval(500 * time.Millisecond); got != minCaptureInterval {
+		t.Errorf("fastCaptureInterval(500ms) = %s, want %s", got, minCaptureInterval)
+	}
+}
+
+func TestNextTick(t *testing.T) {
+	p := newPollState()
+	if got := p.nextTick(time.Second); got != time.Second {
+		t.Errorf("idle nextTick() = %s, want 1s", got)
+	}
+	p.interval[1] = time.Second
+	p.interval[2] = 250 * time.Millisecond
+	if got := p.nextTick(time.Second); got != 250*time.Millisecond {
+		t.Errorf("streaming nextTick() = %s, want 250ms", got)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GNU screen sessions map onto the kitty layout as one OS window and tab per
+// session, with the session's windows as windows. Screen addresses windows
+// by session name and number, so they are given integer IDs.
+
+type screenBackend struct {
+	ids *windowIDs
+}
+
+func newScreenBackend() screenBackend {
+	return screenBackend{ids: newWindowIDs()}
+}
+
+func (screenBackend) Name() string { return "screen" }
+
+// screenWindow is one window of a screen session.
+type screenWindow struct {
+	Number int
+	Title  string
+}
+
+func (b screenBackend) List() ([]kittyOSWindow, error) {
+	// screen -ls exits non-zero even when it lists sessions
+	out, err := exec.Command("screen", "-ls").Output()
+	sessions := parseScreenSessions(string(out))
+	if len(sessions) == 0 {
+		if err != nil && len(out) == 0 {
+			return nil, fmt.Errorf("screen -ls: %w", err)
+		}
+		return nil, nil
+	}
+	ps, err := runPs(commandRunner{}, "-A", "-o", "tty=,pid=,args=")
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	byTTY := parseTTYProcesses(string(ps))
+
+	var osWindows []kittyOSWindow
+	for _, name := range sessions {
+		out, err := exec.Command("screen", "-S", name, "-Q", "windows").Output()
+		if err != nil {
+			continue // the session went away
+		}
+		tab := kittyTab{ID: b.ids.id(name), Title: name}
+		for _, w := range parseScreenWindows(string(out)) {
+			key := screenKey(name, w.Number)
+			win := kittyWindow{ID: b.ids.id(key), Title: w.Title}
+			tty, err := exec.Command("screen", "-S", name, "-p", strconv.Itoa(w.Number), "-Q", "tty").Output()
+			if err == nil {
+				win.ForegroundProcesses = byTTY[strings.TrimPrefix(strings.TrimSpace(string(tty)), "/dev/")]
+			}
+			if n := len(win.ForegroundProcesses); n > 0 {
+				win.Cwd, _ = os.Readlink(fmt.Sprintf("/proc/%d/cwd", win.ForegroundProcesses[n-1].Pid))
+			}
+			tab.Windows = append(tab.Windows, win)
+		}
+		osWindows = append(osWindows, kittyOSWindow{Tabs: []kittyTab{tab}})
+	}
+	return osWindows, nil
+}
+
+// parseScreenSessions returns the session names from `screen -ls`, whose
+// session lines are tab-indented: "\t12345.pts-0.host\t(Detached)".
+func parseScreenSessions(out string) []string {
+	var sessions []string
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "\t") {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			sessions = append(sessions, fields[0])
+		}
+	}
+	return sessions
+}
+
+// screenWindowRe matches one entry of `screen -Q windows`, e.g. "1*$ claude".
+// Entries are separated by two spaces; flags mark the current (*), previous
+// (-), logged-in ($), activity (@), and bell (!) windows.
+var screenWindowRe = regexp.MustCompile(`^(\d+)[*\-$!@&ZL]*\s+(.*)$`)
+
+func parseScreenWindows(out string) []screenWindow {
+	var windows []screenWindow
+	for _, entry := range strings.Split(strings.TrimSpace(out), "  ") {
+		m := screenWindowRe.FindStringSubmatch(strings.TrimSpace(entry))
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		windows = append(windows, screenWindow{Number: n, Title: m[2]})
+	}
+	return windows
+}
+
+func screenKey(session string, number int) string {
+	return session + ":" + strconv.Itoa(number)
+}
+
+// target returns the session and window number an ID was assigned to.
+func (b screenBackend) target(windowID int) (string, string, error) {
+	key, err := b.ids.key(windowID)
+	if err != nil {
+		return "", "", err
+	}
+	i := strings.LastIndex(key, ":")
+	if i < 0 {
+		return "", "", fmt.Errorf("no screen window %d", windowID)
+	}
+	return key[:i], key[i+1:], nil
+}
+
+// screenHardcopyWait bounds how long CaptureText waits for screen to write
+// the hardcopy; -X returns once the command is sent, not when it is done.
+const screenHardcopyWait = 500 * time.Millisecond
+
+func (b screenBackend) CaptureText(windowID int, extent string) (string, error) {
+	session, number, err := b.target(windowID)
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "lazyccg-screen")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "hardcopy")
+
+	args := []string{"-S", session, "-p", number, "-X", "hardcopy"}
+	if extent == "all" {
+		args = append(args, "-h")
+	}
+	if err := exec.Command("screen", append(args, path)...).Run(); err != nil {
+		return "", fmt.Errorf("screen hardcopy: %w", err)
+	}
+	for deadline := time.Now().Add(screenHardcopyWait); ; time.Sleep(10 * time.Millisecond) {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return string(data), nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("screen hardcopy: %w", err)
+		}
+	}
+}
+
+func (b screenBackend) command(windowID int, args ...string) error {
+	session, number, err := b.target(windowID)
+	if err != nil {
+		return err
+	}
+	return exec.Command("screen", append([]string{"-S", session, "-p", number, "-X"}, args...)...).Run()
+}
+
+func (b screenBackend) Focus(windowID int) error {
+	_, number, err := b.target(windowID)
+	if err != nil {
+		return err
+	}
+	return b.command(windowID, "select", number)
+}
+
+func (b screenBackend) Rename(windowID int, title string) error {
+	return b.command(windowID, "title", title)
+}
+
+func (b screenBackend) SendText(windowID int, text string) error {
+	return b.command(windowID, "stuff", text)
+}
+
+var errScreenUnsupported = errors.New("not supported by GNU screen")
+
+// Scroll is not supported: screen's copy mode cannot be driven from outside.
+func (screenBackend) Scroll(windowID, lines int) error {
+	return errScreenUnsupported
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseScreenSessions(t *testing.T) {
+	out := "There are screens on:\n" +
+		"\t12345.pts-0.host\t(Detached)\n" +
+		"\t6789.work\t(07/01/2024 10:00:00 AM)\t(Attached)\n" +
+		"2 Sockets in /run/screen/S-me.\n"
+	want := []string{"12345.pts-0.host", "6789.work"}
+	if got := parseScreenSessions(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseScreenSessions() = %q, want %q", got, want)
+	}
+	if got := parseScreenSessions("No Sockets found in /run/screen/S-me.\n"); len(got) != 0 {
+		t.Errorf("parseScreenSessions() = %q, want none", got)
+	}
+}
+
+func TestParseScreenWindows(t *testing.T) {
+	got := parseScreenWindows("0$ bash  1*$ claude code  2-@ logs\n")
+	want := []screenWindow{{0, "bash"}, {1, "claude code"}, {2, "logs"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseScreenWindows() = %+v, want %+v", got, want)
+	}
+}
+
+func TestScreenTarget(t *testing.T) {
+	b := newScreenBackend()
+	id := b.ids.id(screenKey("6789.work", 2))
+	session, number, err := b.target(id)
+	if err != nil || session != "6789.work" || number != "2" {
+		t.Errorf("target(%d) = %q, %q, %v", id, session, number, err)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// scriptEngine runs user Lua scripts from the scripts dir. Scripts register
+// hooks through the global "lazyccg" table:
+//
+//	lazyccg.detector(function(s) return "WAITING" end)
+//	lazyccg.formatter(function(s) return s.title .. "!" end)
+//	lazyccg.on_status_change(function(s, old) ... end)
+//
+// A detector or formatter returning nil defers to the next one.
+type scriptEngine struct {
+	mu         sync.Mutex
+	dir        string
+	L          *lua.LState
+	detectors  []*lua.LFunction
+	formatters []*lua.LFunction
+	handlers   []*lua.LFunction
+}
+
+var scripts *scriptEngine
+
+func scriptsDir() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "scripts")
+}
+
+func newScriptEngine(dir string) *scriptEngine {
+	return &scriptEngine{dir: dir}
+}
+
+// Close frees the Lua state; handlers no longer run.
+func (e *scriptEngine) Close() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.L != nil {
+		e.L.Close()
+		e.L = nil
+	}
+	e.detectors, e.formatters, e.handlers = nil, nil, nil
+}
+
+// Load (re)loads every *.lua file in the scripts dir, replacing any
+// previously registered hooks. It returns the number of files loaded.
+func (e *scriptEngine) Load() (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.L != nil {
+		e.L.Close()
+	}
+	e.L = lua.NewState()
+	e.detectors, e.formatters, e.handlers = nil, nil, nil
+
+	api := e.L.NewTable()
+	e.L.SetFuncs(api, map[string]lua.LGFunction{
+		"detector":         e.register(&e.detectors),
+		"formatter":        e.register(&e.formatters),
+		"on_status_change": e.register(&e.handlers),
+	})
+	e.L.SetGlobal("lazyccg", api)
+
+	if e.dir == "" {
+		return 0, nil
+	}
+	files, err := filepath.Glob(filepath.Join(e.dir, "*.lua"))
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		if err := e.L.DoFile(f); err != nil {
+			return 0, fmt.Errorf("script %s: %w", filepath.Base(f), err)
+		}
+	}
+	return len(files), nil
+}
+
+func (e *scriptEngine) register(hooks *[]*lua.LFunction) lua.LGFunction {
+	return func(L *lua.LState) int {
+		*hooks = append(*hooks, L.CheckFunction(1))
+		return 0
+	}
+}
+
+// DetectStatus returns the first status produced by a detector, or "".
+func (e *scriptEngine) DetectStatus(s session) string {
+	return e.firstString(func() []*lua.LFunction { return e.detectors }, s)
+}
+
+// FormatTitle returns the first title produced by a formatter, or "".
+func (e *scriptEngine) FormatTitle(s session) string {
+	return e.firstString(func() []*lua.LFunction { return e.formatters }, s)
+}
+
+// StatusChanged calls every on_status_change handler.
+func (e *scriptEngine) StatusChanged(s session, old agentstatus.Status) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, fn := range e.handlers {
+		err := e.L.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}, e.sessionTable(s), lua.LString(old.String()))
+		e.logError(err)
+	}
+}
+
+func (e *scriptEngine) firstString(hooks func() []*lua.LFunction, s session) string {
+	if e == nil {
+		return ""
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, fn := range hooks() {
+		if err := e.L.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, e.sessionTable(s)); err != nil {
+			e.logError(err)
+			continue
+		}
+		ret := e.L.Get(-1)
+		e.L.Pop(1)
+		if str, ok := ret.(lua.LString); ok && str != "" {
+			return string(str)
+		}
+	}
+	return ""
+}
+
+func (e *scriptEngine) sessionTable(s session) *lua.LTable {
+	t := e.L.NewTable()
+	t.RawSetString("ai", lua.LString(s.AI))
+	t.RawSetString("title", lua.LString(s.Title))
+	t.RawSetString("status", lua.LString(s.Status.String()))
+	t.RawSetString("status_source", lua.LString(s.StatusSource))
+	t.RawSetString("confidence", lua.LNumber(s.Confidence))
+	t.RawSetString("cwd", lua.LString(s.Cwd))
+	t.RawSetString("window_id", lua.LNumber(s.WindowID))
+	t.RawSetString("tab_id", lua.LNumber(s.TabID))
+	lines := e.L.NewTable()
+	for _, line := range s.Lines {
+		lines.Append(lua.LString(line))
+	}
+	t.RawSetString("lines", lines)
+	return t
+}
+
+func (e *scriptEngine) logError(err error) {
+	if err != nil && debugLog != nil {
+		fmt.Fprintf(debugLog, "[script] %v\n", err)
+	}
+}
+
+// loadScripts initializes the global script engine, reporting load errors
+// on stderr so a broken script doesn't prevent startup.
+func loadScripts() {
+	scripts = newScriptEngine(scriptsDir())
+	if _, err := scripts.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load scripts:", err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScriptEngine(t *testing.T) {
+	dir := t.TempDir()
+	script := `
+lazyccg.detector(function(s)
+  if s.lines[#s.lines] == "deploy?" then return "waiting" end
+end)
+lazyccg.formatter(function(s) return s.ai .. ":" .. s.title end)
+changes = 0
+lazyccg.on_status_change(function(s, old) changes = changes + 1 end)
+`
+	if err := os.WriteFile(filepath.Join(dir, "a.lua"), []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := newScriptEngine(dir)
+	n, err := e.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Load() = %d, want 1", n)
+	}
+
+	s := session{AI: "claude", Title: "api", Lines: []string{"building", "deploy?"}}
+	if got := e.DetectStatus(s); got != "waiting" {
+		t.Errorf("DetectStatus() = %q, want %q", got, "waiting")
+	}
+	s.Lines = []string{"building"}
+	if got := e.DetectStatus(s); got != "" {
+		t.Errorf("DetectStatus() = %q, want empty", got)
+	}
+	if got := e.FormatTitle(s); got != "claude:api" {
+		t.Errorf("FormatTitle() = %q, want %q", got, "claude:api")
+	}
+
+	e.StatusChanged(s, "IDLE")
+	if got := e.L.GetGlobal("changes").String(); got != "1" {
+		t.Errorf("handler call count = %s, want 1", got)
+	}
+
+	// Reloading replaces previously registered hooks
+	if err := os.WriteFile(filepath.Join(dir, "a.lua"), []byte("x = 1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := e.FormatTitle(s); got != "" {
+		t.Errorf("FormatTitle() after reload = %q, want empty", got)
+	}
+}
+
+func TestScriptEngineNil(t *testing.T) {
+	var e *scriptEngine
+	if got := e.DetectStatus(session{}); got != "" {
+		t.Errorf("nil engine DetectStatus() = %q", got)
+	}
+	e.StatusChanged(session{}, "IDLE")
+}
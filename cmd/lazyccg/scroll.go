@@ -0,0 +1,68 @@
+package main
+
+// scrollState is the Output panel viewport for one session. It is anchored to
+// the text of its top line so new output doesn't move what is on screen.
+type scrollState struct {
+	top    int    // index into session.Lines of the first visible line
+	anchor string // text of that line when the offset was set
+}
+
+func newScrollState(lines []string, top int) scrollState {
+	if top < 0 {
+		top = 0
+	}
+	if top >= len(lines) {
+		return scrollState{top: len(lines)}
+	}
+	return scrollState{top: top, anchor: lines[top]}
+}
+
+// reanchor finds the anchor line in refreshed lines, preferring the match
+// closest to the previous offset. If the line is gone the offset is clamped.
+func (st scrollState) reanchor(lines []string) scrollState {
+	best := -1
+	for i, line := range lines {
+		if line != st.anchor {
+			continue
+		}
+		if best < 0 || abs(i-st.top) < abs(best-st.top) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		return scrollState{top: best, anchor: st.anchor}
+	}
+	return newScrollState(lines, st.top)
+}
+
+// visible returns the lines shown in a viewport of height n and whether the
+// viewport is at the bottom.
+func (st scrollState) visible(lines []string, n int) ([]string, bool) {
+	top, end, bottom := st.window(len(lines), n)
+	return lines[top:end], bottom
+}
+
+// window returns the range of the total lines shown in a viewport of height
+// n and whether the viewport is at the bottom.
+func (st scrollState) window(total, n int) (top, end int, bottom bool) {
+	maxTop := total - n
+	if maxTop < 0 {
+		maxTop = 0
+	}
+	top = st.top
+	if top > maxTop {
+		top = maxTop
+	}
+	end = top + n
+	if end > total {
+		end = total
+	}
+	return top, end, top == maxTop
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
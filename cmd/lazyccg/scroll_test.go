@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScrollStateReanchor(t *testing.T) {
+	lines := []string{"a", "b", "c", "d", "e"}
+	st := newScrollState(lines, 2) // top line "c"
+
+	// New output appended and the oldest line trimmed by max-lines
+	refreshed := []string{"b", "c", "d", "e", "f", "g"}
+	st = st.reanchor(refreshed)
+	if st.top != 1 {
+		t.Errorf("reanchor() top = %d, want 1", st.top)
+	}
+
+	// Anchor line disappeared: offset is clamped
+	st = st.reanchor([]string{"x", "y"})
+	if st.top != 1 || st.anchor != "y" {
+		t.Errorf("reanchor() without anchor = %+v", st)
+	}
+}
+
+func TestScrollStateVisible(t *testing.T) {
+	lines := []string{"a", "b", "c", "d", "e"}
+
+	got, atBottom := newScrollState(lines, 1).visible(lines, 2)
+	if !reflect.DeepEqual(got, []string{"b", "c"}) || atBottom {
+		t.Errorf("visible() = %v, %v", got, atBottom)
+	}
+
+	got, atBottom = newScrollState(lines, 4).visible(lines, 3)
+	if !reflect.DeepEqual(got, []string{"c", "d", "e"}) || !atBottom {
+		t.Errorf("visible() past end = %v, %v", got, atBottom)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// w swaps the selected session's Output panel between the lines polling
+// keeps (-max-lines) and its whole scrollback, to review everything the
+// agent did since it started. The scrollback is captured once, when asked
+// for; w again goes back to the live output.
+
+type scrollbackMsg struct {
+	windowID int
+	lines    []string
+	err      error
+}
+
+// scrollbackCmd captures windowID's whole scrollback, cleaned up the way
+// polled output is.
+func scrollbackCmd(windowID int) tea.Cmd {
+	return func() tea.Msg {
+		text, err := backend.CaptureText(windowID, "all")
+		if err != nil {
+			return scrollbackMsg{windowID: windowID, err: err}
+		}
+		lines := agentstatus.CollapseFrames(agentsession.NormalizeLines(text, 0))
+		return scrollbackMsg{windowID: windowID, lines: lines}
+	}
+}
+
+// toggleScrollback goes back to s's live output if its scrollback is shown,
+// or returns the command that captures it.
+func (m *model) toggleScrollback(s session) tea.Cmd {
+	if _, ok := m.scrollback[s.WindowID]; ok {
+		delete(m.scrollback, s.WindowID)
+		delete(m.scroll, s.WindowID)
+		m.notice = "live output"
+		return nil
+	}
+	return scrollbackCmd(s.WindowID)
+}
+
+// showScrollback puts a captured scrollback in the Output panel.
+func (m *model) showScrollback(msg scrollbackMsg) {
+	if msg.err != nil {
+		m.notice = "scrollback: " + msg.err.Error()
+		return
+	}
+	if m.scrollback == nil {
+		m.scrollback = make(map[int][]string)
+	}
+	m.scrollback[msg.windowID] = msg.lines
+	delete(m.scroll, msg.windowID)
+	m.notice = fmt.Sprintf("whole scrollback: %d lines", len(msg.lines))
+}
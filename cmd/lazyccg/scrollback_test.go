@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestScrollbackReplacesOutput(t *testing.T) {
+	m := model{pollEvery: time.Second, poll: newPollState(), unread: make(map[int]int), renamed: make(map[int]bool), scroll: make(map[int]scrollState)}
+	m = driveModel(m, tea.WindowSizeMsg{Width: 100, Height: 24}, sessionsMsg{sessions: snapshotSessions()})
+	s, _ := m.selectedSession()
+	full := []string{"$ claude", "> Fix the login bug", "⏺ Read(auth.go)"}
+	m = driveModel(m, scrollbackMsg{windowID: s.WindowID, lines: full})
+	if got := m.outputLines(s); !slices.Equal(got, full) {
+		t.Errorf("outputLines() = %q, want the whole scrollback", got)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Output [scrollback]") || !strings.Contains(view, "Fix the login bug") {
+		t.Errorf("the Output panel doesn't show the scrollback:\n%s", view)
+	}
+
+	m = driveModel(m, key("w"))
+	if got := m.outputLines(s); !slices.Equal(got, s.Lines) {
+		t.Errorf("after w, outputLines() = %q; want the live output back", got)
+	}
+
+	m = driveModel(m, scrollbackMsg{windowID: s.WindowID, err: errors.New("no such window")})
+	if _, ok := m.scrollback[s.WindowID]; ok || !strings.Contains(m.notice, "no such window") {
+		t.Errorf("a failed capture: scrollback %v, notice %q", m.scrollback, m.notice)
+	}
+}
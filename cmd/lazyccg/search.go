@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// outputSearch is a search over a session's full kitty scrollback.
+type outputSearch struct {
+	windowID int
+	query    string
+	lines    []string // raw scrollback lines, oldest first
+	matches  []int    // indexes into lines, ascending
+	current  int      // index into matches
+}
+
+type searchResultMsg struct {
+	search *outputSearch
+	err    error
+}
+
+// findMatches returns the indexes of lines containing query, ignoring case.
+func findMatches(lines []string, query string) []int {
+	query = strings.ToLower(query)
+	var matches []int
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(line), query) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// linesFromBottom returns how far the current match is from the end of the
+// scrollback, which is what kitty's scroll-window needs.
+func (s *outputSearch) linesFromBottom() int {
+	if len(s.matches) == 0 {
+		return 0
+	}
+	return len(s.lines) - 1 - s.matches[s.current]
+}
+
+// step moves to an older (delta < 0) or newer (delta > 0) match, wrapping.
+func (s *outputSearch) step(delta int) {
+	if len(s.matches) == 0 {
+		return
+	}
+	s.current = (s.current + delta + len(s.matches)) % len(s.matches)
+}
+
+// context returns up to n lines centered on the current match and the
+// position of the match within them.
+func (s *outputSearch) context(n int) ([]string, int) {
+	if len(s.matches) == 0 || n < 1 {
+		return nil, -1
+	}
+	idx := s.matches[s.current]
+	start := idx - n/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + n
+	if end > len(s.lines) {
+		end = len(s.lines)
+		start = end - n
+		if start < 0 {
+			start = 0
+		}
+	}
+	return s.lines[start:end], idx - start
+}
+
+func searchCmd(windowID int, query string) tea.Cmd {
+	return func() tea.Msg {
+		text, err := backend.CaptureText(windowID, "all")
+		if err != nil {
+			return searchResultMsg{err: err}
+		}
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = agentsession.SanitizeLine(line)
+		}
+		matches := findMatches(lines, query)
+		return searchResultMsg{search: &outputSearch{
+			windowID: windowID,
+			query:    query,
+			lines:    lines,
+			matches:  matches,
+			current:  len(matches) - 1,
+		}}
+	}
+}
+
+// searchScrollMargin keeps the match a few lines above the bottom edge.
+const searchScrollMargin = 5
+
+// focusAndScrollCmd focuses the window and scrolls its scrollback so the
+// line fromBottom lines above the end is visible.
+func focusAndScrollCmd(windowID, fromBottom int) tea.Cmd {
+	return func() tea.Msg {
+		if msg := focusCmd(windowID)(); msg != nil {
+			return msg
+		}
+		amount := fromBottom - searchScrollMargin
+		if amount < 0 {
+			amount = 0
+		}
+		if err := backend.Scroll(windowID, amount); err != nil {
+			return err
+		}
+		return nil
+	}
+}
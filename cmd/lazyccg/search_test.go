@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindMatches(t *testing.T) {
+	lines := []string{"Editing billing.go", "tests pass", "BILLING done", ""}
+	if got := findMatches(lines, "billing"); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Errorf("findMatches() = %v, want [0 2]", got)
+	}
+	if got := findMatches(lines, "nothing"); got != nil {
+		t.Errorf("findMatches() = %v, want nil", got)
+	}
+}
+
+func TestOutputSearch(t *testing.T) {
+	s := &outputSearch{
+		lines:   []string{"a", "match 1", "b", "c", "match 2", "d"},
+		matches: []int{1, 4},
+		current: 1,
+	}
+
+	if got := s.linesFromBottom(); got != 1 {
+		t.Errorf("linesFromBottom() = %d, want 1", got)
+	}
+
+	lines, idx := s.context(3)
+	if !reflect.DeepEqual(lines, []string{"c", "match 2", "d"}) || idx != 1 {
+		t.Errorf("context(3) = %v, %d", lines, idx)
+	}
+
+	s.step(-1)
+	if got := s.linesFromBottom(); got != 4 {
+		t.Errorf("linesFromBottom() after step = %d, want 4", got)
+	}
+	s.step(-1) // wraps to the newest match
+	if s.current != 1 {
+		t.Errorf("step() should wrap, current = %d", s.current)
+	}
+
+	lines, idx = (&outputSearch{lines: []string{"x", "y"}, matches: []int{0}}).context(5)
+	if len(lines) != 2 || idx != 0 {
+		t.Errorf("context() near start = %v, %d", lines, idx)
+	}
+}
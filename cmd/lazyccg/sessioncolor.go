@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Each session has an accent color of its own, picked by a hash of which
+// agent it is, so the same agent has the same color every time lazyccg
+// starts: a bar at the start of its row in the Sessions panel and before
+// its name over its output.
+
+// sessionAccents are ANSI colors far enough apart to tell at a glance.
+var sessionAccents = []lipgloss.Color{"39", "208", "141", "42", "203", "220", "75", "170", "114", "180", "99", "44"}
+
+// sessionAccent is s's color: by its terminal, window, and agent, which
+// stay the same while the window is open.
+func sessionAccent(s session) lipgloss.Color {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s\x00%d\x00%s", s.Instance, s.WindowID, s.AI)
+	return sessionAccents[h.Sum32()%uint32(len(sessionAccents))]
+}
+
+// sessionMarker renders the bar marking s in its color.
+func sessionMarker(s session) string {
+	return lipgloss.NewStyle().Foreground(sessionAccent(s)).Render("▌")
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestSessionAccent(t *testing.T) {
+	api := session{Instance: "kitty-1", WindowID: 3, AI: "claude", Title: "api", Cwd: "/src/api"}
+	renamed := api
+	renamed.Title, renamed.Cwd = "api (refactor)", "/src/api/internal"
+	if sessionAccent(api) != sessionAccent(renamed) {
+		t.Errorf("accent changed with the title or directory")
+	}
+
+	seen := make(map[string]bool)
+	for id := 1; id <= 40; id++ {
+		seen[string(sessionAccent(session{WindowID: id, AI: "claude"}))] = true
+	}
+	if len(seen) < len(sessionAccents)/2 {
+		t.Errorf("40 windows got only %d of %d accents", len(seen), len(sessionAccents))
+	}
+}
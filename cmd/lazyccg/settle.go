@@ -0,0 +1,56 @@
+package main
+
+import (
+	"regexp"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// A status read off the screen has to be seen on status_settle captures in
+// a row before the Sessions panel shows it, so a frame caught between two
+// steps, e.g. a spinner gone for a moment, doesn't flip a session from
+// RUNNING to IDLE and back. Statuses that are certain, such as an exited
+// process or a hook event, and bells show at once.
+//
+//	status_settle: 3   # captures in a row; 1 shows every change at once
+
+// defaultStatusSettle is how many captures in a row a status needs by
+// default.
+const defaultStatusSettle = 2
+
+func statusSettle() int {
+	if cfg.StatusSettle > 0 {
+		return cfg.StatusSettle
+	}
+	return defaultStatusSettle
+}
+
+// unsettledNote is the note settle adds to the reason shown while another
+// status waits; the shown session already has it from the capture before.
+var unsettledNote = regexp.MustCompile(` \(\w+ not settled yet\)$`)
+
+// pendingStatus is a status seen but not shown yet.
+type pendingStatus struct {
+	Status agentstatus.Status
+	Seen   int // captures in a row it was seen on
+}
+
+// settle returns s with the status to show, given the session shown before
+// and the status pending for it, and what is pending now.
+func settle(s, shown session, known bool, pending pendingStatus, need int) (session, pendingStatus) {
+	if !known || s.Status == shown.Status || s.Confidence >= agentstatus.Certain || s.StatusSource == agentstatus.SourceBell {
+		return s, pendingStatus{}
+	}
+	if pending.Status == s.Status {
+		pending.Seen++
+	} else {
+		pending = pendingStatus{Status: s.Status, Seen: 1}
+	}
+	if pending.Seen >= need {
+		return s, pendingStatus{}
+	}
+	s.Status, s.StatusSource, s.Confidence = shown.Status, shown.StatusSource, shown.Confidence
+	s.StatusWhy = unsettledNote.ReplaceAllString(shown.StatusWhy, "") + " (" + pending.Status.String() + " not settled yet)"
+	s.ErrorLine = shown.ErrorLine
+	return s, pending
+}
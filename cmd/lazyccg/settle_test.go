@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestSettle(t *testing.T) {
+	running := session{AI: "claude", Status: agentstatus.Running, StatusSource: agentstatus.SourceActivity, Confidence: agentstatus.Likely, StatusWhy: "the screen changed"}
+	idle := session{AI: "claude", Status: agentstatus.Idle, StatusSource: agentstatus.SourceOutput, Confidence: agentstatus.Likely}
+
+	// One still frame doesn't make it IDLE
+	got, pending := settle(idle, running, true, pendingStatus{}, 2)
+	if got.Status != agentstatus.Running || pending != (pendingStatus{agentstatus.Idle, 1}) {
+		t.Fatalf("first IDLE: shown %s, pending %+v; want RUNNING kept with IDLE pending", got.Status, pending)
+	}
+	if got.StatusWhy != "the screen changed (IDLE not settled yet)" {
+		t.Errorf("StatusWhy = %q", got.StatusWhy)
+	}
+	// The second does
+	if got, pending = settle(idle, got, true, pending, 2); got.Status != agentstatus.Idle || pending.Seen != 0 {
+		t.Errorf("second IDLE: shown %s, pending %+v; want IDLE", got.Status, pending)
+	}
+	// Waiting longer notes it once, whatever is pending
+	got, pending = running, pendingStatus{}
+	for i, s := range []session{idle, idle, {AI: "claude", Status: agentstatus.NeedsInput, Confidence: agentstatus.Likely}} {
+		got, pending = settle(s, got, true, pending, 3)
+		want := "the screen changed (" + s.Status.String() + " not settled yet)"
+		if got.Status != agentstatus.Running || got.StatusWhy != want {
+			t.Errorf("need 3, capture %d: shown %s, StatusWhy %q; want RUNNING, %q", i+1, got.Status, got.StatusWhy, want)
+		}
+	}
+	// Back to RUNNING before IDLE settled: the count starts over
+	_, pending = settle(idle, running, true, pendingStatus{}, 2)
+	if got, pending = settle(running, running, true, pending, 2); got.Status != agentstatus.Running || pending.Seen != 0 {
+		t.Errorf("RUNNING again: shown %s, pending %+v; want RUNNING with nothing pending", got.Status, pending)
+	}
+
+	tests := []struct {
+		name  string
+		s     session
+		known bool
+		need  int
+	}{
+		{"first capture", idle, false, 2},
+		{"settle off", idle, true, 1},
+		{"certain", session{AI: "claude", Status: agentstatus.Exited, Confidence: agentstatus.Certain}, true, 2},
+		{"bell", session{AI: "claude", Status: agentstatus.Waiting, StatusSource: agentstatus.SourceBell, Confidence: agentstatus.Likely}, true, 2},
+	}
+	for _, tt := range tests {
+		if got, _ := settle(tt.s, running, tt.known, pendingStatus{}, tt.need); got.Status != tt.s.Status {
+			t.Errorf("%s: shown %s, want %s at once", tt.name, got.Status, tt.s.Status)
+		}
+	}
+}
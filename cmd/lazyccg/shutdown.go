@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// shutdownCtx is canceled when lazyccg starts to exit. Commands it runs in
+// the background (status commands, ssh probes) are tied to it so none
+// outlive the dashboard.
+var shutdownCtx = context.Background()
+
+// shutdown releases what the dashboard started, once the TUI has stopped
+// and shutdownCtx is canceled.
+func shutdown() {
+	// Deliver the last events to history and mirrors first
+	bus.Close()
+	sshProbes.Wait()
+	mirrors.Close()
+	scripts.Close()
+	closeBackend(backend)
+}
+
+// closeBackend closes connections b keeps open, such as kitty's remote
+// control socket.
+func closeBackend(b Backend) {
+	switch b := b.(type) {
+	case nestedTmuxBackend:
+		closeBackend(b.Backend)
+	case multiBackend:
+		for _, inner := range b.instances {
+			closeBackend(inner)
+		}
+	case interface{ Close() error }:
+		b.Close()
+	}
+}
+
+// mirrorPrefix starts the names of mirror files, which also carry the pid of
+// the lazyccg that wrote them.
+const mirrorPrefix = "lazyccg-mirror-"
+
+// cleanStale removes files left behind by processes that didn't exit
+// cleanly: lazyccg mirror files in tmpDir whose process is gone, and VS Code
+// extension sockets in tmpDir and ssh control sockets in sshDir that
+// nothing is listening on. It returns the removed paths.
+func cleanStale(tmpDir, sshDir string) ([]string, error) {
+	var removed []string
+	var errs []error
+	remove := func(path string) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+			return
+		}
+		removed = append(removed, path)
+	}
+
+	mirrorFiles, _ := filepath.Glob(filepath.Join(tmpDir, mirrorPrefix+"*.log"))
+	for _, path := range mirrorFiles {
+		var pid int
+		// Files from before mirrors recorded a pid don't parse and are stale
+		if _, err := fmt.Sscanf(strings.TrimPrefix(filepath.Base(path), mirrorPrefix), "%d-w", &pid); err == nil && processAlive(pid) {
+			continue
+		}
+		remove(path)
+	}
+
+	sockets, _ := filepath.Glob(filepath.Join(tmpDir, vscodeSocketGlob))
+	if sshDir != "" {
+		controls, _ := filepath.Glob(filepath.Join(sshDir, "lazyccg-*"))
+		sockets = append(sockets, controls...)
+	}
+	for _, path := range sockets {
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err == nil {
+			conn.Close()
+			continue
+		}
+		remove(path)
+	}
+	return removed, errors.Join(errs...)
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// sshControlDir is where ssh control sockets go (see sshOptions).
+func sshControlDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh")
+}
+
+// runCleanup implements -cleanup.
+func runCleanup() error {
+	removed, err := cleanStale(os.TempDir(), sshControlDir())
+	for _, path := range removed {
+		fmt.Println("removed", path)
+	}
+	if len(removed) == 0 && err == nil {
+		fmt.Println("nothing to clean up")
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestCleanStale(t *testing.T) {
+	tmp, sshDir := t.TempDir(), t.TempDir()
+	live := fmt.Sprintf("%s%d-w3-111.log", mirrorPrefix, os.Getpid())
+	for _, name := range []string{live, mirrorPrefix + "999999999-w3-222.log", mirrorPrefix + "3-333.log"} {
+		os.WriteFile(filepath.Join(tmp, name), nil, 0o600)
+	}
+	os.WriteFile(filepath.Join(sshDir, "lazyccg-me@dead:22"), nil, 0o600)
+	l, err := net.Listen("unix", filepath.Join(sshDir, "lazyccg-me@live:22"))
+	if err != nil {
+		t.Skip("unix sockets unavailable:", err)
+	}
+	defer l.Close()
+
+	removed, err := cleanStale(tmp, sshDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, path := range removed {
+		removed[i] = filepath.Base(path)
+	}
+	sort.Strings(removed)
+	want := []string{"lazyccg-me@dead:22", mirrorPrefix + "3-333.log", mirrorPrefix + "999999999-w3-222.log"}
+	if fmt.Sprint(removed) != fmt.Sprint(want) {
+		t.Errorf("cleanStale() removed %v, want %v", removed, want)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, live)); err != nil {
+		t.Error("the mirror of a running lazyccg was removed")
+	}
+}
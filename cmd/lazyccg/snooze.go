@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// snooze hides a session until a time passes or, when until is zero, until
+// its status changes from status.
+type snooze struct {
+	until  time.Time
+	status agentstatus.Status
+}
+
+var snoozeChoices = []struct {
+	label string
+	d     time.Duration // 0 = until the status changes
+}{
+	{"15 minutes", 15 * time.Minute},
+	{"1 hour", time.Hour},
+	{"until status change", 0},
+}
+
+func newSnooze(s session, d time.Duration, now time.Time) snooze {
+	if d == 0 {
+		return snooze{status: s.Status}
+	}
+	return snooze{until: now.Add(d)}
+}
+
+func (z snooze) expired(s session, now time.Time) bool {
+	if z.until.IsZero() {
+		return s.Status != z.status
+	}
+	return !now.Before(z.until)
+}
+
+// updateSnoozes drops snoozes that expired or whose window is gone and
+// returns the windows that woke up.
+func updateSnoozes(snoozed map[int]snooze, sessions []session, now time.Time) (map[int]snooze, []session) {
+	remaining := make(map[int]snooze)
+	var woke []session
+	for _, s := range sessions {
+		z, ok := snoozed[s.WindowID]
+		if !ok {
+			continue
+		}
+		if z.expired(s, now) {
+			woke = append(woke, s)
+		} else {
+			remaining[s.WindowID] = z
+		}
+	}
+	return remaining, woke
+}
+
+// withoutSnoozed returns sessions minus the snoozed ones.
+func withoutSnoozed(sessions []session, snoozed map[int]snooze) []session {
+	if len(snoozed) == 0 {
+		return sessions
+	}
+	var out []session
+	for _, s := range sessions {
+		if _, ok := snoozed[s.WindowID]; !ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (m model) updateSnoozePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "esc", "q", "z":
+		m.snoozePicking = false
+	case "up", "k":
+		if m.snoozeChoice > 0 {
+			m.snoozeChoice--
+		}
+	case "down", "j":
+		if m.snoozeChoice < len(snoozeChoices)-1 {
+			m.snoozeChoice++
+		}
+	case "enter", "1", "2", "3":
+		choice := m.snoozeChoice
+		if key != "enter" {
+			choice = int(key[0] - '1')
+		}
+		m.snoozePicking = false
+		if s, ok := m.selectedSession(); ok {
+			snoozed := make(map[int]snooze, len(m.snoozed)+1)
+			for id, z := range m.snoozed {
+				snoozed[id] = z
+			}
+			snoozed[s.WindowID] = newSnooze(s, snoozeChoices[choice].d, time.Now())
+			m.snoozed = snoozed
+			m.acknowledge(s.WindowID)
+			m.notice = fmt.Sprintf("snoozed %s (%s)", s.Title, snoozeChoices[choice].label)
+			if m.selected > 0 && m.selected >= len(m.filteredSessions()) {
+				m.selected--
+			}
+		}
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m model) renderSnoozePicker(width, height int) string {
+	boxWidth := min(width, 40)
+	var content []string
+	for i, c := range snoozeChoices {
+		line := fmt.Sprintf(" %d. %s", i+1, c.label)
+		if i == m.snoozeChoice {
+			line = selectedStyle.Render(line + strings.Repeat(" ", max(0, boxWidth-2-lipgloss.Width(line))))
+		}
+		content = append(content, line)
+	}
+	box := drawBox("Snooze", content, boxWidth, len(content)+2, gray)
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateSnoozes(t *testing.T) {
+	now := time.Now()
+	sessions := []session{
+		{WindowID: 1, Status: "RUNNING"},
+		{WindowID: 2, Status: "WAITING"},
+		{WindowID: 3, Status: "RUNNING"},
+		{WindowID: 4, Status: "RUNNING"},
+	}
+	snoozed := map[int]snooze{
+		1: newSnooze(sessions[0], 15*time.Minute, now.Add(-20*time.Minute)), // expired
+		2: {status: "RUNNING"},                                              // status changed
+		3: newSnooze(sessions[2], 0, now),                                   // still RUNNING
+		4: newSnooze(sessions[3], time.Hour, now),
+		5: {status: "RUNNING"}, // window gone
+	}
+
+	remaining, woke := updateSnoozes(snoozed, sessions, now)
+	if len(remaining) != 2 {
+		t.Errorf("remaining = %v, want windows 3 and 4", remaining)
+	}
+	if _, ok := remaining[3]; !ok {
+		t.Error("window 3 should stay snoozed")
+	}
+	if len(woke) != 2 || woke[0].WindowID != 1 || woke[1].WindowID != 2 {
+		t.Errorf("woke = %+v, want windows 1 and 2", woke)
+	}
+
+	if got := withoutSnoozed(sessions, remaining); len(got) != 2 || got[0].WindowID != 1 {
+		t.Errorf("withoutSnoozed() = %+v", got)
+	}
+}
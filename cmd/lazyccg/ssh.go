@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sshOptions keep one multiplexed connection per host open between polls,
+// so each `tmux list-panes` doesn't pay for a new handshake, and fail
+// instead of prompting for a password in the middle of the TUI.
+var sshOptions = []string{
+	"-o", "BatchMode=yes",
+	"-o", "ControlMaster=auto",
+	"-o", "ControlPath=~/.ssh/lazyccg-%r@%h:%p",
+	"-o", "ControlPersist=60",
+}
+
+// sshArgs builds the ssh arguments that run argv on host. ssh hands the
+// command to the remote shell as one string, so each word is quoted.
+func sshArgs(host string, argv []string) []string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	args := append([]string{}, sshOptions...)
+	return append(args, "--", host, strings.Join(quoted, " "))
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// parseHosts splits the -ssh flag. Host names are case-sensitive aliases
+// from ~/.ssh/config, so unlike prefixes they are not lowercased.
+func parseHosts(value string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = appendUnique(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// newSSHBackend returns the backend called name on host, driven through
+// ssh. "local" is this machine. Remote kitty has to be reached through
+// `kitty @ --to`, so kittySocket must name a socket on that host. "auto"
+// means kitty when kittySocket is set and tmux otherwise.
+func newSSHBackend(host, name, kittySocket string) (Backend, error) {
+	if host == "local" {
+		return newBackend(name, resolveKittySocket(kittySocket))
+	}
+	run := commandRunner{host: host}
+	if name == "auto" {
+		// There's no environment to look at on the remote host
+		name = "tmux"
+		if kittySocket != "" {
+			name = "kitty"
+		}
+	}
+	switch name {
+	case "kitty":
+		if kittySocket == "" {
+			return nil, fmt.Errorf("%s: kitty over ssh needs -kitty-socket (e.g. unix:/tmp/kitty)", host)
+		}
+		return kittyBackend{socket: kittySocket, run: run}, nil
+	case "tmux":
+		return tmuxBackend{run: run}, nil
+	case "wezterm":
+		return weztermBackend{run: run}, nil
+	}
+	return nil, fmt.Errorf("unknown backend %q (want kitty, tmux or wezterm)", name)
+}
+
+// newSSHBackends watches every host at once; each session row is tagged
+// with its host.
+func newSSHBackends(hosts []string, name, kittySocket string) (Backend, error) {
+	var m multiBackend
+	for _, host := range hosts {
+		b, err := newSSHBackend(host, name, kittySocket)
+		if err != nil {
+			return nil, err
+		}
+		m.instances = append(m.instances, b)
+		m.names = append(m.names, host)
+	}
+	if len(m.instances) == 1 {
+		return m.instances[0], nil
+	}
+	return m, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"tmux":                "tmux",
+		"unix:/tmp/kitty-42":  "unix:/tmp/kitty-42",
+		"":                    "''",
+		"#{pane_id}\t#{host}": "'#{pane_id}\t#{host}'",
+		"it's":                `'it'\''s'`,
+		"echo hi; rm -rf /":   "'echo hi; rm -rf /'",
+		"pid=,ppid=,args=":    "pid=,ppid=,args=",
+		"$(whoami)":           "'$(whoami)'",
+	}
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSSHCommand(t *testing.T) {
+	cmd := commandRunner{host: "devbox"}.command("tmux", "send-keys", "-l", "fix it")
+	args := cmd.Args[len(cmd.Args)-3:]
+	want := []string{"--", "devbox", "tmux send-keys -l 'fix it'"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("ssh args end with %q, want %q", args, want)
+	}
+
+	local := commandRunner{}.command("tmux", "ls")
+	if !reflect.DeepEqual(local.Args, []string{"tmux", "ls"}) {
+		t.Errorf("local command = %q", local.Args)
+	}
+}
+
+func TestNewSSHBackends(t *testing.T) {
+	b, err := newSSHBackends(parseHosts("devbox, gpu1,devbox"), "tmux", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := b.(multiBackend)
+	if !ok {
+		t.Fatalf("got %T, want multiBackend", b)
+	}
+	if !reflect.DeepEqual(m.names, []string{"devbox", "gpu1"}) {
+		t.Errorf("names = %q", m.names)
+	}
+	if got := backendRemote(b); got != "devbox,gpu1" {
+		t.Errorf("Remote() = %q", got)
+	}
+	if _, err := newSSHBackends([]string{"devbox"}, "kitty", ""); err == nil {
+		t.Error("kitty over ssh without a socket should fail")
+	}
+}
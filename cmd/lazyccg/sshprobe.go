@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A window whose foreground process is ssh shows a remote shell, and the
+// agent running there is invisible locally. With ssh_probe enabled,
+// lazyccg runs a command on the remote host (over its own ssh connection)
+// that prints one process command line per line, and treats the window as
+// running an agent when one of them matches.
+//
+// Several windows may be logged in to the same host, so a process only
+// counts for the window whose connection started it. Its SSH_CONNECTION
+// holds the client's port, which is the local port of the window's ssh. The
+// default command prints it before each command line, tab-separated; lines
+// without one, such as from a plain ps, never match.
+
+type sshProbeConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Command runs on the remote host; the default lists the user's
+	// processes. An agent-side helper can print just the agents instead.
+	Command string `yaml:"command"`
+	// Interval is how long a probe result is reused.
+	Interval time.Duration `yaml:"interval"`
+}
+
+const (
+	// defaultSSHProbeCommand prints "port<TAB>command line" for each of the
+	// user's processes, from /proc, or just the command lines from ps where
+	// there is no /proc.
+	defaultSSHProbeCommand = `if [ -d /proc/self ]; then
+  for d in /proc/[0-9]*; do
+    [ -O "$d" ] || continue
+    a=$(tr '\0' ' ' 2>/dev/null <"$d/cmdline") && [ -n "$a" ] || continue
+    p=$(tr '\0' '\n' 2>/dev/null <"$d/environ" | sed -n 's/^SSH_CONNECTION=[^ ]* \([0-9]*\) .*/\1/p')
+    printf '%s\t%s\n' "${p:--}" "$a"
+  done
+else
+  ps -o args= -U "$(id -u)"
+fi`
+	defaultSSHProbeInterval = 30 * time.Second
+	sshProbeTimeout         = 10 * time.Second
+)
+
+// probedProcess is a process on a remote host, with the client port of the
+// ssh connection it runs under, or 0 when that's unknown.
+type probedProcess struct {
+	clientPort int
+	cmdline    []string
+}
+
+type sshProbeResult struct {
+	procs   []probedProcess
+	checked time.Time
+	running bool
+}
+
+// sshProber caches probe results by host. Probes run in the background so
+// a slow host never holds up a poll.
+type sshProber struct {
+	mu      sync.Mutex
+	command string
+	every   time.Duration
+	hosts   map[string]*sshProbeResult
+	probe   func(host, command string) ([]probedProcess, error)
+	// localPort finds the local port of an ssh process's connection, or 0
+	localPort func(pid int) int
+	wg        sync.WaitGroup // probes in flight
+}
+
+var sshProbes *sshProber
+
+func newSSHProber(c sshProbeConfig) *sshProber {
+	p := &sshProber{command: c.Command, every: c.Interval, hosts: make(map[string]*sshProbeResult), probe: runSSHProbe, localPort: sshLocalPort}
+	if p.command == "" {
+		p.command = defaultSSHProbeCommand
+	}
+	if p.every <= 0 {
+		p.every = defaultSSHProbeInterval
+	}
+	return p
+}
+
+// sshOptionsWithValue are the ssh flags that take an argument.
+const sshOptionsWithValue = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// sshDestination returns the host an ssh command line connects to, or ""
+// when the process isn't ssh.
+func sshDestination(cmdline []string) string {
+	if len(cmdline) == 0 || filepath.Base(cmdline[0]) != "ssh" {
+		return ""
+	}
+	for i := 1; i < len(cmdline); i++ {
+		arg := cmdline[i]
+		if arg == "--" {
+			if i+1 < len(cmdline) {
+				return cmdline[i+1]
+			}
+			return ""
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return arg
+		}
+		// -p 22 takes the next argument, -p22 doesn't
+		if flag := arg[len(arg)-1:]; len(arg) == 2 && strings.Contains(sshOptionsWithValue, flag) {
+			i++
+		}
+	}
+	return ""
+}
+
+// agent returns the agent running in win's ssh login, from the last probe
+// of the host it's logged in to.
+func (p *sshProber) agent(win kittyWindow, prefixes []string) (string, bool) {
+	for _, proc := range win.ForegroundProcesses {
+		host := sshDestination(proc.Cmdline)
+		if host == "" {
+			continue
+		}
+		port := p.localPort(proc.Pid)
+		if port == 0 {
+			// A multiplexed ssh has no connection of its own to tell by
+			continue
+		}
+		var remote kittyWindow
+		for _, r := range p.processes(host) {
+			if r.clientPort == port {
+				remote.ForegroundProcesses = append(remote.ForegroundProcesses, foregroundProcess{Cmdline: r.cmdline})
+			}
+		}
+		if ai, ok := detectAgent(remote, prefixes); ok {
+			return ai, true
+		}
+	}
+	return "", false
+}
+
+// processes returns host's last probed processes, starting a new probe in
+// the background when the result is stale.
+func (p *sshProber) processes(host string) []probedProcess {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := p.hosts[host]
+	if r == nil {
+		r = &sshProbeResult{}
+		p.hosts[host] = r
+	}
+	if !r.running && time.Since(r.checked) >= p.every {
+		r.running = true
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			procs, err := p.probe(host, p.command)
+			if err != nil && debugLog != nil {
+				fmt.Fprintf(debugLog, "[%s] ssh probe %s: %v\n", time.Now().Format("15:04:05"), host, err)
+			}
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			r.procs, r.checked, r.running = procs, time.Now(), false
+		}()
+	}
+	return r.procs
+}
+
+// Wait waits for the probes in flight, which stop early once shutdownCtx
+// is canceled.
+func (p *sshProber) Wait() {
+	if p != nil {
+		p.wg.Wait()
+	}
+}
+
+func runSSHProbe(host, command string) ([]probedProcess, error) {
+	ctx, cancel := context.WithTimeout(shutdownCtx, sshProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ssh", sshArgs(host, []string{"sh", "-c", command})...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseProbeOutput(string(out)), nil
+}
+
+// parseProbeOutput reads one command line per line, each after its
+// client port and a tab when known: "53422\tnode /usr/bin/claude".
+func parseProbeOutput(out string) []probedProcess {
+	var procs []probedProcess
+	for _, line := range strings.Split(out, "\n") {
+		var port int
+		if before, after, ok := strings.Cut(line, "\t"); ok {
+			port, _ = strconv.Atoi(strings.TrimSpace(before))
+			line = after
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			procs = append(procs, probedProcess{clientPort: port, cmdline: fields})
+		}
+	}
+	return procs
+}
+
+// sshLocalPort returns the local port of the TCP connection ssh process pid
+// made, from /proc or else lsof, or 0 when it has none, as a multiplexed
+// client doesn't.
+func sshLocalPort(pid int) int {
+	if pid <= 0 {
+		return 0
+	}
+	if port, ok := procLocalPort(pid); ok {
+		return port
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sshProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "lsof", "-nP", "-a", "-p", strconv.Itoa(pid), "-iTCP", "-sTCP:ESTABLISHED", "-Fn").Output()
+	if err != nil {
+		return 0
+	}
+	return parseLsofPort(string(out))
+}
+
+// parseLsofPort returns the local port of the first connection in lsof's
+// -Fn output: "n10.0.0.2:53422->10.0.0.5:22".
+func parseLsofPort(out string) int {
+	for _, line := range strings.Split(out, "\n") {
+		name, ok := strings.CutPrefix(line, "n")
+		if !ok {
+			continue
+		}
+		local, _, ok := strings.Cut(name, "->")
+		if !ok {
+			continue
+		}
+		if i := strings.LastIndexByte(local, ':'); i >= 0 {
+			if port, err := strconv.Atoi(local[i+1:]); err == nil {
+				return port
+			}
+		}
+	}
+	return 0
+}
+
+// procLocalPort finds pid's first established TCP connection through its
+// file descriptors and /proc/<pid>/net/tcp. It reports false where there is
+// no /proc.
+func procLocalPort(pid int) (int, bool) {
+	dir := fmt.Sprintf("/proc/%d", pid)
+	fds, err := os.ReadDir(filepath.Join(dir, "fd"))
+	if err != nil {
+		return 0, false
+	}
+	ports := make(map[string]int) // socket inode -> local port
+	for _, table := range []string{"tcp", "tcp6"} {
+		data, _ := os.ReadFile(filepath.Join(dir, "net", table))
+		maps.Copy(ports, parseProcTCP(string(data)))
+	}
+	slices.SortFunc(fds, func(a, b os.DirEntry) int {
+		x, _ := strconv.Atoi(a.Name())
+		y, _ := strconv.Atoi(b.Name())
+		return x - y
+	})
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+		if err != nil {
+			continue
+		}
+		inode, ok := strings.CutPrefix(link, "socket:[")
+		if port, found := ports[strings.TrimSuffix(inode, "]")]; ok && found {
+			return port, true
+		}
+	}
+	return 0, true
+}
+
+// parseProcTCP reads the local ports of the established connections in a
+// /proc/net/tcp table, by socket inode.
+func parseProcTCP(table string) map[string]int {
+	ports := make(map[string]int)
+	for _, line := range strings.Split(table, "\n") {
+		// sl local_address rem_address st ... inode
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[3] != "01" {
+			continue
+		}
+		_, hex, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		if port, err := strconv.ParseUint(hex, 16, 16); err == nil {
+			ports[fields[9]] = int(port)
+		}
+	}
+	return ports
+}
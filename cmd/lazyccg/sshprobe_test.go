@@ -0,0 +1,102 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSSHDestination(t *testing.T) {
+	tests := []struct {
+		cmdline []string
+		want    string
+	}{
+		{[]string{"ssh", "devbox"}, "devbox"},
+		{[]string{"/usr/bin/ssh", "-p", "2222", "-A", "me@devbox"}, "me@devbox"},
+		{[]string{"ssh", "-p2222", "-o", "ServerAliveInterval=30", "devbox", "tmux", "attach"}, "devbox"},
+		{[]string{"ssh", "--", "devbox"}, "devbox"},
+		{[]string{"mosh", "devbox"}, ""},
+		{[]string{"ssh", "-v"}, ""},
+	}
+	for _, tt := range tests {
+		if got := sshDestination(tt.cmdline); got != tt.want {
+			t.Errorf("sshDestination(%q) = %q, want %q", tt.cmdline, got, tt.want)
+		}
+	}
+}
+
+func TestSSHProberAgent(t *testing.T) {
+	probed := make(chan string, 1)
+	p := newSSHProber(sshProbeConfig{})
+	p.probe = func(host, command string) ([]probedProcess, error) {
+		probed <- host
+		return parseProbeOutput("53422\t-bash\n53422\tnode /usr/local/bin/claude --resume\n53500\t-bash\n"), nil
+	}
+	p.localPort = func(pid int) int { return map[int]int{100: 53422, 101: 53500}[pid] }
+	win := kittyWindow{ForegroundProcesses: []foregroundProcess{{Pid: 100, Cmdline: []string{"ssh", "devbox"}}}}
+
+	// The first poll starts the probe; its result is used once it lands
+	if _, ok := p.agent(win, []string{"claude"}); ok {
+		t.Error("agent found before the probe finished")
+	}
+	if host := <-probed; host != "devbox" {
+		t.Errorf("probed %q, want devbox", host)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if ai, ok := p.agent(win, []string{"claude"}); ok {
+			if ai != "claude" {
+				t.Errorf("agent() = %q, want claude", ai)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("probe result never used")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case <-probed:
+		t.Error("fresh result was probed again")
+	default:
+	}
+
+	// Another window logged in to devbox runs only a shell
+	other := kittyWindow{ForegroundProcesses: []foregroundProcess{{Pid: 101, Cmdline: []string{"ssh", "devbox"}}}}
+	if ai, ok := p.agent(other, []string{"claude"}); ok {
+		t.Errorf("agent(other window) = %q; want none, claude runs in the first window's login", ai)
+	}
+	// A multiplexed ssh can't be told apart
+	muxed := kittyWindow{ForegroundProcesses: []foregroundProcess{{Pid: 102, Cmdline: []string{"ssh", "devbox"}}}}
+	if ai, ok := p.agent(muxed, []string{"claude"}); ok {
+		t.Errorf("agent(multiplexed) = %q; want none", ai)
+	}
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	got := parseProbeOutput("53422\tnode /usr/bin/claude\n-\t-bash\nvim notes.md\n\n")
+	want := []probedProcess{
+		{53422, []string{"node", "/usr/bin/claude"}},
+		{0, []string{"-bash"}},
+		{0, []string{"vim", "notes.md"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProbeOutput() = %v, want %v", got, want)
+	}
+}
+
+func TestLocalPortParsers(t *testing.T) {
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1111 1 0000000000000000 100 0 0 10 0
+   1: 0200000A:D0CE 0500000A:0016 01 00000000:00000000 02:000A7D5C 00000000  1000        0 2222 1 0000000000000000 20 4 30 10 -1
+`
+	if got, want := parseProcTCP(table), map[string]int{"2222": 53454}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProcTCP() = %v, want the established connection %v", got, want)
+	}
+	if got := parseLsofPort("p4242\nf3\nn10.0.0.2:53422->10.0.0.5:22\n"); got != 53422 {
+		t.Errorf("parseLsofPort() = %d, want 53422", got)
+	}
+	if got := parseLsofPort("p4242\n"); got != 0 {
+		t.Errorf("parseLsofPort(no connection) = %d, want 0", got)
+	}
+}
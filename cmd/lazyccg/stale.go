@@ -0,0 +1,40 @@
+package main
+
+import "time"
+
+// The Sessions panel shows how long each session has been in its status,
+// e.g. "RUNNING 12m", and marks sessions WAITING longer than stale_after as
+// STALE.
+
+// defaultStaleAfter is how long a session may wait on the user before it
+// is marked STALE.
+const defaultStaleAfter = 15 * time.Minute
+
+func staleAfter() time.Duration {
+	if cfg.StaleAfter > 0 {
+		return cfg.StaleAfter
+	}
+	return defaultStaleAfter
+}
+
+// stale reports whether s has been waiting on the user for longer than
+// after at now.
+func stale(s session, after time.Duration, now time.Time) bool {
+	return s.Status.IsWaiting() && !s.StatusSince.IsZero() && now.Sub(s.StatusSince) >= after
+}
+
+// statusAge renders how long a session has been in its status in one unit,
+// e.g. "45s", "12m", or "3h", or "" when that isn't known.
+func statusAge(since, now time.Time) string {
+	if since.IsZero() {
+		return ""
+	}
+	d := now.Sub(since)
+	switch {
+	case d >= time.Hour:
+		d = d.Truncate(time.Hour)
+	case d >= time.Minute:
+		d = d.Truncate(time.Minute)
+	}
+	return timeFmt.Duration(d)
+}
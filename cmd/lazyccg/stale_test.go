@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStale(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		s    session
+		want bool
+	}{
+		{"waiting too long", session{Status: "WAITING", StatusSince: now.Add(-20 * time.Minute)}, true},
+		{"approval too long", session{Status: "NEEDS_APPROVAL", StatusSince: now.Add(-15 * time.Minute)}, true},
+		{"waiting briefly", session{Status: "WAITING", StatusSince: now.Add(-time.Minute)}, false},
+		{"running long", session{Status: "RUNNING", StatusSince: now.Add(-time.Hour)}, false},
+		{"since unknown", session{Status: "WAITING"}, false},
+	}
+	for _, tt := range tests {
+		if got := stale(tt.s, 15*time.Minute, now); got != tt.want {
+			t.Errorf("%s: stale() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStatusAge(t *testing.T) {
+	saved := timeFmt
+	t.Cleanup(func() { timeFmt = saved })
+	timeFmt = newTimeFormatter("en_US", "")
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{45 * time.Second, "45s"},
+		{12*time.Minute + 30*time.Second, "12m"},
+		{3*time.Hour + 20*time.Minute, "3h"},
+	}
+	for _, tt := range tests {
+		if got := statusAge(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("statusAge(%v ago) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+	if got := statusAge(time.Time{}, now); got != "" {
+		t.Errorf("statusAge() of an unknown time = %q, want empty", got)
+	}
+}
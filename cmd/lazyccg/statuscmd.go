@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+const statusCommandTimeout = 2 * time.Second
+
+// runStatusCommand runs an external status provider for s. The captured
+// output is written to its stdin and session metadata is passed in the
+// environment. The first line of stdout, upper-cased, is the status.
+func runStatusCommand(command string, s session) (agentstatus.Status, error) {
+	ctx, cancel := context.WithTimeout(shutdownCtx, statusCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = strings.NewReader(strings.Join(s.Lines, "\n") + "\n")
+	cmd.Env = commandEnv(s)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("status command for %s: %w", s.AI, err)
+	}
+	return parseStatusOutput(stdout.String()), nil
+}
+
+// commandEnv passes session metadata to status and capture commands.
+func commandEnv(s session) []string {
+	return append(os.Environ(),
+		"LAZYCCG_AI="+s.AI,
+		"LAZYCCG_TITLE="+s.Title,
+		"LAZYCCG_CWD="+s.Cwd,
+		fmt.Sprintf("LAZYCCG_WINDOW_ID=%d", s.WindowID),
+		fmt.Sprintf("LAZYCCG_TAB_ID=%d", s.TabID),
+		"LAZYCCG_STATUS="+s.Status.String(),
+	)
+}
+
+// captureCommand returns the command that reads ai's output in place of a
+// screen capture, from the config or else ai's profile, or "".
+func captureCommand(ai string) string {
+	if command := cfg.CaptureCommands[ai]; command != "" {
+		return command
+	}
+	return profiles.capture(ai)
+}
+
+// captureWindow reads win's output: from ai's capture command while the
+// agent runs, falling back to the screen when the command fails. The
+// cursor is known when the screen was read from a terminal that tells.
+func captureWindow(win kittyWindow, tabID int, ai string, status agentstatus.Status, exited bool) (string, screenCursor, error) {
+	if command := captureCommand(ai); command != "" && !exited {
+		s := session{AI: ai, Title: win.Title, Cwd: win.Cwd, WindowID: win.ID, TabID: tabID, Status: status}
+		text, err := runCaptureCommand(command, s)
+		if err == nil {
+			return text, screenCursor{}, nil
+		}
+		if debugLog != nil {
+			fmt.Fprintf(debugLog, "[%s] %v\n", time.Now().Format("15:04:05"), err)
+		}
+	}
+	if c, ok := backend.(cursorBackend); ok {
+		return c.CaptureWithCursor(win.ID)
+	}
+	text, err := backend.CaptureText(win.ID, "")
+	return text, screenCursor{}, err
+}
+
+// runCaptureCommand runs command for the window s is in and returns its
+// stdout, which is treated like captured screen text. s has the window's
+// metadata and the previous poll's status.
+func runCaptureCommand(command string, s session) (string, error) {
+	ctx, cancel := context.WithTimeout(shutdownCtx, statusCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = commandEnv(s)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("capture command for %s: %w", s.AI, err)
+	}
+	return string(out), nil
+}
+
+// parseStatusOutput returns the first non-empty line of out as a status.
+func parseStatusOutput(out string) agentstatus.Status {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) != "" {
+			return agentstatus.Parse(line)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestParseStatusOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want agentstatus.Status
+	}{
+		{name: "single line", out: "waiting\n", want: "WAITING"},
+		{name: "leading blank lines", out: "\n\n  running  \nextra", want: "RUNNING"},
+		{name: "empty", out: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseStatusOutput(tt.out); got != tt.want {
+				t.Errorf("parseStatusOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunStatusCommand(t *testing.T) {
+	s := session{AI: "claude", Title: "api", Lines: []string{"foo", "> "}}
+
+	got, err := runStatusCommand(`tail -n 1 >/dev/null; echo "$LAZYCCG_AI-done"`, s)
+	if err != nil {
+		t.Fatalf("runStatusCommand() error = %v", err)
+	}
+	if got != "CLAUDE-DONE" {
+		t.Errorf("runStatusCommand() = %q, want %q", got, "CLAUDE-DONE")
+	}
+
+	if _, err := runStatusCommand("exit 3", s); err == nil {
+		t.Error("runStatusCommand() should fail on non-zero exit")
+	}
+}
+
+func TestCaptureWindow(t *testing.T) {
+	oldCfg, oldProfiles, oldBackend := cfg, profiles, backend
+	defer func() { cfg, profiles, backend = oldCfg, oldProfiles, oldBackend }()
+	backend = psBackend{}
+
+	p, err := parseProfile([]byte("name: aider\ncapture: echo from-profile\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiles = profileSet{byName: map[string]*profile{"aider": p}}
+	cfg = config{CaptureCommands: map[string]string{
+		"claude": `echo "$LAZYCCG_AI in $LAZYCCG_CWD"; echo line2`,
+		"codex":  "exit 1",
+	}}
+	win := kittyWindow{ID: 4, Cwd: "/src/api"}
+
+	tests := []struct {
+		ai   string
+		want string
+	}{
+		{"claude", "claude in /src/api\nline2\n"},
+		{"aider", "from-profile\n"},
+	}
+	for _, tt := range tests {
+		got, _, err := captureWindow(win, 1, tt.ai, "", false)
+		if err != nil || got != tt.want {
+			t.Errorf("captureWindow(%s) = %q, %v, want %q", tt.ai, got, err, tt.want)
+		}
+	}
+	// A failing command, an exited agent, and an agent without a command
+	// all fall back to the screen
+	for _, ai := range []string{"codex", "gemini"} {
+		if _, _, err := captureWindow(win, 1, ai, "", false); err != errNoCapture {
+			t.Errorf("captureWindow(%s) error = %v, want the screen capture's", ai, err)
+		}
+	}
+	if _, _, err := captureWindow(win, 1, "claude", "", true); err != errNoCapture {
+		t.Errorf("captureWindow() of an exited agent error = %v, want the screen capture's", err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// statusRule is a status rule from the config, e.g. for a shell prompt or
+// an agent version the built-in parsers get wrong:
+//
+//	status_rules:
+//	  - match: '^❯ $'
+//	    status: IDLE
+//	    lookback: 1
+//	  - ai: codex
+//	    match: 'esc to interrupt'
+//	    status: RUNNING
+//	    priority: 10
+//
+// Rules without ai apply to every agent. Higher priorities are tried first,
+// equal ones in file order; the first match wins.
+type statusRule struct {
+	profileRule `yaml:",inline"`
+	AI          string `yaml:"ai"`
+	Priority    int    `yaml:"priority"`
+}
+
+// compileStatusRules validates rules and sorts them by priority.
+func compileStatusRules(rules []statusRule) error {
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return fmt.Errorf("status_rules[%d]: %w", i, err)
+		}
+		rules[i].AI = strings.ToLower(strings.TrimSpace(rules[i].AI))
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority > rules[j].Priority })
+	return nil
+}
+
+// matchStatusRules returns the status of the first rule for ai that
+// matches lines and why, or "".
+func matchStatusRules(rules []statusRule, ai string, lines []string) (agentstatus.Status, string) {
+	for _, r := range rules {
+		if r.AI != "" && r.AI != ai {
+			continue
+		}
+		if line, ok := r.matchingLine(lines); ok {
+			return r.Status, "status rule " + r.why(line)
+		}
+	}
+	return "", ""
+}
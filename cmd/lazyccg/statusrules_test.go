@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+func TestStatusRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte(`status_rules:
+  - match: 'running'
+    status: idle
+    lookback: 1
+  - ai: codex
+    match: 'esc to interrupt'
+    status: RUNNING
+    priority: 10
+  - ai: codex
+    match: '^› $'
+    status: IDLE
+`), 0o644)
+	c, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.StatusRules[0].AI; got != "codex" || c.StatusRules[0].Priority != 10 {
+		t.Fatalf("rules not sorted by priority: %+v", c.StatusRules)
+	}
+
+	tests := []struct {
+		ai    string
+		lines []string
+		want  agentstatus.Status
+	}{
+		// The prompt of the user's shell says "running"
+		{"claude", []string{"[running] ~/src $"}, agentstatus.Idle},
+		{"claude", []string{"[running] ~/src $", "more"}, ""}, // outside lookback
+		{"codex", []string{"› ", "Working (esc to interrupt)", "› "}, agentstatus.Running},
+		{"codex", []string{"done", "› "}, agentstatus.Idle},
+		{"gemini", []string{"› "}, ""}, // codex only
+	}
+	for _, tt := range tests {
+		if got, _ := matchStatusRules(c.StatusRules, tt.ai, tt.lines); got != tt.want {
+			t.Errorf("matchStatusRules(%s, %q) = %q, want %q", tt.ai, tt.lines, got, tt.want)
+		}
+	}
+	_, why := matchStatusRules(c.StatusRules, "codex", []string{"Working (esc to interrupt)", ""})
+	if want := `status rule /esc to interrupt/ matched "Working (esc to interrupt)"`; why != want {
+		t.Errorf("matchStatusRules() why = %q, want %q", why, want)
+	}
+
+	os.WriteFile(path, []byte("status_rules:\n  - match: '('\n    status: IDLE\n"), 0o644)
+	if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), "status_rules[0]") {
+		t.Errorf("loadConfig() error = %v, want the bad rule named", err)
+	}
+}
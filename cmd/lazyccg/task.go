@@ -0,0 +1,55 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Under each session the Sessions panel shows what it is working on: the
+// task its spinner names, such as Claude Code's current todo, or else the
+// last prompt it was given.
+//
+//	✶ Running the auth tests… (esc to interrupt · ctrl+t to hide todos)
+//	> fix the flaky login test in auth_test.go
+
+// spinnerTask matches a spinner line naming its task, e.g. "✶ Running the
+// auth tests… (esc to interrupt)" or "⠋ Indexing the repository...".
+var spinnerTask = regexp.MustCompile(`^\s*[\x{2801}-\x{28FF}✻✽✢✳✶✺·*◐◓◑◒◴◷◶◵]\s+(\pL[^…(]*?)\s*(?:…|\.\.\.)`)
+
+// taskLines is how far up the screen a spinner naming a task is looked for.
+const taskLines = 8
+
+// currentTask returns what the screen says the agent is working on, or "".
+func currentTask(lines []string) string {
+	for i := len(lines) - 1; i >= max(len(lines)-taskLines, 0); i-- {
+		m := spinnerTask.FindStringSubmatch(lines[i])
+		// A single word is a spinner's verb, e.g. "Pondering…", not a task
+		if m != nil && len(strings.Fields(m[1])) > 1 {
+			return m[1]
+		}
+	}
+	return lastPrompt(lines)
+}
+
+// lastPrompt returns the latest user prompt visible in the capture. Like
+// extractPrompt it skips the last line, the live input box.
+func lastPrompt(lines []string) string {
+	for i := len(lines) - 2; i >= 0; i-- {
+		line := strings.TrimLeft(strings.TrimSpace(lines[i]), "│ ")
+		for _, marker := range promptMarkers {
+			if text, ok := strings.CutPrefix(line, marker); ok {
+				text = strings.TrimSpace(strings.TrimRight(text, "│"))
+				if len([]rune(text)) >= 10 {
+					return text
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// showTask reports whether the current task line is shown under each
+// session, as current_task says.
+func showTask() bool {
+	return cfg.CurrentTask
+}
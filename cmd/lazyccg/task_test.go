@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestCurrentTask(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"claude todo", []string{"> fix the login flow please", "⏺ Read(auth.go)", "✶ Running the auth tests… (esc to interrupt · ctrl+t to hide todos)", "> "}, "Running the auth tests"},
+		{"braille spinner", []string{"⠋ Indexing the repository... (esc to cancel)"}, "Indexing the repository"},
+		{"spinner verb falls back to prompt", []string{"> first prompt given here", "> add rate limiting to the API", "✻ Thinking… (esc to interrupt)", "> "}, "add rate limiting to the API"},
+		{"latest prompt", []string{"› write the migration for users", "• Done", "› now add an index on email", "› "}, "now add an index on email"},
+		{"short prompt skipped", []string{"> explain the cache layer", "> yes", "> "}, "explain the cache layer"},
+		{"nothing", []string{"$ ls", "README.md"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := currentTask(tt.lines); got != tt.want {
+				t.Errorf("currentTask() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Colors and styles of the current theme, set by usePalette. The colors are
+// named after the default theme's; other themes swap what they stand for
+// (accent, selection, text, muted, running, waiting, exited, web).
+var (
+	cyan     lipgloss.Color
+	darkCyan lipgloss.Color
+	white    lipgloss.Color
+	gray     lipgloss.Color
+	green    lipgloss.Color
+	yellow   lipgloss.Color
+	red      lipgloss.Color
+	magenta  lipgloss.Color
+
+	titleStyle    lipgloss.Style
+	selectedStyle lipgloss.Style
+
+	statusRunning lipgloss.Style
+	statusIdle    lipgloss.Style
+	statusWaiting lipgloss.Style
+	statusDone    lipgloss.Style
+	statusExited  lipgloss.Style
+
+	statusApproval lipgloss.Style
+	statusInput    lipgloss.Style
+	statusError    lipgloss.Style
+	statusLimited  lipgloss.Style
+
+	helpKeyStyle  lipgloss.Style
+	helpDescStyle lipgloss.Style
+)
+
+// palette is a theme's colors, by role.
+type palette struct {
+	accent, selection, text, muted lipgloss.Color
+	running, waiting, done, exited lipgloss.Color
+	web                            lipgloss.Color
+	approval, input, error         lipgloss.Color // WAITING sub-states
+	limited                        lipgloss.Color
+	bold                           bool // bold statuses
+	symbols                        bool // mark statuses with symbols by default
+}
+
+// palettes are the built-in themes. The colorblind-safe ones follow the
+// Okabe-Ito palette: blue and orange instead of green and red, which
+// deuteranopes and protanopes confuse.
+var palettes = map[string]palette{
+	"default": {
+		accent: "86", selection: "30", text: "255", muted: "240",
+		running: "78", waiting: "220", done: "86", exited: "203", web: "177",
+		approval: "208", input: "117", error: "197", limited: "105",
+	},
+	"high-contrast": {
+		accent: "51", selection: "21", text: "231", muted: "250",
+		running: "46", waiting: "226", done: "51", exited: "196", web: "201",
+		approval: "214", input: "123", error: "199", limited: "141",
+		bold: true, symbols: true,
+	},
+	"deuteranopia": {
+		accent: "117", selection: "24", text: "255", muted: "245",
+		running: "32", waiting: "214", done: "117", exited: "166", web: "175",
+		approval: "220", input: "153", error: "161", limited: "103",
+		symbols: true,
+	},
+	"protanopia": {
+		accent: "117", selection: "24", text: "255", muted: "245",
+		running: "32", waiting: "220", done: "117", exited: "175", web: "172",
+		approval: "214", input: "153", error: "133", limited: "109",
+		symbols: true,
+	},
+}
+
+// statusSymbols marks statuses with a symbol as well as a color.
+var statusSymbols bool
+
+var statusSymbol = map[agentstatus.Status]string{
+	agentstatus.Running: "▶",
+	agentstatus.Waiting: "✋",
+	agentstatus.Done:    "✔",
+	agentstatus.Exited:  "✖",
+	agentstatus.Idle:    "○",
+
+	agentstatus.NeedsApproval: "⚑",
+	agentstatus.NeedsInput:    "?",
+	agentstatus.Error:         "‼",
+	agentstatus.RateLimited:   "⊘",
+}
+
+// statusColumn shortens the statuses too wide for the Sessions column.
+var statusColumn = map[agentstatus.Status]string{
+	agentstatus.NeedsApproval: "APPROVE",
+	agentstatus.NeedsInput:    "INPUT",
+	agentstatus.RateLimited:   "LIMITED",
+}
+
+func init() { usePalette(palettes["default"]) }
+
+// applyTheme switches to the named theme. symbols overrides whether the
+// theme marks statuses with symbols.
+func applyTheme(name string, symbols *bool) error {
+	if name == "" {
+		name = "default"
+	}
+	p, ok := palettes[name]
+	if !ok {
+		names := make([]string, 0, len(palettes))
+		for n := range palettes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown theme %q (%s)", name, strings.Join(names, ", "))
+	}
+	usePalette(p)
+	statusSymbols = p.symbols
+	if symbols != nil {
+		statusSymbols = *symbols
+	}
+	return nil
+}
+
+func usePalette(p palette) {
+	cyan, darkCyan, white, gray = p.accent, p.selection, p.text, p.muted
+	green, yellow, red, magenta = p.running, p.waiting, p.exited, p.web
+
+	titleStyle = lipgloss.NewStyle().Foreground(cyan).Bold(true)
+	selectedStyle = lipgloss.NewStyle().Background(darkCyan).Foreground(white)
+
+	status := lipgloss.NewStyle().Bold(p.bold)
+	statusRunning = status.Foreground(green)
+	statusIdle = status.Foreground(gray)
+	statusWaiting = status.Foreground(yellow)
+	statusDone = status.Foreground(p.done)
+	statusExited = status.Foreground(red)
+	statusApproval = status.Foreground(p.approval)
+	statusInput = status.Foreground(p.input)
+	statusError = status.Foreground(p.error)
+	statusLimited = status.Foreground(p.limited)
+
+	helpKeyStyle = lipgloss.NewStyle().Foreground(cyan)
+	helpDescStyle = lipgloss.NewStyle().Foreground(gray)
+	toolStyles = newToolStyles()
+}
+
+// statusLabel is status with its symbol in front, when symbols are on.
+func statusLabel(status agentstatus.Status) string {
+	if sym, ok := statusSymbol[status]; ok && statusSymbols {
+		return sym + " " + status.String()
+	}
+	return status.String()
+}
+
+// statusSymbolCell is status's symbol padded to a fixed width, so statuses
+// line up in a column, or "" when symbols are off.
+func statusSymbolCell(status agentstatus.Status) string {
+	if !statusSymbols {
+		return ""
+	}
+	cell := statusSymbol[status] + " "
+	if pad := 3 - lipgloss.Width(cell); pad > 0 {
+		cell += strings.Repeat(" ", pad)
+	}
+	return cell
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestApplyTheme(t *testing.T) {
+	t.Cleanup(func() { applyTheme("", nil) })
+	for name, p := range palettes {
+		// Status must not hinge on telling two of these apart
+		colors := make(map[lipgloss.Color]bool)
+		for _, c := range []lipgloss.Color{p.running, p.waiting, p.exited, p.muted, p.approval, p.input, p.error, p.limited} {
+			colors[c] = true
+		}
+		if len(colors) != 8 {
+			t.Errorf("theme %s reuses a status color", name)
+		}
+		if err := applyTheme(name, nil); err != nil {
+			t.Errorf("applyTheme(%q) error = %v", name, err)
+		}
+		if statusSymbols != (name != "default") {
+			t.Errorf("theme %s: symbols = %v", name, statusSymbols)
+		}
+	}
+	if err := applyTheme("solarized", nil); err == nil {
+		t.Error("applyTheme() should reject an unknown theme")
+	}
+
+	off := false
+	applyTheme("protanopia", &off)
+	if got := statusLabel(agentstatus.Waiting); got != "WAITING" {
+		t.Errorf("statusLabel() with symbols off = %q", got)
+	}
+	on := true
+	applyTheme("", &on)
+	if got := statusLabel(agentstatus.Waiting); got != "✋ WAITING" {
+		t.Errorf("statusLabel() = %q, want ✋ WAITING", got)
+	}
+	if got := statusLabel("BLOCKED"); got != "BLOCKED" {
+		t.Errorf("statusLabel() of a custom status = %q", got)
+	}
+	if a, b := statusSymbolCell(agentstatus.Running), statusSymbolCell(agentstatus.Waiting); len([]rune(a)) != 3 || len([]rune(b)) != 2 {
+		t.Errorf("symbol cells %q and %q should both be 3 columns wide", a, b)
+	}
+
+	// Sub-states of WAITING fit the Sessions column
+	applyTheme("", &off)
+	m := model{}
+	for _, s := range []agentstatus.Status{agentstatus.NeedsApproval, agentstatus.NeedsInput, agentstatus.Error, agentstatus.RateLimited} {
+		if w := lipgloss.Width(m.formatStatus(s)); w != 7 {
+			t.Errorf("formatStatus(%s) is %d columns wide, want 7", s, w)
+		}
+	}
+}
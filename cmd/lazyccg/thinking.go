@@ -0,0 +1,99 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/atani/lazyccg/internal/ui"
+)
+
+// Agents that show their reasoning interleave it with their answers. With
+// split thinking on (T), the Output panel shows the two in stacked panes:
+// the response on top and the reasoning under it, both scrolled to the same
+// point in the capture.
+
+// streamLine is a line of the response or reasoning, with its index in the
+// capture.
+type streamLine struct {
+	index int
+	text  string
+}
+
+var (
+	// A reasoning header on a line of its own: "thinking" (codex exec),
+	// "∴ Thinking…" (Claude Code's transcript), "<thinking>". The spinner,
+	// "✻ Thinking… (esc to interrupt)", has more after it and doesn't count.
+	thinkingStartPattern = regexp.MustCompile(`(?i)^\s*(?:[∴✻✽✢*]\s*)?(?:thinking|reasoning)(?:…|\.\.\.|:)?\s*$|^\s*<thinking>\s*$`)
+	// Lines that end reasoning: the answer's header in codex exec, a
+	// message bullet from Claude Code or Gemini CLI, a closing tag, or the
+	// prompt.
+	responseStartPattern = regexp.MustCompile(`(?i)^\s*(?:codex|</thinking>)\s*$|^\s*[⏺●✦]|^\s*(?:│\s*)?[>›❯]`)
+)
+
+// splitThinking splits lines into reasoning and response. Reasoning runs
+// from a reasoning header to the next line that starts a response or a
+// tool call; everything else is response. Headers and tags are left out.
+func splitThinking(lines []string) (reasoning, response []streamLine) {
+	thinking := false
+	for i, line := range lines {
+		switch {
+		case thinkingStartPattern.MatchString(line):
+			thinking = true
+			continue
+		case responseStartPattern.MatchString(line):
+			thinking = false
+			if strings.TrimSpace(line) == "</thinking>" || strings.EqualFold(strings.TrimSpace(line), "codex") {
+				continue
+			}
+		case thinking:
+			if _, ok := parseToolCall(line); ok {
+				thinking = false
+			}
+		}
+		if thinking {
+			reasoning = append(reasoning, streamLine{i, line})
+		} else {
+			response = append(response, streamLine{i, line})
+		}
+	}
+	return reasoning, response
+}
+
+// streamTail returns the last n lines of stream from before capture index
+// end, keeping the two panes at the same point of the capture.
+func streamTail(stream []streamLine, end, n int) []streamLine {
+	stop := len(stream)
+	for stop > 0 && stream[stop-1].index >= end {
+		stop--
+	}
+	start := stop - n
+	if start < 0 {
+		start = 0
+	}
+	return stream[start:stop]
+}
+
+// renderSplitOutput renders the response and reasoning panes in rows lines
+// for the capture lines before end, or returns false when there is no
+// reasoning to split off.
+func renderSplitOutput(lines []string, end, rows, width int) ([]string, bool) {
+	reasoning, response := splitThinking(lines)
+	if len(reasoning) == 0 || rows < 3 {
+		return nil, false
+	}
+	reasoningRows := rows / 3
+	responseRows := rows - reasoningRows - 1
+
+	var out []string
+	for _, l := range streamTail(response, end, responseRows) {
+		out = append(out, renderOutputLine(l.text, width))
+	}
+	for len(out) < responseRows {
+		out = append(out, "")
+	}
+	out = append(out, helpDescStyle.Render(" ── reasoning "+strings.Repeat("─", max(0, width-14))))
+	for _, l := range streamTail(reasoning, end, reasoningRows) {
+		out = append(out, helpDescStyle.Render(" "+ui.Truncate(l.text, width-1)))
+	}
+	return out, true
+}
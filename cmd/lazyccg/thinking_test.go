@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitThinking(t *testing.T) {
+	lines := []string{
+		"> fix the flaky test",
+		"∴ Thinking…",
+		"  The test races on the port.",
+		"  A random port would fix it.",
+		"⏺ The test now listens on a random port.",
+		"● Bash(go test ./...)",
+		"thinking",
+		"Checking the other tests.",
+		"● Read(server_test.go)",
+		"<thinking>",
+		"Same race here.",
+		"</thinking>",
+		"Fixed both.",
+	}
+	reasoning, response := splitThinking(lines)
+	texts := func(stream []streamLine) []string {
+		var out []string
+		for _, l := range stream {
+			out = append(out, strings.TrimSpace(l.text))
+		}
+		return out
+	}
+	wantReasoning := []string{"The test races on the port.", "A random port would fix it.", "Checking the other tests.", "Same race here."}
+	if got := texts(reasoning); !reflect.DeepEqual(got, wantReasoning) {
+		t.Errorf("reasoning = %q, want %q", got, wantReasoning)
+	}
+	wantResponse := []string{"> fix the flaky test", "⏺ The test now listens on a random port.", "● Bash(go test ./...)", "● Read(server_test.go)", "Fixed both."}
+	if got := texts(response); !reflect.DeepEqual(got, wantResponse) {
+		t.Errorf("response = %q, want %q", got, wantResponse)
+	}
+
+	// The spinner isn't reasoning
+	if reasoning, _ := splitThinking([]string{"✻ Thinking… (3s · esc to interrupt)"}); len(reasoning) != 0 {
+		t.Errorf("spinner taken for reasoning: %q", texts(reasoning))
+	}
+}
+
+func TestStreamTail(t *testing.T) {
+	stream := []streamLine{{1, "a"}, {3, "b"}, {5, "c"}, {8, "d"}}
+	tests := []struct {
+		end, n int
+		want   string
+	}{
+		{9, 2, "cd"}, // at the bottom
+		{6, 2, "bc"}, // scrolled up past d
+		{4, 5, "ab"}, // fewer than n
+		{1, 3, ""},   // nothing yet
+		{100, 9, "abcd"},
+	}
+	for _, tt := range tests {
+		var got string
+		for _, l := range streamTail(stream, tt.end, tt.n) {
+			got += l.text
+		}
+		if got != tt.want {
+			t.Errorf("streamTail(end %d, %d) = %q, want %q", tt.end, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRenderSplitOutput(t *testing.T) {
+	if _, ok := renderSplitOutput([]string{"plain output", "> "}, 2, 10, 40); ok {
+		t.Error("output without reasoning should not be split")
+	}
+	out, ok := renderSplitOutput([]string{"thinking", "why", "codex", "answer"}, 4, 9, 40)
+	// 9 rows: 5 for the response, the separator, and 3 for the reasoning
+	if !ok || len(out) != 7 {
+		t.Fatalf("renderSplitOutput() = %d lines, %v; want the response padded to 5, the separator, and 1 reasoning line", len(out), ok)
+	}
+	if !strings.Contains(out[0], "answer") || !strings.Contains(out[5], "reasoning") || !strings.Contains(out[6], "why") {
+		t.Errorf("renderSplitOutput() = %q", out)
+	}
+}
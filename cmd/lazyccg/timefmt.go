@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// timeLocale holds the strings used to render times for a language.
+type timeLocale struct {
+	clock24 string // time.Format layout for 24-hour locales
+	clock12 string // time.Format layout for 12-hour locales
+	now     string
+	ago     string // format with one %s for the duration
+	units   [3]string
+	sep     string // between duration units
+}
+
+var timeLocales = map[string]timeLocale{
+	"en": {clock24: "15:04:05", clock12: "3:04:05 PM", now: "just now", ago: "%s ago", units: [3]string{"h", "m", "s"}, sep: " "},
+	"ja": {clock24: "15:04:05", clock12: "15:04:05", now: "たった今", ago: "%s前", units: [3]string{"時間", "分", "秒"}},
+	"de": {clock24: "15:04:05", clock12: "15:04:05", now: "gerade eben", ago: "vor %s", units: [3]string{"h", "min", "s"}, sep: " "},
+	"fr": {clock24: "15:04:05", clock12: "15:04:05", now: "à l'instant", ago: "il y a %s", units: [3]string{"h", "min", "s"}, sep: " "},
+}
+
+// twelveHourRegions use a 12-hour clock by default.
+var twelveHourRegions = map[string]bool{"US": true, "CA": true, "AU": true, "IN": true, "PH": true}
+
+// timeFormatter renders timestamps and durations consistently across the UI.
+type timeFormatter struct {
+	locale   timeLocale
+	twelveH  bool
+	relative bool
+}
+
+var timeFmt = newTimeFormatter("", "")
+
+// newTimeFormatter builds a formatter for locale (e.g. "ja_JP.UTF-8", empty
+// means $LC_ALL/$LC_TIME/$LANG) and style ("relative" or "absolute").
+func newTimeFormatter(locale, style string) timeFormatter {
+	if locale == "" {
+		locale = envLocale()
+	}
+	lang, region := parseLocale(locale)
+	loc, ok := timeLocales[lang]
+	if !ok {
+		loc = timeLocales["en"]
+	}
+	return timeFormatter{
+		locale:   loc,
+		twelveH:  twelveHourRegions[region],
+		relative: style == "relative",
+	}
+}
+
+func envLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseLocale splits "ja_JP.UTF-8" into ("ja", "JP").
+func parseLocale(locale string) (string, string) {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	lang, region, _ := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	return strings.ToLower(lang), strings.ToUpper(region)
+}
+
+// Clock renders t as a time of day.
+func (f timeFormatter) Clock(t time.Time) string {
+	if f.twelveH {
+		return t.Format(f.locale.clock12)
+	}
+	return t.Format(f.locale.clock24)
+}
+
+// Duration renders d with at most two units, e.g. "3m 12s" or "3分12秒".
+func (f timeFormatter) Duration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	u := f.locale.units
+	switch {
+	case h > 0 && m > 0:
+		return fmt.Sprintf("%d%s%s%d%s", h, u[0], f.locale.sep, m, u[1])
+	case h > 0:
+		return fmt.Sprintf("%d%s", h, u[0])
+	case m > 0 && s > 0:
+		return fmt.Sprintf("%d%s%s%d%s", m, u[1], f.locale.sep, s, u[2])
+	case m > 0:
+		return fmt.Sprintf("%d%s", m, u[1])
+	default:
+		return fmt.Sprintf("%d%s", s, u[2])
+	}
+}
+
+// Ago renders how long ago t was, e.g. "3m ago".
+func (f timeFormatter) Ago(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < time.Second {
+		return f.locale.now
+	}
+	if d >= time.Minute {
+		d = d.Truncate(time.Minute)
+	}
+	return fmt.Sprintf(f.locale.ago, f.Duration(d))
+}
+
+// Timestamp renders t in the configured style: relative or clock time.
+func (f timeFormatter) Timestamp(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if f.relative {
+		return f.Ago(t, now)
+	}
+	return f.Clock(t)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		in           string
+		lang, region string
+	}{
+		{"ja_JP.UTF-8", "ja", "JP"},
+		{"en-US", "en", "US"},
+		{"C", "c", ""},
+		{"de_DE@euro", "de", "DE"},
+	}
+	for _, tt := range tests {
+		lang, region := parseLocale(tt.in)
+		if lang != tt.lang || region != tt.region {
+			t.Errorf("parseLocale(%q) = (%q, %q), want (%q, %q)", tt.in, lang, region, tt.lang, tt.region)
+		}
+	}
+}
+
+func TestTimeFormatter(t *testing.T) {
+	now := time.Date(2026, 1, 19, 15, 4, 5, 0, time.UTC)
+	earlier := now.Add(-3*time.Minute - 12*time.Second)
+
+	tests := []struct {
+		name   string
+		locale string
+		style  string
+		want   string
+	}{
+		{name: "en_US absolute", locale: "en_US.UTF-8", want: "3:00:53 PM"},
+		{name: "en_GB absolute", locale: "en_GB.UTF-8", want: "15:00:53"},
+		{name: "en relative", locale: "en_US", style: "relative", want: "3m ago"},
+		{name: "ja relative", locale: "ja_JP.UTF-8", style: "relative", want: "3分前"},
+		{name: "de relative", locale: "de_DE", style: "relative", want: "vor 3min"},
+		{name: "unknown locale falls back to en", locale: "xx_YY", style: "relative", want: "3m ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTimeFormatter(tt.locale, tt.style)
+			if got := f.Timestamp(earlier, now); got != tt.want {
+				t.Errorf("Timestamp() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeFormatterDuration(t *testing.T) {
+	en := newTimeFormatter("en_US", "")
+	ja := newTimeFormatter("ja_JP", "")
+	tests := []struct {
+		d      time.Duration
+		en, ja string
+	}{
+		{45 * time.Second, "45s", "45秒"},
+		{3*time.Minute + 12*time.Second, "3m 12s", "3分12秒"},
+		{2*time.Hour + 5*time.Minute, "2h 5m", "2時間5分"},
+		{time.Hour, "1h", "1時間"},
+	}
+	for _, tt := range tests {
+		if got := en.Duration(tt.d); got != tt.en {
+			t.Errorf("en Duration(%v) = %q, want %q", tt.d, got, tt.en)
+		}
+		if got := ja.Duration(tt.d); got != tt.ja {
+			t.Errorf("ja Duration(%v) = %q, want %q", tt.d, got, tt.ja)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/atani/lazyccg/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Session titles are cut to 20 characters by default. title_width sets
+// another width in columns, or "auto" to give titles whatever the Sessions
+// panel has left; with title_scroll the selected session's title scrolls
+// instead of being cut.
+
+const (
+	defaultTitleWidth = 20
+	minTitleWidth     = 8
+	// titleReserve is the rest of a session row: borders, AI, status, and
+	// time in status.
+	titleReserve = 30
+	// marqueeEvery is how often a scrolling title moves by one character;
+	// it waits marqueePause steps before it starts.
+	marqueeEvery = 300 * time.Millisecond
+	marqueePause = 4
+)
+
+// validateTitleWidth checks title_width: empty, "auto", or a number of
+// columns.
+func validateTitleWidth(setting string) error {
+	if setting == "" || setting == "auto" {
+		return nil
+	}
+	if n, err := strconv.Atoi(setting); err != nil || n < minTitleWidth {
+		return fmt.Errorf("title_width: want auto or a number of columns (at least %d), got %q", minTitleWidth, setting)
+	}
+	return nil
+}
+
+// titleWidth is the columns titles get in a Sessions panel width wide, or
+// 0 for the default cut at 20 characters.
+func titleWidth(setting string, width int) int {
+	switch setting {
+	case "":
+		return 0
+	case "auto":
+		return max(minTitleWidth, width-titleReserve)
+	}
+	n, _ := strconv.Atoi(setting)
+	return n
+}
+
+// fitTitle cuts or, when selected and scrolling is on, scrolls name to the
+// configured width. step counts marquee steps since the selection changed.
+func fitTitle(name string, width int, selected bool, step int) string {
+	if width == 0 {
+		if selected && cfg.TitleScroll {
+			width = defaultTitleWidth
+		} else {
+			return ui.Truncate(name, defaultTitleWidth)
+		}
+	}
+	if selected && cfg.TitleScroll {
+		return ui.Marquee(name, width, max(0, step-marqueePause))
+	}
+	return ui.TruncateWidth(name, width)
+}
+
+type marqueeMsg struct{}
+
+func marqueeTick() tea.Cmd {
+	return tea.Tick(marqueeEvery, func(time.Time) tea.Msg { return marqueeMsg{} })
+}
+
+// stepMarquee moves the selected title along, or starts over when the
+// selection changed.
+func (m *model) stepMarquee() {
+	s, _ := m.selectedSession()
+	if s.WindowID != m.marqueeWindow {
+		m.marqueeWindow, m.marqueeStep = s.WindowID, 0
+		return
+	}
+	m.marqueeStep++
+}
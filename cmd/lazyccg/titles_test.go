@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestTitleWidth(t *testing.T) {
+	var c config
+	if err := yaml.Unmarshal([]byte("title_width: 30\n"), &c); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateTitleWidth(c.TitleWidth); err != nil {
+		t.Errorf("validateTitleWidth(%q) error = %v", c.TitleWidth, err)
+	}
+	for _, bad := range []string{"wide", "3"} {
+		if validateTitleWidth(bad) == nil {
+			t.Errorf("validateTitleWidth(%q) should fail", bad)
+		}
+	}
+
+	tests := []struct {
+		setting string
+		width   int
+		want    int
+	}{
+		{"", 80, 0},
+		{"30", 80, 30},
+		{"auto", 80, 50},
+		{"auto", 20, minTitleWidth},
+	}
+	for _, tt := range tests {
+		if got := titleWidth(tt.setting, tt.width); got != tt.want {
+			t.Errorf("titleWidth(%q, %d) = %d, want %d", tt.setting, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestFitTitle(t *testing.T) {
+	t.Cleanup(func() { cfg.TitleScroll = false })
+	long := "refactor the billing module"
+
+	if got := fitTitle(long, 0, true, 0); got != "refactor the bill..." {
+		t.Errorf("fitTitle() by default = %q", got)
+	}
+	if got := fitTitle("請求モジュールのリファクタリング", 12, false, 0); got != "請求モジュ…" {
+		t.Errorf("fitTitle() of a wide title = %q", got)
+	}
+
+	cfg.TitleScroll = true
+	if got := fitTitle(long, 12, true, marqueePause); got != "refactor the" {
+		t.Errorf("fitTitle() before scrolling = %q", got)
+	}
+	if got := fitTitle(long, 12, true, marqueePause+9); got != "the billing " {
+		t.Errorf("fitTitle() scrolled = %q", got)
+	}
+	if got := fitTitle(long, 12, false, marqueePause+9); got != "refactor th…" {
+		t.Errorf("fitTitle() of an unselected title = %q", got)
+	}
+}
+
+func TestStepMarquee(t *testing.T) {
+	m := model{sessions: []session{{WindowID: 1}, {WindowID: 2}}}
+	m.stepMarquee()
+	m.stepMarquee()
+	if m.marqueeWindow != 1 || m.marqueeStep != 1 {
+		t.Errorf("after two steps: window %d, step %d", m.marqueeWindow, m.marqueeStep)
+	}
+	m.selected = 1
+	m.stepMarquee()
+	if m.marqueeWindow != 2 || m.marqueeStep != 0 {
+		t.Errorf("a new selection should start over: window %d, step %d", m.marqueeWindow, m.marqueeStep)
+	}
+}
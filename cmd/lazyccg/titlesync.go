@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"github.com/atani/lazyccg/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultTitleTemplate = "{ai} {repo}:{branch}"
+
+// computeTitle expands the title template for s. Placeholders: {ai},
+// {repo}, {branch}, {task}, {status}. Empty placeholders and the separators
+// left dangling by them are removed.
+func computeTitle(template string, s session, git gitInfo) string {
+	if template == "" {
+		template = defaultTitleTemplate
+	}
+	repo := git.Repo
+	if repo == "" && s.Cwd != "" {
+		repo = filepath.Base(s.Cwd)
+	}
+	title := strings.NewReplacer(
+		"{ai}", s.AI,
+		"{repo}", repo,
+		"{branch}", git.Branch,
+		"{task}", ui.Truncate(s.Prompt, 30),
+		"{status}", s.Status.String(),
+	).Replace(template)
+	title = strings.Join(strings.Fields(title), " ")
+	return strings.Trim(title, " :/-·")
+}
+
+// titleSyncSkips lists the windows title sync leaves alone: those the user
+// renamed from lazyccg, and locked ones, which r can't rename either.
+func (m model) titleSyncSkips() map[int]bool {
+	skip := maps.Clone(m.renamed)
+	if skip == nil {
+		skip = make(map[int]bool)
+	}
+	for _, id := range m.view.Locked {
+		skip[id] = true
+	}
+	return skip
+}
+
+// syncTitlesCmd pushes computed names into window titles. Windows in skip
+// are left alone.
+func syncTitlesCmd(sessions []session, template string, skip map[int]bool) tea.Cmd {
+	return func() tea.Msg {
+		for _, s := range sessions {
+			if skip[s.WindowID] || s.WindowID == 0 {
+				continue
+			}
+			title := computeTitle(template, s, lookupGitInfo(s.Cwd))
+			if title == "" || title == s.Title {
+				continue
+			}
+			if err := backend.Rename(s.WindowID, title); err != nil && debugLog != nil {
+				fmt.Fprintf(debugLog, "[titlesync] window %d: %v\n", s.WindowID, err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeTitle(t *testing.T) {
+	s := session{AI: "claude", Cwd: "/src/api", Status: "RUNNING", Prompt: "fix the billing rounding bug"}
+
+	tests := []struct {
+		name     string
+		template string
+		git      gitInfo
+		want     string
+	}{
+		{name: "default", git: gitInfo{Repo: "api", Branch: "main"}, want: "claude api:main"},
+		{name: "no git falls back to cwd", want: "claude api"},
+		{name: "task", template: "{repo} · {task}", git: gitInfo{Repo: "api"}, want: "api · fix the billing rounding bug"},
+		{name: "status", template: "[{status}] {repo}", git: gitInfo{Repo: "web"}, want: "[RUNNING] web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeTitle(tt.template, s, tt.git); got != tt.want {
+				t.Errorf("computeTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleSyncSkips(t *testing.T) {
+	m := model{renamed: map[int]bool{3: true}}
+	m.view.Locked = []int{5}
+	if got, want := m.titleSyncSkips(), map[int]bool{3: true, 5: true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titleSyncSkips() = %v, want renamed and locked windows %v", got, want)
+	}
+	if m.renamed[5] {
+		t.Error("titleSyncSkips changed the renamed windows")
+	}
+}
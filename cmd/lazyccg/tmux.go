@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tmux panes are mapped onto the kitty layout: each tmux session becomes an
+// OS window, each tmux window a tab and each pane a window. Pane IDs (%N)
+// and window IDs (@N) are unique per server, so N is used as the ID.
+
+// tmuxBackend drives the tmux server of the current user, locally or on
+// run.host.
+type tmuxBackend struct {
+	run commandRunner
+}
+
+func (tmuxBackend) Name() string { return "tmux" }
+
+// Remote returns the SSH host, or "" for the local tmux.
+func (t tmuxBackend) Remote() string { return t.run.host }
+
+const tmuxPaneFormat = "#{session_name}\t#{window_id}\t#{window_name}\t#{pane_id}\t#{pane_title}\t#{pane_current_path}\t#{pane_pid}\t#{host}"
+
+func (t tmuxBackend) List() ([]kittyOSWindow, error) {
+	out, err := t.run.command("tmux", "list-panes", "-a", "-F", tmuxPaneFormat).Output()
+	if err != nil {
+		return nil, fmt.Errorf("tmux list-panes: %w", err)
+	}
+	ps, err := runPs(t.run, "-A", "-o", "pid=,ppid=,args=")
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	return t.run.mark(parseTmuxPanes(string(out), parseProcessTable(string(ps)))), nil
+}
+
+// parseTmuxPanes turns `tmux list-panes -F tmuxPaneFormat` output into
+// windows. A pane's foreground processes are its shell and every process
+// below it.
+func parseTmuxPanes(out string, procs processTable) []kittyOSWindow {
+	var osWindows []kittyOSWindow
+	sessionIndex := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 8 {
+			continue
+		}
+		sessionName, windowID, windowName, paneID, paneTitle, cwd, panePid, hostname := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7]
+		tabID, err1 := strconv.Atoi(strings.TrimPrefix(windowID, "@"))
+		winID, err2 := strconv.Atoi(strings.TrimPrefix(paneID, "%"))
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		// tmux defaults pane titles to the hostname, which says nothing
+		if paneTitle == hostname {
+			paneTitle = ""
+		}
+
+		idx, ok := sessionIndex[sessionName]
+		if !ok {
+			idx = len(osWindows)
+			sessionIndex[sessionName] = idx
+			osWindows = append(osWindows, kittyOSWindow{})
+		}
+		ow := &osWindows[idx]
+		var tab *kittyTab
+		for i := range ow.Tabs {
+			if ow.Tabs[i].ID == tabID {
+				tab = &ow.Tabs[i]
+			}
+		}
+		if tab == nil {
+			ow.Tabs = append(ow.Tabs, kittyTab{ID: tabID, Title: windowName})
+			tab = &ow.Tabs[len(ow.Tabs)-1]
+		}
+
+		win := kittyWindow{ID: winID, Title: paneTitle, Cwd: cwd}
+		if pid, err := strconv.Atoi(panePid); err == nil {
+			for _, p := range procs.tree(pid) {
+				win.ForegroundProcesses = append(win.ForegroundProcesses, foregroundProcess{Pid: p, Cwd: cwd, Cmdline: procs.args[p]})
+			}
+		}
+		tab.Windows = append(tab.Windows, win)
+	}
+	return osWindows
+}
+
+// processTable is a snapshot of `ps -A -o pid=,ppid=,args=`.
+type processTable struct {
+	args     map[int][]string
+	children map[int][]int
+}
+
+func parseProcessTable(out string) processTable {
+	t := processTable{args: make(map[int][]string), children: make(map[int][]int)}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		t.args[pid] = fields[2:]
+		t.children[ppid] = append(t.children[ppid], pid)
+	}
+	return t
+}
+
+// tree returns pid and all of its descendants.
+func (t processTable) tree(pid int) []int {
+	if _, ok := t.args[pid]; !ok {
+		return nil
+	}
+	pids := []int{pid}
+	for i := 0; i < len(pids); i++ {
+		pids = append(pids, t.children[pids[i]]...)
+	}
+	return pids
+}
+
+func tmuxTarget(windowID int) string {
+	return fmt.Sprintf("%%%d", windowID)
+}
+
+func (t tmuxBackend) CaptureText(windowID int, extent string) (string, error) {
+	args := []string{"capture-pane", "-p", "-J", "-t", tmuxTarget(windowID)}
+	if extent == "all" {
+		args = append(args, "-S", "-")
+	}
+	out, err := t.run.command("tmux", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (t tmuxBackend) Focus(windowID int) error {
+	target := tmuxTarget(windowID)
+	// switch-client fails outside tmux; selecting the window and pane still
+	// makes it current for the next attach
+	_ = t.run.command("tmux", "switch-client", "-t", target).Run()
+	if err := t.run.command("tmux", "select-window", "-t", target).Run(); err != nil {
+		return err
+	}
+	return t.run.command("tmux", "select-pane", "-t", target).Run()
+}
+
+func (t tmuxBackend) Rename(windowID int, title string) error {
+	return t.run.command("tmux", "select-pane", "-t", tmuxTarget(windowID), "-T", title).Run()
+}
+
+func (t tmuxBackend) SendText(windowID int, text string) error {
+	return t.run.command("tmux", "send-keys", "-t", tmuxTarget(windowID), "-l", text).Run()
+}
+
+// Scroll enters copy mode and scrolls up lines from the bottom.
+func (t tmuxBackend) Scroll(windowID, lines int) error {
+	target := tmuxTarget(windowID)
+	if err := t.run.command("tmux", "copy-mode", "-t", target).Run(); err != nil {
+		return err
+	}
+	if lines == 0 {
+		return nil
+	}
+	return t.run.command("tmux", "send-keys", "-t", target, "-X", "-N", strconv.Itoa(lines), "scroll-up").Run()
+}
+
+// Launch opens a tmux window running argv.
+func (t tmuxBackend) Launch(title string, argv []string) error {
+	return t.run.command("tmux", append([]string{"new-window", "-n", title}, argv...)...).Run()
+}
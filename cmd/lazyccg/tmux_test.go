@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+)
+
+func TestParseProcessTable(t *testing.T) {
+	procs := parseProcessTable(`    1     0 /sbin/init
+  100     1 -zsh
+  101   100 node /usr/local/bin/claude --resume
+  102   101 /bin/sh -c make test
+  200     1 -bash
+`)
+	if got, want := procs.tree(100), []int{100, 101, 102}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tree(100) = %v, want %v", got, want)
+	}
+	if got := procs.tree(999); got != nil {
+		t.Errorf("tree(999) = %v, want nil", got)
+	}
+	if got, want := procs.args[101], []string{"node", "/usr/local/bin/claude", "--resume"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args[101] = %v, want %v", got, want)
+	}
+}
+
+func TestParseTmuxPanes(t *testing.T) {
+	procs := parseProcessTable("100 1 -zsh\n101 100 claude\n200 1 -bash\n")
+	out := "work\t@1\tapi\t%3\thost\t/src/api\t100\thost\n" +
+		"work\t@1\tapi\t%4\tlogs\t/src/api\t200\thost\n" +
+		"play\t@2\tweb\t%5\thost\t/src/web\t300\thost\n"
+
+	osWindows := parseTmuxPanes(out, procs)
+	if len(osWindows) != 2 {
+		t.Fatalf("got %d OS windows, want 2", len(osWindows))
+	}
+	tab := osWindows[0].Tabs[0]
+	if tab.ID != 1 || tab.Title != "api" || len(tab.Windows) != 2 {
+		t.Fatalf("unexpected tab %+v", tab)
+	}
+	win := tab.Windows[0]
+	if win.ID != 3 || win.Title != "" || win.Cwd != "/src/api" {
+		t.Errorf("unexpected window %+v", win)
+	}
+	if ai, ok := agentsession.DetectAI(win, []string{"claude"}); !ok || ai != "claude" {
+		t.Errorf("agentsession.DetectAI() = %q, %v; want claude", ai, ok)
+	}
+	if tab.Windows[1].Title != "logs" {
+		t.Errorf("pane title = %q, want logs", tab.Windows[1].Title)
+	}
+	if _, ok := agentsession.DetectAI(osWindows[1].Tabs[0].Windows[0], []string{"claude"}); ok {
+		t.Error("pane with no running process should not match")
+	}
+}
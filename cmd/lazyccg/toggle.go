@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// `lazyccg toggle` makes the dashboard a pop-over: bound to a kitty
+// shortcut, it brings the dashboard's window forward, and from the
+// dashboard goes back to the window you were in. kitty says which window
+// has the keyboard; the one toggle left is kept in a file between runs.
+//
+//	map ctrl+shift+space launch --type=background lazyccg toggle
+
+// toggleTarget is what toggle focuses, given which window is focused and
+// which one it came from last time.
+type toggleTarget struct {
+	Window   int  // the window to focus
+	Launch   bool // no dashboard is running: start one
+	Previous int  // the window to go back to next time; 0 leaves it as is
+}
+
+// isDashboard reports whether cmdline runs the lazyccg dashboard rather
+// than one of its commands.
+func isDashboard(cmdline []string) bool {
+	if len(cmdline) == 0 || filepath.Base(cmdline[0]) != "lazyccg" {
+		return false
+	}
+	for _, arg := range cmdline[1:] {
+		if slices.Contains(commandNames, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// dashboardWindow finds the window the dashboard runs in, leaving out
+// process self, and the window that has the keyboard. A tmux pane counts as
+// focused when the kitty window showing its tmux is.
+func dashboardWindow(osWindows []kittyOSWindow, self int) (dashboard int, dashboardFocused bool, focused int, found bool) {
+	focusedIDs := map[int]bool{}
+	for _, ow := range osWindows {
+		for _, tab := range ow.Tabs {
+			for _, win := range tab.Windows {
+				if win.IsFocused {
+					focused = win.ID
+					focusedIDs[win.ID] = true
+				}
+			}
+		}
+	}
+	for _, ow := range osWindows {
+		if ow.Remote {
+			continue
+		}
+		for _, tab := range ow.Tabs {
+			for _, win := range tab.Windows {
+				for _, proc := range win.ForegroundProcesses {
+					if proc.Pid != self && isDashboard(proc.Cmdline) && !found {
+						dashboard, found = win.ID, true
+						dashboardFocused = focusedIDs[win.ID] || win.Parent != 0 && focusedIDs[win.Parent]
+					}
+				}
+			}
+		}
+	}
+	return dashboard, dashboardFocused, focused, found
+}
+
+// planToggle decides what toggle does: from the dashboard, go back to
+// previous; from anywhere else, go to the dashboard, starting one if none
+// is running, and remember where we were.
+func planToggle(osWindows []kittyOSWindow, self, previous int) (toggleTarget, error) {
+	dashboard, inDashboard, focused, found := dashboardWindow(osWindows, self)
+	if focused == 0 {
+		return toggleTarget{}, errors.New("toggle needs kitty: the terminal doesn't say which window is focused")
+	}
+	switch {
+	case !found:
+		return toggleTarget{Launch: true, Previous: focused}, nil
+	case !inDashboard:
+		return toggleTarget{Window: dashboard, Previous: focused}, nil
+	case previous == 0 || !hasWindow(osWindows, previous):
+		return toggleTarget{}, errors.New("the dashboard is focused and there is no window to go back to")
+	default:
+		return toggleTarget{Window: previous}, nil
+	}
+}
+
+func hasWindow(osWindows []kittyOSWindow, id int) bool {
+	for _, ow := range osWindows {
+		for _, tab := range ow.Tabs {
+			for _, win := range tab.Windows {
+				if win.ID == id {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func toggleStatePath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "toggle")
+}
+
+// loadToggleState reads the window toggle last left; 0 if none.
+func loadToggleState(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	id, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return id
+}
+
+// saveToggleState records the window toggle left; errors are ignored, it
+// only costs the way back.
+func saveToggleState(path string, id int) {
+	if path == "" {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(path), 0o755) == nil {
+		os.WriteFile(path, []byte(strconv.Itoa(id)+"\n"), 0o600)
+	}
+}
+
+// runToggle implements `lazyccg toggle`.
+func runToggle(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: lazyccg toggle")
+	}
+	osWindows, err := backend.List()
+	if err != nil {
+		return err
+	}
+	path := toggleStatePath()
+	target, err := planToggle(osWindows, os.Getpid(), loadToggleState(path))
+	if err != nil {
+		return err
+	}
+	if target.Previous != 0 {
+		saveToggleState(path, target.Previous)
+	}
+	if !target.Launch {
+		return backend.Focus(target.Window)
+	}
+	l, ok := defaultLauncher(backend)
+	if !ok {
+		return errors.New("no dashboard is running, and " + backend.Name() + " can't open a window for one")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "lazyccg"
+	}
+	return l.Launch("lazyccg", []string{exe})
+}
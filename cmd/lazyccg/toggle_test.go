@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/atani/lazyccg/pkg/kitty"
+)
+
+func TestIsDashboard(t *testing.T) {
+	tests := []struct {
+		cmdline []string
+		want    bool
+	}{
+		{[]string{"/usr/local/bin/lazyccg"}, true},
+		{[]string{"lazyccg", "-poll", "2s", "demo"}, true},
+		{[]string{"lazyccg", "toggle"}, false},
+		{[]string{"lazyccg", "-format", "json", "list"}, false},
+		{[]string{"less", "lazyccg"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := isDashboard(tt.cmdline); got != tt.want {
+			t.Errorf("isDashboard(%q) = %v, want %v", tt.cmdline, got, tt.want)
+		}
+	}
+}
+
+func TestPlanToggle(t *testing.T) {
+	layout := func(focused int, dashboard []string) []kittyOSWindow {
+		wins := []kittyWindow{
+			{ID: 1, ForegroundProcesses: []kitty.ForegroundProcess{{Pid: 100, Cmdline: []string{"claude"}}}},
+			{ID: 2, ForegroundProcesses: []kitty.ForegroundProcess{{Pid: 200, Cmdline: dashboard}}},
+			{ID: 3, ForegroundProcesses: []kitty.ForegroundProcess{{Pid: 300, Cmdline: []string{"lazyccg", "toggle"}}}},
+		}
+		for i := range wins {
+			wins[i].IsFocused = wins[i].ID == focused
+		}
+		return []kittyOSWindow{{Tabs: []kittyTab{{ID: 1, Windows: wins}}}}
+	}
+	tests := []struct {
+		name      string
+		osWindows []kittyOSWindow
+		previous  int
+		want      toggleTarget
+		wantErr   bool
+	}{
+		{"to the dashboard", layout(1, []string{"lazyccg"}), 0, toggleTarget{Window: 2, Previous: 1}, false},
+		{"back from the dashboard", layout(2, []string{"lazyccg"}), 1, toggleTarget{Window: 1}, false},
+		{"back to a closed window", layout(2, []string{"lazyccg"}), 9, toggleTarget{}, true},
+		{"no dashboard running", layout(1, []string{"zsh"}), 0, toggleTarget{Launch: true, Previous: 1}, false},
+		{"nothing focused", layout(0, []string{"lazyccg"}), 1, toggleTarget{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planToggle(tt.osWindows, 300, tt.previous)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("planToggle() = %+v, %v; want %+v (error: %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToggleState(t *testing.T) {
+	path := t.TempDir() + "/lazyccg/toggle"
+	if got := loadToggleState(path); got != 0 {
+		t.Errorf("loadToggleState() of no file = %d, want 0", got)
+	}
+	saveToggleState(path, 42)
+	if got := loadToggleState(path); got != 42 {
+		t.Errorf("loadToggleState() = %d, want 42", got)
+	}
+}
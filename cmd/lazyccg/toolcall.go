@@ -0,0 +1,95 @@
+package main
+
+import (
+	"regexp"
+
+	"github.com/atani/lazyccg/internal/ui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// toolCall is a tool invocation recognized in an agent transcript.
+type toolCall struct {
+	Kind string // shell, edit, read, search, web, other
+	Name string // tool name as printed by the agent, e.g. "Bash"
+	Arg  string
+}
+
+var (
+	// Claude Code: "● Bash(go test ./...)", "⏺ Update(main.go)"
+	claudeToolPattern = regexp.MustCompile(`^\s*[●⏺]\s*([A-Za-z][\w:.-]*)\((.*?)\)?\s*$`)
+	// Codex: "• Ran go test ./...", "• Edited main.go (+3 -1)"
+	codexToolPattern = regexp.MustCompile(`^\s*•\s*(Ran|Edited|Added|Deleted|Read|Explored|Searched|Listed)\s+(.*)$`)
+	// Gemini CLI: "✔ Shell go test ./...", "✓ ReadFile main.go"
+	geminiToolPattern = regexp.MustCompile(`^\s*[│ ]*[✔✓✗x]\s+(Shell|ReadFile|ReadManyFiles|ReadFolder|WriteFile|Edit|WebFetch|GoogleSearch|SearchText|FindFiles)\s*(.*?)[│ ]*$`)
+)
+
+var toolKinds = map[string]string{
+	"Bash": "shell", "Ran": "shell", "Shell": "shell",
+
+	"Edit": "edit", "MultiEdit": "edit", "Write": "edit", "Update": "edit", "NotebookEdit": "edit",
+	"Edited": "edit", "Added": "edit", "Deleted": "edit", "WriteFile": "edit",
+
+	"Read": "read", "ReadFile": "read", "ReadManyFiles": "read", "ReadFolder": "read",
+	"Explored": "read", "Listed": "read", "LS": "read",
+
+	"Grep": "search", "Glob": "search", "Search": "search", "Searched": "search",
+	"SearchText": "search", "FindFiles": "search",
+
+	"WebFetch": "web", "WebSearch": "web", "GoogleSearch": "web", "Fetch": "web",
+}
+
+// parseToolCall recognizes a tool invocation line from Claude Code, Codex,
+// or Gemini CLI output.
+func parseToolCall(line string) (toolCall, bool) {
+	for _, re := range []*regexp.Regexp{claudeToolPattern, codexToolPattern, geminiToolPattern} {
+		if m := re.FindStringSubmatch(line); m != nil {
+			kind, ok := toolKinds[m[1]]
+			if !ok {
+				kind = "other"
+			}
+			return toolCall{Kind: kind, Name: m[1], Arg: m[2]}, true
+		}
+	}
+	return toolCall{}, false
+}
+
+type toolStyle struct {
+	icon  string
+	style lipgloss.Style
+}
+
+// toolStyles tags each kind of tool call; set by usePalette.
+var toolStyles map[string]toolStyle
+
+func newToolStyles() map[string]toolStyle {
+	return map[string]toolStyle{
+		"shell":  {"$", lipgloss.NewStyle().Foreground(yellow)},
+		"edit":   {"✎", lipgloss.NewStyle().Foreground(green)},
+		"read":   {"≡", lipgloss.NewStyle().Foreground(gray)},
+		"search": {"⌕", lipgloss.NewStyle().Foreground(cyan)},
+		"web":    {"⇣", lipgloss.NewStyle().Foreground(magenta)},
+		"other":  {"⚙", lipgloss.NewStyle().Foreground(white)},
+	}
+}
+
+// renderOutputLine truncates line to width and tags tool calls with an icon
+// and color.
+func renderOutputLine(line string, width int) string {
+	call, ok := parseToolCall(line)
+	if !ok {
+		return " " + ui.Truncate(line, width-1)
+	}
+	ts := toolStyles[call.Kind]
+	return ts.style.Render(ts.icon + ui.Truncate(line, width-1))
+}
+
+// toolCallLines returns only the lines that are tool calls.
+func toolCallLines(lines []string) []string {
+	var out []string
+	for _, line := range lines {
+		if _, ok := parseToolCall(line); ok {
+			out = append(out, line)
+		}
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseToolCall(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   toolCall
+		wantOK bool
+	}{
+		{"● Bash(go test ./...)", toolCall{Kind: "shell", Name: "Bash", Arg: "go test ./..."}, true},
+		{"⏺ Update(cmd/lazyccg/main.go)", toolCall{Kind: "edit", Name: "Update", Arg: "cmd/lazyccg/main.go"}, true},
+		{"● WebFetch(https://example.com)", toolCall{Kind: "web", Name: "WebFetch", Arg: "https://example.com"}, true},
+		{"● mcp__github__create_issue(title: \"x\")", toolCall{Kind: "other", Name: "mcp__github__create_issue", Arg: "title: \"x\""}, true},
+		{"• Ran go build ./...", toolCall{Kind: "shell", Name: "Ran", Arg: "go build ./..."}, true},
+		{"• Edited README.md (+4 -0)", toolCall{Kind: "edit", Name: "Edited", Arg: "README.md (+4 -0)"}, true},
+		{"✔ ReadFile main.go", toolCall{Kind: "read", Name: "ReadFile", Arg: "main.go"}, true},
+		{"● I'll fix the failing test now.", toolCall{}, false},
+		{"• Working (3s • esc to interrupt)", toolCall{}, false},
+		{"plain output", toolCall{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseToolCall(tt.line)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseToolCall(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestToolCallLines(t *testing.T) {
+	lines := []string{"thinking", "● Bash(ls)", "output", "• Edited a.go (+1 -1)"}
+	want := []string{"● Bash(ls)", "• Edited a.go (+1 -1)"}
+	if got := toolCallLines(lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("toolCallLines() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// toolKindOrder is the display order of tool kinds in stats.
+var toolKindOrder = []string{"edit", "shell", "web", "search", "read", "other"}
+
+// toolStats counts tool calls by kind.
+type toolStats map[string]int
+
+// countToolCalls counts the tool calls in lines.
+func countToolCalls(lines []string) toolStats {
+	stats := make(toolStats)
+	for _, line := range lines {
+		if call, ok := parseToolCall(line); ok {
+			stats[call.Kind]++
+		}
+	}
+	return stats
+}
+
+// merge returns the sum of s and other.
+func (s toolStats) merge(other toolStats) toolStats {
+	out := make(toolStats, len(s))
+	for k, n := range s {
+		out[k] += n
+	}
+	for k, n := range other {
+		out[k] += n
+	}
+	return out
+}
+
+func (s toolStats) total() int {
+	total := 0
+	for _, n := range s {
+		total += n
+	}
+	return total
+}
+
+// String renders e.g. "5 edit · 3 shell · 1 web".
+func (s toolStats) String() string {
+	var parts []string
+	for _, kind := range toolKindOrder {
+		if n := s[kind]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+	if len(parts) == 0 {
+		return "no tool calls"
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestToolStats(t *testing.T) {
+	stats := countToolCalls([]string{
+		"● Bash(go test ./...)",
+		"● Edit(a.go)",
+		"● Edit(b.go)",
+		"some output",
+		"● WebFetch(https://go.dev)",
+	})
+	if got := stats.String(); got != "2 edit · 1 shell · 1 web" {
+		t.Errorf("String() = %q", got)
+	}
+	merged := stats.merge(toolStats{"shell": 2})
+	if merged["shell"] != 3 || merged.total() != 6 {
+		t.Errorf("merge() = %v", merged)
+	}
+	if got := (toolStats{}).String(); got != "no tool calls" {
+		t.Errorf("empty String() = %q", got)
+	}
+}
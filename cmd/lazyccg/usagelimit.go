@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Agents out of their plan's usage say when they can go on:
+//
+//	Claude usage limit reached. Your limit will reset at 5pm (America/New_York).
+//	Weekly limit reached ∙ resets Oct 20, 9am
+//	You've hit your usage limit. … or try again in 2 hours 13 minutes.
+//	You've hit your usage limit. … or try again at 5:04 PM.
+//
+// Codex's rollout logs say it too, in their rate_limits. The Sessions
+// title counts down to the reset of each agent out of usage, so the others
+// can be given the work until then.
+
+// usageLimit is when an agent's usage limit resets.
+type usageLimit struct {
+	Resets time.Time
+	Weekly bool   // a weekly limit rather than one of a few hours
+	Line   string // the banner it was read from, if from the screen
+}
+
+var (
+	// "resets 3pm", "reset at 5:30 PM (Asia/Tokyo)", "resets Oct 20, 9am"
+	limitResetClock = regexp.MustCompile(`(?i)(?:resets?|try again)(?: at| on)? (?:([a-z]{3}) (\d{1,2}),? (?:at )?)?(\d{1,2})(?::(\d{2}))? ?([ap]\.?m\.?)?(?: \(([\w/+-]+)\))?`)
+	// "try again in 2 hours 13 minutes", "try again in 4 days 1 hour"
+	limitResetIn   = regexp.MustCompile(`(?i)try again in ((?:\d+ (?:days?|hours?|minutes?|mins?|seconds?|secs?)(?:,? (?:and )?)?)+)`)
+	limitResetPart = regexp.MustCompile(`(\d+) (d|h|m|s)`)
+)
+
+// usageLimitLines is how far up the screen usage limit banners are looked
+// for.
+const usageLimitLines = 15
+
+// parseUsageLimit reads when the agent's usage limit resets from the last
+// banner on screen that says.
+func parseUsageLimit(lines []string, now time.Time) (usageLimit, bool) {
+	for i := len(lines) - 1; i >= max(len(lines)-usageLimitLines, 0); i-- {
+		line := strings.TrimSpace(lines[i])
+		lower := strings.ToLower(line)
+		if !strings.Contains(lower, "limit") {
+			continue
+		}
+		limit := usageLimit{Weekly: strings.Contains(lower, "weekly"), Line: line}
+		if m := limitResetIn.FindStringSubmatch(line); m != nil {
+			var d time.Duration
+			for _, part := range limitResetPart.FindAllStringSubmatch(strings.ToLower(m[1]), -1) {
+				n, _ := strconv.Atoi(part[1])
+				d += time.Duration(n) * map[string]time.Duration{"d": 24 * time.Hour, "h": time.Hour, "m": time.Minute, "s": time.Second}[part[2]]
+			}
+			limit.Resets = now.Add(d)
+			return limit, true
+		}
+		if m := limitResetClock.FindStringSubmatch(line); m != nil {
+			if resets, ok := resetTime(m, now); ok {
+				limit.Resets = resets
+				return limit, true
+			}
+		}
+	}
+	return usageLimit{}, false
+}
+
+// resetTime is the next time after now that a limitResetClock match names.
+func resetTime(m []string, now time.Time) (time.Time, bool) {
+	hour, _ := strconv.Atoi(m[3])
+	minute, _ := strconv.Atoi(m[4])
+	switch strings.ToLower(strings.ReplaceAll(m[5], ".", "")) {
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "":
+		if m[4] == "" {
+			// A bare number, e.g. "try again on 3 devices"
+			return time.Time{}, false
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return time.Time{}, false
+	}
+	loc := now.Location()
+	if m[6] != "" {
+		if l, err := time.LoadLocation(m[6]); err == nil {
+			loc = l
+		}
+	}
+	now = now.In(loc)
+	year, month, day := now.Date()
+	if m[1] != "" {
+		date, err := time.Parse("Jan 2", m[1]+" "+m[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		month, day = date.Month(), date.Day()
+	}
+	t := time.Date(year, month, day, hour, minute, 0, 0, loc)
+	for !t.After(now) {
+		if m[1] != "" {
+			t = t.AddDate(1, 0, 0)
+		} else {
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+	return t, true
+}
+
+// sessionUsageLimit is when s's usage limit resets, from its screen or
+// else its log. A relative "try again in 2 hours" is read once, from when
+// the banner first showed, not again on every poll.
+func sessionUsageLimit(lines []string, log usageLimit, prev session, now time.Time) usageLimit {
+	if limit, ok := parseUsageLimit(lines, now); ok {
+		if prev.Limit.Line == limit.Line && !prev.Limit.Resets.IsZero() {
+			return prev.Limit
+		}
+		return limit
+	}
+	return log
+}
+
+// agentLimit is the reset an agent waits for, shown in the Sessions title.
+type agentLimit struct {
+	AI string
+	usageLimit
+}
+
+// usageLimits returns, for each agent out of usage, the latest reset its
+// sessions wait for, soonest first.
+func usageLimits(sessions []session, now time.Time) []agentLimit {
+	latest := make(map[string]usageLimit)
+	for _, s := range sessions {
+		if s.Limit.Resets.After(now) && s.Limit.Resets.After(latest[s.AI].Resets) {
+			latest[s.AI] = s.Limit
+		}
+	}
+	limits := make([]agentLimit, 0, len(latest))
+	for ai, limit := range latest {
+		limits = append(limits, agentLimit{AI: ai, usageLimit: limit})
+	}
+	sort.Slice(limits, func(i, j int) bool {
+		if !limits[i].Resets.Equal(limits[j].Resets) {
+			return limits[i].Resets.Before(limits[j].Resets)
+		}
+		return limits[i].AI < limits[j].AI
+	})
+	return limits
+}
+
+// countdown renders the time left until the limit resets, e.g. "CL 2h13m"
+// or, for a weekly limit, "CO 52h wk".
+func (l agentLimit) countdown(now time.Time) string {
+	text := agents.label(l.AI) + " " + timeFmt.Duration(l.Resets.Sub(now).Truncate(time.Minute))
+	if l.Weekly {
+		text += " wk"
+	}
+	return text
+}
+
+// describe renders the limit for the Detail panel, e.g. "resets 17:00 (in
+// 2h13m)".
+func (l usageLimit) describe(now time.Time) string {
+	layout := "15:04"
+	if l.Resets.Sub(now) >= 24*time.Hour {
+		layout = "Jan 2 15:04"
+	}
+	text := fmt.Sprintf("resets %s (in %s)", l.Resets.In(now.Location()).Format(layout), timeFmt.Duration(l.Resets.Sub(now).Truncate(time.Minute)))
+	if l.Weekly {
+		text = "weekly, " + text
+	}
+	return text
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUsageLimit(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	now := time.Date(2025, 10, 16, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		lines  []string
+		want   time.Time
+		weekly bool
+		ok     bool
+	}{
+		{"claude reset at", []string{"Claude usage limit reached. Your limit will reset at 5pm."}, time.Date(2025, 10, 16, 17, 0, 0, 0, time.UTC), false, true},
+		{"claude time zone", []string{"5-hour limit reached ∙ resets 9am (Asia/Tokyo)"}, time.Date(2025, 10, 17, 9, 0, 0, 0, tokyo), false, true},
+		{"already past today", []string{"Your limit will reset at 1:15 PM"}, time.Date(2025, 10, 17, 13, 15, 0, 0, time.UTC), false, true},
+		{"weekly with date", []string{"Weekly limit reached ∙ resets Oct 20, 9am"}, time.Date(2025, 10, 20, 9, 0, 0, 0, time.UTC), true, true},
+		{"codex try again in", []string{"■ You've hit your usage limit. Upgrade to Pro, or try again in 2 hours 13 minutes."}, now.Add(2*time.Hour + 13*time.Minute), false, true},
+		{"codex try again in days", []string{"You've hit your usage limit. Try again in 4 days 1 hour."}, now.Add(97 * time.Hour), false, true},
+		{"codex try again at", []string{"You've hit your usage limit. Try again at 5:04 PM."}, time.Date(2025, 10, 16, 17, 4, 0, 0, time.UTC), false, true},
+		{"not a limit", []string{"Tests will reset at 5pm"}, time.Time{}, false, false},
+		{"no time", []string{"rate limit reached, retrying"}, time.Time{}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseUsageLimit(tt.lines, now)
+			if ok != tt.ok || !got.Resets.Equal(tt.want) || got.Weekly != tt.weekly {
+				t.Errorf("parseUsageLimit() = %v, weekly %v, %v; want %v, weekly %v, %v", got.Resets, got.Weekly, ok, tt.want, tt.weekly, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSessionUsageLimitKeepsRelativeReset(t *testing.T) {
+	lines := []string{"You've hit your usage limit. Try again in 2 hours."}
+	start := time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC)
+	first := sessionUsageLimit(lines, usageLimit{}, session{}, start)
+	later := sessionUsageLimit(lines, usageLimit{}, session{Limit: first}, start.Add(30*time.Minute))
+	if !later.Resets.Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("reset moved to %v on a later poll; want %v", later.Resets, start.Add(2*time.Hour))
+	}
+}
+
+func TestUsageLimits(t *testing.T) {
+	now := time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC)
+	sessions := []session{
+		{AI: "claude", Limit: usageLimit{Resets: now.Add(2 * time.Hour)}},
+		{AI: "claude", Limit: usageLimit{Resets: now.Add(3 * time.Hour)}},
+		{AI: "codex", Limit: usageLimit{Resets: now.Add(time.Hour), Weekly: true}},
+		{AI: "gemini", Limit: usageLimit{Resets: now.Add(-time.Hour)}},
+		{AI: "aider"},
+	}
+	var got []string
+	for _, l := range usageLimits(sessions, now) {
+		got = append(got, l.countdown(now))
+	}
+	want := []string{"CO 1h wk", "CL 3h"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("countdowns = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// View snapshots live in testdata/snapshots. After an intended layout
+// change, regenerate them with:
+//
+//	go test ./cmd/lazyccg -run TestViewSnapshots -update
+var updateSnapshots = flag.Bool("update", false, "rewrite the view snapshots in testdata/snapshots")
+
+// snapshotSessions is a fixed dashboard: no timestamps, so views don't
+// depend on the clock.
+func snapshotSessions() []session {
+	return []session{
+		{TabID: 1, WindowID: 11, Title: "api", AI: "claude", Status: "RUNNING", Cwd: "/src/api",
+			StatusSource: agentstatus.SourceActivity, Confidence: agentstatus.Likely,
+			Lines: []string{"> add rate limiting", "⏺ Read(internal/limit.go)", "⏺ Edit(internal/limit.go)", "✻ Thinking… (esc to interrupt)"}},
+		{TabID: 2, WindowID: 12, Title: "web", AI: "codex", Status: "WAITING", Cwd: "/src/web",
+			Lines: []string{"Allow command `npm test`?", "  1. Yes", "› 2. No, tell Codex what to do"}},
+		{TabID: 3, WindowID: 13, Title: "docs", AI: "gemini", Status: "IDLE", Cwd: "/src/docs",
+			Lines: []string{"✦ Updated README.md", "> "}},
+	}
+}
+
+// taskedSessions is snapshotSessions with the current tasks read from
+// their screens.
+func taskedSessions() []session {
+	sessions := snapshotSessions()
+	sessions[0].Task = "add rate limiting to the public API"
+	sessions[1].Task = "make the web tests pass"
+	sessions = append(sessions, session{TabID: 4, WindowID: 14, Title: "jobs", AI: "claude", Status: "ERROR", Cwd: "/src/jobs",
+		Task: "retry failed jobs", ErrorLine: "panic: runtime error: invalid memory address or nil pointer dereference"})
+	return sessions
+}
+
+// driveModel feeds msgs through Update the way the program would, dropping
+// the commands it returns.
+func driveModel(m model, msgs ...tea.Msg) model {
+	for _, msg := range msgs {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+	return m
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestViewSnapshots(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		msgs          []tea.Msg
+		theme         string
+		task          bool // current_task: true
+	}{
+		{name: "empty", width: 80, height: 20},
+		{name: "sessions-80x24", width: 80, height: 24, msgs: []tea.Msg{sessionsMsg{sessions: snapshotSessions()}}},
+		{name: "sessions-120x30", width: 120, height: 30, msgs: []tea.Msg{sessionsMsg{sessions: snapshotSessions()}}},
+		{name: "narrow-60x16", width: 60, height: 16, msgs: []tea.Msg{sessionsMsg{sessions: snapshotSessions()}}},
+		{name: "filter-waiting", width: 100, height: 24, msgs: []tea.Msg{
+			sessionsMsg{sessions: snapshotSessions()}, key("tab"), key("down"), key("down"), key("enter"),
+		}},
+		{name: "preview", width: 100, height: 24, msgs: []tea.Msg{sessionsMsg{sessions: snapshotSessions()}, key("p")}},
+		{name: "detail", width: 100, height: 24, msgs: []tea.Msg{sessionsMsg{sessions: snapshotSessions()}, key("d")}},
+		{name: "grouped-collapsed", width: 100, height: 24, msgs: []tea.Msg{
+			sessionsMsg{sessions: append(snapshotSessions(), session{TabID: 4, WindowID: 14, Title: "api-2", AI: "codex", Status: "IDLE", Cwd: "/src/api"})},
+			key("g"), key(" "), key("down"),
+		}},
+		{name: "group-filter", width: 100, height: 24, msgs: []tea.Msg{
+			sessionsMsg{sessions: append(snapshotSessions(), session{TabID: 4, WindowID: 14, Title: "api-2", AI: "codex", Status: "IDLE", Cwd: "/src/api"})},
+			key("g"), key(" "), key("enter"),
+		}},
+		{name: "current-task", width: 100, height: 24, msgs: []tea.Msg{sessionsMsg{sessions: taskedSessions()}}, task: true},
+		{name: "current-task-off", width: 100, height: 24, msgs: []tea.Msg{sessionsMsg{sessions: taskedSessions()}}},
+		{name: "status-symbols", width: 100, height: 24, msgs: []tea.Msg{sessionsMsg{sessions: snapshotSessions()}}, theme: "deuteranopia"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.theme != "" {
+				if err := applyTheme(tt.theme, nil); err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { applyTheme("", nil) })
+			}
+			if tt.task {
+				cfg.CurrentTask = true
+				t.Cleanup(func() { cfg.CurrentTask = false })
+			}
+			m := model{
+				pollEvery: time.Second,
+				poll:      newPollState(),
+				unread:    make(map[int]int),
+				renamed:   make(map[int]bool),
+				scroll:    make(map[int]scrollState),
+			}
+			m = driveModel(m, append([]tea.Msg{tea.WindowSizeMsg{Width: tt.width, Height: tt.height}}, tt.msgs...)...)
+			assertSnapshot(t, tt.name, m.View())
+		})
+	}
+}
+
+// assertSnapshot compares view, without styling, to its golden file.
+func assertSnapshot(t *testing.T, name, view string) {
+	t.Helper()
+	lines := strings.Split(ansi.Strip(view), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	got := strings.Join(lines, "\n") + "\n"
+
+	path := filepath.Join("testdata", "snapshots", name+".golden")
+	if *updateSnapshots {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("view differs from %s (run with -update if intended)\n--- got\n%s--- want\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	agentstatus "github.com/atani/lazyccg/pkg/status"
+)
+
+// A session's health is a score from 0 to 100 made up from the signs of an
+// agent in trouble: errors in its output, retries, rate limits, and being
+// stuck waiting. H sorts the sickest sessions first.
+
+// errorLinePattern matches lines that report a failure: "Error: ...",
+// "TypeError: ...", "FAIL", "3 failed", "Build failed", "exit code 1". Lines
+// that only mention errors, such as "0 failed" or "fix the error handling",
+// don't count.
+var (
+	errorLinePattern = regexp.MustCompile(`(?i)^\W*(?:error|fatal|panic)(?:\[\w+\])?:|\b[1-9]\d* (?:failed|failures?|errors?)\b|\b(?:build|tests?|command|compilation) failed\b|\bexit(?:ed)?(?: with)? (?:code|status):? ?[1-9]|\btraceback \(most recent call last\)|(?-i:\w(?:Error|Exception):|\bFAIL\b)`)
+	retryLinePattern = regexp.MustCompile(`(?i)\bretry(?:ing)?\b|\battempt \d+\s*/\s*\d+`)
+)
+
+// vitalsLines is how far up the screen the signs of trouble are counted;
+// older output is what the agent has already dealt with.
+const vitalsLines = 50
+
+// vitals is a session's health and what took from it.
+type vitals struct {
+	Score   int
+	Reasons []string // e.g. "3 errors", most costly first
+}
+
+// penalty is one sign of trouble and the points it costs.
+type penalty struct {
+	reason string
+	cost   int
+}
+
+// counted is the penalty for n occurrences of one thing, at per points
+// each, up to most.
+func counted(n int, one, many string, per, most int) penalty {
+	if n != 1 {
+		one = many
+	}
+	return penalty{fmt.Sprintf("%d %s", n, one), min(n*per, most)}
+}
+
+// sessionVitals scores s at now; waiting longer than stall counts as stuck.
+func sessionVitals(s session, stall time.Duration, now time.Time) vitals {
+	var errors, retries, limits int
+	lines := s.Lines
+	if len(lines) > vitalsLines {
+		lines = lines[len(lines)-vitalsLines:]
+	}
+	for _, line := range lines {
+		if agentstatus.RateLimitedOn([]string{line}) {
+			limits++
+		} else if errorLinePattern.MatchString(line) {
+			errors++
+		}
+		if retryLinePattern.MatchString(line) {
+			retries++
+		}
+	}
+	penalties := []penalty{
+		counted(errors, "error", "errors", 5, 25),
+		counted(retries, "retry", "retries", 5, 20),
+		counted(limits, "rate limit", "rate limits", 5, 20),
+	}
+	switch s.Status {
+	case agentstatus.Error:
+		penalties = append(penalties, penalty{"crashed", 30})
+	case agentstatus.RateLimited:
+		penalties = append(penalties, penalty{"rate limited", 15})
+	}
+	if stuck := now.Sub(s.StatusSince); stall > 0 && stuck > 0 && !s.StatusSince.IsZero() &&
+		(s.Status.IsWaiting() || s.Status == agentstatus.RateLimited) {
+		// Up to 25 points, all of them once it's been stuck for stall
+		penalties = append(penalties, penalty{"stuck " + statusAge(s.StatusSince, now), int(min(stuck, stall) * 25 / stall)})
+	}
+	sort.SliceStable(penalties, func(i, j int) bool { return penalties[i].cost > penalties[j].cost })
+
+	v := vitals{Score: 100}
+	for _, p := range penalties {
+		if p.cost > 0 {
+			v.Score -= p.cost
+			v.Reasons = append(v.Reasons, p.reason)
+		}
+	}
+	v.Score = max(v.Score, 0)
+	return v
+}
+
+// healthyScore is the lowest healthy score; the Sessions panel only shows
+// the scores under it.
+const healthyScore = 80
+
+// healthEmoji marks a score: 🟢 healthy, 🟡 struggling, 🔴 sick.
+func healthEmoji(score int) string {
+	switch {
+	case score >= healthyScore:
+		return "🟢"
+	case score >= 50:
+		return "🟡"
+	}
+	return "🔴"
+}
+
+// sortBySickness orders sessions from the lowest health score up, keeping
+// the order of equally healthy ones.
+func sortBySickness(sessions []session, stall time.Duration, now time.Time) []session {
+	type scored struct {
+		s     session
+		score int
+	}
+	all := make([]scored, len(sessions))
+	for i, s := range sessions {
+		all[i] = scored{s, sessionVitals(s, stall, now).Score}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score < all[j].score })
+	sorted := make([]session, len(all))
+	for i, a := range all {
+		sorted[i] = a.s
+	}
+	return sorted
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSessionVitals(t *testing.T) {
+	saved := timeFmt
+	t.Cleanup(func() { timeFmt = saved })
+	timeFmt = newTimeFormatter("en_US", "")
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		s           session
+		wantScore   int
+		wantReasons []string
+	}{
+		{"healthy", session{Status: "RUNNING", Lines: []string{"● Edit(main.go)", "All tests pass"}}, 100, nil},
+		{
+			"errors and retries",
+			session{Status: "RUNNING", Lines: []string{"Error: build failed", "Retrying (attempt 2/5)", "FAIL: TestParse", "Retrying (attempt 3/5)"}},
+			80, []string{"2 errors", "2 retries"},
+		},
+		{
+			"rate limited",
+			session{Status: "RATE_LIMITED", Lines: []string{"429 Too Many Requests", "429 Too Many Requests"}},
+			75, []string{"rate limited", "2 rate limits"},
+		},
+		{
+			"crashed and stuck",
+			session{Status: "ERROR", StatusSince: now.Add(-time.Hour), Lines: []string{"panic: nil map", "goroutine 1 [running]:"}},
+			40, []string{"crashed", "stuck 1h", "1 error"},
+		},
+		{
+			"errors cap out",
+			session{Status: "IDLE", Lines: []string{"error: x", "error: x", "error: x", "error: x", "error: x", "error: x", "error: x", "error: x"}},
+			75, []string{"8 errors"},
+		},
+		{
+			"mentions of errors",
+			session{Status: "IDLE", Lines: []string{"● I fixed the error handling in parse()", "Tests: 12 passed, 0 failed", "no exceptions raised"}},
+			100, nil,
+		},
+		{
+			"failure summaries",
+			session{Status: "IDLE", Lines: []string{"TypeError: x is undefined", "Tests: 3 failed, 9 passed", "Process exited with code 2"}},
+			85, []string{"3 errors"},
+		},
+		{
+			"old errors scrolled up",
+			session{Status: "IDLE", Lines: append([]string{"Error: build failed"}, make([]string, vitalsLines)...)},
+			100, nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := sessionVitals(tt.s, 15*time.Minute, now)
+			if v.Score != tt.wantScore || !reflect.DeepEqual(v.Reasons, tt.wantReasons) {
+				t.Errorf("sessionVitals() = %d %q, want %d %q", v.Score, v.Reasons, tt.wantScore, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestHealthEmoji(t *testing.T) {
+	for score, want := range map[int]string{100: "🟢", 80: "🟢", 79: "🟡", 50: "🟡", 49: "🔴", 0: "🔴"} {
+		if got := healthEmoji(score); got != want {
+			t.Errorf("healthEmoji(%d) = %s, want %s", score, got, want)
+		}
+	}
+}
+
+func TestSortBySickness(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []session{
+		{WindowID: 1, Status: "IDLE"},
+		{WindowID: 2, Status: "ERROR", Lines: []string{"Traceback (most recent call last):"}},
+		{WindowID: 3, Status: "RUNNING"},
+		{WindowID: 4, Status: "RUNNING", Lines: []string{"Retrying in 5s"}},
+	}
+	var ids []int
+	for _, s := range sortBySickness(sessions, 15*time.Minute, now) {
+		ids = append(ids, s.WindowID)
+	}
+	if want := []int{2, 4, 1, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("sortBySickness() = %v, want %v", ids, want)
+	}
+}
+
+func TestSelectionFollowsSort(t *testing.T) {
+	m := model{
+		pollEvery: time.Second,
+		poll:      newPollState(),
+		unread:    make(map[int]int),
+		renamed:   make(map[int]bool),
+		scroll:    make(map[int]scrollState),
+	}
+	m.view.Sort = "health"
+	m = driveModel(m, tea.WindowSizeMsg{Width: 100, Height: 24}, sessionsMsg{sessions: []session{
+		{TabID: 1, WindowID: 1, Title: "api", AI: "claude", Status: "RUNNING"},
+		{TabID: 2, WindowID: 2, Title: "web", AI: "codex", Status: "IDLE"},
+	}}, key("down"))
+	if s, _ := m.selectedSession(); s.WindowID != 2 {
+		t.Fatalf("selected window %d, want 2", s.WindowID)
+	}
+	// api gets sick and moves above web
+	m = driveModel(m, sessionsMsg{sessions: []session{
+		{TabID: 1, WindowID: 1, Title: "api", AI: "claude", Status: "ERROR", Lines: []string{"panic: nil map"}},
+		{TabID: 2, WindowID: 2, Title: "web", AI: "codex", Status: "IDLE"},
+	}})
+	if s, _ := m.selectedSession(); s.WindowID != 2 {
+		t.Errorf("after resorting, selected window %d, want 2", s.WindowID)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VS Code has no remote control for its integrated terminals, so a small
+// companion extension (contrib/vscode-lazyccg) serves them over a unix
+// socket, one per VS Code window. Each window becomes an OS window and each
+// terminal a tab with one window. The extension reports the shell's pid;
+// agents are matched by the processes on the shell's tty, as for iTerm2.
+
+// vscodeSocketGlob matches the sockets the extension listens on.
+const vscodeSocketGlob = "lazyccg-vscode-*.sock"
+
+const vscodeTimeout = 2 * time.Second
+
+type vscodeBackend struct {
+	dir string     // where the extension's sockets are
+	ids *windowIDs // socket + "\x00" + terminal id -> window ID
+}
+
+func newVSCodeBackend() vscodeBackend {
+	return vscodeBackend{dir: os.TempDir(), ids: newWindowIDs()}
+}
+
+func (vscodeBackend) Name() string { return "vscode" }
+
+// Limitations says what the dashboard can't do with this backend.
+func (vscodeBackend) Limitations() string {
+	return "vscode: output of shell-integrated commands only, no scroll"
+}
+
+type vscodeRequest struct {
+	Cmd    string `json:"cmd"`
+	ID     int    `json:"id,omitempty"`
+	Extent string `json:"extent,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+// vscodeTerminal is one terminal as listed by the extension.
+type vscodeTerminal struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Pid  int    `json:"pid"` // the shell
+	Cwd  string `json:"cwd"`
+}
+
+// call sends req to the extension listening on socket and decodes the
+// result into v, which may be nil.
+func (vscodeBackend) call(socket string, req vscodeRequest, v any) error {
+	conn, err := net.DialTimeout("unix", socket, vscodeTimeout)
+	if err != nil {
+		return fmt.Errorf("vscode: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(vscodeTimeout))
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("vscode %s: %w", req.Cmd, err)
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("vscode %s: %w", req.Cmd, err)
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("vscode %s: %w", req.Cmd, err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("vscode %s: %s", req.Cmd, resp.Error)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, v)
+}
+
+func (b vscodeBackend) List() ([]kittyOSWindow, error) {
+	sockets, err := filepath.Glob(filepath.Join(b.dir, vscodeSocketGlob))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(sockets)
+	listed := make(map[string][]vscodeTerminal)
+	for _, socket := range sockets {
+		var terminals []vscodeTerminal
+		if err := b.call(socket, vscodeRequest{Cmd: "list"}, &terminals); err != nil {
+			// VS Code quit without removing its socket
+			continue
+		}
+		listed[socket] = terminals
+	}
+	if len(listed) == 0 {
+		return nil, nil
+	}
+	ps, err := runPs(commandRunner{}, "-A", "-o", "tty=,pid=,args=")
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	return b.windows(sockets, listed, parseTTYProcesses(string(ps))), nil
+}
+
+// windows lays out the terminals of each VS Code window, in socket order,
+// with the processes on each shell's tty.
+func (b vscodeBackend) windows(sockets []string, listed map[string][]vscodeTerminal, byTTY map[string][]foregroundProcess) []kittyOSWindow {
+	ttyOf := make(map[int]string)
+	for tty, procs := range byTTY {
+		for _, proc := range procs {
+			ttyOf[proc.Pid] = tty
+		}
+	}
+	var osWindows []kittyOSWindow
+	for _, socket := range sockets {
+		terminals, ok := listed[socket]
+		if !ok {
+			continue
+		}
+		var ow kittyOSWindow
+		for _, t := range terminals {
+			id := b.ids.id(socket + "\x00" + strconv.Itoa(t.ID))
+			win := kittyWindow{ID: id, Title: t.Name, Cwd: t.Cwd}
+			if tty, ok := ttyOf[t.Pid]; ok {
+				for _, proc := range byTTY[tty] {
+					proc.Cwd = t.Cwd
+					win.ForegroundProcesses = append(win.ForegroundProcesses, proc)
+				}
+			}
+			ow.Tabs = append(ow.Tabs, kittyTab{ID: id, Title: t.Name, Windows: []kittyWindow{win}})
+		}
+		osWindows = append(osWindows, ow)
+	}
+	return osWindows
+}
+
+// terminal sends req to the terminal behind windowID.
+func (b vscodeBackend) terminal(windowID int, req vscodeRequest, v any) error {
+	key, err := b.ids.key(windowID)
+	if err != nil {
+		return err
+	}
+	socket, id, _ := strings.Cut(key, "\x00")
+	req.ID, _ = strconv.Atoi(id)
+	return b.call(socket, req, v)
+}
+
+func (b vscodeBackend) CaptureText(windowID int, extent string) (string, error) {
+	var text string
+	err := b.terminal(windowID, vscodeRequest{Cmd: "capture", Extent: extent}, &text)
+	return text, err
+}
+
+func (b vscodeBackend) Focus(windowID int) error {
+	return b.terminal(windowID, vscodeRequest{Cmd: "focus"}, nil)
+}
+
+func (b vscodeBackend) Rename(windowID int, title string) error {
+	return b.terminal(windowID, vscodeRequest{Cmd: "rename", Title: title}, nil)
+}
+
+func (b vscodeBackend) SendText(windowID int, text string) error {
+	return b.terminal(windowID, vscodeRequest{Cmd: "send", Text: text}, nil)
+}
+
+// Scroll is not supported: the extension API has no scroll position.
+func (vscodeBackend) Scroll(windowID, lines int) error { return nil }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+// serveVSCode answers extension requests on a socket in dir the way the
+// companion extension does.
+func serveVSCode(t *testing.T, dir string, handle func(vscodeRequest) (any, string)) string {
+	t.Helper()
+	socket := filepath.Join(dir, "lazyccg-vscode-42.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skip("unix sockets unavailable:", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			var req vscodeRequest
+			line, _ := bufio.NewReader(conn).ReadBytes('\n')
+			json.Unmarshal(line, &req)
+			result, errMsg := handle(req)
+			json.NewEncoder(conn).Encode(map[string]any{"result": result, "error": errMsg})
+			conn.Close()
+		}
+	}()
+	return socket
+}
+
+func TestVSCodeBackend(t *testing.T) {
+	dir := t.TempDir()
+	var sent vscodeRequest
+	socket := serveVSCode(t, dir, func(req vscodeRequest) (any, string) {
+		switch req.Cmd {
+		case "list":
+			return []vscodeTerminal{{ID: 3, Name: "zsh", Pid: 100, Cwd: "/src/api"}, {ID: 4, Name: "node", Pid: 200}}, ""
+		case "capture":
+			if req.ID != 3 {
+				return nil, "no terminal"
+			}
+			return "Allow edit?\n> ", ""
+		case "send":
+			sent = req
+			return nil, ""
+		}
+		return nil, "unknown command " + req.Cmd
+	})
+	b := vscodeBackend{dir: dir, ids: newWindowIDs()}
+
+	var terminals []vscodeTerminal
+	if err := b.call(socket, vscodeRequest{Cmd: "list"}, &terminals); err != nil {
+		t.Fatal(err)
+	}
+	byTTY := map[string][]foregroundProcess{
+		"ttys001": {{Pid: 100, Cmdline: []string{"-zsh"}}, {Pid: 101, Cmdline: []string{"claude"}}},
+	}
+	osWindows := b.windows([]string{socket, filepath.Join(dir, "gone.sock")}, map[string][]vscodeTerminal{socket: terminals}, byTTY)
+	if len(osWindows) != 1 || len(osWindows[0].Tabs) != 2 {
+		t.Fatalf("windows() = %+v", osWindows)
+	}
+	win := osWindows[0].Tabs[0].Windows[0]
+	if win.Title != "zsh" || len(win.ForegroundProcesses) != 2 || win.ForegroundProcesses[1].Cwd != "/src/api" {
+		t.Errorf("first terminal = %+v", win)
+	}
+	if procs := osWindows[0].Tabs[1].Windows[0].ForegroundProcesses; len(procs) != 0 {
+		t.Errorf("terminal with an unknown shell pid got processes %+v", procs)
+	}
+
+	text, err := b.CaptureText(win.ID, "")
+	if err != nil || text != "Allow edit?\n> " {
+		t.Errorf("CaptureText() = %q, %v", text, err)
+	}
+	if _, err := b.CaptureText(osWindows[0].Tabs[1].Windows[0].ID, ""); err == nil {
+		t.Error("extension errors should be returned")
+	}
+	if err := b.SendText(win.ID, "y"); err != nil || sent.ID != 3 || sent.Text != "y" {
+		t.Errorf("SendText() sent %+v, %v", sent, err)
+	}
+	if err := b.Focus(win.ID); err == nil {
+		t.Error("Focus() should fail for an unknown command")
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// waitingConfig configures the daily WAITING total.
+type waitingConfig struct {
+	// Nag shows a reminder when today's total passes each of these, e.g.
+	// [30m, 1h]. None by default.
+	Nag []time.Duration `yaml:"nag"`
+}
+
+// waitingSaveEvery limits how often the running total is written to disk.
+const waitingSaveEvery = time.Minute
+
+// waitTracker adds up the time sessions spend WAITING on the user, in any of
+// its sub-states, per calendar day. Two sessions waiting for a minute count two minutes.
+// Snoozed sessions don't count.
+type waitTracker struct {
+	path   string // where today's total is kept; "" keeps it in memory
+	day    string // 2006-01-02 of total, local time
+	total  time.Duration
+	nagged int               // nag thresholds passed today
+	since  map[int]time.Time // windowID -> waiting counted up to
+	saved  time.Time
+}
+
+// waitingFile is the saved total.
+type waitingFile struct {
+	Day     string  `json:"day"`
+	Minutes float64 `json:"minutes"`
+}
+
+func waitingPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "waiting.json")
+}
+
+// loadWaitTracker picks up today's total from path, if it was saved today.
+func loadWaitTracker(path string, now time.Time) *waitTracker {
+	t := &waitTracker{path: path, day: now.Format(time.DateOnly), since: make(map[int]time.Time)}
+	if path == "" {
+		return t
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return t
+	}
+	var f waitingFile
+	if json.Unmarshal(data, &f) == nil && f.Day == t.day {
+		t.total = time.Duration(f.Minutes * float64(time.Minute))
+		t.nagged = passed(cfg.Waiting.Nag, t.total)
+	}
+	return t
+}
+
+// passed counts the thresholds total has reached.
+func passed(thresholds []time.Duration, total time.Duration) int {
+	n := 0
+	for _, d := range thresholds {
+		if total >= d {
+			n++
+		}
+	}
+	return n
+}
+
+// update counts the time since the last poll for sessions still WAITING and
+// returns a nag when today's total just passed a threshold, or "".
+func (t *waitTracker) update(sessions []session, snoozed map[int]snooze, nag []time.Duration, now time.Time) string {
+	if t == nil {
+		return ""
+	}
+	if day := now.Format(time.DateOnly); day != t.day {
+		// A new day: what's waiting counts from midnight
+		t.save()
+		y, mo, d := now.Date()
+		midnight := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+		for id, since := range t.since {
+			if since.Before(midnight) {
+				t.since[id] = midnight
+			}
+		}
+		t.day, t.total, t.nagged = day, 0, 0
+	}
+	waiting := make(map[int]bool)
+	for _, s := range sessions {
+		if !s.Status.IsWaiting() {
+			continue
+		}
+		if _, ok := snoozed[s.WindowID]; ok {
+			continue
+		}
+		waiting[s.WindowID] = true
+		if since, ok := t.since[s.WindowID]; ok && now.After(since) {
+			t.total += now.Sub(since)
+		}
+		t.since[s.WindowID] = now
+	}
+	for id := range t.since {
+		if !waiting[id] {
+			delete(t.since, id)
+		}
+	}
+	if now.Sub(t.saved) >= waitingSaveEvery {
+		t.save()
+		t.saved = now
+	}
+
+	thresholds := append([]time.Duration{}, nag...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+	n := passed(thresholds, t.total)
+	if n <= t.nagged {
+		return ""
+	}
+	t.nagged = n
+	return fmt.Sprintf("agents have waited %s on you today", timeFmt.Duration(thresholds[n-1]))
+}
+
+// Today returns today's total.
+func (t *waitTracker) Today() time.Duration {
+	if t == nil {
+		return 0
+	}
+	return t.total
+}
+
+// save writes today's total; errors are ignored, the total is a hint.
+func (t *waitTracker) save() {
+	if t == nil || t.path == "" {
+		return
+	}
+	data, _ := json.Marshal(waitingFile{Day: t.day, Minutes: t.total.Minutes()})
+	err := os.WriteFile(t.path, data, 0o600)
+	if errors.Is(err, os.ErrNotExist) {
+		if os.MkdirAll(filepath.Dir(t.path), 0o755) == nil {
+			os.WriteFile(t.path, data, 0o600)
+		}
+	}
+}
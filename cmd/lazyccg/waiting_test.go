@@ -0,0 +1,56 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWaitTracker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lazyccg", "waiting.json")
+	start := time.Date(2026, 3, 2, 23, 0, 0, 0, time.Local)
+	w := loadWaitTracker(path, start)
+	nag := []time.Duration{time.Hour, 20 * time.Minute}
+
+	waiting := []session{{WindowID: 1, Status: "WAITING"}, {WindowID: 2, Status: "WAITING"}, {WindowID: 3, Status: "RUNNING"}}
+	if got := w.update(waiting, nil, nag, start); got != "" || w.Today() != 0 {
+		t.Fatalf("first poll: nag %q, total %s", got, w.Today())
+	}
+	// Two sessions waiting 10 minutes each
+	got := w.update(waiting, nil, nag, start.Add(10*time.Minute))
+	if w.Today() != 20*time.Minute || got != "agents have waited 20m on you today" {
+		t.Errorf("after 10m: total %s, nag %q", w.Today(), got)
+	}
+	// Window 2 is snoozed, window 1 answered: nothing more counts
+	if got := w.update(waiting[1:], map[int]snooze{2: {}}, nag, start.Add(30*time.Minute)); got != "" || w.Today() != 20*time.Minute {
+		t.Errorf("snoozed: total %s, nag %q", w.Today(), got)
+	}
+	w.update(waiting[:1], nil, nag, start.Add(40*time.Minute))
+
+	// Saved, and picked up again the same day
+	w.save()
+	if again := loadWaitTracker(path, start.Add(45*time.Minute)); again.Today() != 20*time.Minute {
+		t.Errorf("reloaded total = %s, want 20m", again.Today())
+	}
+
+	// Past midnight only time since midnight counts
+	w.update(waiting[:1], nil, nag, start.Add(70*time.Minute))
+	if w.Today() != 10*time.Minute {
+		t.Errorf("total after midnight = %s, want 10m", w.Today())
+	}
+	if again := loadWaitTracker(path, start.Add(71*time.Minute)); again.Today() != 10*time.Minute {
+		t.Errorf("yesterday's total should not carry over, got %s", again.Today())
+	}
+}
+
+func TestWaitingConfig(t *testing.T) {
+	var c config
+	if err := yaml.Unmarshal([]byte("waiting:\n  nag: [30m, 1h]\n"), &c); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Waiting.Nag; len(got) != 2 || got[0] != 30*time.Minute || got[1] != time.Hour {
+		t.Errorf("nag = %v", got)
+	}
+}
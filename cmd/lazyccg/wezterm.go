@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// wezterm panes map onto the kitty layout the same way tmux panes do: GUI
+// windows become OS windows, tabs stay tabs and panes become windows.
+
+// weztermBackend drives WezTerm through `wezterm cli`, locally or on
+// run.host.
+type weztermBackend struct {
+	run commandRunner
+}
+
+func (weztermBackend) Name() string { return "wezterm" }
+
+// Remote returns the SSH host, or "" for the local WezTerm.
+func (w weztermBackend) Remote() string { return w.run.host }
+
+type weztermPane struct {
+	WindowID int    `json:"window_id"`
+	TabID    int    `json:"tab_id"`
+	PaneID   int    `json:"pane_id"`
+	Title    string `json:"title"`
+	TabTitle string `json:"tab_title"`
+	Cwd      string `json:"cwd"` // file://host/path
+	TTYName  string `json:"tty_name"`
+}
+
+func (w weztermBackend) List() ([]kittyOSWindow, error) {
+	out, err := w.run.command("wezterm", "cli", "list", "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("wezterm cli list: %w", err)
+	}
+	var panes []weztermPane
+	if err := json.Unmarshal(out, &panes); err != nil {
+		return nil, fmt.Errorf("json parse: %w", err)
+	}
+	ps, err := runPs(w.run, "-A", "-o", "tty=,pid=,args=")
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	return w.run.mark(weztermWindows(panes, parseTTYProcesses(string(ps)))), nil
+}
+
+// weztermWindows groups panes into windows. wezterm does not report
+// processes, so a pane's foreground processes are those on its tty.
+func weztermWindows(panes []weztermPane, byTTY map[string][]foregroundProcess) []kittyOSWindow {
+	var osWindows []kittyOSWindow
+	windowIndex := make(map[int]int)
+	for _, p := range panes {
+		idx, ok := windowIndex[p.WindowID]
+		if !ok {
+			idx = len(osWindows)
+			windowIndex[p.WindowID] = idx
+			osWindows = append(osWindows, kittyOSWindow{})
+		}
+		ow := &osWindows[idx]
+		var tab *kittyTab
+		for i := range ow.Tabs {
+			if ow.Tabs[i].ID == p.TabID {
+				tab = &ow.Tabs[i]
+			}
+		}
+		if tab == nil {
+			ow.Tabs = append(ow.Tabs, kittyTab{ID: p.TabID, Title: p.TabTitle})
+			tab = &ow.Tabs[len(ow.Tabs)-1]
+		}
+
+		cwd := p.Cwd
+		if u, err := url.Parse(p.Cwd); err == nil && u.Scheme == "file" {
+			cwd = u.Path
+		}
+		win := kittyWindow{ID: p.PaneID, Title: p.Title, Cwd: cwd}
+		for _, proc := range byTTY[strings.TrimPrefix(p.TTYName, "/dev/")] {
+			proc.Cwd = cwd
+			win.ForegroundProcesses = append(win.ForegroundProcesses, proc)
+		}
+		tab.Windows = append(tab.Windows, win)
+	}
+	return osWindows
+}
+
+// parseTTYProcesses groups `ps -A -o tty=,pid=,args=` output by tty.
+func parseTTYProcesses(out string) map[string][]foregroundProcess {
+	byTTY := make(map[string][]foregroundProcess)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] == "?" || fields[0] == "??" {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		byTTY[fields[0]] = append(byTTY[fields[0]], foregroundProcess{Pid: pid, Cmdline: fields[2:]})
+	}
+	return byTTY
+}
+
+// weztermScrollbackLines is how far back get-text reaches for extent "all";
+// wezterm clamps it to the scrollback it has.
+const weztermScrollbackLines = 100000
+
+func (w weztermBackend) CaptureText(windowID int, extent string) (string, error) {
+	args := []string{"cli", "get-text", "--pane-id", strconv.Itoa(windowID)}
+	if extent == "all" {
+		args = append(args, "--start-line", strconv.Itoa(-weztermScrollbackLines))
+	}
+	out, err := w.run.command("wezterm", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (w weztermBackend) Focus(windowID int) error {
+	return w.run.command("wezterm", "cli", "activate-pane", "--pane-id", strconv.Itoa(windowID)).Run()
+}
+
+// Rename sets the title of the pane's tab; wezterm has no pane titles of its
+// own.
+func (w weztermBackend) Rename(windowID int, title string) error {
+	return w.run.command("wezterm", "cli", "set-tab-title", "--pane-id", strconv.Itoa(windowID), title).Run()
+}
+
+func (w weztermBackend) SendText(windowID int, text string) error {
+	return w.run.command("wezterm", "cli", "send-text", "--pane-id", strconv.Itoa(windowID), "--no-paste", text).Run()
+}
+
+var errWeztermUnsupported = errors.New("not supported by wezterm cli")
+
+// Scroll is not supported: wezterm cli cannot scroll a pane.
+func (weztermBackend) Scroll(windowID, lines int) error {
+	return errWeztermUnsupported
+}
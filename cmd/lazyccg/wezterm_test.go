@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	agentsession "github.com/atani/lazyccg/pkg/session"
+)
+
+func TestParseTTYProcesses(t *testing.T) {
+	byTTY := parseTTYProcesses(`?        1 /sbin/init
+pts/3   100 -zsh
+pts/3   101 node /usr/local/bin/codex
+ttys004 200 -bash
+`)
+	if len(byTTY["pts/3"]) != 2 || len(byTTY["ttys004"]) != 1 {
+		t.Fatalf("unexpected grouping: %v", byTTY)
+	}
+	if _, ok := byTTY["?"]; ok {
+		t.Error("processes without a tty should be skipped")
+	}
+}
+
+func TestWeztermWindows(t *testing.T) {
+	panes := []weztermPane{
+		{WindowID: 0, TabID: 1, PaneID: 2, Title: "codex", TabTitle: "api", Cwd: "file://host/src/api", TTYName: "/dev/pts/3"},
+		{WindowID: 0, TabID: 1, PaneID: 3, Title: "zsh", Cwd: "file://host/src/api", TTYName: "/dev/pts/4"},
+		{WindowID: 1, TabID: 5, PaneID: 6, Cwd: "file://host/tmp", TTYName: "/dev/pts/5"},
+	}
+	byTTY := parseTTYProcesses("pts/3 100 -zsh\npts/3 101 node /usr/local/bin/codex\npts/4 200 -zsh\n")
+
+	osWindows := weztermWindows(panes, byTTY)
+	if len(osWindows) != 2 || len(osWindows[0].Tabs) != 1 || len(osWindows[0].Tabs[0].Windows) != 2 {
+		t.Fatalf("unexpected layout: %+v", osWindows)
+	}
+	win := osWindows[0].Tabs[0].Windows[0]
+	if win.ID != 2 || win.Cwd != "/src/api" {
+		t.Errorf("unexpected window %+v", win)
+	}
+	if ai, ok := agentsession.DetectAI(win, []string{"codex"}); !ok || ai != "codex" {
+		t.Errorf("agentsession.DetectAI() = %q, %v; want codex", ai, ok)
+	}
+	if _, ok := agentsession.DetectAI(osWindows[0].Tabs[0].Windows[1], []string{"codex"}); ok {
+		t.Error("shell pane should not match")
+	}
+}
@@ -0,0 +1,158 @@
+// lazyccg companion: serves VS Code's integrated terminals to lazyccg over
+// a unix socket, lazyccg-vscode-<pid>.sock in the temp dir, one per window.
+// Each connection carries one JSON request line and gets one JSON response
+// line: {"result": ...} or {"error": "..."}.
+//
+// Output is read through shell integration, so only output of commands run
+// from an integrated shell (e.g. `claude`) is captured.
+
+const fs = require('fs');
+const net = require('net');
+const os = require('os');
+const path = require('path');
+const vscode = require('vscode');
+
+const MAX_LINES = 5000; // kept per terminal
+const SCREEN_LINES = 50; // returned for a "screen" capture
+
+const ids = new Map(); // Terminal -> id
+const buffers = new Map(); // id -> lines
+const pendingCR = new Set(); // ids whose output ended in \r, maybe half of \r\n
+let nextId = 1;
+let server;
+let socketPath;
+
+function idOf(terminal) {
+  if (!ids.has(terminal)) {
+    ids.set(terminal, nextId++);
+  }
+  return ids.get(terminal);
+}
+
+function terminalById(id) {
+  for (const [terminal, tid] of ids) {
+    if (tid === id) {
+      return terminal;
+    }
+  }
+  return undefined;
+}
+
+// append adds output to a terminal's lines. A carriage return starts the
+// current line over, as spinners and progress bars redraw it.
+function append(id, data) {
+  const lines = buffers.get(id) || [''];
+  if (pendingCR.delete(id) && !data.startsWith('\n')) {
+    data = '\r' + data;
+  }
+  if (data.endsWith('\r')) {
+    pendingCR.add(id);
+    data = data.slice(0, -1);
+  }
+  data.replace(/\r\n/g, '\n').split('\n').forEach((part, i) => {
+    if (i > 0) {
+      lines.push('');
+    }
+    const cr = part.lastIndexOf('\r');
+    if (cr >= 0) {
+      lines[lines.length - 1] = part.slice(cr + 1);
+    } else {
+      lines[lines.length - 1] += part;
+    }
+  });
+  if (lines.length > MAX_LINES) {
+    lines.splice(0, lines.length - MAX_LINES);
+  }
+  buffers.set(id, lines);
+}
+
+async function handle(req) {
+  if (req.cmd === 'list') {
+    return Promise.all(vscode.window.terminals.map(async (t) => ({
+      id: idOf(t),
+      name: t.name,
+      pid: (await t.processId) || 0,
+      cwd: (t.shellIntegration && t.shellIntegration.cwd && t.shellIntegration.cwd.fsPath) || '',
+    })));
+  }
+  const terminal = terminalById(req.id);
+  if (!terminal) {
+    throw new Error(`no terminal ${req.id}`);
+  }
+  switch (req.cmd) {
+    case 'capture': {
+      const lines = buffers.get(req.id) || [];
+      return (req.extent === 'all' ? lines : lines.slice(-SCREEN_LINES)).join('\n');
+    }
+    case 'focus':
+      terminal.show(false);
+      return null;
+    case 'rename':
+      // renameWithArg renames the active terminal
+      terminal.show(true);
+      await vscode.commands.executeCommand('workbench.action.terminal.renameWithArg', { name: req.title });
+      return null;
+    case 'send':
+      terminal.sendText(req.text, false);
+      return null;
+  }
+  throw new Error(`unknown command ${req.cmd}`);
+}
+
+function serve(conn) {
+  let buf = '';
+  conn.setEncoding('utf8');
+  conn.on('data', async (chunk) => {
+    buf += chunk;
+    const nl = buf.indexOf('\n');
+    if (nl < 0) {
+      return;
+    }
+    let resp;
+    try {
+      resp = { result: await handle(JSON.parse(buf.slice(0, nl))) };
+    } catch (err) {
+      resp = { error: String(err.message || err) };
+    }
+    conn.end(JSON.stringify(resp) + '\n');
+  });
+  conn.on('error', () => {});
+}
+
+function activate(context) {
+  context.subscriptions.push(
+    vscode.window.onDidStartTerminalShellExecution(async (e) => {
+      const id = idOf(e.terminal);
+      for await (const data of e.execution.read()) {
+        append(id, data);
+      }
+    }),
+    vscode.window.onDidCloseTerminal((t) => {
+      buffers.delete(ids.get(t));
+      pendingCR.delete(ids.get(t));
+      ids.delete(t);
+    }),
+  );
+
+  socketPath = path.join(os.tmpdir(), `lazyccg-vscode-${process.pid}.sock`);
+  try {
+    fs.unlinkSync(socketPath);
+  } catch (err) {
+    // not there
+  }
+  server = net.createServer(serve);
+  server.listen(socketPath);
+}
+
+function deactivate() {
+  if (server) {
+    server.close();
+  }
+  try {
+    fs.unlinkSync(socketPath);
+  } catch (err) {
+    // already gone
+  }
+}
+
+module.exports = { activate, deactivate };
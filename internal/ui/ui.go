@@ -0,0 +1,119 @@
+// Package ui holds the text layout helpers lazyccg's panels are drawn with.
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// Truncate cuts s to maxLen runes, ending in "..." when there is room.
+func Truncate(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	if maxLen <= 3 {
+		return string(runes[:maxLen])
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// TruncateLeft keeps the end of s, which is the informative part of a path.
+func TruncateLeft(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	if maxLen <= 3 {
+		return string(runes[len(runes)-maxLen:])
+	}
+	return "..." + string(runes[len(runes)-maxLen+3:])
+}
+
+// TruncateWidth cuts s to width terminal columns, ending in "…" when cut,
+// so wide (e.g. Japanese) characters count for two.
+func TruncateWidth(s string, width int) string {
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+	cut, _ := fitWidth([]rune(s), width-1)
+	return cut + "…"
+}
+
+// marqueeGap separates the end of a scrolling text from its start.
+const marqueeGap = "   "
+
+// Marquee shows s in width columns, scrolled offset runes to the left and
+// wrapping around, for text too wide to show whole. Text that fits is
+// returned as is.
+func Marquee(s string, width, offset int) string {
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	loop := []rune(s + marqueeGap)
+	offset %= len(loop)
+	rotated := append(append([]rune{}, loop[offset:]...), loop[:offset]...)
+	shown, used := fitWidth(rotated, width)
+	// Keep the width when a wide rune didn't fit
+	return shown + strings.Repeat(" ", width-used)
+}
+
+// fitWidth takes runes from the start of r while they fit in width columns
+// and returns them with the columns they take.
+func fitWidth(r []rune, width int) (string, int) {
+	var b strings.Builder
+	used := 0
+	for _, c := range r {
+		w := runewidth.RuneWidth(c)
+		if used+w > width {
+			break
+		}
+		b.WriteRune(c)
+		used += w
+	}
+	return b.String(), used
+}
+
+// Box draws a rounded border of width x height around content with title,
+// already styled, set into the top edge. Content lines are padded to the
+// inner width; extra lines are dropped.
+func Box(title string, content []string, width, height int, borderColor lipgloss.Color) string {
+	colorStyle := lipgloss.NewStyle().Foreground(borderColor)
+
+	innerWidth := width - 2
+	if innerWidth < 1 {
+		innerWidth = 1
+	}
+
+	titleLen := lipgloss.Width(title)
+	remainingWidth := width - 3 - titleLen
+	if remainingWidth < 0 {
+		remainingWidth = 0
+	}
+	topLine := colorStyle.Render("╭─") + title + colorStyle.Render(strings.Repeat("─", remainingWidth)+"╮")
+
+	var lines []string
+	lines = append(lines, topLine)
+
+	for i := 0; i < height-2; i++ {
+		var lineContent string
+		if i < len(content) {
+			lineContent = content[i]
+		}
+		lineWidth := lipgloss.Width(lineContent)
+		padding := innerWidth - lineWidth
+		if padding < 0 {
+			padding = 0
+		}
+		lines = append(lines, colorStyle.Render("│")+lineContent+strings.Repeat(" ", padding)+colorStyle.Render("│"))
+	}
+
+	lines = append(lines, colorStyle.Render("╰"+strings.Repeat("─", innerWidth)+"╯"))
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,78 @@
+package ui
+
+import "testing"
+
+func TestTruncateLeft(t *testing.T) {
+	tests := []struct {
+		s      string
+		maxLen int
+		want   string
+	}{
+		{"/short", 10, "/short"},
+		{"/home/user/src/project", 11, ".../project"},
+		{"abcdef", 3, "def"},
+	}
+
+	for _, tt := range tests {
+		if got := TruncateLeft(tt.s, tt.maxLen); got != tt.want {
+			t.Errorf("TruncateLeft(%q, %d) = %q, want %q", tt.s, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s      string
+		maxLen int
+		want   string
+	}{
+		{"short", 10, "short"},
+		{"a long session title", 10, "a long ..."},
+		{"abcdef", 2, "ab"},
+	}
+
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.maxLen); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"a long session title", 10, "a long se…"},
+		{"日本語のタイトル", 8, "日本語…"}, // wide runes take two columns
+		{"日本語のタイトル", 16, "日本語のタイトル"},
+	}
+	for _, tt := range tests {
+		if got := TruncateWidth(tt.s, tt.width); got != tt.want {
+			t.Errorf("TruncateWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestMarquee(t *testing.T) {
+	tests := []struct {
+		s      string
+		width  int
+		offset int
+		want   string
+	}{
+		{"fits", 10, 3, "fits"},
+		{"abcdefgh", 5, 0, "abcde"},
+		{"abcdefgh", 5, 6, "gh   "},
+		{"abcdefgh", 5, 9, "  abc"},  // the gap, then the start again
+		{"abcdefgh", 5, 12, "bcdef"}, // wrapped around
+		{"日本語のタイトル", 5, 1, "本語 "},    // a wide rune that doesn't fit leaves a space
+	}
+	for _, tt := range tests {
+		if got := Marquee(tt.s, tt.width, tt.offset); got != tt.want {
+			t.Errorf("Marquee(%q, %d, %d) = %q, want %q", tt.s, tt.width, tt.offset, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package kitty describes the window layout reported by `kitty @ ls` and
+// speaks kitty's remote control protocol.
+//
+// lazyccg uses this layout (OS windows > tabs > windows) for every terminal
+// it supports, so other backends report their panes in the same shape.
+package kitty
+
+import "encoding/json"
+
+// OSWindow is a top-level terminal window.
+type OSWindow struct {
+	Tabs     []Tab  `json:"tabs"`
+	Instance string `json:"-"` // set when several terminals are merged
+	Remote   bool   `json:"-"` // listed over SSH; pids are the remote host's
+}
+
+// Tab is a tab inside an OS window.
+type Tab struct {
+	ID      int      `json:"id"`
+	Title   string   `json:"title"`
+	Layout  string   `json:"layout"` // e.g. "tall"; "" when the terminal doesn't say
+	Windows []Window `json:"windows"`
+}
+
+// Window is a single pane running a program.
+type Window struct {
+	ID                  int                 `json:"id"`
+	Title               string              `json:"title"`
+	Cwd                 string              `json:"cwd"`
+	IsFocused           bool                `json:"is_focused"` // the window that has the keyboard
+	ForegroundProcesses []ForegroundProcess `json:"foreground_processes"`
+	AtPrompt            bool                `json:"at_prompt"`            // the shell is at its prompt, by shell integration
+	LastCmdExitStatus   *int                `json:"last_cmd_exit_status"` // the last command's, by shell integration
+	Parent              int                 `json:"-"`                    // window running the tmux this pane is in
+	Nested              string              `json:"-"`                    // that tmux session
+}
+
+// ForegroundProcess is a process in a window's foreground process group.
+type ForegroundProcess struct {
+	Pid     int      `json:"pid"`
+	Cwd     string   `json:"cwd"`
+	Cmdline []string `json:"cmdline"`
+}
+
+// ParseLayout decodes the output of `kitty @ ls` (or `kitten @ ls`). Old
+// kitty versions report each window's own pid and command line instead of
+// its foreground processes; those become a single foreground process.
+func ParseLayout(data []byte) ([]OSWindow, error) {
+	var osWindows []OSWindow
+	if err := json.Unmarshal(data, &osWindows); err != nil {
+		return nil, err
+	}
+	var legacy []struct {
+		Tabs []struct {
+			Windows []struct {
+				Pid     int      `json:"pid"`
+				Cmdline []string `json:"cmdline"`
+			} `json:"windows"`
+		} `json:"tabs"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, err
+	}
+	for i := range osWindows {
+		for j := range osWindows[i].Tabs {
+			for k := range osWindows[i].Tabs[j].Windows {
+				w := &osWindows[i].Tabs[j].Windows[k]
+				old := legacy[i].Tabs[j].Windows[k]
+				if w.ForegroundProcesses == nil && len(old.Cmdline) > 0 {
+					w.ForegroundProcesses = []ForegroundProcess{{Pid: old.Pid, Cwd: w.Cwd, Cmdline: old.Cmdline}}
+				}
+			}
+		}
+	}
+	return osWindows, nil
+}
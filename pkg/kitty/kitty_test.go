@@ -0,0 +1,53 @@
+package kitty
+
+import "testing"
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string // first window's first foreground command
+		wantPid int
+	}{
+		{
+			name:    "current",
+			data:    `[{"tabs":[{"id":1,"windows":[{"id":2,"cwd":"/src","pid":10,"cmdline":["/bin/zsh"],"foreground_processes":[{"pid":11,"cmdline":["claude"]}]}]}]}]`,
+			want:    "claude",
+			wantPid: 11,
+		},
+		{
+			name:    "without foreground processes",
+			data:    `[{"tabs":[{"id":1,"windows":[{"id":2,"cwd":"/src","pid":10,"cmdline":["codex","--full-auto"]}]}]}]`,
+			want:    "codex",
+			wantPid: 10, // the window's own
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			osWindows, err := ParseLayout([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w := osWindows[0].Tabs[0].Windows[0]; w.AtPrompt || w.LastCmdExitStatus != nil {
+				t.Errorf("shell integration state = %v, %v; want none reported", w.AtPrompt, w.LastCmdExitStatus)
+			}
+			procs := osWindows[0].Tabs[0].Windows[0].ForegroundProcesses
+			if len(procs) != 1 || procs[0].Cmdline[0] != tt.want || procs[0].Pid != tt.wantPid {
+				t.Errorf("foreground processes = %+v, want %s (pid %d)", procs, tt.want, tt.wantPid)
+			}
+		})
+	}
+	osWindows, err := ParseLayout([]byte(`[{"tabs":[{"id":1,"windows":[{"id":2,"is_focused":true,"at_prompt":true,"last_cmd_exit_status":1}]}]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := osWindows[0].Tabs[0].Windows[0]; !w.AtPrompt || w.LastCmdExitStatus == nil || *w.LastCmdExitStatus != 1 {
+		t.Errorf("shell integration state = %v, %v; want at the prompt after exit status 1", w.AtPrompt, w.LastCmdExitStatus)
+	}
+	if !osWindows[0].Tabs[0].Windows[0].IsFocused {
+		t.Error("IsFocused = false, want the focused window marked")
+	}
+	if _, err := ParseLayout([]byte(`{"tabs": []}`)); err == nil {
+		t.Error("ParseLayout() of an object should fail")
+	}
+}
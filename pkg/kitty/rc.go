@@ -0,0 +1,240 @@
+package kitty
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client speaks kitty's remote control protocol directly over its socket,
+// so a caller doesn't spawn a `kitty @` process per command. Messages are
+// JSON framed as DCS sequences: ESC P @kitty-cmd <json> ESC \.
+type Client struct {
+	addrMu  sync.Mutex // guards network and address, which Retarget changes
+	network string
+	address string
+
+	mu       sync.Mutex
+	conn     net.Conn
+	r        *bufio.Reader
+	failures int       // consecutive failed dials
+	nextDial time.Time // no dialing before this while failing
+}
+
+const (
+	rcPrefix  = "\x1bP@kitty-cmd"
+	rcSuffix  = "\x1b\\"
+	rcTimeout = 5 * time.Second
+
+	maxDialBackoff = 30 * time.Second
+)
+
+// Version is the protocol version sent with each command; kitty accepts
+// commands from older clients.
+var Version = []int{0, 26, 0}
+
+// NewClient parses a kitty socket address such as unix:/tmp/kitty,
+// unix:@abstract, or tcp:host:port. It does not dial until the first call.
+func NewClient(socket string) (*Client, error) {
+	network, address, err := parseSocket(socket)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{network: network, address: address}, nil
+}
+
+func parseSocket(socket string) (network, address string, err error) {
+	network, address, ok := strings.Cut(socket, ":")
+	if !ok || address == "" || (network != "unix" && network != "tcp") {
+		return "", "", fmt.Errorf("unsupported kitty socket %q", socket)
+	}
+	return network, address, nil
+}
+
+// Network returns "unix" or "tcp".
+func (c *Client) Network() string {
+	c.addrMu.Lock()
+	defer c.addrMu.Unlock()
+	return c.network
+}
+
+// Address returns the socket path or host:port.
+func (c *Client) Address() string {
+	c.addrMu.Lock()
+	defer c.addrMu.Unlock()
+	return c.address
+}
+
+// Socket returns the address in the form NewClient takes.
+func (c *Client) Socket() string {
+	c.addrMu.Lock()
+	defer c.addrMu.Unlock()
+	return c.network + ":" + c.address
+}
+
+// Retarget points the client at another socket, e.g. the one a restarted
+// kitty listens on. The open connection is closed and the next call dials
+// the new socket right away.
+func (c *Client) Retarget(socket string) error {
+	network, address, err := parseSocket(socket)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn, c.r = nil, nil
+	}
+	c.failures, c.nextDial = 0, time.Time{}
+	c.addrMu.Lock()
+	c.network, c.address = network, address
+	c.addrMu.Unlock()
+	return nil
+}
+
+// Request is one remote control command.
+type Request struct {
+	Cmd        string `json:"cmd"`
+	Version    []int  `json:"version"`
+	NoResponse bool   `json:"no_response,omitempty"`
+	Payload    any    `json:"payload,omitempty"`
+}
+
+// Response is kitty's reply to a Request.
+type Response struct {
+	OK    bool            `json:"ok"`
+	Data  json.RawMessage `json:"data"`
+	Error string          `json:"error"`
+}
+
+// Call runs cmd and returns the response data. The connection is kept open
+// between calls; if kitty closed it, the command is retried once on a new
+// one.
+func (c *Client) Call(cmd string, payload any) (json.RawMessage, error) {
+	req := Request{Cmd: cmd, Version: Version, Payload: payload}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reused := c.conn != nil
+	data, err := c.roundTrip(req)
+	if err != nil && reused {
+		data, err = c.roundTrip(req)
+	}
+	return data, err
+}
+
+func (c *Client) roundTrip(req Request) (json.RawMessage, error) {
+	if c.conn == nil {
+		// Back off between dials so an unreachable remote kitty doesn't
+		// stall every call for the dial timeout
+		if wait := time.Until(c.nextDial); wait > 0 {
+			return nil, fmt.Errorf("kitty %s unreachable, retrying in %s", c.Address(), wait.Round(time.Second))
+		}
+		conn, err := net.DialTimeout(c.Network(), c.Address(), rcTimeout)
+		if err != nil {
+			c.failures++
+			c.nextDial = time.Now().Add(dialBackoff(c.failures))
+			return nil, err
+		}
+		c.failures = 0
+		c.conn, c.r = conn, bufio.NewReader(conn)
+	}
+	fail := func(err error) (json.RawMessage, error) {
+		c.conn.Close()
+		c.conn, c.r = nil, nil
+		return nil, err
+	}
+
+	c.conn.SetDeadline(time.Now().Add(rcTimeout))
+	if err := WriteMessage(c.conn, req); err != nil {
+		return fail(err)
+	}
+	raw, err := ReadMessage(c.r)
+	if err != nil {
+		return fail(err)
+	}
+	var resp Response
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return fail(fmt.Errorf("kitty response: %w", err))
+	}
+	if !resp.OK {
+		return nil, errors.New(strings.TrimSpace(resp.Error))
+	}
+	return resp.Data, nil
+}
+
+// dialBackoff doubles a one second wait for each consecutive failed dial,
+// up to maxDialBackoff.
+func dialBackoff(failures int) time.Duration {
+	d := time.Second
+	for i := 0; i < failures && d < maxDialBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxDialBackoff)
+}
+
+// WriteMessage frames v as one remote control message.
+func WriteMessage(w io.Writer, v any) error {
+	msg, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]byte(rcPrefix + string(msg) + rcSuffix))
+	return err
+}
+
+// ReadMessage reads one framed message and returns its JSON.
+func ReadMessage(r *bufio.Reader) ([]byte, error) {
+	var buf []byte
+	for {
+		chunk, err := r.ReadBytes('\\')
+		buf = append(buf, chunk...)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasSuffix(buf, []byte(rcSuffix)) {
+			break
+		}
+	}
+	start := bytes.Index(buf, []byte(rcPrefix))
+	if start < 0 {
+		return nil, fmt.Errorf("kitty response: missing header")
+	}
+	return buf[start+len(rcPrefix) : len(buf)-len(rcSuffix)], nil
+}
+
+// CallString runs cmd and decodes response data that is a JSON string.
+func (c *Client) CallString(cmd string, payload any) (string, error) {
+	data, err := c.Call(cmd, payload)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	return string(data), nil
+}
+
+// Close closes the connection; the next call dials again.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.r = nil, nil
+	return err
+}
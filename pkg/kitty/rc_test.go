@@ -0,0 +1,134 @@
+package kitty
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// serve answers each request on one connection with reply(req).
+func serve(t *testing.T, reply func(Request) Response) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kitty.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					raw, err := ReadMessage(r)
+					if err != nil {
+						return
+					}
+					var req Request
+					json.Unmarshal(raw, &req)
+					WriteMessage(conn, reply(req))
+				}
+			}()
+		}
+	}()
+	return "unix:" + path
+}
+
+func TestClientCallString(t *testing.T) {
+	socket := serve(t, func(req Request) Response {
+		if req.Cmd != "ls" {
+			return Response{Error: "unexpected " + req.Cmd}
+		}
+		data, _ := json.Marshal(`[{"tabs":[{"id":1,"title":"t","windows":[{"id":7,"title":"claude \\ x"}]}]}]`)
+		return Response{OK: true, Data: data}
+	})
+
+	c, err := NewClient(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for i := 0; i < 2; i++ { // the second call reuses the connection
+		data, err := c.CallString("ls", nil)
+		if err != nil {
+			t.Fatalf("CallString() error = %v", err)
+		}
+		var osWindows []OSWindow
+		if err := json.Unmarshal([]byte(data), &osWindows); err != nil {
+			t.Fatal(err)
+		}
+		if got := osWindows[0].Tabs[0].Windows[0]; got.ID != 7 || got.Title != `claude \ x` {
+			t.Errorf("unexpected window %+v", got)
+		}
+	}
+}
+
+func TestClientError(t *testing.T) {
+	socket := serve(t, func(req Request) Response {
+		return Response{Error: "No matching windows\n"}
+	})
+	c, _ := NewClient(socket)
+	defer c.Close()
+	if _, err := c.Call("focus-window", nil); err == nil || err.Error() != "No matching windows" {
+		t.Errorf("Call() error = %v, want kitty's error", err)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	for _, socket := range []string{"unix:/tmp/kitty", "unix:@kitty", "tcp:localhost:5000"} {
+		if _, err := NewClient(socket); err != nil {
+			t.Errorf("NewClient(%q) error = %v", socket, err)
+		}
+	}
+	for _, socket := range []string{"", "/tmp/kitty", "fd:3"} {
+		if _, err := NewClient(socket); err == nil {
+			t.Errorf("NewClient(%q) should fail", socket)
+		}
+	}
+}
+
+func TestClientDialBackoff(t *testing.T) {
+	c, err := NewClient("unix:" + filepath.Join(t.TempDir(), "missing.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Call("ls", nil); err == nil {
+		t.Fatal("dialing a missing socket should fail")
+	}
+	_, err = c.Call("ls", nil)
+	if err == nil || !strings.Contains(err.Error(), "retrying in") {
+		t.Errorf("second call error = %v, want it to back off without dialing", err)
+	}
+}
+
+func TestClientRetarget(t *testing.T) {
+	c, _ := NewClient("unix:" + filepath.Join(t.TempDir(), "kitty-100"))
+	defer c.Close()
+	if _, err := c.Call("ls", nil); err == nil {
+		t.Fatal("dialing a missing socket should fail")
+	}
+	// kitty restarted under another pid
+	socket := serve(t, func(req Request) Response {
+		return Response{OK: true, Data: json.RawMessage(`"[]"`)}
+	})
+	if err := c.Retarget(socket); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CallString("ls", nil); err != nil {
+		t.Errorf("call after Retarget() error = %v, want it to dial the new socket at once", err)
+	}
+	if got := c.Socket(); got != socket {
+		t.Errorf("Socket() = %q, want %q", got, socket)
+	}
+	if err := c.Retarget("fd:3"); err == nil {
+		t.Error("Retarget() to an unsupported socket should fail")
+	}
+}
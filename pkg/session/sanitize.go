@@ -0,0 +1,53 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const tabWidth = 8
+
+// escapePattern matches CSI, OSC, and two-byte escape sequences.
+var escapePattern = regexp.MustCompile(`\x1b\[[0-9;?<=>!]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)?|\x1b[@-_]`)
+
+// SanitizeLine makes captured text safe to draw inside a box: escape
+// sequences are stripped, backspaces applied, tabs expanded to tab stops,
+// and other non-printable runes replaced with '?'.
+func SanitizeLine(line string) string {
+	if strings.IndexFunc(line, needsSanitizing) < 0 {
+		return line
+	}
+	line = escapePattern.ReplaceAllString(line, "")
+
+	var out []rune
+	col := 0
+	for _, r := range line {
+		switch {
+		case r == '\t':
+			n := tabWidth - col%tabWidth
+			for i := 0; i < n; i++ {
+				out = append(out, ' ')
+			}
+			col += n
+		case r == '\b':
+			if len(out) > 0 {
+				col -= runewidth.RuneWidth(out[len(out)-1])
+				out = out[:len(out)-1]
+			}
+		case !unicode.IsGraphic(r):
+			out = append(out, '?')
+			col++
+		default:
+			out = append(out, r)
+			col += runewidth.RuneWidth(r)
+		}
+	}
+	return string(out)
+}
+
+func needsSanitizing(r rune) bool {
+	return r == '\t' || !unicode.IsGraphic(r)
+}
@@ -0,0 +1,27 @@
+package session
+
+import "testing"
+
+func TestSanitizeLine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "hello world", want: "hello world"},
+		{name: "tab stops", in: "a\tb\tc", want: "a       b       c"},
+		{name: "tab after wide rune", in: "日本\tx", want: "日本    x"},
+		{name: "color codes", in: "\x1b[1;32mok\x1b[0m done", want: "ok done"},
+		{name: "osc title", in: "\x1b]0;title\x07text", want: "text"},
+		{name: "backspace", in: "abc\b\bd", want: "ad"},
+		{name: "control chars", in: "a\x00b\x7fc", want: "a?b?c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeLine(tt.in); got != tt.want {
+				t.Errorf("SanitizeLine(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
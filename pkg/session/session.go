@@ -0,0 +1,148 @@
+// Package session finds AI coding agents among terminal windows and turns
+// their captured output into clean lines.
+package session
+
+import (
+	"strings"
+
+	"github.com/atani/lazyccg/pkg/kitty"
+)
+
+// Agent is a window running an AI coding agent.
+type Agent struct {
+	AI     string // the matching prefix, e.g. "claude"
+	Tab    kitty.Tab
+	Window kitty.Window
+}
+
+// Find returns the windows in osWindows that run one of prefixes, in
+// listing order.
+func Find(osWindows []kitty.OSWindow, prefixes []string) []Agent {
+	var agents []Agent
+	for _, ow := range osWindows {
+		for _, tab := range ow.Tabs {
+			for _, win := range tab.Windows {
+				if ai, ok := DetectAI(win, prefixes); ok {
+					agents = append(agents, Agent{AI: ai, Tab: tab, Window: win})
+				}
+			}
+		}
+	}
+	return agents
+}
+
+// DetectAI reports which of prefixes one of the window's foreground
+// processes runs, matching executable basenames and path components.
+func DetectAI(win kitty.Window, prefixes []string) (string, bool) {
+	return DetectAICommand(win, prefixes, nil)
+}
+
+// DetectAICommand is DetectAI, except that the prefixes in commandOnly only
+// match the command a process runs: its executable, or the script or
+// package an interpreter such as node runs, not its other arguments. A
+// prefix with subcommands listed also needs the command to be run without
+// arguments or with one of them. Generic names need this: `git checkout
+// amp` isn't amp, and `goose up` is a database migration.
+func DetectAICommand(win kitty.Window, prefixes []string, commandOnly map[string][]string) (string, bool) {
+	for _, proc := range win.ForegroundProcesses {
+		if len(proc.Cmdline) == 0 {
+			continue
+		}
+		command := commandIndex(proc.Cmdline)
+		// Check all cmdline elements for AI tool names
+		for i, arg := range proc.Cmdline {
+			argLower := strings.ToLower(arg)
+			for _, p := range prefixes {
+				if subcommands, ok := commandOnly[p]; ok && (i != command || !runsSubcommand(proc.Cmdline[i+1:], subcommands)) {
+					continue
+				}
+				// Check basename (e.g., /usr/bin/claude -> claude)
+				base := arg
+				if idx := strings.LastIndex(arg, "/"); idx >= 0 {
+					base = arg[idx+1:]
+				}
+				baseLower := strings.ToLower(base)
+				if baseLower == p {
+					return p, true
+				}
+				// Check if path contains the prefix as a path component
+				// e.g., /path/to/@openai/codex/bin/codex
+				if strings.Contains(argLower, "/"+p+"/") || strings.HasSuffix(argLower, "/"+p) {
+					return p, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// commandIndex returns the index of the argument in cmdline that names the
+// command it runs: 0, or for an interpreter the first argument that isn't a
+// flag.
+func commandIndex(cmdline []string) int {
+	base := strings.ToLower(cmdline[0])
+	if idx := strings.LastIndex(base, "/"); idx >= 0 {
+		base = base[idx+1:]
+	}
+	switch {
+	case base == "node", base == "nodejs", base == "bun", base == "deno",
+		base == "npx", base == "bunx", base == "uvx", strings.HasPrefix(base, "python"):
+	default:
+		return 0
+	}
+	for i, arg := range cmdline[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// runsSubcommand reports whether args, the arguments after a command, are
+// none or start with one of subcommands. Any args do when subcommands is
+// empty.
+func runsSubcommand(args, subcommands []string) bool {
+	if len(subcommands) == 0 || len(args) == 0 {
+		return true
+	}
+	for _, sub := range subcommands {
+		if args[0] == sub {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePrefixes splits a comma-separated prefix list, lowercasing entries
+// and dropping empty ones.
+func ParsePrefixes(s string) []string {
+	parts := strings.Split(s, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// NormalizeLines splits captured text into sanitized, non-blank lines and
+// keeps the last maxLines of them (all when maxLines <= 0).
+func NormalizeLines(text string, maxLines int) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	lines := strings.Split(text, "\n")
+	trimmed := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimRight(SanitizeLine(line), " ")
+		if line == "" {
+			continue
+		}
+		trimmed = append(trimmed, line)
+	}
+	if maxLines > 0 && len(trimmed) > maxLines {
+		trimmed = trimmed[len(trimmed)-maxLines:]
+	}
+	return trimmed
+}
@@ -0,0 +1,274 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/atani/lazyccg/pkg/kitty"
+)
+
+func TestDetectAI(t *testing.T) {
+	prefixes := []string{"codex", "claude", "gemini"}
+
+	tests := []struct {
+		name   string
+		win    kitty.Window
+		wantAI string
+		wantOK bool
+	}{
+		{
+			name: "codex via node binary path",
+			win: kitty.Window{
+				ForegroundProcesses: []kitty.ForegroundProcess{
+					{
+						Cmdline: []string{
+							"/Users/akira/.local/share/mise/installs/node/24.5.0/lib/node_modules/@openai/codex/vendor/aarch64-apple-darwin/codex/codex",
+						},
+					},
+				},
+			},
+			wantAI: "codex",
+			wantOK: true,
+		},
+		{
+			name: "codex via node script",
+			win: kitty.Window{
+				ForegroundProcesses: []kitty.ForegroundProcess{
+					{
+						Cmdline: []string{
+							"node",
+							"/Users/akira/.local/share/mise/installs/node/24.5.0/bin/codex",
+						},
+					},
+				},
+			},
+			wantAI: "codex",
+			wantOK: true,
+		},
+		{
+			name: "claude direct binary",
+			win: kitty.Window{
+				ForegroundProcesses: []kitty.ForegroundProcess{
+					{
+						Cmdline: []string{"/usr/local/bin/claude"},
+					},
+				},
+			},
+			wantAI: "claude",
+			wantOK: true,
+		},
+		{
+			name: "gemini cli",
+			win: kitty.Window{
+				ForegroundProcesses: []kitty.ForegroundProcess{
+					{
+						Cmdline: []string{"gemini", "chat"},
+					},
+				},
+			},
+			wantAI: "gemini",
+			wantOK: true,
+		},
+		{
+			name: "no match - just node",
+			win: kitty.Window{
+				ForegroundProcesses: []kitty.ForegroundProcess{
+					{
+						Cmdline: []string{"node", "/some/other/script.js"},
+					},
+				},
+			},
+			wantAI: "",
+			wantOK: false,
+		},
+		{
+			name: "no match - zsh",
+			win: kitty.Window{
+				ForegroundProcesses: []kitty.ForegroundProcess{
+					{
+						Cmdline: []string{"/bin/zsh"},
+					},
+				},
+			},
+			wantAI: "",
+			wantOK: false,
+		},
+		{
+			name: "empty cmdline",
+			win: kitty.Window{
+				ForegroundProcesses: []kitty.ForegroundProcess{
+					{Cmdline: []string{}},
+				},
+			},
+			wantAI: "",
+			wantOK: false,
+		},
+		{
+			name: "multiple processes - one is codex",
+			win: kitty.Window{
+				ForegroundProcesses: []kitty.ForegroundProcess{
+					{Cmdline: []string{"node", "/path/to/context7-mcp"}},
+					{Cmdline: []string{"python", "/path/to/serena"}},
+					{Cmdline: []string{"/path/to/@openai/codex/vendor/codex/codex"}},
+				},
+			},
+			wantAI: "codex",
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAI, gotOK := DetectAI(tt.win, prefixes)
+			if gotAI != tt.wantAI || gotOK != tt.wantOK {
+				t.Errorf("DetectAI() = (%q, %v), want (%q, %v)", gotAI, gotOK, tt.wantAI, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDetectAICommand(t *testing.T) {
+	prefixes := []string{"claude", "goose", "amp"}
+	commandOnly := map[string][]string{"goose": {"session"}, "amp": nil}
+
+	tests := []struct {
+		cmdline []string
+		want    string
+	}{
+		{[]string{"goose"}, "goose"},
+		{[]string{"goose", "session"}, "goose"},
+		{[]string{"goose", "up"}, ""},
+		{[]string{"amp", "-x", "fix the tests"}, "amp"},
+		{[]string{"bun", "/home/me/.bun/install/global/node_modules/amp/cli.js"}, "amp"},
+		{[]string{"git", "checkout", "amp"}, ""},
+		{[]string{"less", "/var/log/goose"}, ""},
+		{[]string{"nice", "claude"}, "claude"},
+	}
+	for _, tt := range tests {
+		win := kitty.Window{ForegroundProcesses: []kitty.ForegroundProcess{{Cmdline: tt.cmdline}}}
+		if ai, _ := DetectAICommand(win, prefixes, commandOnly); ai != tt.want {
+			t.Errorf("DetectAICommand(%q) = %q, want %q", tt.cmdline, ai, tt.want)
+		}
+	}
+}
+
+func TestParseKittyJSON(t *testing.T) {
+	// Simulated kitty @ ls output based on user's actual data
+	kittyJSON := `[
+		{
+			"tabs": [
+				{
+					"id": 1,
+					"title": "codex",
+					"windows": [
+						{
+							"id": 10,
+							"title": "codex",
+							"cwd": "/Users/akira/src/github.com/atani/idea/lazyccg",
+							"foreground_processes": [
+								{
+									"cmdline": ["node", "/Users/akira/.npm/_npx/eea2bd7412d4593b/node_modules/.bin/context7-mcp"],
+									"cwd": "/Users/akira/src/github.com/atani/idea/lazyccg",
+									"pid": 35783
+								},
+								{
+									"cmdline": ["/Users/akira/.local/share/mise/installs/node/24.5.0/lib/node_modules/@openai/codex/vendor/aarch64-apple-darwin/codex/codex"],
+									"cwd": "/Users/akira/src/github.com/atani/idea/lazyccg",
+									"pid": 35646
+								},
+								{
+									"cmdline": ["node", "/Users/akira/.local/share/mise/installs/node/24.5.0/bin/codex"],
+									"cwd": "/Users/akira/src/github.com/atani/idea/lazyccg",
+									"pid": 35645
+								}
+							]
+						}
+					]
+				},
+				{
+					"id": 2,
+					"title": "lazyccg",
+					"windows": [
+						{
+							"id": 20,
+							"title": "lazyccg",
+							"cwd": "/Users/akira/src/github.com/atani/idea/lazyccg",
+							"foreground_processes": [
+								{
+									"cmdline": ["go", "run", "./cmd/lazyccg"],
+									"cwd": "/Users/akira/src/github.com/atani/idea/lazyccg",
+									"pid": 40000
+								}
+							]
+						}
+					]
+				}
+			]
+		}
+	]`
+
+	var osWindows []kitty.OSWindow
+	err := json.Unmarshal([]byte(kittyJSON), &osWindows)
+	if err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if len(osWindows) != 1 {
+		t.Fatalf("Expected 1 OS window, got %d", len(osWindows))
+	}
+
+	if len(osWindows[0].Tabs) != 2 {
+		t.Fatalf("Expected 2 tabs, got %d", len(osWindows[0].Tabs))
+	}
+
+	// Check first tab (codex)
+	codexTab := osWindows[0].Tabs[0]
+	if codexTab.Title != "codex" {
+		t.Errorf("Expected tab title 'codex', got %q", codexTab.Title)
+	}
+	if len(codexTab.Windows) != 1 {
+		t.Fatalf("Expected 1 window in codex tab, got %d", len(codexTab.Windows))
+	}
+
+	codexWin := codexTab.Windows[0]
+	if len(codexWin.ForegroundProcesses) != 3 {
+		t.Fatalf("Expected 3 foreground processes, got %d", len(codexWin.ForegroundProcesses))
+	}
+
+	// Test DetectAI on the codex window
+	prefixes := []string{"codex", "claude", "gemini"}
+	ai, ok := DetectAI(codexWin, prefixes)
+	if !ok {
+		t.Error("DetectAI should have found codex")
+	}
+	if ai != "codex" {
+		t.Errorf("Expected AI 'codex', got %q", ai)
+	}
+
+	// Test DetectAI on the lazyccg window (should not match)
+	lazyccgWin := osWindows[0].Tabs[1].Windows[0]
+	ai, ok = DetectAI(lazyccgWin, prefixes)
+	if ok {
+		t.Error("DetectAI should NOT have found AI in lazyccg window")
+	}
+
+	agents := Find(osWindows, prefixes)
+	if len(agents) != 1 || agents[0].AI != "codex" || agents[0].Window.ID != 10 || agents[0].Tab.Title != "codex" {
+		t.Errorf("Find() = %+v, want the codex window", agents)
+	}
+}
+
+func TestParsePrefixes(t *testing.T) {
+	got := ParsePrefixes(" Claude, codex,,gemini ")
+	if strings.Join(got, ",") != "claude,codex,gemini" {
+		t.Errorf("ParsePrefixes() = %v", got)
+	}
+}
+
+func TestNormalizeLines(t *testing.T) {
+	got := NormalizeLines("one\r\n\n\x1b[1mtwo\x1b[0m  \rthree\nfour\n", 3)
+	if strings.Join(got, "|") != "two|three|four" {
+		t.Errorf("NormalizeLines() = %q", got)
+	}
+}
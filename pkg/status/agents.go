@@ -0,0 +1,88 @@
+package status
+
+import "strings"
+
+// phraseParser reads the screen of an agent by the phrases its UI shows,
+// for agents whose screens are told apart by a few fixed strings. What
+// none of them match falls to Classify.
+type phraseParser struct {
+	approval []string // asking to run a tool or edit a file
+	running  []string // shown only while it works, e.g. its interrupt hint
+	prompt   []string // how its input prompt starts, on the last line
+	footer   []string // shown under its prompt while it waits for input
+}
+
+func (p phraseParser) Classify(lines []string) (Status, bool) {
+	status, matched, _ := p.explain(lines, 0)
+	return status, matched
+}
+
+func (p phraseParser) explain(lines []string, lookback int) (Status, bool, string) {
+	tail := tail(lines, lookback)
+	if line, ok := rateLimitLine(lines); ok {
+		return RateLimited, true, because("rate-limit message", line)
+	}
+	if line, ok := lineContaining(tail, p.approval...); ok {
+		return NeedsApproval, true, because("approval prompt", line)
+	}
+	if line, ok := lineContaining(tail, p.running...); ok {
+		return Running, true, because("working hint", line)
+	}
+	if line, ok := animatedLine(lines); ok {
+		return Running, true, because("spinner", line)
+	}
+	if len(lines) > 0 {
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if anyPrefix([]string{strings.ToLower(last)}, p.prompt...) {
+			return idleOrAsked(lines, last)
+		}
+	}
+	if line, ok := lineContaining(tail, p.footer...); ok {
+		return idleOrAsked(lines, line)
+	}
+	return classify(lines, lookback)
+}
+
+// Agents read by their phrases:
+//
+//	aider         Add file to the chat? (Y)es/(N)o [Yes]:     NEEDS_APPROVAL
+//	              Waiting for claude-sonnet-4                  RUNNING
+//	goose         Goose would like to call the above tool      NEEDS_APPROVAL
+//	              ( O)>                                        IDLE
+//	opencode      Permission required  enter accept  esc reject
+//	              working…  esc interrupt                      RUNNING
+//	openhands     Proceed with action? (y)es/(n)o/(a)lways     NEEDS_APPROVAL
+//	              Agent running... (Press Ctrl-P to pause)     RUNNING
+var (
+	aiderParser = phraseParser{
+		approval: []string{"(y)es/(n)o", "run shell command?", "add file to the chat?", "create new file?", "allow edits to"},
+		running:  []string{"waiting for ", "updated repo map", "ctrl+c to interrupt"},
+		prompt:   []string{">", "architect>", "ask>", "code>", "multi>"},
+	}
+	gooseParser = phraseParser{
+		approval: []string{"would like to call the above tool", "allow?", "always allow"},
+		running:  []string{"ctrl+c to interrupt", "press ctrl+c"},
+		prompt:   []string{"( o)>", "(o)>"},
+	}
+	ampParser = phraseParser{
+		approval: []string{"allow this command", "approve?", "run this command?", "[y]es", "(y)es"},
+		running:  []string{"esc to cancel", "esc to interrupt", "ctrl+c to cancel"},
+		footer:   []string{"? for help", "ctrl+j for newline"},
+	}
+	opencodeParser = phraseParser{
+		approval: []string{"permission required", "accept always", "enter accept"},
+		running:  []string{"esc interrupt", "esc to interrupt", "working..."},
+		footer:   []string{"enter send", "ctrl+p commands"},
+	}
+	cursorAgentParser = phraseParser{
+		approval: []string{"run this command?", "(y) run", "run once", "skip (esc", "reject (n)"},
+		running:  []string{"ctrl+c to stop", "esc to stop", "generating"},
+		footer:   []string{"/ commands", "@ files", "→ add a follow-up", "plan, search, build"},
+	}
+	openhandsParser = phraseParser{
+		approval: []string{"proceed with action?", "(y)es/(n)o", "awaiting user confirmation", "confirm action"},
+		running:  []string{"agent running", "ctrl-p to pause", "agent is working"},
+		prompt:   []string{">"},
+		footer:   []string{"agent is awaiting user input", "enter your message"},
+	}
+)
@@ -0,0 +1,41 @@
+package status
+
+// claudeParser reads Claude Code's screen:
+//
+//	⎿ API Error (429 rate_limit_error) · Retrying in 5s…    RATE_LIMITED
+//	✻ Crunching… (12s · ↑ 1.2k tokens · esc to interrupt)   RUNNING
+//	Do you want to proceed? ❯ 1. Yes                        NEEDS_APPROVAL
+//	⏺ Should I also update the tests? (above the prompt)    NEEDS_INPUT
+//	? for shortcuts (under the prompt box)                  IDLE
+type claudeParser struct{}
+
+func (p claudeParser) Classify(lines []string) (Status, bool) {
+	status, matched, _ := p.explain(lines, 0)
+	return status, matched
+}
+
+func (claudeParser) explain(lines []string, lookback int) (Status, bool, string) {
+	recent, tail := recent(lines, lookback), tail(lines, lookback)
+	// Checked first: agents keep their spinner up while they retry
+	if line, ok := rateLimitLine(lines); ok {
+		return RateLimited, true, because("rate-limit message", line)
+	}
+	// A permission menu; the spinner may still be drawn above it
+	if line, ok := lineContaining(tail, "do you want to", "would you like to"); ok && anyPrefix(recent, "❯ 1.", "> 1.") {
+		return NeedsApproval, true, because("permission menu", line)
+	}
+	if line, ok := lineContaining(tail, "waiting for your approval", "waiting for permission"); ok {
+		return NeedsApproval, true, because("permission request", line)
+	}
+	if line, ok := lineContaining(tail, "esc to interrupt"); ok {
+		return Running, true, because("interrupt hint", line)
+	}
+	if line, ok := animatedLine(lines); ok {
+		return Running, true, because("spinner", line)
+	}
+	if line, ok := lineContaining(tail, "? for shortcuts", "accept edits on", "plan mode on", "bypass permissions on",
+		"crunched for", "brewed for", "worked for", "cooked for", "baked for"); ok {
+		return idleOrAsked(lines, line)
+	}
+	return classify(lines, lookback)
+}
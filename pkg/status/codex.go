@@ -0,0 +1,36 @@
+package status
+
+// codexParser reads the Codex CLI's screen:
+//
+//	■ stream error: 429 Too Many Requests                   RATE_LIMITED
+//	Working (12s • esc to interrupt)                        RUNNING
+//	Would you like to run the following command?            NEEDS_APPROVAL
+//	• Want me to open a PR as well? (above the prompt)      NEEDS_INPUT
+//	? for shortcuts               87% context left          IDLE
+type codexParser struct{}
+
+func (p codexParser) Classify(lines []string) (Status, bool) {
+	status, matched, _ := p.explain(lines, 0)
+	return status, matched
+}
+
+func (codexParser) explain(lines []string, lookback int) (Status, bool, string) {
+	tail := tail(lines, lookback)
+	if line, ok := rateLimitLine(lines); ok {
+		return RateLimited, true, because("rate-limit message", line)
+	}
+	if line, ok := lineContaining(tail, "would you like to run", "would you like to make", "allow command?", "yes, proceed"); ok {
+		return NeedsApproval, true, because("approval prompt", line)
+	}
+	if line, ok := lineContaining(tail, "esc to interrupt"); ok {
+		return Running, true, because("interrupt hint", line)
+	}
+	if line, ok := animatedLine(lines); ok {
+		return Running, true, because("spinner", line)
+	}
+	// The footer under the prompt, shown while nothing runs
+	if line, ok := lineContaining(tail, "context left", "tokens used", "? for shortcuts", "⏎ send", "worked for"); ok {
+		return idleOrAsked(lines, line)
+	}
+	return classify(lines, lookback)
+}
@@ -0,0 +1,35 @@
+package status
+
+// geminiParser reads the Gemini CLI's screen:
+//
+//	✕ [API Error: 429 RESOURCE_EXHAUSTED]                   RATE_LIMITED
+//	⠋ Thinking... (esc to cancel, 5s)                       RUNNING
+//	Allow execution? ● 1. Yes, allow once                   NEEDS_APPROVAL
+//	✦ Which port should it listen on? (above the prompt)    NEEDS_INPUT
+//	gemini-2.5-pro (99% context left)                       IDLE
+type geminiParser struct{}
+
+func (p geminiParser) Classify(lines []string) (Status, bool) {
+	status, matched, _ := p.explain(lines, 0)
+	return status, matched
+}
+
+func (geminiParser) explain(lines []string, lookback int) (Status, bool, string) {
+	tail := tail(lines, lookback)
+	if line, ok := rateLimitLine(lines); ok {
+		return RateLimited, true, because("rate-limit message", line)
+	}
+	if line, ok := lineContaining(tail, "allow execution", "apply this change", "waiting for user confirmation", "yes, allow once"); ok {
+		return NeedsApproval, true, because("confirmation prompt", line)
+	}
+	if line, ok := lineContaining(tail, "esc to cancel"); ok {
+		return Running, true, because("cancel hint", line)
+	}
+	if line, ok := animatedLine(lines); ok {
+		return Running, true, because("spinner", line)
+	}
+	if line, ok := lineContaining(tail, "type your message", "context left"); ok {
+		return idleOrAsked(lines, line)
+	}
+	return classify(lines, lookback)
+}
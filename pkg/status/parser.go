@@ -0,0 +1,204 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Parser reads one agent's status from its screen. Each agent CLI draws
+// its own UI, so each gets its own parser; a change in one CLI's output then
+// only needs a change in its parser.
+type Parser interface {
+	// Classify returns the status and whether anything on screen showed
+	// it. When nothing did the status is Idle.
+	Classify(lines []string) (Status, bool)
+}
+
+// parsers holds the parsers by agent name.
+var parsers = map[string]Parser{
+	"claude": claudeParser{},
+	"codex":  codexParser{},
+	"gemini": geminiParser{},
+
+	"aider":        aiderParser,
+	"goose":        gooseParser,
+	"amp":          ampParser,
+	"opencode":     opencodeParser,
+	"cursor-agent": cursorAgentParser,
+	"openhands":    openhandsParser,
+	"qwen":         geminiParser{}, // Qwen Code is a fork of Gemini CLI
+}
+
+// ParserFor returns ai's parser, or the generic one for agents without
+// their own.
+func ParserFor(ai string) Parser {
+	if p, ok := parsers[ai]; ok {
+		return p
+	}
+	return genericParser{}
+}
+
+// ClassifyAgent is Classify with ai's parser.
+func ClassifyAgent(ai string, lines []string) (Status, bool) {
+	return ParserFor(ai).Classify(lines)
+}
+
+// explainer is a Parser that can say why it chose a status.
+type explainer interface {
+	explain(lines []string, lookback int) (Status, bool, string)
+}
+
+// ExplainAgent is ClassifyAgent that also says why: which of the parser's
+// rules decided, and the line it matched, e.g.
+//
+//	interrupt hint: "✻ Crunching… (12s · esc to interrupt)"
+func ExplainAgent(ai string, lines []string) (Status, bool, string) {
+	return ExplainAgentWithin(ai, lines, 0)
+}
+
+// ExplainAgentWithin is ExplainAgent looking for its phrases in the last
+// lookback lines of the screen, rather than the parser's own window of 10
+// to 15; 0 keeps the parser's.
+func ExplainAgentWithin(ai string, lines []string, lookback int) (Status, bool, string) {
+	p := ParserFor(ai)
+	if e, ok := p.(explainer); ok {
+		return e.explain(lines, lookback)
+	}
+	status, matched := p.Classify(lines)
+	return status, matched, ""
+}
+
+// genericParser is the keyword matching of Classify, for any agent.
+type genericParser struct{}
+
+func (genericParser) Classify(lines []string) (Status, bool) { return Classify(lines) }
+
+func (genericParser) explain(lines []string, lookback int) (Status, bool, string) {
+	return classify(lines, lookback)
+}
+
+// because gives the reason for a status: the rule, and the line that
+// matched it.
+func because(rule, line string) string {
+	if line = strings.TrimSpace(line); line == "" {
+		return rule
+	}
+	return fmt.Sprintf("%s: %q", rule, line)
+}
+
+// tail returns the last lookback lines as they are, or agentRecentLines
+// when lookback is 0.
+func tail(lines []string, lookback int) []string {
+	return last(lines, window(lookback, agentRecentLines))
+}
+
+// last returns the last n lines.
+func last(lines []string, n int) []string {
+	if len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// window is lookback, or def when it is 0.
+func window(lookback, def int) int {
+	if lookback > 0 {
+		return lookback
+	}
+	return def
+}
+
+// lineContaining returns the first line containing any of substrs, which
+// are lowercase, ignoring case.
+func lineContaining(lines []string, substrs ...string) (string, bool) {
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		for _, s := range substrs {
+			if strings.Contains(lower, s) {
+				return line, true
+			}
+		}
+	}
+	return "", false
+}
+
+// agentRecentLines is how far up the screen agent parsers look: the prompt
+// box and footer of a full-screen agent UI take several lines.
+const agentRecentLines = 15
+
+// recent is tail, lowercased and trimmed.
+func recent(lines []string, lookback int) []string {
+	lines = tail(lines, lookback)
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.ToLower(strings.TrimSpace(line))
+	}
+	return out
+}
+
+// anyPrefix reports whether any line starts with any of prefixes.
+func anyPrefix(lines []string, prefixes ...string) bool {
+	for _, line := range lines {
+		for _, p := range prefixes {
+			if strings.HasPrefix(line, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// promptLine returns the index of the agent's input prompt line, or -1.
+func promptLine(lines []string) int {
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimLeft(strings.TrimSpace(lines[i]), "│┃ ")
+		if strings.HasPrefix(line, ">") || strings.HasPrefix(line, "›") || strings.HasPrefix(line, "❯") {
+			return i
+		}
+	}
+	return -1
+}
+
+// questionAbove returns the agent's last message, the text above its
+// prompt line, when it asks the user a question.
+func questionAbove(lines []string) (string, bool) {
+	i := promptLine(lines)
+	if i < 0 {
+		return "", false
+	}
+	asked := strings.TrimLeft(LastMeaningfulLine(lines[:i]), "⏺●•✦ ")
+	return asked, question(asked)
+}
+
+// outputAbove returns the tool or command output the agent's turn ended on,
+// above its prompt line: the lines from the last tool-output marker, or
+// none when the agent wrote a message after it.
+func outputAbove(lines []string) []string {
+	i := promptLine(lines)
+	if i < 0 {
+		return nil
+	}
+	for j := i - 1; j >= 0; j-- {
+		switch {
+		case markedWith(lines[j], toolOutputMarkers):
+			return lines[j:i]
+		case markedWith(lines[j], messageMarkers):
+			return nil
+		}
+	}
+	return nil
+}
+
+// idleOrAsked is the status at an idle prompt: NEEDS_INPUT when the agent's
+// last message asks something, ERROR when its turn ended on a failed tool
+// or command, else IDLE because of the footer line.
+func idleOrAsked(lines []string, footer string) (Status, bool, string) {
+	asked, ok := questionAbove(lines)
+	if ok {
+		return NeedsInput, true, because("question above the prompt", asked)
+	}
+	if line, rule, ok := errorLine(outputAbove(lines)); ok {
+		return Error, true, because(rule+" above the prompt", line)
+	}
+	return Idle, true, because("prompt footer", footer)
+}
@@ -0,0 +1,100 @@
+package status
+
+import "testing"
+
+func TestClassifyAgent(t *testing.T) {
+	tests := []struct {
+		name  string
+		ai    string
+		lines []string
+		want  Status
+	}{
+		{"claude spinner", "claude", []string{"● Edit(main.go)", "✻ Crunching… (12s · ↑ 1.2k tokens · esc to interrupt)", "╭────╮", "│ >  │", "╰────╯", "  ? for shortcuts"}, Running},
+		{"claude menu", "claude", []string{"● Bash(rm -rf build)", " Do you want to proceed?", " ❯ 1. Yes", "   2. No"}, NeedsApproval},
+		{"claude prompt", "claude", []string{"● Done, tests pass.", "✻ Worked for 2m 3s", "│ >  │", "  ⏵⏵ accept edits on"}, Idle},
+		{"claude question", "claude", []string{"⏺ The tests pass. Should I also update the docs?", "╭────╮", "│ >  │", "╰────╯", "  ? for shortcuts"}, NeedsInput},
+		{"claude crashed", "claude", []string{"Traceback (most recent call last):", `  File "x.py", line 1`, "KeyError: 'x'", "$ "}, Error},
+		{"claude stopped on a failed build", "claude", []string{"⏺ Bash(make)", "  ⎿  Error: Exit code 2", "     make: *** [all] Error 2", "╭────╮", "│ >  │", "╰────╯", "  ? for shortcuts"}, Error},
+		// Its own messages about failures aren't errors
+		{"claude says tests failed before", "claude", []string{"⏺ Bash(go test ./...)", "  ⎿  ok  example.com/app  0.2s", "⏺ 3 tests failed before the fix; all pass now.", "╭────╮", "│ >  │", "╰────╯", "  ? for shortcuts"}, Idle},
+		{"claude explains a build failure", "claude", []string{"⏺ The build failed because the linker can't find -lssl.", "  Install libssl-dev and run make again.", "╭────╮", "│ >  │", "╰────╯", "  ? for shortcuts"}, Idle},
+		{"codex says a command failed", "codex", []string{"• Ran npm test", "  └ 12 passing", "• The command failed with exit code 1 earlier; it passes now.", "› ", "  ? for shortcuts       87% context left"}, Idle},
+		{"codex stopped on a failed command", "codex", []string{"• Ran npm test", "  └ npm ERR! command failed", "    npm ERR! exit code 1", "› ", "  ? for shortcuts       87% context left"}, Error},
+		{"claude recovered from a failed command", "claude", []string{"  ⎿  Error: Exit code 1", "⏺ Fixed the import; all tests pass now.", "│ >  │", "  ? for shortcuts"}, Idle},
+		{"claude retrying", "claude", []string{"● Edit(main.go)", "  ⎿  API Error (429 {\"type\":\"rate_limit_error\"}) · Retrying in 5 seconds… (attempt 2/10)", "✻ Crunching… (1m · esc to interrupt)", "│ >  │"}, RateLimited},
+		{"claude rate limit scrolled away", "claude", []string{"⎿ API Error: Overloaded", "● Read(a.go)", "● Read(b.go)", "● Read(c.go)", "● Edit(a.go)", "● Edit(b.go)", "✻ Crunching… (1m · esc to interrupt)"}, Running},
+		// "running" in its own output doesn't make Claude busy
+		{"claude output mentions running", "claude", []string{"The server is running on :8080.", "│ >  │", "  ? for shortcuts"}, Idle},
+		{"codex working", "codex", []string{"• Running tests", "• Working (12s • esc to interrupt)", "› ", "  87% context left"}, Running},
+		{"codex approval", "codex", []string{"Would you like to run the following command?", "$ make deploy", "› 1. Yes, proceed", "  2. No"}, NeedsApproval},
+		{"codex question", "codex", []string{"• Want me to open a PR as well?", "› ", "  ? for shortcuts       87% context left"}, NeedsInput},
+		{"codex quota", "codex", []string{"■ stream error: exceeded retry limit, last status: 429 Too Many Requests", "› ", "  ? for shortcuts"}, RateLimited},
+		{"codex footer", "codex", []string{"• Task completed", "› Implement {feature}", "  ? for shortcuts       87% context left"}, Idle},
+		{"gemini thinking", "gemini", []string{"⠋ Thinking... (esc to cancel, 5s)", ">   Type your message or @path/to/file"}, Running},
+		{"gemini spinner", "gemini", []string{"⣽ Refining the plan", ">   Type your message or @path/to/file", "gemini-2.5-pro (98% context left)"}, Running},
+		{"gemini confirm", "gemini", []string{"Shell rm -rf build", "Allow execution?", "● 1. Yes, allow once", "  2. No"}, NeedsApproval},
+		{"gemini question", "gemini", []string{"✦ Which port should it listen on?", ">   Type your message or @path/to/file", "gemini-2.5-pro (99% context left)"}, NeedsInput},
+		{"gemini quota", "gemini", []string{"✕ [API Error: Quota exceeded for quota metric 'Gemini 2.5 Pro Requests']", ">   Type your message or @path/to/file"}, RateLimited},
+		{"gemini prompt", "gemini", []string{"✦ All done.", ">   Type your message or @path/to/file", "gemini-2.5-pro (99% context left)"}, Idle},
+		{"claude spinner without its hint", "claude", []string{"● Read(a.go)", "✶ Pondering…", "│ >  │"}, Running},
+		{"aider confirm", "aider", []string{"src/app.py", "Add file to the chat? (Y)es/(N)o/(D)on't ask again [Yes]:"}, NeedsApproval},
+		{"aider waiting for the model", "aider", []string{"> fix the test", "░█ Waiting for claude-sonnet-4"}, Running},
+		{"aider prompt", "aider", []string{"Tokens: 5.2k sent, 312 received.", "Applied edit to app.py", "architect> "}, Idle},
+		{"goose tool call", "goose", []string{"─── shell | developer ───", "command: make test", "◇ Goose would like to call the above tool, do you allow?", "│ ● Allow / ○ Always Allow / ○ Deny"}, NeedsApproval},
+		{"goose prompt", "goose", []string{"All tests pass.", "( O)> "}, Idle},
+		{"opencode permission", "opencode", []string{"Permission required", "$ rm -rf dist", "enter accept  a accept always  esc reject"}, NeedsApproval},
+		{"opencode working", "opencode", []string{"┃ refactor the parser", "working...  esc interrupt"}, Running},
+		{"openhands confirm", "openhands", []string{"Action: CmdRunAction", "Proceed with action? (y)es/(n)o/(a)lways:"}, NeedsApproval},
+		{"openhands running", "openhands", []string{"Agent running... (Press Ctrl-P to pause)"}, Running},
+		{"qwen reads like gemini", "qwen", []string{"Shell rm -rf build", "Allow execution?", "● 1. Yes, allow once", "  2. No"}, NeedsApproval},
+		{"unknown agent", "mystery", []string{"Executing command..."}, Running},
+		{"fallback to generic", "claude", []string{"Task completed"}, Done},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := ClassifyAgent(tt.ai, tt.lines); got != tt.want {
+				t.Errorf("ClassifyAgent(%q) = %q, want %q", tt.ai, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainAgentWithin(t *testing.T) {
+	// The menu's question is 17 lines up, past the parser's own window
+	lines := []string{"Do you want to make this edit to main.go?", "❯ 1. Yes"}
+	for i := 0; i < 15; i++ {
+		lines = append(lines, "  + added line")
+	}
+	if got, _, _ := ExplainAgentWithin("claude", lines, 0); got == NeedsApproval {
+		t.Errorf("ExplainAgentWithin(0) = %s, want the default window to miss the menu", got)
+	}
+	if got, _, _ := ExplainAgentWithin("claude", lines, 20); got != NeedsApproval {
+		t.Errorf("ExplainAgentWithin(20) = %s, want %s", got, NeedsApproval)
+	}
+	if got, _, _ := ExplainAgentWithin("mystery", []string{"Executing command...", "", "", "$ "}, 2); got != Idle {
+		t.Errorf("ExplainAgentWithin(mystery, 2) = %s, want %s with the keyword out of reach", got, Idle)
+	}
+}
+
+func TestExplainAgent(t *testing.T) {
+	tests := []struct {
+		ai    string
+		lines []string
+		want  Status
+		why   string
+	}{
+		{"claude", []string{"● Edit(main.go)", "✻ Crunching… (12s · esc to interrupt)", "│ >  │"}, Running, `interrupt hint: "✻ Crunching… (12s · esc to interrupt)"`},
+		{"claude", []string{"⏺ Should I also update the docs?", "│ >  │", "  ? for shortcuts"}, NeedsInput, `question above the prompt: "Should I also update the docs?"`},
+		{"codex", []string{"• Done", "› ", "  87% context left"}, Idle, `prompt footer: "87% context left"`},
+		{"gemini", []string{"✕ [API Error: 429 RESOURCE_EXHAUSTED]", ">   Type your message"}, RateLimited, `rate-limit message: "✕ [API Error: 429 RESOURCE_EXHAUSTED]"`},
+		{"mystery", []string{"Executing command..."}, Running, `progress keyword: "Executing command..."`},
+		{"mystery", []string{"some output"}, Idle, "nothing matched; IDLE is the default"},
+		{"goose", []string{"( O)> "}, Idle, `prompt footer: "( O)>"`},
+	}
+	for _, tt := range tests {
+		got, _, why := ExplainAgent(tt.ai, tt.lines)
+		if got != tt.want || why != tt.why {
+			t.Errorf("ExplainAgent(%s, %q) = %s, %q; want %s, %q", tt.ai, tt.lines, got, why, tt.want, tt.why)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package status
+
+import "regexp"
+
+// Agent TUIs show they are working with an animated line rather than new
+// output: a braille spinner, a "✻ Thinking…" line whose glyph cycles, or a
+// progress bar. A screen showing one is RUNNING.
+
+var (
+	// "⠋ Thinking... (esc to cancel)", "⣾ Building"; U+2800, the blank
+	// pattern, isn't a frame
+	brailleSpinner = regexp.MustCompile(`^\s*[\x{2801}-\x{28FF}]\s*\pL`)
+	// "✻ Crunching…", "· Pondering...", "◐ Loading"; the finished
+	// "✻ Worked for 2m" has no ellipsis and doesn't count
+	glyphSpinner = regexp.MustCompile(`^\s*[✻✽✢✳✶✺·*◐◓◑◒◴◷◶◵]\s+\pL+(?:…|\.\.\.)`)
+	// "████████░░░░ 64%", "[=====>    ] 45%", "45% |████"
+	progressBar = regexp.MustCompile(`[█▉▊▋▌▍▎▏▓▒░]{3,}.*\d{1,3}%|\d{1,3}%.*[█▉▊▋▌▍▎▏▓▒░]{3,}|\[[=#>-]{2,}[ .>-]*\]`)
+)
+
+// spinnerLines is how far up the screen an animation counts: spinners and
+// progress bars sit at the bottom, just above the prompt box and footer.
+const spinnerLines = 8
+
+// Animated reports whether the bottom of the screen shows a spinner or a
+// progress bar.
+func Animated(lines []string) bool {
+	_, ok := animatedLine(lines)
+	return ok
+}
+
+// animatedLine returns the spinner or progress bar line of Animated.
+func animatedLine(lines []string) (string, bool) {
+	if len(lines) > spinnerLines {
+		lines = lines[len(lines)-spinnerLines:]
+	}
+	for _, line := range lines {
+		if brailleSpinner.MatchString(line) || glyphSpinner.MatchString(line) || progressBar.MatchString(line) {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// An animated line is repainted every frame: its glyph cycles, its timer
+// and token count tick, its bar grows. Compared frame by frame the screen
+// never stops changing, so lines are compared by FrameKey, which is the
+// same for every frame of one animation, and the frames a capture holds
+// one after another, such as a progress bar redrawn with \r, are collapsed
+// into the latest.
+
+var (
+	// "• Working (12s • esc to interrupt)": Codex's timer, without a spinner
+	workingTimer = regexp.MustCompile(`(?i)\(\d+[hms]\b.*\b(?:esc|ctrl\+c) to (?:interrupt|cancel)`)
+	frameGlyph   = regexp.MustCompile(`^(\s*)[\x{2801}-\x{28FF}✻✽✢✳✶✺·*◐◓◑◒◴◷◶◵]`)
+	frameBar     = regexp.MustCompile(`[█▉▊▋▌▍▎▏▓▒░]+|\[[=#>. -]*\]`)
+	// "12s", "1m 02s", "1.2k": a count with its unit
+	frameNumber = regexp.MustCompile(`\d[\d.,]*[kKmMhs]?(?:\s+\d[\d.,]*[kKmMhs]?)*`)
+)
+
+// FrameKey returns line with what changes between the frames of an
+// animation blanked out, or line itself when it isn't animated.
+func FrameKey(line string) string {
+	if !brailleSpinner.MatchString(line) && !glyphSpinner.MatchString(line) &&
+		!progressBar.MatchString(line) && !workingTimer.MatchString(line) {
+		return line
+	}
+	key := frameGlyph.ReplaceAllString(line, "$1*")
+	key = frameBar.ReplaceAllString(key, "█")
+	return frameNumber.ReplaceAllString(key, "#")
+}
+
+// FrameKeys returns the FrameKey of each line, to tell whether a screen
+// changed other than by animating.
+func FrameKeys(lines []string) []string {
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		keys[i] = FrameKey(line)
+	}
+	return keys
+}
+
+// CollapseFrames drops animated lines followed by another frame of the
+// same animation, keeping the latest.
+func CollapseFrames(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if i+1 < len(lines) {
+			if key := FrameKey(line); key != line && key == FrameKey(lines[i+1]) {
+				continue
+			}
+		}
+		out = append(out, line)
+	}
+	return out
+}
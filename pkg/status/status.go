@@ -0,0 +1,355 @@
+// Package status infers what an AI coding agent is doing from the text on
+// its screen.
+package status
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Status is what an agent is doing. The constants are the statuses lazyccg
+// infers itself; scripts, profiles, and status commands may report others.
+type Status string
+
+const (
+	Running Status = "RUNNING"
+	Idle    Status = "IDLE"
+	Waiting Status = "WAITING"
+	Done    Status = "DONE"
+	Exited  Status = "EXITED"
+	Unknown Status = "UNKNOWN" // output can't be read
+
+	// RateLimited means the provider's API turned the agent away: rate or
+	// usage limits, or overload. The agent is stalled, not working.
+	RateLimited Status = "RATE_LIMITED"
+
+	// WAITING, split by what the agent waits for, when that can be told.
+	NeedsApproval Status = "NEEDS_APPROVAL" // a permission prompt
+	NeedsInput    Status = "NEEDS_INPUT"    // a question for the user
+	Error         Status = "ERROR"          // stopped on an error
+)
+
+// IsWaiting reports whether s is WAITING or one of its sub-states: the agent
+// can't go on without the user.
+func (s Status) IsWaiting() bool {
+	switch s {
+	case Waiting, NeedsApproval, NeedsInput, Error:
+		return true
+	}
+	return false
+}
+
+// Parse normalizes a status reported as text, e.g. by a script.
+func Parse(s string) Status {
+	return Status(strings.ToUpper(strings.TrimSpace(s)))
+}
+
+func (s Status) String() string { return string(s) }
+
+// Source says how a status was determined.
+type Source string
+
+const (
+	SourceOutput   Source = "output"   // matched in the output text
+	SourceActivity Source = "activity" // output is changing
+	SourceProcess  Source = "process"  // the agent process exited
+	SourceProfile  Source = "profile"  // a detection profile rule
+	SourceRule     Source = "rule"     // a status rule in the config
+	SourceScript   Source = "script"   // a Lua detector
+	SourceCommand  Source = "command"  // an external status command
+	SourceHook     Source = "hook"     // an event sent by the agent's hooks
+	SourceBell     Source = "bell"     // the terminal bell rang in its window
+	SourceLog      Source = "log"      // the session log the agent writes
+	SourceNone     Source = "none"     // nothing to go on
+)
+
+// Confidence levels, from 0 to 1, for how sure a status is.
+const (
+	Certain   = 1.0
+	Likely    = 0.8
+	Guess     = 0.4 // no pattern matched; the default applies
+	NoSignals = 0.0
+)
+
+// Infer determines status from output content. It is meant for output that
+// hasn't changed since the last look; changing output means Running.
+func Infer(lines []string) Status {
+	status, _ := Classify(lines)
+	return status
+}
+
+// Classify is Infer that also reports whether any pattern matched; when
+// none does the status is Idle by default.
+func Classify(lines []string) (Status, bool) {
+	status, matched, _ := classify(lines, 0)
+	return status, matched
+}
+
+// classify is Classify that also says why, looking for keywords in the last
+// lookback lines, or the last genericRecentLines when it is 0.
+func classify(lines []string, lookback int) (Status, bool, string) {
+	if len(lines) == 0 {
+		return Idle, false, "no output"
+	}
+
+	lastLine := strings.TrimSpace(lines[len(lines)-1])
+	lastLineLower := strings.ToLower(lastLine)
+
+	recentLines := last(lines, window(lookback, genericRecentLines))
+
+	// RATE_LIMITED: turned away by the API
+	if line, ok := rateLimitLine(lines); ok {
+		return RateLimited, true, because("rate-limit message", line)
+	}
+
+	// ERROR: stopped on a crash or a failed command
+	if line, rule, ok := errorLine(recentLines); ok {
+		return Error, true, because(rule, line)
+	}
+
+	// WAITING: needs user confirmation
+	if line, ok := lineContaining(recentLines, "waiting", "approval", "confirm", "press enter"); ok {
+		return Waiting, true, because("confirmation keyword", line)
+	}
+
+	// NEEDS_INPUT: ends on a question
+	if question(lastLine) {
+		return NeedsInput, true, because("question on the last line", lastLine)
+	}
+
+	// RUNNING: a spinner or progress bar; checked before DONE, whose words
+	// may be left over from an earlier step
+	if line, ok := animatedLine(lines); ok {
+		return Running, true, because("spinner or progress bar", line)
+	}
+
+	// DONE: explicit completion signals, since the last prompt; one above
+	// it finished an earlier turn
+	if line, ok := lineContaining(sincePrompt(recentLines), "completed", "success"); ok {
+		return Done, true, because("completion keyword", line)
+	}
+
+	// RUNNING: explicit progress signals
+	if line, ok := lineContaining(recentLines, "running", "processing", "executing", "reading files"); ok {
+		return Running, true, because("progress keyword", line)
+	}
+
+	// IDLE: prompt waiting patterns
+	if lastLine == ">" || lastLine == ">>" ||
+		strings.HasPrefix(lastLine, "> ") ||
+		strings.HasPrefix(lastLine, "$ ") ||
+		strings.HasPrefix(lastLine, "% ") ||
+		strings.HasSuffix(lastLine, " >") ||
+		strings.Contains(lastLineLower, "context left") ||
+		strings.Contains(lastLineLower, "? for shortcuts") {
+		return Idle, true, because("prompt on the last line", lastLine)
+	}
+	if line, ok := lineContaining(recentLines, "accept edits", "crunched for", "brewed for", "worked for"); ok {
+		return Idle, true, because("finished-turn footer", line)
+	}
+
+	// Default to IDLE when output hasn't changed
+	return Idle, false, "nothing matched; IDLE is the default"
+}
+
+// genericRecentLines is how far up the screen classify looks for keywords.
+const genericRecentLines = 10
+
+// promptPrefixes are how a prompt the user submitted starts in scrollback.
+var promptPrefixes = []string{">", "›", "❯"}
+
+// sincePrompt returns the lines after the last prompt the user submitted,
+// e.g. "> fix the tests", or all of them when none shows. The last line,
+// the live input box, doesn't count.
+func sincePrompt(lines []string) []string {
+	for i := len(lines) - 2; i >= 0; i-- {
+		line := strings.TrimLeft(strings.TrimSpace(lines[i]), "│┃ ")
+		for _, p := range promptPrefixes {
+			if rest, ok := strings.CutPrefix(line, p); ok && strings.Trim(rest, "> ") != "" {
+				return lines[i+1:]
+			}
+		}
+	}
+	return lines
+}
+
+// rateLimitLines is how far up the screen a rate-limit message counts: once
+// the agent gets going again, its output pushes the message up.
+const rateLimitLines = 6
+
+var rateLimitMarkers = []string{
+	"rate limit", "rate_limit", "ratelimit", "too many requests",
+	"overloaded",
+	"quota exceeded", "exceeded your current quota", "resource_exhausted", "resource exhausted",
+	"usage limit reached",
+}
+
+// http429 matches the HTTP status of rate limiting, but not any 429 such as
+// a line number.
+var http429 = regexp.MustCompile(`(?:error|status|code|http)[ :]+429\b|\(429\)`)
+
+// RateLimitedOn reports whether the last lines show the agent being turned
+// away by its API.
+func RateLimitedOn(lines []string) bool {
+	_, ok := rateLimitLine(lines)
+	return ok
+}
+
+// rateLimitLine returns the line of RateLimitedOn's message, or "" when it
+// runs over several lines.
+func rateLimitLine(lines []string) (string, bool) {
+	if len(lines) > rateLimitLines {
+		lines = lines[len(lines)-rateLimitLines:]
+	}
+	limited := func(text string) bool {
+		for _, m := range rateLimitMarkers {
+			if strings.Contains(text, m) {
+				return true
+			}
+		}
+		return http429.MatchString(text)
+	}
+	for _, line := range lines {
+		if limited(strings.ToLower(line)) {
+			return line, true
+		}
+	}
+	return "", limited(strings.ToLower(strings.Join(lines, " ")))
+}
+
+// stackTraceMarkers start the stack traces and crash reports of common
+// runtimes.
+var stackTraceMarkers = []string{
+	"traceback (most recent call last)", // Python
+	"panic: ",                           // Go
+	"goroutine 1 [",
+	"uncaught exception", // Node.js, Ruby
+	"unhandled exception",
+	"exception in thread \"", // Java
+	"fatal error: ",
+}
+
+// stackTraceLine returns the line of lines that starts a stack trace.
+func stackTraceLine(lines []string) (string, bool) {
+	return lineContaining(lines, stackTraceMarkers...)
+}
+
+// failureSummary matches the summary of a command or build that failed:
+// "command failed", "Build failed", "exit code 1", "exited with status 2".
+var failureSummary = regexp.MustCompile(`(?i)\bcommand failed\b|\b(?:build|tests?|compilation) failed\b|\bexit(?:ed)?(?: with)? (?:code|status):? ?[1-9]\d*\b|\bnon-zero exit\b`)
+
+// messageMarkers start an agent's own messages and tool calls: Claude's ⏺
+// and ●, Codex's •, Gemini's ✦. toolOutputMarkers start what a tool call
+// printed under it: Claude's ⎿, Codex's └.
+var (
+	messageMarkers    = []string{"⏺", "●", "•", "✦"}
+	toolOutputMarkers = []string{"⎿", "└"}
+)
+
+// markedWith reports whether line, inside any box border, starts with one
+// of markers.
+func markedWith(line string, markers []string) bool {
+	line = strings.TrimLeft(line, "│┃ \t")
+	for _, m := range markers {
+		if strings.HasPrefix(line, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutMessages returns lines without the agent's own messages, leaving
+// what commands and tools printed. A message runs from its marker over the
+// indented lines under it, up to a tool's output.
+func withoutMessages(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	inMessage := false
+	for _, line := range lines {
+		switch {
+		case markedWith(line, toolOutputMarkers):
+			inMessage = false
+		case markedWith(line, messageMarkers):
+			inMessage = true
+		case inMessage && line != strings.TrimLeft(line, " \t"):
+			// The message goes on
+		default:
+			inMessage = false
+		}
+		if !inMessage {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// errorLine returns the line of lines that shows the agent stopped on an
+// error, and the rule it matched: a stack trace or a failure summary. Only
+// what commands and tools printed counts; the agent's messages say "tests
+// failed" about the past too.
+func errorLine(lines []string) (line, rule string, ok bool) {
+	lines = withoutMessages(lines)
+	if line, ok := stackTraceLine(lines); ok {
+		return line, "stack trace", true
+	}
+	for _, line := range lines {
+		if failureSummary.MatchString(line) {
+			return line, "failure summary", true
+		}
+	}
+	return "", "", false
+}
+
+// ErrorExcerpt returns the line that best says what went wrong at the bottom
+// of the screen: the failure summary, the panic, or, for a traceback, the
+// exception it ends on. It is "" when no error shows.
+func ErrorExcerpt(lines []string) string {
+	if len(lines) > errorLines {
+		lines = lines[len(lines)-errorLines:]
+	}
+	line, rule, ok := errorLine(lines)
+	if !ok {
+		return ""
+	}
+	if rule == "stack trace" && strings.Contains(strings.ToLower(line), "traceback") {
+		// Python ends on the exception: KeyError: 'x'
+		start := slices.Index(lines, line)
+		for i := len(lines) - 1; i > start; i-- {
+			if l := lines[i]; l != "" && !unicode.IsSpace(rune(l[0])) && strings.Contains(l, ":") {
+				return strings.TrimSpace(l)
+			}
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// errorLines is how far up the screen ErrorExcerpt looks, as classify does.
+const errorLines = 10
+
+// questionPrefixes start questions an agent asks the user.
+var questionPrefixes = []string{"do you want", "would you like", "should i ", "shall i "}
+
+// question reports whether line asks the user something.
+func question(line string) bool {
+	lower := strings.ToLower(line)
+	for _, p := range questionPrefixes {
+		if strings.HasPrefix(lower, p) {
+			return true
+		}
+	}
+	return strings.HasSuffix(lower, "?") || strings.HasSuffix(lower, "[y/n]") || strings.HasSuffix(lower, "(y/n)")
+}
+
+// LastMeaningfulLine returns the last line containing a letter or digit,
+// skipping box borders and separators drawn by agent TUIs.
+func LastMeaningfulLine(lines []string) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.IndexFunc(lines[i], func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsNumber(r)
+		}) >= 0 {
+			return strings.TrimSpace(lines[i])
+		}
+	}
+	return ""
+}
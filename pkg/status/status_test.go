@@ -0,0 +1,237 @@
+package status
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInfer(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  Status
+	}{
+		{
+			name:  "empty lines",
+			lines: []string{},
+			want:  "IDLE",
+		},
+		{
+			name:  "waiting for input",
+			lines: []string{"Processing...", "Waiting for user input"},
+			want:  "WAITING",
+		},
+		{
+			name:  "waiting for approval",
+			lines: []string{"Changes ready", "Press enter to approve"},
+			want:  "WAITING",
+		},
+		{
+			name:  "stack trace",
+			lines: []string{"goroutine 1 [running]:", "panic: runtime error: index out of range"},
+			want:  "ERROR",
+		},
+		{
+			name:  "failed command",
+			lines: []string{"$ npm run build", "npm ERR! command failed", "npm ERR! exit code 1"},
+			want:  "ERROR",
+		},
+		{
+			name:  "exit status",
+			lines: []string{"go test ./...", "FAIL github.com/x/y 0.2s", "exited with status 1"},
+			want:  "ERROR",
+		},
+		{
+			name:  "agent's message about a failure",
+			lines: []string{"⏺ 3 tests failed before the fix;", "  all pass now, and the build failed only on CI."},
+			want:  "IDLE",
+		},
+		{
+			name:  "tool output after the agent's message",
+			lines: []string{"⏺ Bash(npm run build)", "  ⎿  npm ERR! command failed"},
+			want:  "ERROR",
+		},
+		{
+			name:  "question",
+			lines: []string{"Plan ready.", "Do you want me to start with the parser"},
+			want:  "NEEDS_INPUT",
+		},
+		{
+			name:  "question answered by a prompt",
+			lines: []string{"Continue?", "$ "},
+			want:  "IDLE",
+		},
+		{
+			name:  "done",
+			lines: []string{"Task completed successfully"},
+			want:  "DONE",
+		},
+		{
+			name:  "done since the last prompt",
+			lines: []string{"> run the migrations", "Migration completed", "> "},
+			want:  "DONE",
+		},
+		{
+			name:  "completion from an earlier turn",
+			lines: []string{"Build completed", "> now update the changelog", "Editing CHANGELOG.md", "> "},
+			want:  "IDLE",
+		},
+		{
+			name:  "spinner over old completion",
+			lines: []string{"Step 1 completed", "⠙ Compiling"},
+			want:  "RUNNING",
+		},
+		{
+			name:  "running",
+			lines: []string{"Executing command...", "Reading files..."},
+			want:  "RUNNING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Infer(tt.lines)
+			if got != tt.want {
+				t.Errorf("Infer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	if got, ok := Classify([]string{"some output", "more output"}); got != Idle || ok {
+		t.Errorf("Classify() = (%q, %v), want the unmatched default (IDLE, false)", got, ok)
+	}
+	if got, ok := Classify([]string{"> "}); got != Idle || !ok {
+		t.Errorf("Classify() = (%q, %v) for a prompt, want (IDLE, true)", got, ok)
+	}
+}
+
+func TestRateLimitedOn(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"Error: 429 Too Many Requests", true},
+		{"HTTP 429", true},
+		{"anthropic: overloaded_error", true},
+		{"You exceeded your current quota", true},
+		{"main.go:429: undefined: foo", false},
+		{"Fixed 429 lint warnings", false},
+	}
+	for _, tt := range tests {
+		if got := RateLimitedOn([]string{tt.line}); got != tt.want {
+			t.Errorf("RateLimitedOn(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestAnimated(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"⠋ Thinking... (esc to cancel, 5s)", true},
+		{"  ⣾ Installing dependencies", true},
+		{"✻ Crunching… (12s · esc to interrupt)", true},
+		{"◐ Loading...", true},
+		{"████████░░░░ 64%", true},
+		{"[=====>    ] 45%", true},
+		{"✻ Worked for 2m 3s", false},
+		{"⠀ blank braille", false},
+		{"Coverage: 64%", false},
+		{"> ", false},
+	}
+	for _, tt := range tests {
+		if got := Animated([]string{tt.line}); got != tt.want {
+			t.Errorf("Animated(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+	// A spinner scrolled up past the bottom of the screen has stopped
+	lines := []string{"⠋ Thinking..."}
+	for range spinnerLines {
+		lines = append(lines, "output")
+	}
+	if Animated(lines) {
+		t.Error("Animated() = true for a spinner above the last lines")
+	}
+}
+
+func TestErrorExcerpt(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"traceback", []string{"Traceback (most recent call last):", `  File "x.py", line 1, in <module>`, "    d['x']", "KeyError: 'x'", "$ "}, "KeyError: 'x'"},
+		{"go panic", []string{"panic: runtime error: index out of range [3] with length 3", "", "goroutine 1 [running]:", "main.main()"}, "panic: runtime error: index out of range [3] with length 3"},
+		{"exit code", []string{"$ make deploy", "  make: *** [deploy] Error 1", "  Process exited with code 2"}, "Process exited with code 2"},
+		{"exit code 0", []string{"Process exited with code 0"}, ""},
+		{"no error", []string{"All tests pass.", "> "}, ""},
+		{"agent's message", []string{"⏺ Bash(make)", "  ⎿  ok", "⏺ The build failed before; it passes now.", "> "}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorExcerpt(tt.lines); got != tt.want {
+				t.Errorf("ErrorExcerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrameKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"✻ Crunching… (12s · ↑ 340 tokens · esc to interrupt)", "✶ Crunching… (13s · ↑ 1.2k tokens · esc to interrupt)", true},
+		{"⠋ Thinking... (esc to cancel, 5s)", "⠙ Thinking... (esc to cancel, 6s)", true},
+		{"• Working (12s • esc to interrupt)", "• Working (1m 02s • esc to interrupt)", true},
+		{"████░░░░░░░░ 34%", "████████░░░░ 64%", true},
+		{"[==>       ] 25%", "[=====>    ] 45%", true},
+		{"✻ Crunching… (12s · esc to interrupt)", "✻ Reading files… (12s · esc to interrupt)", false},
+		{"ran 12 tests", "ran 13 tests", false},
+	}
+	for _, tt := range tests {
+		if got := FrameKey(tt.a) == FrameKey(tt.b); got != tt.same {
+			t.Errorf("FrameKey(%q) == FrameKey(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestCollapseFrames(t *testing.T) {
+	lines := []string{"npm install", "[=>    ] 10%", "[==>   ] 40%", "[=====>] 100%", "added 312 packages", "ok", "ok"}
+	want := []string{"npm install", "[=====>] 100%", "added 312 packages", "ok", "ok"}
+	if got := CollapseFrames(lines); !slices.Equal(got, want) {
+		t.Errorf("CollapseFrames() = %q, want %q", got, want)
+	}
+}
+
+func TestIsWaiting(t *testing.T) {
+	for _, s := range []Status{Waiting, NeedsApproval, NeedsInput, Error} {
+		if !s.IsWaiting() {
+			t.Errorf("%s.IsWaiting() = false, want true", s)
+		}
+	}
+	for _, s := range []Status{Running, Idle, Done, Exited, RateLimited, "BLOCKED"} {
+		if s.IsWaiting() {
+			t.Errorf("%s.IsWaiting() = true, want false", s)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if got := Parse(" blocked\n"); got != "BLOCKED" {
+		t.Errorf("Parse() = %q, want BLOCKED", got)
+	}
+}
+
+func TestLastMeaningfulLine(t *testing.T) {
+	lines := []string{"Compiling...", "  Tests passed  ", "╰──────╯", "───"}
+	if got := LastMeaningfulLine(lines); got != "Tests passed" {
+		t.Errorf("LastMeaningfulLine() = %q, want %q", got, "Tests passed")
+	}
+	if got := LastMeaningfulLine(nil); got != "" {
+		t.Errorf("LastMeaningfulLine(nil) = %q, want empty", got)
+	}
+}